@@ -0,0 +1,253 @@
+package servermanager
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// ChampionshipCountedResults are rules for which rounds count towards the driver standings of a Championship, e.g.
+// "best 8 of 10 rounds count".
+type ChampionshipCountedResults struct {
+	// DropWorstRounds is the number of each driver's worst rounds which do not count.
+	DropWorstRounds int
+
+	// CountBestRounds is the number of each driver's best rounds which count. 0 counts every round.
+	CountBestRounds int
+
+	// KeepFinalRound stops the final round of the Championship from being dropped.
+	KeepFinalRound bool
+
+	// DropDisqualifications allows rounds that a driver was disqualified from to be dropped.
+	DropDisqualifications bool
+}
+
+// Enabled is true if any rounds can be dropped.
+func (cr ChampionshipCountedResults) Enabled() bool {
+	return cr.DropWorstRounds > 0 || cr.CountBestRounds > 0
+}
+
+// Summary describes the rules to drivers.
+func (cr ChampionshipCountedResults) Summary() string {
+	var summary string
+
+	switch {
+	case cr.DropWorstRounds > 0 && cr.CountBestRounds > 0:
+		summary = fmt.Sprintf("Each driver's worst %d rounds are dropped, and at most their best %d rounds count", cr.DropWorstRounds, cr.CountBestRounds)
+	case cr.DropWorstRounds > 0:
+		summary = fmt.Sprintf("Each driver's worst %d rounds are dropped", cr.DropWorstRounds)
+	case cr.CountBestRounds > 0:
+		summary = fmt.Sprintf("Each driver's best %d rounds count", cr.CountBestRounds)
+	default:
+		return "Every round counts"
+	}
+
+	if cr.KeepFinalRound {
+		summary += ". The final round can't be dropped"
+	}
+
+	if !cr.DropDisqualifications {
+		summary += ". Rounds a driver was disqualified from can't be dropped"
+	}
+
+	return summary + "."
+}
+
+// numRoundsToDrop is how many rounds are dropped for each driver after numRounds of the numSeasonRounds rounds of the
+// championship have been completed. Each driver's best (numSeasonRounds - DropWorstRounds) rounds count, so no rounds
+// are dropped until more rounds than that have been completed.
+func (cr ChampionshipCountedResults) numRoundsToDrop(numRounds, numSeasonRounds int) int {
+	numCounted := numSeasonRounds - cr.DropWorstRounds
+
+	if cr.CountBestRounds > 0 && cr.CountBestRounds < numCounted {
+		numCounted = cr.CountBestRounds
+	}
+
+	if numCounted < 0 {
+		numCounted = 0
+	}
+
+	return numRounds - numCounted
+}
+
+// championshipRound is a completed event of a championship. Each session of a race weekend is scored as its own event,
+// but they are all part of the same round.
+type championshipRound struct {
+	ID     uuid.UUID
+	Number int
+	Name   string
+	Final  bool
+
+	// eventIDs are the IDs of the (race weekend session) events which make up the round.
+	eventIDs map[uuid.UUID]bool
+}
+
+// championshipRounds are the rounds of inEvents which have at least one completed session, in championship order.
+func championshipRounds(championship *Championship, inEvents []*ChampionshipEvent) []*championshipRound {
+	var rounds []*championshipRound
+
+	for _, event := range inEvents {
+		if round, completed := newChampionshipRound(championship, event); completed {
+			rounds = append(rounds, round)
+		}
+	}
+
+	sort.SliceStable(rounds, func(i, j int) bool {
+		return rounds[i].Number < rounds[j].Number
+	})
+
+	return rounds
+}
+
+// championshipSeasonRounds are every round of the championship, whether they have been completed or not.
+func championshipSeasonRounds(championship *Championship) []*championshipRound {
+	var rounds []*championshipRound
+
+	for _, event := range championship.Events {
+		round, _ := newChampionshipRound(championship, event)
+
+		rounds = append(rounds, round)
+	}
+
+	return rounds
+}
+
+// newChampionshipRound creates the round for an event of the championship. completed is true if at least one of its
+// sessions has been completed.
+func newChampionshipRound(championship *Championship, event *ChampionshipEvent) (round *championshipRound, completed bool) {
+	round = &championshipRound{
+		ID:       event.ID,
+		Name:     prettifyName(event.RaceSetup.Track, false),
+		eventIDs: make(map[uuid.UUID]bool),
+	}
+
+	for i, championshipEvent := range championship.Events {
+		if championshipEvent.ID == event.ID {
+			round.Number = i + 1
+			round.Final = i == len(championship.Events)-1
+		}
+	}
+
+	if round.Number > 0 {
+		round.Name = fmt.Sprintf("Round %d (%s)", round.Number, round.Name)
+	}
+
+	for _, roundEvent := range ExtractRaceWeekendSessionsIntoIndividualEvents([]*ChampionshipEvent{event}) {
+		round.eventIDs[roundEvent.ID] = true
+
+		for _, session := range roundEvent.Sessions {
+			if session.Completed() && session.Results != nil {
+				completed = true
+			}
+		}
+	}
+
+	return round, completed
+}
+
+// roundForEvent finds the round that an event (or race weekend session) is part of.
+func roundForEvent(rounds []*championshipRound, eventID uuid.UUID) *championshipRound {
+	for _, round := range rounds {
+		if round.eventIDs[eventID] {
+			return round
+		}
+	}
+
+	return nil
+}
+
+// ChampionshipDroppedRound is a round which does not count towards a driver's points.
+type ChampionshipDroppedRound struct {
+	RoundID uuid.UUID
+	Name    string
+	Points  float64
+}
+
+// dropRounds works out which of a driver's rounds are dropped, given the points they scored in each round of a
+// championship of numSeasonRounds rounds and the rounds they were disqualified from. Rounds the driver missed score 0 points, so they are dropped first. If rounds
+// scored the same points, the earliest is dropped.
+func (cr ChampionshipCountedResults) dropRounds(rounds []*championshipRound, numSeasonRounds int, points map[uuid.UUID]float64, disqualified map[uuid.UUID]bool) []*ChampionshipDroppedRound {
+	numToDrop := cr.numRoundsToDrop(len(rounds), numSeasonRounds)
+
+	if numToDrop <= 0 {
+		return nil
+	}
+
+	var droppable []*championshipRound
+
+	for _, round := range rounds {
+		if (cr.KeepFinalRound && round.Final) || (!cr.DropDisqualifications && disqualified[round.ID]) {
+			continue
+		}
+
+		droppable = append(droppable, round)
+	}
+
+	sort.SliceStable(droppable, func(i, j int) bool {
+		return points[droppable[i].ID] < points[droppable[j].ID]
+	})
+
+	if numToDrop > len(droppable) {
+		numToDrop = len(droppable)
+	}
+
+	var dropped []*ChampionshipDroppedRound
+
+	for _, round := range droppable[:numToDrop] {
+		dropped = append(dropped, &ChampionshipDroppedRound{
+			RoundID: round.ID,
+			Name:    round.Name,
+			Points:  points[round.ID],
+		})
+	}
+
+	sort.Slice(dropped, func(i, j int) bool {
+		return roundNumber(rounds, dropped[i].RoundID) < roundNumber(rounds, dropped[j].RoundID)
+	})
+
+	return dropped
+}
+
+func roundNumber(rounds []*championshipRound, roundID uuid.UUID) int {
+	for i, round := range rounds {
+		if round.ID == roundID {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// roundsDisqualifiedFrom finds the rounds each driver was disqualified from in any session.
+func roundsDisqualifiedFrom(rounds []*championshipRound, events []*ChampionshipEvent) map[string]map[uuid.UUID]bool {
+	disqualified := make(map[string]map[uuid.UUID]bool)
+
+	for _, event := range events {
+		round := roundForEvent(rounds, event.ID)
+
+		if round == nil {
+			continue
+		}
+
+		for _, session := range event.Sessions {
+			if !session.Completed() || session.Results == nil {
+				continue
+			}
+
+			for _, result := range session.Results.Result {
+				if !result.Disqualified {
+					continue
+				}
+
+				if _, ok := disqualified[result.DriverGUID]; !ok {
+					disqualified[result.DriverGUID] = make(map[uuid.UUID]bool)
+				}
+
+				disqualified[result.DriverGUID][round.ID] = true
+			}
+		}
+	}
+
+	return disqualified
+}
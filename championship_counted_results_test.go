@@ -0,0 +1,167 @@
+package servermanager
+
+import (
+	"testing"
+)
+
+func standingsPoints(standings []*ChampionshipStanding) map[string]float64 {
+	points := make(map[string]float64)
+
+	for _, standing := range standings {
+		points[standing.Car.Driver.GUID] = standing.Points
+	}
+
+	return points
+}
+
+func TestChampionshipClass_StandingsCountedResults(t *testing.T) {
+	orders := [][]string{
+		{"A", "B"},
+		{"B", "DSQ:A"},
+		{"A", "B"},
+	}
+
+	for _, testCase := range []struct {
+		name           string
+		countedResults ChampionshipCountedResults
+		expected       map[string]float64
+	}{
+		{
+			name:     "Every round counts",
+			expected: map[string]float64{"A": 50, "B": 61},
+		},
+		{
+			name:           "Disqualifications can't be dropped",
+			countedResults: ChampionshipCountedResults{DropWorstRounds: 1},
+			expected:       map[string]float64{"A": 25, "B": 43},
+		},
+		{
+			name:           "Disqualifications can be dropped",
+			countedResults: ChampionshipCountedResults{DropWorstRounds: 1, DropDisqualifications: true},
+			expected:       map[string]float64{"A": 50, "B": 43},
+		},
+		{
+			name:           "Best round counts",
+			countedResults: ChampionshipCountedResults{CountBestRounds: 1, DropDisqualifications: true},
+			expected:       map[string]float64{"A": 25, "B": 25},
+		},
+		{
+			name:           "Final round can't be dropped",
+			countedResults: ChampionshipCountedResults{CountBestRounds: 1, KeepFinalRound: true, DropDisqualifications: true},
+			expected:       map[string]float64{"A": 25, "B": 18},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			championship, class := newTestChampionship().CountedResults(testCase.countedResults).Places(25, 18).Races(orders...).Build()
+
+			standings := class.Standings(championship, championship.Events)
+			points := standingsPoints(standings)
+
+			for guid, expected := range testCase.expected {
+				if points[guid] != expected {
+					t.Errorf("Expected %s to have %.0f points, got %.0f", guid, expected, points[guid])
+				}
+			}
+
+			for _, standing := range standings {
+				var counted float64
+
+				for _, award := range standing.Awards {
+					if !award.Dropped {
+						counted += award.Points
+					}
+				}
+
+				if counted != standing.Points {
+					t.Errorf("Expected the counted awards of %s to add up to %.0f, got %.0f", standing.Car.Driver.GUID, standing.Points, counted)
+				}
+			}
+
+			// dropped rounds never apply to the standings of a single event
+			eventPoints := standingsPoints(class.StandingsForEvent(championship, championship.Events[0]))
+
+			if eventPoints["A"] != 25 || eventPoints["B"] != 18 {
+				t.Errorf("Expected event standings to be unaffected, got %v", eventPoints)
+			}
+		})
+	}
+
+	t.Run("Rounds are only dropped once too few rounds remain", func(t *testing.T) {
+		for _, testCase := range []struct {
+			name           string
+			countedResults ChampionshipCountedResults
+			numCompleted   int
+			expected       map[string]float64
+		}{
+			{
+				name:           "Worst round dropped, after 1 round",
+				countedResults: ChampionshipCountedResults{DropWorstRounds: 1, DropDisqualifications: true},
+				numCompleted:   1,
+				expected:       map[string]float64{"A": 25, "B": 18},
+			},
+			{
+				name:           "Worst round dropped, after 2 rounds",
+				countedResults: ChampionshipCountedResults{DropWorstRounds: 1, DropDisqualifications: true},
+				numCompleted:   2,
+				expected:       map[string]float64{"A": 25, "B": 43},
+			},
+			{
+				name:           "Best round counts, after 1 round",
+				countedResults: ChampionshipCountedResults{CountBestRounds: 1, DropDisqualifications: true},
+				numCompleted:   1,
+				expected:       map[string]float64{"A": 25, "B": 18},
+			},
+			{
+				name:           "Best round counts, after 2 rounds",
+				countedResults: ChampionshipCountedResults{CountBestRounds: 1, DropDisqualifications: true},
+				numCompleted:   2,
+				expected:       map[string]float64{"A": 25, "B": 25},
+			},
+		} {
+			t.Run(testCase.name, func(t *testing.T) {
+				championship, class := newTestChampionship().CountedResults(testCase.countedResults).Places(25, 18).
+					Races(orders[:testCase.numCompleted]...).
+					RemainingRaces(len(orders) - testCase.numCompleted).
+					Build()
+
+				points := standingsPoints(class.Standings(championship, championship.Events))
+
+				for guid, expected := range testCase.expected {
+					if points[guid] != expected {
+						t.Errorf("Expected %s to have %.0f points, got %.0f", guid, expected, points[guid])
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Dropped rounds are reported", func(t *testing.T) {
+		championship, class := newTestChampionship().CountedResults(ChampionshipCountedResults{DropWorstRounds: 1}).Places(25, 18).Races(orders...).Build()
+
+		for _, standing := range class.Standings(championship, championship.Events) {
+			if standing.Car.Driver.GUID != "B" {
+				continue
+			}
+
+			if len(standing.DroppedRounds) != 1 || standing.DroppedRounds[0].RoundID != championship.Events[0].ID || standing.DroppedRounds[0].Points != 18 || standing.DroppedRounds[0].Name != "Round 1 (Monza)" {
+				t.Errorf("Incorrect dropped rounds: %+v", standing.DroppedRounds)
+			}
+		}
+	})
+
+	t.Run("Ties are broken by counted results", func(t *testing.T) {
+		// every driver has a win, a second and a third, so they're tied on every round. with their worst round
+		// dropped, B is the only driver whose counted results don't include a second place.
+		championship, class := newTestChampionship().CountedResults(ChampionshipCountedResults{DropWorstRounds: 1}).Places(10, 5, 5).
+			Race("A", "B", "C").
+			Race("B", "C", "A").
+			Race("C", "A", "B").
+			Build()
+
+		standings := class.Standings(championship, championship.Events)
+
+		if len(standings) != 3 || standings[2].Car.Driver.GUID != "B" || standings[2].Points != 15 {
+			t.Errorf("Expected B to be last on counted results, got: %s", standings[2].Car.Driver.GUID)
+		}
+	})
+}
@@ -0,0 +1,428 @@
+package servermanager
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrGridPenaltyNotFound = errors.New("servermanager: grid penalty not found")
+
+// A GridPenalty moves a Championship entrant back on the grid of the next event that they start. GridPenalties are
+// either given in the results of a Championship event (as a Grid Drop Penalty), or added to the Championship by hand.
+type GridPenalty struct {
+	ID      uuid.UUID
+	Created time.Time
+
+	DriverGUID string
+	DriverName string
+	Places     int
+	Reason     string
+	Steward    string
+
+	// EventID is the event that the penalty was given in, if any. A penalty is never applied to the event it was
+	// given in.
+	EventID uuid.UUID
+	// PenaltyID and SessionFile are set if the penalty was given in the results of a session.
+	PenaltyID   uuid.UUID
+	SessionFile string
+
+	// AppliedEventID is the event that the penalty was applied to. uuid.Nil means that the penalty is still pending.
+	AppliedEventID   uuid.UUID
+	AppliedEventName string
+	Applied          time.Time
+}
+
+func (gp *GridPenalty) IsPending() bool {
+	return gp.AppliedEventID == uuid.Nil
+}
+
+// appliesTo reports whether the penalty should be applied to the grid of an event, either because it is pending or
+// because it has already been applied to that event (e.g. if the event is restarted).
+func (gp *GridPenalty) appliesTo(eventID uuid.UUID) bool {
+	if gp.IsPending() {
+		return gp.EventID != eventID
+	}
+
+	return gp.AppliedEventID == eventID
+}
+
+type GridPenalties []*GridPenalty
+
+// Pending returns the penalties which have not yet been applied.
+func (gps GridPenalties) Pending() GridPenalties {
+	var out GridPenalties
+
+	for _, penalty := range gps {
+		if penalty.IsPending() {
+			out = append(out, penalty)
+		}
+	}
+
+	return out
+}
+
+// PlacesForEvent returns the number of grid places each driver should be moved back in an event, by driver GUID.
+func (gps GridPenalties) PlacesForEvent(eventID uuid.UUID) map[string]int {
+	places := make(map[string]int)
+
+	for _, penalty := range gps {
+		if penalty.appliesTo(eventID) {
+			places[penalty.DriverGUID] += penalty.Places
+		}
+	}
+
+	return places
+}
+
+// markApplied marks any pending penalties which apply to the event as applied.
+func (gps GridPenalties) markApplied(eventID uuid.UUID, eventName string) {
+	for _, penalty := range gps {
+		if penalty.IsPending() && penalty.appliesTo(eventID) {
+			penalty.AppliedEventID = eventID
+			penalty.AppliedEventName = eventName
+			penalty.Applied = time.Now()
+		}
+	}
+}
+
+// sync adds the grid drop penalties given in the results of an event, and removes pending penalties from those
+// results which have since been revoked. Penalties which have already been applied are kept.
+func (gps GridPenalties) sync(eventID uuid.UUID, results *SessionResults) GridPenalties {
+	active := make(map[uuid.UUID]bool)
+
+	for _, result := range results.Result {
+		for _, penalty := range result.ActivePenalties() {
+			if penalty.Type != PenaltyTypeGridDrop {
+				continue
+			}
+
+			active[penalty.ID] = true
+
+			if gps.findByPenaltyID(penalty.ID) != nil {
+				continue
+			}
+
+			gps = append(gps, &GridPenalty{
+				ID:          uuid.New(),
+				Created:     penalty.Created,
+				DriverGUID:  result.DriverGUID,
+				DriverName:  result.DriverName,
+				Places:      penalty.GridPlaces,
+				Reason:      penalty.Reason,
+				Steward:     penalty.Steward,
+				EventID:     eventID,
+				PenaltyID:   penalty.ID,
+				SessionFile: results.SessionFile,
+			})
+		}
+	}
+
+	var out GridPenalties
+
+	for _, penalty := range gps {
+		if penalty.IsPending() && penalty.SessionFile == results.SessionFile && penalty.PenaltyID != uuid.Nil && !active[penalty.PenaltyID] {
+			continue
+		}
+
+		out = append(out, penalty)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Created.Before(out[j].Created)
+	})
+
+	return out
+}
+
+func (gps GridPenalties) findByPenaltyID(id uuid.UUID) *GridPenalty {
+	for _, penalty := range gps {
+		if penalty.PenaltyID == id {
+			return penalty
+		}
+	}
+
+	return nil
+}
+
+// gridOrderWithPenalties returns the order of a grid once cars have been moved back, as indexes of the original grid.
+// places gives the number of places to move back the car in each grid position. Penalised cars are taken out of the
+// grid, then put back in the position they were moved back to (but no further than the back of the grid), in order of
+// that position then their original position.
+func gridOrderWithPenalties(places []int) []int {
+	var order, penalised []int
+
+	for i, numPlaces := range places {
+		if numPlaces > 0 {
+			penalised = append(penalised, i)
+		} else {
+			order = append(order, i)
+		}
+	}
+
+	target := func(i int) int {
+		if i+places[i] >= len(places) {
+			return len(places) - 1
+		}
+
+		return i + places[i]
+	}
+
+	sort.SliceStable(penalised, func(i, j int) bool {
+		return target(penalised[i]) < target(penalised[j])
+	})
+
+	for _, car := range penalised {
+		pos := target(car)
+
+		if pos > len(order) {
+			pos = len(order)
+		}
+
+		order = append(order[:pos], append([]int{car}, order[pos:]...)...)
+	}
+
+	return order
+}
+
+// gridPlacesForGUID returns the grid penalty for a car, which may be shared between drivers.
+func gridPlacesForGUID(places map[string]int, guid string) int {
+	total := 0
+
+	for _, guid := range strings.Split(guid, driverSwapEntrantSeparator) {
+		total += places[guid]
+	}
+
+	return total
+}
+
+// ApplyGridPenalties moves entrants back on the grid by the number of places given for their GUID, reassigning
+// pit boxes in the new grid order.
+func (e EntryList) ApplyGridPenalties(places map[string]int) {
+	if len(places) == 0 {
+		return
+	}
+
+	entrants := e.AsSlice()
+	entrantPlaces := make([]int, len(entrants))
+
+	for i, entrant := range entrants {
+		entrantPlaces[i] = gridPlacesForGUID(places, entrant.GUID)
+	}
+
+	for k := range e {
+		delete(e, k)
+	}
+
+	for pitBox, i := range gridOrderWithPenalties(entrantPlaces) {
+		e.AddInPitBox(entrants[i], pitBox)
+	}
+}
+
+// applyGridPenalties returns the entrants in grid order, with entrants moved back by the number of places given for
+// their GUID. The entrants themselves are not reordered.
+func applyGridPenalties(entrants []*RaceWeekendSessionEntrant, places map[string]int) []*RaceWeekendSessionEntrant {
+	if len(places) == 0 {
+		return entrants
+	}
+
+	entrantPlaces := make([]int, len(entrants))
+
+	for i, entrant := range entrants {
+		entrantPlaces[i] = gridPlacesForGUID(places, entrant.Car.GetGUID())
+	}
+
+	grid := make([]*RaceWeekendSessionEntrant, len(entrants))
+
+	for pos, i := range gridOrderWithPenalties(entrantPlaces) {
+		grid[pos] = entrants[i]
+	}
+
+	return grid
+}
+
+// sessionGridPenalties returns the grid drops given to each driver in the results of a session.
+func sessionGridPenalties(sessionResults []*RaceWeekendSessionEntrant) map[string]int {
+	places := make(map[string]int)
+
+	for _, entrant := range sessionResults {
+		if entrant.EntrantResult == nil {
+			continue
+		}
+
+		if gridPenalty := entrant.EntrantResult.GridPenalty(); gridPenalty > 0 {
+			places[entrant.EntrantResult.DriverGUID] += gridPenalty
+		}
+	}
+
+	return places
+}
+
+// championshipGridPenalties returns the grid places for each driver from the grid penalties of the linked
+// Championship.
+func (rw *RaceWeekend) championshipGridPenalties() map[string]int {
+	if !rw.HasLinkedChampionship() || rw.Championship == nil {
+		return nil
+	}
+
+	event := rw.Championship.eventForRaceWeekend(rw.ID)
+
+	if event == nil {
+		return nil
+	}
+
+	return rw.Championship.GridPenalties.PlacesForEvent(event.ID)
+}
+
+func (c *Championship) eventForRaceWeekend(raceWeekendID uuid.UUID) *ChampionshipEvent {
+	for _, event := range c.Events {
+		if event.RaceWeekendID == raceWeekendID {
+			return event
+		}
+	}
+
+	return nil
+}
+
+// AddGridPenalty adds a grid penalty by hand to a Championship entrant, to be applied at the next event they start.
+func (cm *ChampionshipManager) AddGridPenalty(championshipID, driverGUID string, places int, reason, steward string) error {
+	championship, err := cm.LoadChampionship(championshipID)
+
+	if err != nil {
+		return err
+	}
+
+	if places <= 0 {
+		return ErrInvalidPenalty
+	}
+
+	var driverName string
+
+	for _, entrant := range championship.AllEntrants() {
+		if entrant.GUID == driverGUID {
+			driverName = entrant.Name
+			break
+		}
+	}
+
+	if driverName == "" {
+		return ErrPenaltyDriverNotFound
+	}
+
+	championship.GridPenalties = append(championship.GridPenalties, &GridPenalty{
+		ID:         uuid.New(),
+		Created:    time.Now(),
+		DriverGUID: driverGUID,
+		DriverName: driverName,
+		Places:     places,
+		Reason:     reason,
+		Steward:    steward,
+	})
+
+	return cm.UpsertChampionship(championship)
+}
+
+// DeleteGridPenalty removes a grid penalty from a Championship.
+func (cm *ChampionshipManager) DeleteGridPenalty(championshipID, gridPenaltyID string) error {
+	championship, err := cm.LoadChampionship(championshipID)
+
+	if err != nil {
+		return err
+	}
+
+	for i, penalty := range championship.GridPenalties {
+		if penalty.ID.String() == gridPenaltyID {
+			championship.GridPenalties = append(championship.GridPenalties[:i], championship.GridPenalties[i+1:]...)
+
+			return cm.UpsertChampionship(championship)
+		}
+	}
+
+	return ErrGridPenaltyNotFound
+}
+
+// applyEventGridPenalties applies the Championship's grid penalties to the entry list of an event, and marks them
+// as applied.
+func (cm *ChampionshipManager) applyEventGridPenalties(championship *Championship, event *ChampionshipEvent, entryList EntryList) error {
+	places := championship.GridPenalties.PlacesForEvent(event.ID)
+
+	if len(places) == 0 {
+		return nil
+	}
+
+	logrus.Infof("Applying grid penalties to %d drivers", len(places))
+
+	entryList.ApplyGridPenalties(places)
+	championship.GridPenalties.markApplied(event.ID, championshipEventName(event))
+
+	return cm.UpsertChampionship(championship)
+}
+
+// applyRaceWeekendGridPenalties marks the grid penalties of a Championship which apply to a RaceWeekend as applied.
+// The penalties themselves are applied to the grid of each session which starts from the RaceWeekend's entry list by
+// its RaceWeekendSessionToSessionFilter.
+func (cm *ChampionshipManager) applyRaceWeekendGridPenalties(raceWeekend *RaceWeekend) error {
+	championship, err := cm.LoadChampionship(raceWeekend.ChampionshipID.String())
+
+	if err != nil {
+		return err
+	}
+
+	event := championship.eventForRaceWeekend(raceWeekend.ID)
+
+	if event == nil || len(championship.GridPenalties.PlacesForEvent(event.ID)) == 0 {
+		return nil
+	}
+
+	championship.GridPenalties.markApplied(event.ID, raceWeekend.Name)
+
+	return cm.UpsertChampionship(championship)
+}
+
+func championshipEventName(event *ChampionshipEvent) string {
+	if event.IsRaceWeekend() && event.RaceWeekend != nil {
+		return event.RaceWeekend.Name
+	}
+
+	return prettifyName(event.RaceSetup.Track, false)
+}
+
+func (ch *ChampionshipsHandler) addGridPenalty(w http.ResponseWriter, r *http.Request) {
+	err := ch.championshipManager.AddGridPenalty(
+		chi.URLParam(r, "championshipID"),
+		r.FormValue("DriverGUID"),
+		formValueAsInt(r.FormValue("Places")),
+		r.FormValue("Reason"),
+		AccountFromRequest(r).Name,
+	)
+
+	if err != nil {
+		logrus.WithError(err).Errorf("Could not add championship grid penalty")
+
+		AddErrorFlash(w, r, "Couldn't add grid penalty")
+	} else {
+		AddFlash(w, r, "Grid penalty successfully added")
+	}
+
+	http.Redirect(w, r, r.Referer(), http.StatusFound)
+}
+
+func (ch *ChampionshipsHandler) deleteGridPenalty(w http.ResponseWriter, r *http.Request) {
+	err := ch.championshipManager.DeleteGridPenalty(chi.URLParam(r, "championshipID"), chi.URLParam(r, "gridPenaltyID"))
+
+	if err != nil {
+		logrus.WithError(err).Errorf("Could not delete championship grid penalty")
+
+		AddErrorFlash(w, r, "Couldn't delete grid penalty")
+	} else {
+		AddFlash(w, r, "Grid penalty successfully deleted")
+	}
+
+	http.Redirect(w, r, r.Referer(), http.StatusFound)
+}
@@ -0,0 +1,162 @@
+package servermanager
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestEntryList_ApplyGridPenalties(t *testing.T) {
+	testCases := []struct {
+		name     string
+		grid     string
+		places   map[string]int
+		expected string
+	}{
+		{"No penalties", "0,1,2,3,4", nil, "0,1,2,3,4"},
+		{"Pole sitter moved back", "0,1,2,3,4", map[string]int{"0": 2}, "1,2,0,3,4"},
+		{"Moved back no further than the back of the grid", "0,1,2,3,4", map[string]int{"3": 10}, "0,1,2,4,3"},
+		{"Penalised drivers are moved in grid order", "0,1,2,3,4", map[string]int{"0": 1, "1": 1}, "2,0,1,3,4"},
+		{"Driver swap entrants share penalties", "4;5,0,1,2,3", map[string]int{"4": 1, "5": 2}, "0,1,2,4;5,3"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			entryList := make(EntryList)
+
+			for _, guid := range strings.Split(testCase.grid, ",") {
+				entrant := NewEntrant()
+				entrant.GUID = guid
+
+				entryList.AddToBackOfGrid(entrant)
+			}
+
+			entryList.ApplyGridPenalties(testCase.places)
+
+			var grid []string
+
+			for i, entrant := range entryList.AsSlice() {
+				if entrant.PitBox != i || entryList[fmt.Sprintf("CAR_%d", i)] != entrant {
+					t.Errorf("Entrant %s is in the wrong pit box: %d", entrant.GUID, entrant.PitBox)
+				}
+
+				grid = append(grid, entrant.GUID)
+			}
+
+			if strings.Join(grid, ",") != testCase.expected {
+				t.Errorf("Expected grid %s, got %s", testCase.expected, strings.Join(grid, ","))
+			}
+		})
+	}
+}
+
+func TestGridPenalties(t *testing.T) {
+	eventID, nextEventID := uuid.New(), uuid.New()
+
+	results := &SessionResults{
+		SessionFile: "2019_3_2_22_28_RACE",
+		Result: []*SessionResult{
+			{DriverGUID: "1", Penalties: []*Penalty{{ID: uuid.New(), Type: PenaltyTypeGridDrop, GridPlaces: 5}}},
+			{DriverGUID: "2", Penalties: []*Penalty{{ID: uuid.New(), Type: PenaltyTypeTime}}},
+		},
+	}
+
+	manual := &GridPenalty{ID: uuid.New(), DriverGUID: "2", Places: 3}
+
+	gridPenalties := GridPenalties{manual}.sync(eventID, results)
+
+	if len(gridPenalties) != 2 || len(gridPenalties.Pending()) != 2 {
+		t.Fatalf("Expected the grid drop to be added, got %d grid penalties", len(gridPenalties))
+	}
+
+	if places := gridPenalties.PlacesForEvent(eventID); places["1"] != 0 || places["2"] != 3 {
+		t.Errorf("Expected a penalty not to apply to the event it was given in, got: %v", places)
+	}
+
+	if places := gridPenalties.PlacesForEvent(nextEventID); places["1"] != 5 || places["2"] != 3 {
+		t.Errorf("Expected both penalties to apply to the next event, got: %v", places)
+	}
+
+	if gridPenalties = gridPenalties.sync(eventID, results); len(gridPenalties) != 2 {
+		t.Errorf("Expected grid drops to be added once, got %d grid penalties", len(gridPenalties))
+	}
+
+	gridPenalties.markApplied(nextEventID, "Next Event")
+
+	if len(gridPenalties.Pending()) != 0 || len(gridPenalties.PlacesForEvent(uuid.New())) != 0 {
+		t.Errorf("Expected all penalties to be applied")
+	}
+
+	if places := gridPenalties.PlacesForEvent(nextEventID); places["1"] != 5 {
+		t.Errorf("Expected applied penalties to still apply to a restarted event, got: %v", places)
+	}
+
+	t.Run("Revoked penalties are removed if they are pending", func(t *testing.T) {
+		pending := GridPenalties{}.sync(eventID, results)
+
+		results.Result[0].Penalties[0].Revoked = &PenaltyRevocation{}
+		defer func() {
+			results.Result[0].Penalties[0].Revoked = nil
+		}()
+
+		if pending = pending.sync(eventID, results); len(pending) != 0 {
+			t.Errorf("Expected the revoked penalty to be removed, got %d grid penalties", len(pending))
+		}
+
+		if gridPenalties = gridPenalties.sync(eventID, results); len(gridPenalties) != 2 {
+			t.Errorf("Expected the applied penalty to be kept, got %d grid penalties", len(gridPenalties))
+		}
+	})
+}
+
+func TestRaceWeekendSessionToSessionFilter_GridPenalties(t *testing.T) {
+	raceWeekend := NewRaceWeekend()
+
+	parentSession := NewRaceWeekendSession()
+	parentSession.Results = &SessionResults{}
+	parentSession.CompletedTime = time.Now()
+
+	childSession := NewRaceWeekendSession()
+
+	var parentSessionResults []*RaceWeekendSessionEntrant
+
+	for i := 0; i < 4; i++ {
+		result := &SessionResult{DriverGUID: fmt.Sprintf("%d", i)}
+
+		if i == 1 {
+			result.Penalties = []*Penalty{{Type: PenaltyTypeGridDrop, GridPlaces: 2}}
+		}
+
+		parentSessionResults = append(parentSessionResults, NewRaceWeekendSessionEntrant(parentSession.ID, &SessionCar{Driver: SessionDriver{GUID: result.DriverGUID}}, result, parentSession.Results))
+	}
+
+	filter := &RaceWeekendSessionToSessionFilter{
+		ResultStart:    1,
+		ResultEnd:      4,
+		EntryListStart: 1,
+		SplitType:      SplitTypeNumeric,
+	}
+
+	var entryList RaceWeekendEntryList
+
+	if err := filter.Filter(raceWeekend, parentSession, childSession, parentSessionResults, &entryList); err != nil {
+		t.Fatal(err)
+	}
+
+	var grid []string
+
+	for _, entrant := range entryList.Sorted() {
+		grid = append(grid, entrant.Car.GetGUID())
+	}
+
+	if strings.Join(grid, ",") != "0,2,3,1" {
+		t.Errorf("Expected the penalised driver to be moved back two places, got %s", strings.Join(grid, ","))
+	}
+
+	if parentSessionResults[1].Car.GetGUID() != "1" {
+		t.Errorf("Expected the parent session's results not to be reordered")
+	}
+}
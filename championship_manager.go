@@ -27,7 +27,8 @@ import (
 
 type ChampionshipManager struct {
 	*RaceManager
-	acsrClient *ACSRClient
+	acsrClient             *ACSRClient
+	resultsRevisionManager *ResultsRevisionManager
 
 	activeChampionship *ActiveChampionship
 	mutex              sync.Mutex
@@ -36,10 +37,11 @@ type ChampionshipManager struct {
 	championshipEventReminderTimers map[string]*when.Timer
 }
 
-func NewChampionshipManager(raceManager *RaceManager, acsrClient *ACSRClient) *ChampionshipManager {
+func NewChampionshipManager(raceManager *RaceManager, acsrClient *ACSRClient, resultsRevisionManager *ResultsRevisionManager) *ChampionshipManager {
 	return &ChampionshipManager{
-		RaceManager: raceManager,
-		acsrClient:  acsrClient,
+		RaceManager:            raceManager,
+		acsrClient:             acsrClient,
+		resultsRevisionManager: resultsRevisionManager,
 	}
 }
 
@@ -304,6 +306,12 @@ func (cm *ChampionshipManager) HandleCreateChampionship(r *http.Request) (champi
 
 	championship.Info = template.HTML(r.FormValue("ChampionshipInfo"))
 	championship.DefaultTab = ChampionshipTab(r.FormValue("ChampionshipDefaultTab"))
+	championship.CountedResults = ChampionshipCountedResults{
+		DropWorstRounds:       formValueAsInt(r.FormValue("CountedResults.DropWorstRounds")),
+		CountBestRounds:       formValueAsInt(r.FormValue("CountedResults.CountBestRounds")),
+		KeepFinalRound:        r.FormValue("CountedResults.KeepFinalRound") == "on" || r.FormValue("CountedResults.KeepFinalRound") == "1",
+		DropDisqualifications: r.FormValue("CountedResults.DropDisqualifications") == "on" || r.FormValue("CountedResults.DropDisqualifications") == "1",
+	}
 	championship.OverridePassword = r.FormValue("OverridePassword") == "on" || r.FormValue("OverridePassword") == "1"
 
 	if Premium() {
@@ -384,6 +392,10 @@ func (cm *ChampionshipManager) HandleCreateChampionship(r *http.Request) (champi
 		class.Points.CollisionWithEnv = formValueAsInt(r.Form["Points.CollisionWithEnv"][i])
 		class.Points.CutTrack = formValueAsInt(r.Form["Points.CutTrack"][i])
 
+		if i < len(r.Form["PointsScheme"]) {
+			class.PointsScheme = PointsSchemeType(r.Form["PointsScheme"][i])
+		}
+
 		if previousClass, ok := previousClasses[class.ID]; ok {
 			// look for previous penalties and apply them back across
 			class.DriverPenalties = previousClass.DriverPenalties
@@ -669,6 +681,7 @@ func (cm *ChampionshipManager) FinalEventConfigurationFiles(championship *Champi
 	raceSetup.Cars = strings.Join(championship.ValidCarIDs(), ";")
 
 	entryList := event.CombineEntryLists(championship)
+	championship.applyTeamLiveries(entryList)
 
 	if championship.HasSpectatorCar() {
 		entryList.AddInPitBox(&championship.SpectatorCar, maxEntryListSize+1)
@@ -722,6 +735,12 @@ func (cm *ChampionshipManager) StartEvent(championshipID string, eventID string,
 
 	raceSetup, entryList := cm.FinalEventConfigurationFiles(championship, event, isPreChampionshipPracticeEvent)
 
+	if !isPreChampionshipPracticeEvent && entryList != nil {
+		if err := cm.applyEventGridPenalties(championship, event, entryList); err != nil {
+			return err
+		}
+	}
+
 	if config.Lua.Enabled && Premium() {
 		err := championshipEventStartPlugin(event, championship, &entryList)
 
@@ -1165,7 +1184,7 @@ func (cm *ChampionshipManager) handleSessionChanges(message udp.Message, champio
 
 		// Update the old results json file with more championship information, required for applying penalties properly
 		championship.EnhanceResults(results)
-		err = saveResults(filename, results)
+		err = cm.resultsRevisionManager.UpdateResults(strings.TrimSuffix(filename, ".json"), results, "Championship information added")
 
 		if err != nil {
 			logrus.WithError(err).Errorf("Could not update session results for %s", cm.activeChampionship.SessionType.String())
@@ -1173,6 +1192,7 @@ func (cm *ChampionshipManager) handleSessionChanges(message udp.Message, champio
 		}
 
 		currentSession, ok := championship.Events[currentEventIndex].Sessions[cm.activeChampionship.SessionType]
+		decidedTitlesBefore := decidedTitles(championship)
 
 		if ok {
 			currentSession.CompletedTime = time.Now()
@@ -1182,6 +1202,8 @@ func (cm *ChampionshipManager) handleSessionChanges(message udp.Message, champio
 			return
 		}
 
+		cm.notifyDecidedTitles(championship, decidedTitlesBefore)
+
 		lastSession := championship.Events[currentEventIndex].LastSession()
 
 		if cm.activeChampionship.SessionType == lastSession {
@@ -1321,21 +1343,13 @@ func (cm *ChampionshipManager) ListAvailableResultsFilesForEvent(championshipID
 		return nil, nil, err
 	}
 
-	results, err := ListAllResults()
+	results, err := cm.trackManager.ResultsForLayout(event.RaceSetup.Track, event.RaceSetup.TrackLayout)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var filteredResults []SessionResults
-
-	for _, result := range results {
-		if result.TrackName == event.RaceSetup.Track && result.TrackConfig == event.RaceSetup.TrackLayout {
-			filteredResults = append(filteredResults, result)
-		}
-	}
-
-	return event, filteredResults, nil
+	return event, results, nil
 }
 
 func (cm *ChampionshipManager) ImportChampionship(jsonData string) (string, error) {
@@ -1456,43 +1470,83 @@ func (cm *ChampionshipManager) ImportEvent(championshipID string, eventID string
 			return err
 		}
 
-		if championship.OpenEntrants && championship.PersistOpenEntrants {
-			// if the championship is open, we might have entrants in this session file who have not
-			// raced in this championship before. add them to the championship as they would be added
-			// if they joined during a race.
-			for _, car := range results.Cars {
-				if car.GetGUID() == "" {
-					continue
-				}
+		if err := cm.importEventSession(championship, event, sessionType, sessionFile, results); err != nil {
+			return err
+		}
+	}
 
-				foundFreeSlot, _, err := cm.AddEntrantFromSessionData(championship, car, false, false)
+	return cm.UpsertChampionship(championship)
+}
 
-				if err != nil {
-					return err
-				}
+// ImportEventResults attaches results which are not yet saved (e.g. those converted from another source) to a
+// session of a championship event. The session is worked out from the type of the results.
+func (cm *ChampionshipManager) ImportEventResults(championshipID string, eventID string, results *SessionResults) error {
+	championship, err := cm.LoadChampionship(championshipID)
 
-				if !foundFreeSlot {
-					logrus.WithField("car", car).Warn("Could not add entrant to championship. No free slot found")
-				}
+	if err != nil {
+		return err
+	}
+
+	event, _, err := championship.EventByID(eventID)
+
+	if err != nil {
+		return err
+	}
+
+	if event.IsRaceWeekend() {
+		return ErrRaceWeekendEventResultsImport
+	}
+
+	if event.Sessions == nil {
+		event.Sessions = make(map[SessionType]*ChampionshipSession)
+	}
+
+	if err := cm.importEventSession(championship, event, results.Type, results.SessionFile, results); err != nil {
+		return err
+	}
+
+	return cm.UpsertChampionship(championship)
+}
+
+// importEventSession adds the results to the event as the given session type, then saves them with the championship
+// information added.
+func (cm *ChampionshipManager) importEventSession(championship *Championship, event *ChampionshipEvent, sessionType SessionType, sessionFile string, results *SessionResults) error {
+	if championship.OpenEntrants && championship.PersistOpenEntrants {
+		// if the championship is open, we might have entrants in this session file who have not
+		// raced in this championship before. add them to the championship as they would be added
+		// if they joined during a race.
+		for _, car := range results.Cars {
+			if car.GetGUID() == "" {
+				continue
 			}
-		}
 
-		championship.EnhanceResults(results)
+			foundFreeSlot, _, err := cm.AddEntrantFromSessionData(championship, car, false, false)
 
-		if err := saveResults(sessionFile+".json", results); err != nil {
-			return err
-		}
+			if err != nil {
+				return err
+			}
 
-		event.Sessions[sessionType] = &ChampionshipSession{
-			StartedTime:   results.Date.Add(-time.Minute * 30),
-			CompletedTime: results.Date,
-			Results:       results,
+			if !foundFreeSlot {
+				logrus.WithField("car", car).Warn("Could not add entrant to championship. No free slot found")
+			}
 		}
+	}
 
-		event.CompletedTime = results.Date
+	championship.EnhanceResults(results)
+
+	if err := cm.resultsRevisionManager.UpdateResults(sessionFile, results, "Imported into the championship"); err != nil {
+		return err
 	}
 
-	return cm.UpsertChampionship(championship)
+	event.Sessions[sessionType] = &ChampionshipSession{
+		StartedTime:   results.Date.Add(-time.Minute * 30),
+		CompletedTime: results.Date,
+		Results:       results,
+	}
+
+	event.CompletedTime = results.Date
+
+	return nil
 }
 
 func (cm *ChampionshipManager) AddEntrantFromSessionData(championship *Championship, potentialEntrant PotentialChampionshipEntrant, overwriteSkinForAllEvents bool, takeFirstFreeSlot bool) (foundFreeEntrantSlot bool, entrantClass *ChampionshipClass, err error) {
@@ -130,6 +130,14 @@ func (d dummyNotificationManager) SendRaceWeekendReminderMessage(raceWeekend *Ra
 	return nil
 }
 
+func (d dummyNotificationManager) SendLapAnomalyReportMessage(report *LapAnomalyReport) error {
+	return nil
+}
+
+func (d dummyNotificationManager) SendChampionshipTitleDecidedMessage(championship *Championship, permutations *ChampionshipTitlePermutations, champion *ChampionshipTitleContender) error {
+	return nil
+}
+
 func (d dummyNotificationManager) SendMessage(title string, msg string) error {
 	return nil
 }
@@ -172,16 +180,21 @@ func (d dummyNotificationManager) SaveServerOptions(oldServerOpts *GlobalServerC
 
 func init() {
 	config = &Configuration{}
+	resultsIndex := NewResultsIndex(testStore)
+	trackManager := NewTrackManager(resultsIndex)
+	resultsRevisionManager := NewResultsRevisionManager(testStore, resultsIndex)
+
 	championshipManager = NewChampionshipManager(
 		NewRaceManager(
 			NewJSONStore(filepath.Join(os.TempDir(), "asm-race-store"), filepath.Join(os.TempDir(), "asm-race-store-shared")),
 			dummyServerProcess{},
-			NewCarManager(NewTrackManager(), false, false),
-			NewTrackManager(),
+			NewCarManager(trackManager, resultsIndex, false, false),
+			trackManager,
 			&dummyNotificationManager{},
-			NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore)),
+			NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, resultsRevisionManager), resultsRevisionManager),
 		),
 		&ACSRClient{Enabled: false},
+		resultsRevisionManager,
 	)
 }
 
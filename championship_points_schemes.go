@@ -0,0 +1,580 @@
+package servermanager
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// PointsSchemeType identifies how points are scored in a ChampionshipClass.
+type PointsSchemeType string
+
+const (
+	// PointsSchemeCustom uses the points configured for the class (or race weekend session). It is the default.
+	PointsSchemeCustom          PointsSchemeType = ""
+	PointsSchemeF1              PointsSchemeType = "f1"
+	PointsSchemeIndyCar         PointsSchemeType = "indycar"
+	PointsSchemeWEC             PointsSchemeType = "wec"
+	PointsSchemePositionsGained PointsSchemeType = "positions-gained"
+	PointsSchemeLua             PointsSchemeType = "lua"
+)
+
+// A PointsScheme decides how many points the drivers in a class score for a session.
+type PointsScheme interface {
+	Name() string
+	Description() string
+
+	// Score gives points to drivers for their results in a session.
+	Score(session *PointsSession, give PointsGiver)
+
+	// MaxPoints is the most points a single driver can score in a session which hasn't been run yet. ok is false if
+	// the scheme can't work it out.
+	MaxPoints(session *PointsSession) (points float64, ok bool)
+}
+
+// PointsGiver gives points to a driver. The description explains to drivers why they were given the points.
+type PointsGiver func(driverGUID string, points float64, reason PointsReason, description string)
+
+// PointsSession is a completed championship (or race weekend) session to be scored for a class.
+type PointsSession struct {
+	Type        SessionType
+	RaceWeekend bool
+	ClassID     uuid.UUID
+
+	Results *SessionResults
+
+	// ClassResults are the results of the class's drivers who set a time, in finishing order.
+	ClassResults []*SessionResult
+
+	// Grid is the GUIDs of the class's drivers in the order they started the session. Drivers start in qualifying order
+	// if the event had a qualifying session, otherwise in entry list order.
+	Grid []string
+
+	// Points are the custom points of the class, or of the race weekend session if it has its own.
+	Points ChampionshipPoints
+
+	// Duration is how long a session which hasn't been run yet is configured to last, or 0 if it is lap limited.
+	Duration time.Duration `json:",omitempty"`
+}
+
+// IsRace is true for race sessions, including second races.
+func (ps *PointsSession) IsRace() bool {
+	return ps.Type == SessionTypeRace || ps.Type == SessionTypeSecondRace
+}
+
+// GridPosition is the position (starting from 0) that the driver started the session in, or -1 if they are not on the grid.
+func (ps *PointsSession) GridPosition(driverGUID string) int {
+	for pos, guid := range ps.Grid {
+		if guid == driverGUID {
+			return pos
+		}
+	}
+
+	return -1
+}
+
+// PointsAward is a number of points given to a driver, and why.
+type PointsAward struct {
+	DriverGUID string
+
+	// Event is a short name of the championship event (and race weekend session) the points were scored in. It is
+	// empty for championship points penalties.
+	Event   string
+	Session SessionType
+
+	Points      float64
+	Reason      PointsReason
+	Description string
+
+	// Dropped is true if the points are from a round which doesn't count towards the driver's standings.
+	Dropped bool
+
+	eventCompleted time.Time
+	round          uuid.UUID
+}
+
+// pointsSchemeOrder is the order points schemes are shown in.
+var pointsSchemeOrder = []PointsSchemeType{
+	PointsSchemeCustom,
+	PointsSchemeF1,
+	PointsSchemeIndyCar,
+	PointsSchemeWEC,
+	PointsSchemePositionsGained,
+	PointsSchemeLua,
+}
+
+var pointsSchemes = map[PointsSchemeType]PointsScheme{
+	PointsSchemeCustom:          customPointsScheme{},
+	PointsSchemeF1:              f1PointsScheme{},
+	PointsSchemeIndyCar:         indyCarPointsScheme{},
+	PointsSchemeWEC:             wecPointsScheme{},
+	PointsSchemePositionsGained: positionsGainedPointsScheme{},
+	PointsSchemeLua:             luaPointsScheme{},
+}
+
+// PointsSchemeOption is a points scheme which can be chosen for a class.
+type PointsSchemeOption struct {
+	Type PointsSchemeType
+	PointsScheme
+}
+
+// PointsSchemeOptions lists every points scheme, for use in forms.
+func PointsSchemeOptions() []PointsSchemeOption {
+	var out []PointsSchemeOption
+
+	for _, schemeType := range pointsSchemeOrder {
+		out = append(out, PointsSchemeOption{Type: schemeType, PointsScheme: pointsSchemes[schemeType]})
+	}
+
+	return out
+}
+
+// GetPointsScheme returns the points scheme of the class. Unknown schemes fall back to the custom points.
+func (c *ChampionshipClass) GetPointsScheme() PointsScheme {
+	if scheme, ok := pointsSchemes[c.PointsScheme]; ok {
+		return scheme
+	}
+
+	return customPointsScheme{}
+}
+
+// UsesCustomPoints is true if the class is scored using the points configured for it.
+func (c *ChampionshipClass) UsesCustomPoints() bool {
+	_, ok := c.GetPointsScheme().(customPointsScheme)
+
+	return ok
+}
+
+// scorePositions gives each driver in the class the points for their finishing position from the table. Disqualified
+// drivers score nothing.
+func scorePositions(session *PointsSession, table []float64, multiplier float64, give PointsGiver) {
+	for pos, result := range session.ClassResults {
+		if result.Disqualified || pos >= len(table) || table[pos] == 0 {
+			continue
+		}
+
+		give(result.DriverGUID, table[pos]*multiplier, PointsEventFinish, fmt.Sprintf("Finished %d%s", pos+1, ordinal(int64(pos+1))))
+	}
+}
+
+// scorePolePosition gives points to the driver who qualified fastest in the class.
+func scorePolePosition(session *PointsSession, points float64, give PointsGiver) {
+	if session.Type != SessionTypeQualifying || len(session.ClassResults) == 0 || points == 0 {
+		return
+	}
+
+	give(session.ClassResults[0].DriverGUID, points, PointsPolePosition, "Pole position")
+}
+
+// customPointsScheme is the points configured for the class, with points for fastest laps and pole position,
+// deductions for collisions and cuts and a multiplier for second races.
+type customPointsScheme struct{}
+
+func (customPointsScheme) Name() string {
+	return "Custom"
+}
+
+func (customPointsScheme) Description() string {
+	return "Points are given for finishing positions, fastest laps and pole position, and removed for collisions and cuts, as configured for this class."
+}
+
+func (customPointsScheme) Score(session *PointsSession, give PointsGiver) {
+	points := session.Points
+	pointsMultiplier := 1.0
+
+	if !session.RaceWeekend {
+		switch session.Type {
+		case SessionTypeQualifying:
+			// non race weekend qualifying results get pole position points
+			scorePolePosition(session, float64(points.PolePosition), give)
+			return
+		case SessionTypeSecondRace:
+			pointsMultiplier = points.SecondRaceMultiplier
+		case SessionTypeBooking, SessionTypePractice:
+			return
+		default:
+			// race sessions fall through
+		}
+	}
+
+	fastestLap := session.Results.FastestLapInClass(session.ClassID)
+
+	for pos, driver := range session.ClassResults {
+		if driver.TotalTime <= 0 || driver.Disqualified {
+			continue
+		}
+
+		give(driver.DriverGUID, points.ForPos(pos)*pointsMultiplier, PointsEventFinish, fmt.Sprintf("Finished %d%s", pos+1, ordinal(int64(pos+1))))
+
+		if fastestLap != nil && fastestLap.DriverGUID == driver.DriverGUID {
+			give(driver.DriverGUID, float64(points.BestLap)*pointsMultiplier, PointsFastestLap, "Fastest lap")
+		}
+
+		if session.IsRace() {
+			collisionsWithCars := session.Results.GetCrashesOfType(driver.DriverGUID, driver.CarModel, "COLLISION_WITH_CAR")
+			collisionsWithEnv := session.Results.GetCrashesOfType(driver.DriverGUID, driver.CarModel, "COLLISION_WITH_ENV")
+			cuts := session.Results.GetCuts(driver.DriverGUID, driver.CarModel)
+
+			give(driver.DriverGUID, float64(points.CollisionWithDriver*collisionsWithCars)*pointsMultiplier*-1, PointsCollisionWithCar, fmt.Sprintf("%d collisions with other cars", collisionsWithCars))
+			give(driver.DriverGUID, float64(points.CollisionWithEnv*collisionsWithEnv)*pointsMultiplier*-1, PointsCollisionWithEnvironment, fmt.Sprintf("%d collisions with the environment", collisionsWithEnv))
+			give(driver.DriverGUID, float64(points.CutTrack*cuts)*pointsMultiplier*-1, PointsCutTrack, fmt.Sprintf("%d cuts", cuts))
+		}
+	}
+}
+
+func (customPointsScheme) MaxPoints(session *PointsSession) (float64, bool) {
+	points := session.Points
+	pointsMultiplier := 1.0
+
+	if !session.RaceWeekend {
+		switch session.Type {
+		case SessionTypeQualifying:
+			return math.Max(float64(points.PolePosition), 0), true
+		case SessionTypeSecondRace:
+			pointsMultiplier = points.SecondRaceMultiplier
+		case SessionTypeBooking, SessionTypePractice:
+			return 0, true
+		}
+	}
+
+	var mostPoints float64
+
+	for pos := range points.Places {
+		mostPoints = math.Max(mostPoints, points.ForPos(pos))
+	}
+
+	return (mostPoints + math.Max(float64(points.BestLap), 0)) * pointsMultiplier, true
+}
+
+var (
+	f1RacePoints   = []float64{25, 18, 15, 12, 10, 8, 6, 4, 2, 1}
+	f1SprintPoints = []float64{8, 7, 6, 5, 4, 3, 2, 1}
+
+	indyCarRacePoints = []float64{50, 40, 35, 32, 30, 28, 26, 24, 22, 20, 19, 18, 17, 16, 15, 14, 13, 12, 11, 10, 9, 8, 7, 6, 5, 5, 5, 5, 5, 5, 5, 5, 5}
+)
+
+// f1PointsScheme is the modern Formula 1 points system.
+type f1PointsScheme struct{}
+
+func (f1PointsScheme) Name() string {
+	return "Formula 1"
+}
+
+func (f1PointsScheme) Description() string {
+	return "Races score 25, 18, 15, 12, 10, 8, 6, 4, 2 and 1 points for the top ten, with 1 point for the fastest lap if it was set by a driver in the top ten. Second races are sprints, scoring 8 points down to 1 for the top eight."
+}
+
+func (f1PointsScheme) Score(session *PointsSession, give PointsGiver) {
+	if !session.IsRace() {
+		return
+	}
+
+	if session.Type == SessionTypeSecondRace {
+		scorePositions(session, f1SprintPoints, 1, give)
+		return
+	}
+
+	scorePositions(session, f1RacePoints, 1, give)
+
+	fastestLap := session.Results.FastestLapInClass(session.ClassID)
+
+	for pos, result := range session.ClassResults {
+		if fastestLap != nil && pos < len(f1RacePoints) && !result.Disqualified && result.DriverGUID == fastestLap.DriverGUID {
+			give(result.DriverGUID, 1, PointsFastestLap, "Fastest lap")
+		}
+	}
+}
+
+func (f1PointsScheme) MaxPoints(session *PointsSession) (float64, bool) {
+	switch session.Type {
+	case SessionTypeRace:
+		return f1RacePoints[0] + 1, true
+	case SessionTypeSecondRace:
+		return f1SprintPoints[0], true
+	default:
+		return 0, true
+	}
+}
+
+// indyCarPointsScheme is the IndyCar points system, with bonus points for leading laps.
+type indyCarPointsScheme struct{}
+
+func (indyCarPointsScheme) Name() string {
+	return "IndyCar"
+}
+
+func (indyCarPointsScheme) Description() string {
+	return "Races score 50 points for a win, 40 for second and 35 for third, down to 5 points. Drivers score 1 point for pole position, 1 point for leading a lap and 2 more for leading the most laps."
+}
+
+func (indyCarPointsScheme) Score(session *PointsSession, give PointsGiver) {
+	scorePolePosition(session, 1, give)
+
+	if !session.IsRace() {
+		return
+	}
+
+	scorePositions(session, indyCarRacePoints, 1, give)
+
+	lapsLed := lapsLedByDriver(session)
+
+	mostLapsLed := 0
+
+	for _, laps := range lapsLed {
+		if laps > mostLapsLed {
+			mostLapsLed = laps
+		}
+	}
+
+	for _, result := range session.ClassResults {
+		laps := lapsLed[result.DriverGUID]
+
+		if laps == 0 || result.Disqualified {
+			continue
+		}
+
+		give(result.DriverGUID, 1, PointsLapsLed, fmt.Sprintf("Led %d laps", laps))
+
+		if laps == mostLapsLed {
+			give(result.DriverGUID, 2, PointsLapsLed, "Led the most laps")
+		}
+	}
+}
+
+func (indyCarPointsScheme) MaxPoints(session *PointsSession) (float64, bool) {
+	switch {
+	case session.Type == SessionTypeQualifying:
+		return 1, true
+	case session.IsRace():
+		// a win, leading a lap and leading the most laps
+		return indyCarRacePoints[0] + 1 + 2, true
+	default:
+		return 0, true
+	}
+}
+
+// lapsLedByDriver counts the laps each driver in the class was leading at the end of. The leader of a lap is the first
+// driver to complete it.
+func lapsLedByDriver(session *PointsSession) map[string]int {
+	inClass := make(map[string]bool)
+
+	for _, result := range session.ClassResults {
+		inClass[result.DriverGUID] = true
+	}
+
+	laps := make([]*SessionLap, len(session.Results.Laps))
+	copy(laps, session.Results.Laps)
+
+	sort.SliceStable(laps, func(i, j int) bool {
+		return laps[i].Timestamp < laps[j].Timestamp
+	})
+
+	lapsCompleted := make(map[string]int)
+	leaderLaps := 0
+	lapsLed := make(map[string]int)
+
+	for _, lap := range laps {
+		if !inClass[lap.DriverGUID] {
+			continue
+		}
+
+		lapsCompleted[lap.DriverGUID]++
+
+		if lapsCompleted[lap.DriverGUID] > leaderLaps {
+			leaderLaps = lapsCompleted[lap.DriverGUID]
+			lapsLed[lap.DriverGUID]++
+		}
+	}
+
+	return lapsLed
+}
+
+const (
+	// wecClassificationDistance is the proportion of the winner's laps a driver must complete to be classified.
+	wecClassificationDistance = 0.7
+
+	// races at least wecLongRaceDuration long score one and a half times the points, races at least
+	// wecEnduranceRaceDuration long score double points.
+	wecLongRaceDuration      = 8 * time.Hour
+	wecEnduranceRaceDuration = 24 * time.Hour
+)
+
+// wecPointsScheme is the FIA World Endurance Championship points system, where longer races are worth more points.
+type wecPointsScheme struct{}
+
+func (wecPointsScheme) Name() string {
+	return "FIA WEC"
+}
+
+func (wecPointsScheme) Description() string {
+	return "Races score 25, 18, 15, 12, 10, 8, 6, 4, 2 and 1 points for the top ten, one and a half times as many for races of 8 hours or more and double for races of 24 hours. Drivers must complete 70% of the winner's laps to score points. Pole position scores 1 point."
+}
+
+func (wecPointsScheme) Score(session *PointsSession, give PointsGiver) {
+	scorePolePosition(session, 1, give)
+
+	if !session.IsRace() || len(session.ClassResults) == 0 {
+		return
+	}
+
+	winner := session.ClassResults[0]
+	winnerLaps := session.Results.GetNumLaps(winner.DriverGUID, winner.CarModel)
+	raceDuration := time.Duration(winner.TotalTime) * time.Millisecond
+
+	multiplier := 1.0
+
+	switch {
+	case raceDuration >= wecEnduranceRaceDuration:
+		multiplier = 2
+	case raceDuration >= wecLongRaceDuration:
+		multiplier = 1.5
+	}
+
+	for pos, result := range session.ClassResults {
+		if result.Disqualified || pos >= len(f1RacePoints) {
+			continue
+		}
+
+		if laps := session.Results.GetNumLaps(result.DriverGUID, result.CarModel); float64(laps) < float64(winnerLaps)*wecClassificationDistance {
+			continue
+		}
+
+		description := fmt.Sprintf("Finished %d%s", pos+1, ordinal(int64(pos+1)))
+
+		if multiplier != 1 {
+			description += fmt.Sprintf(" (%gx points for a %s race)", multiplier, raceDuration.Round(time.Minute))
+		}
+
+		give(result.DriverGUID, f1RacePoints[pos]*multiplier, PointsEventFinish, description)
+	}
+}
+
+func (wecPointsScheme) MaxPoints(session *PointsSession) (float64, bool) {
+	switch {
+	case session.Type == SessionTypeQualifying:
+		return 1, true
+	case session.IsRace():
+		multiplier := 1.0
+
+		switch {
+		case session.Duration == 0 || session.Duration >= wecEnduranceRaceDuration:
+			// lap limited races could last long enough for double points.
+			multiplier = 2
+		case session.Duration >= wecLongRaceDuration:
+			multiplier = 1.5
+		}
+
+		return f1RacePoints[0] * multiplier, true
+	default:
+		return 0, true
+	}
+}
+
+// positionsGainedPointsScheme rewards drivers for overtaking.
+type positionsGainedPointsScheme struct{}
+
+func (positionsGainedPointsScheme) Name() string {
+	return "Positions Gained"
+}
+
+func (positionsGainedPointsScheme) Description() string {
+	return "Races score 25, 18, 15, 12, 10, 8, 6, 4, 2 and 1 points for the top ten, plus 1 point for every place gained from the driver's starting position."
+}
+
+func (positionsGainedPointsScheme) Score(session *PointsSession, give PointsGiver) {
+	if !session.IsRace() {
+		return
+	}
+
+	scorePositions(session, f1RacePoints, 1, give)
+
+	for pos, result := range session.ClassResults {
+		gridPosition := session.GridPosition(result.DriverGUID)
+
+		if result.Disqualified || gridPosition <= pos {
+			continue
+		}
+
+		give(result.DriverGUID, float64(gridPosition-pos), PointsPositionsGained, fmt.Sprintf("Gained %d places from %d%s on the grid", gridPosition-pos, gridPosition+1, ordinal(int64(gridPosition+1))))
+	}
+}
+
+func (positionsGainedPointsScheme) MaxPoints(session *PointsSession) (float64, bool) {
+	if !session.IsRace() {
+		return 0, true
+	}
+
+	// a win from the back of the grid
+	return f1RacePoints[0] + math.Max(float64(len(session.Grid)-1), 0), true
+}
+
+// luaPointsScheme calls the onChampionshipSessionScore function in plugins/points.lua.
+type luaPointsScheme struct{}
+
+func (luaPointsScheme) Name() string {
+	return "Lua Script"
+}
+
+func (luaPointsScheme) Description() string {
+	return "Points are given by the onChampionshipSessionScore function in plugins/points.lua. Lua plugins must be enabled, otherwise the points configured for this class are used."
+}
+
+func (luaPointsScheme) Score(session *PointsSession, give PointsGiver) {
+	if !config.Lua.Enabled || !Premium() {
+		customPointsScheme{}.Score(session, give)
+		return
+	}
+
+	var awards []*PointsAward
+
+	p := NewLuaPlugin()
+
+	p.Inputs(session).Outputs(&awards)
+	err := p.Call("./plugins/points.lua", "onChampionshipSessionScore")
+
+	if err != nil {
+		logrus.WithError(err).Error("championship session score plugin script failed")
+		return
+	}
+
+	for _, award := range awards {
+		give(award.DriverGUID, award.Points, award.Reason, award.Description)
+	}
+}
+
+// MaxPoints can't be worked out from a Lua script, unless Lua plugins are disabled and the class's points are used.
+func (luaPointsScheme) MaxPoints(session *PointsSession) (float64, bool) {
+	if !config.Lua.Enabled || !Premium() {
+		return customPointsScheme{}.MaxPoints(session)
+	}
+
+	return 0, false
+}
+
+var pointsAwardSessionOrder = map[SessionType]int{
+	SessionTypeBooking:    0,
+	SessionTypePractice:   1,
+	SessionTypeQualifying: 2,
+	SessionTypeRace:       3,
+	SessionTypeSecondRace: 4,
+}
+
+// sortPointsAwards orders awards by the event and session they were scored in. Championship points penalties are
+// not scored in an event, so they go last.
+func sortPointsAwards(awards []*PointsAward) {
+	sort.SliceStable(awards, func(i, j int) bool {
+		if awards[i].eventCompleted.IsZero() != awards[j].eventCompleted.IsZero() {
+			return awards[j].eventCompleted.IsZero()
+		}
+
+		if !awards[i].eventCompleted.Equal(awards[j].eventCompleted) {
+			return awards[i].eventCompleted.Before(awards[j].eventCompleted)
+		}
+
+		return pointsAwardSessionOrder[awards[i].Session] < pointsAwardSessionOrder[awards[j].Session]
+	})
+}
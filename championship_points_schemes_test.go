@@ -0,0 +1,85 @@
+package servermanager
+
+import (
+	"testing"
+)
+
+// addPointsSchemeTestRace adds a race to the championship which A wins from the back of the grid, leading every lap,
+// while B sets the fastest lap and C only completes two of the three laps.
+func addPointsSchemeTestRace(b *testChampionshipBuilder) *testChampionshipBuilder {
+	b.Race("A", "B", "C").Qualifying("C", "B", "A")
+
+	for lap := 0; lap < 3; lap++ {
+		for i, guid := range []string{"A", "B", "C"} {
+			if guid == "C" && lap == 2 {
+				continue
+			}
+
+			lapTime := 30000
+
+			if guid == "B" && lap == 1 {
+				lapTime = 29000
+			}
+
+			b.Lap(guid, lapTime, (lap+1)*30000+i*100)
+		}
+	}
+
+	return b
+}
+
+func TestChampionshipClass_PointsSchemes(t *testing.T) {
+	for _, testCase := range []struct {
+		scheme   PointsSchemeType
+		expected map[string]float64
+	}{
+		{scheme: PointsSchemeCustom, expected: map[string]float64{"A": 25, "B": 18, "C": 15}},
+		{scheme: PointsSchemeF1, expected: map[string]float64{"A": 25, "B": 19, "C": 15}},
+		{scheme: PointsSchemeIndyCar, expected: map[string]float64{"A": 53, "B": 40, "C": 36}},
+		{scheme: PointsSchemeWEC, expected: map[string]float64{"A": 25, "B": 18, "C": 1}},
+		{scheme: PointsSchemePositionsGained, expected: map[string]float64{"A": 27, "B": 18, "C": 15}},
+	} {
+		t.Run(string(testCase.scheme), func(t *testing.T) {
+			championship, class := addPointsSchemeTestRace(newTestChampionship().PointsScheme(testCase.scheme)).Build()
+
+			standings := class.Standings(championship, championship.Events)
+
+			if len(standings) != len(testCase.expected) {
+				t.Fatalf("Expected %d standings, got %d", len(testCase.expected), len(standings))
+			}
+
+			for _, standing := range standings {
+				if standing.Points != testCase.expected[standing.Car.Driver.GUID] {
+					t.Errorf("Expected %s to have %.0f points, got %.0f", standing.Car.Driver.GUID, testCase.expected[standing.Car.Driver.GUID], standing.Points)
+				}
+
+				var total float64
+
+				for _, award := range standing.Awards {
+					total += award.Points
+				}
+
+				if total != standing.Points {
+					t.Errorf("Expected the awards of %s to add up to %.0f, got %.0f", standing.Car.Driver.GUID, standing.Points, total)
+				}
+			}
+		})
+	}
+
+	t.Run("Points penalties are explained", func(t *testing.T) {
+		championship, class := addPointsSchemeTestRace(newTestChampionship().PointsScheme(PointsSchemeF1)).Build()
+		class.DriverPenalties = map[string]int{"A": 10}
+
+		standings := class.Standings(championship, championship.Events)
+
+		if standings[0].Car.Driver.GUID != "B" || standings[1].Points != 15 {
+			t.Fatalf("Expected the penalty to drop A to second, got: %s first", standings[0].Car.Driver.GUID)
+		}
+
+		awards := standings[1].Awards
+
+		if len(awards) != 2 || awards[0].Description != "Finished 1st" || awards[1].Points != -10 || awards[1].Event != "" {
+			t.Errorf("Incorrect awards: %+v, %+v", awards[0], awards[1])
+		}
+	})
+}
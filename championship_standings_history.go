@@ -0,0 +1,376 @@
+package servermanager
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrChampionshipStandingsHistoryNotFound = errors.New("servermanager: championship standings history not found")
+
+// ChampionshipStandingsHistory is a snapshot of the standings of a Championship after each of its completed rounds.
+// It is rebuilt whenever the Championship changes, so corrections to results and penalties are reflected in
+// every snapshot.
+type ChampionshipStandingsHistory struct {
+	ChampionshipID uuid.UUID
+	Updated        time.Time
+
+	// Snapshots are in the order the rounds were completed.
+	Snapshots []*ChampionshipStandingsSnapshot
+}
+
+// ChampionshipStandingsSnapshot is the standings of each class of a Championship after a round was completed.
+type ChampionshipStandingsSnapshot struct {
+	RoundID       uuid.UUID
+	Round         int
+	Name          string
+	CompletedTime time.Time
+
+	Classes []*ChampionshipClassStandingsSnapshot
+}
+
+type ChampionshipClassStandingsSnapshot struct {
+	ClassID uuid.UUID
+	Name    string
+
+	Drivers []*ChampionshipStandingsSnapshotEntry
+	Teams   []*ChampionshipStandingsSnapshotEntry
+}
+
+// ChampionshipStandingsSnapshotEntry is the position of a driver (or team) in a class after a round. PreviousPosition
+// is 0 if the driver had not scored in a previous round.
+type ChampionshipStandingsSnapshotEntry struct {
+	Position         int
+	PreviousPosition int
+	DriverGUID       string `json:",omitempty"`
+	Name             string
+	Team             string `json:",omitempty"`
+	Points           float64
+	PreviousPoints   float64
+}
+
+// PositionChange is the number of positions gained (positive) or lost (negative) since the previous round.
+func (e *ChampionshipStandingsSnapshotEntry) PositionChange() int {
+	if e.PreviousPosition == 0 {
+		return 0
+	}
+
+	return e.PreviousPosition - e.Position
+}
+
+// NewChampionshipStandingsHistory works out the standings of each class of the championship after each of its
+// completed rounds.
+func NewChampionshipStandingsHistory(championship *Championship) *ChampionshipStandingsHistory {
+	history := &ChampionshipStandingsHistory{
+		ChampionshipID: championship.ID,
+		Updated:        time.Now(),
+	}
+
+	rounds := championshipRounds(championship, championship.Events)
+	completedTimes := make(map[uuid.UUID]time.Time)
+
+	for _, event := range ExtractRaceWeekendSessionsIntoIndividualEvents(championship.Events) {
+		round := roundForEvent(rounds, event.ID)
+
+		if round == nil {
+			continue
+		}
+
+		for _, session := range event.Sessions {
+			if session.Completed() && session.CompletedTime.After(completedTimes[round.ID]) {
+				completedTimes[round.ID] = session.CompletedTime
+			}
+		}
+	}
+
+	sort.SliceStable(rounds, func(i, j int) bool {
+		return completedTimes[rounds[i].ID].Before(completedTimes[rounds[j].ID])
+	})
+
+	var previous *ChampionshipStandingsSnapshot
+
+	for i, round := range rounds {
+		completedRounds := make(map[uuid.UUID]bool)
+
+		for _, completedRound := range rounds[:i+1] {
+			completedRounds[completedRound.ID] = true
+		}
+
+		var events []*ChampionshipEvent
+
+		for _, event := range championship.Events {
+			if completedRounds[event.ID] {
+				events = append(events, event)
+			}
+		}
+
+		snapshot := &ChampionshipStandingsSnapshot{
+			RoundID:       round.ID,
+			Round:         round.Number,
+			Name:          round.Name,
+			CompletedTime: completedTimes[round.ID],
+		}
+
+		for _, class := range championship.Classes {
+			classSnapshot := &ChampionshipClassStandingsSnapshot{
+				ClassID: class.ID,
+				Name:    class.Name,
+			}
+
+			for position, standing := range class.Standings(championship, events) {
+				classSnapshot.Drivers = append(classSnapshot.Drivers, &ChampionshipStandingsSnapshotEntry{
+					Position:   position + 1,
+					DriverGUID: standing.Car.Driver.GUID,
+					Name:       standing.Car.Driver.Name,
+					Team:       standing.TeamSummary(),
+					Points:     standing.Points,
+				})
+			}
+
+			if championship.HasTeamNames() {
+				for position, standing := range class.TeamStandings(championship, events) {
+					classSnapshot.Teams = append(classSnapshot.Teams, &ChampionshipStandingsSnapshotEntry{
+						Position: position + 1,
+						Name:     standing.Team,
+						Points:   standing.Points,
+					})
+				}
+			}
+
+			if previous != nil {
+				if previousClass := previous.Class(class.ID); previousClass != nil {
+					for _, driver := range classSnapshot.Drivers {
+						if previousDriver := previousClass.Driver(driver.DriverGUID); previousDriver != nil {
+							driver.PreviousPosition, driver.PreviousPoints = previousDriver.Position, previousDriver.Points
+						}
+					}
+
+					for _, team := range classSnapshot.Teams {
+						if previousTeam := previousClass.Team(team.Name); previousTeam != nil {
+							team.PreviousPosition, team.PreviousPoints = previousTeam.Position, previousTeam.Points
+						}
+					}
+				}
+			}
+
+			snapshot.Classes = append(snapshot.Classes, classSnapshot)
+		}
+
+		history.Snapshots = append(history.Snapshots, snapshot)
+		previous = snapshot
+	}
+
+	return history
+}
+
+// outdated is true if the championship (or one of its race weekends) has changed since the history was built.
+func (h *ChampionshipStandingsHistory) outdated(championship *Championship) bool {
+	if h.Updated.Before(championship.Updated) {
+		return true
+	}
+
+	for _, event := range championship.Events {
+		if event.IsRaceWeekend() && event.RaceWeekend != nil && h.Updated.Before(event.RaceWeekend.Updated) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasProgression is true once there are at least two rounds to compare.
+func (h *ChampionshipStandingsHistory) HasProgression() bool {
+	return h != nil && len(h.Snapshots) > 1
+}
+
+// Latest is the snapshot of the most recently completed round.
+func (h *ChampionshipStandingsHistory) Latest() *ChampionshipStandingsSnapshot {
+	if h == nil || len(h.Snapshots) == 0 {
+		return nil
+	}
+
+	return h.Snapshots[len(h.Snapshots)-1]
+}
+
+// AsOfRound finds the snapshot taken after the given round of the championship was completed.
+func (h *ChampionshipStandingsHistory) AsOfRound(round int) *ChampionshipStandingsSnapshot {
+	if h == nil {
+		return nil
+	}
+
+	for _, snapshot := range h.Snapshots {
+		if snapshot.Round == round {
+			return snapshot
+		}
+	}
+
+	return nil
+}
+
+// DriverPositionChange is the number of positions a driver gained or lost in the most recently completed round.
+func (h *ChampionshipStandingsHistory) DriverPositionChange(classID uuid.UUID, driverGUID string) int {
+	if class := h.Latest().Class(classID); class != nil {
+		if driver := class.Driver(driverGUID); driver != nil {
+			return driver.PositionChange()
+		}
+	}
+
+	return 0
+}
+
+// TeamPositionChange is the number of positions a team gained or lost in the most recently completed round.
+func (h *ChampionshipStandingsHistory) TeamPositionChange(classID uuid.UUID, team string) int {
+	if class := h.Latest().Class(classID); class != nil {
+		if teamSnapshot := class.Team(team); teamSnapshot != nil {
+			return teamSnapshot.PositionChange()
+		}
+	}
+
+	return 0
+}
+
+func (s *ChampionshipStandingsSnapshot) Class(classID uuid.UUID) *ChampionshipClassStandingsSnapshot {
+	if s == nil {
+		return nil
+	}
+
+	for _, class := range s.Classes {
+		if class.ClassID == classID {
+			return class
+		}
+	}
+
+	return nil
+}
+
+func (cs *ChampionshipClassStandingsSnapshot) Driver(driverGUID string) *ChampionshipStandingsSnapshotEntry {
+	for _, driver := range cs.Drivers {
+		if driver.DriverGUID == driverGUID {
+			return driver
+		}
+	}
+
+	return nil
+}
+
+func (cs *ChampionshipClassStandingsSnapshot) Team(name string) *ChampionshipStandingsSnapshotEntry {
+	for _, team := range cs.Teams {
+		if team.Name == name {
+			return team
+		}
+	}
+
+	return nil
+}
+
+// StandingsHistory loads the standings history of a championship, rebuilding it if the championship has changed since
+// it was last stored.
+func (cm *ChampionshipManager) StandingsHistory(championship *Championship) (*ChampionshipStandingsHistory, error) {
+	history, err := cm.store.LoadChampionshipStandingsHistory(championship.ID.String())
+
+	if err != nil && err != ErrChampionshipStandingsHistoryNotFound {
+		return nil, err
+	}
+
+	if history != nil && !history.outdated(championship) {
+		return history, nil
+	}
+
+	history = NewChampionshipStandingsHistory(championship)
+
+	if err := cm.store.UpsertChampionshipStandingsHistory(history); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (ch *ChampionshipsHandler) loadStandingsHistory(w http.ResponseWriter, r *http.Request) (*ChampionshipStandingsHistory, bool) {
+	championship, err := ch.championshipManager.LoadChampionship(chi.URLParam(r, "championshipID"))
+
+	if err == ErrChampionshipNotFound || os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return nil, false
+	} else if err != nil {
+		logrus.WithError(err).Error("couldn't load championship")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	history, err := ch.championshipManager.StandingsHistory(championship)
+
+	if err != nil {
+		logrus.WithError(err).Error("couldn't load championship standings history")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	for _, snapshot := range history.Snapshots {
+		for _, class := range snapshot.Classes {
+			for _, driver := range class.Drivers {
+				driver.Name = driverName(driver.Name)
+			}
+		}
+	}
+
+	return history, true
+}
+
+func writeStandingsJSON(w http.ResponseWriter, data interface{}) {
+	w.Header().Add("Content-Type", "application/json")
+
+	enc := json.NewEncoder(w)
+	if Debug {
+		enc.SetIndent("", "    ")
+	}
+	_ = enc.Encode(data)
+}
+
+// standingsHistoryJSON serves the standings of a Championship after each of its completed rounds as JSON.
+func (ch *ChampionshipsHandler) standingsHistoryJSON(w http.ResponseWriter, r *http.Request) {
+	history, ok := ch.loadStandingsHistory(w, r)
+
+	if !ok {
+		return
+	}
+
+	writeStandingsJSON(w, history)
+}
+
+// standingsJSON serves the current standings of a Championship as JSON, or the standings as of a given round
+// (e.g. ?round=3).
+func (ch *ChampionshipsHandler) standingsJSON(w http.ResponseWriter, r *http.Request) {
+	history, ok := ch.loadStandingsHistory(w, r)
+
+	if !ok {
+		return
+	}
+
+	snapshot := history.Latest()
+
+	if round := r.URL.Query().Get("round"); round != "" {
+		roundNumber, err := strconv.Atoi(round)
+
+		if err != nil {
+			http.Error(w, "invalid round", http.StatusBadRequest)
+			return
+		}
+
+		snapshot = history.AsOfRound(roundNumber)
+	}
+
+	if snapshot == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	writeStandingsJSON(w, snapshot)
+}
@@ -0,0 +1,84 @@
+package servermanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewChampionshipStandingsHistory(t *testing.T) {
+	championship, class := newTestChampionship().Places(25, 18, 15).
+		Race("A", "B", "C").
+		Race("C", "A", "B").
+		Race("C", "B", "A").
+		Build()
+
+	// a fourth round hasn't been raced yet
+	championship.Events = append(championship.Events, NewChampionshipEvent())
+
+	history := NewChampionshipStandingsHistory(championship)
+
+	if len(history.Snapshots) != 3 {
+		t.Fatalf("Expected a snapshot for each completed round, got %d", len(history.Snapshots))
+	}
+
+	for i, expected := range [][]string{{"A", "B", "C"}, {"A", "C", "B"}, {"C", "A", "B"}} {
+		snapshot := history.Snapshots[i]
+
+		if snapshot.Round != i+1 || snapshot.RoundID != championship.Events[i].ID {
+			t.Errorf("Expected snapshot %d to be of round %d, got round %d", i, i+1, snapshot.Round)
+		}
+
+		drivers := snapshot.Class(class.ID).Drivers
+
+		for position, guid := range expected {
+			if drivers[position].DriverGUID != guid || drivers[position].Position != position+1 {
+				t.Errorf("Expected %s to be P%d after round %d, got %s", guid, position+1, i+1, drivers[position].DriverGUID)
+			}
+		}
+	}
+
+	// after round 2, C (3rd -> 2nd) gained a position and B (2nd -> 3rd) lost one.
+	round2 := history.AsOfRound(2).Class(class.ID)
+
+	if c := round2.Driver("C"); c.PreviousPosition != 3 || c.PositionChange() != 1 || c.Points != 40 || c.PreviousPoints != 15 {
+		t.Errorf("Incorrect progression for C: %+v", c)
+	}
+
+	if b := round2.Driver("B"); b.PositionChange() != -1 {
+		t.Errorf("Expected B to have lost a position, got %d", b.PositionChange())
+	}
+
+	if change := history.DriverPositionChange(class.ID, "A"); change != -1 {
+		t.Errorf("Expected A to have lost a position in the last round, got %d", change)
+	}
+
+	if history.AsOfRound(4) != nil {
+		t.Errorf("Expected no standings as of an uncompleted round")
+	}
+
+	t.Run("Snapshots are in the order rounds were completed", func(t *testing.T) {
+		championship.Events[0].Sessions[SessionTypeRace].CompletedTime = time.Now().Add(time.Hour * 24)
+
+		history := NewChampionshipStandingsHistory(championship)
+
+		if history.Latest().Round != 1 {
+			t.Fatalf("Expected round 1 to be the latest snapshot, got round %d", history.Latest().Round)
+		}
+
+		if drivers := history.Snapshots[0].Class(class.ID).Drivers; drivers[0].DriverGUID != "C" || drivers[0].PreviousPosition != 0 {
+			t.Errorf("Expected the first snapshot to be round 2, won by C")
+		}
+	})
+
+	t.Run("Outdated when the championship changes", func(t *testing.T) {
+		if history.outdated(championship) {
+			t.Errorf("Expected a new history to be up to date")
+		}
+
+		championship.Updated = time.Now()
+
+		if !history.outdated(championship) {
+			t.Errorf("Expected the history to be outdated")
+		}
+	})
+}
@@ -0,0 +1,542 @@
+package servermanager
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ChampionshipTeam is a team in a Championship. A driver is part of a team if the Team of their entrant is the
+// team's Name, so the roster of a team is always the same as the entry list.
+type ChampionshipTeam struct {
+	ID   uuid.UUID
+	Name string
+
+	// Logo is the URL of an image which is shown next to the team in the standings.
+	Logo string
+
+	// Livery is the skin used by the team's cars, for entrants who don't have a skin of their own.
+	Livery string
+
+	// NominatedDrivers are the GUIDs of the drivers whose points count for the team, if only nominated drivers score
+	// team points.
+	NominatedDrivers []string
+}
+
+// IsNominated is true if the driver scores points for the team when only nominated drivers count.
+func (t *ChampionshipTeam) IsNominated(driverGUID string) bool {
+	if t == nil {
+		return false
+	}
+
+	for _, guid := range t.NominatedDrivers {
+		if guid == driverGUID || NormaliseEntrantGUIDs(strings.Split(guid, driverSwapEntrantSeparator)) == driverGUID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// TeamTransferRule decides which team the points of a driver who has changed teams during a Championship count for.
+type TeamTransferRule string
+
+const (
+	// TeamTransferPointsStay keeps points with the team they were scored for.
+	TeamTransferPointsStay TeamTransferRule = ""
+	// TeamTransferPointsMove moves points to the team the driver is in now.
+	TeamTransferPointsMove TeamTransferRule = "move"
+	// TeamTransferPointsForfeit removes points scored for a team the driver has left, so no team keeps them.
+	TeamTransferPointsForfeit TeamTransferRule = "forfeit"
+)
+
+var teamTransferRuleDescriptions = map[TeamTransferRule]string{
+	TeamTransferPointsStay:    "Points stay with the team they were scored for",
+	TeamTransferPointsMove:    "Points move with the driver to their new team",
+	TeamTransferPointsForfeit: "Points scored for a previous team are forfeited",
+}
+
+// Description is shown when choosing a transfer rule.
+func (r TeamTransferRule) Description() string {
+	return teamTransferRuleDescriptions[r]
+}
+
+// TeamTransferRules are the transfer rules which can be chosen for a Championship.
+func TeamTransferRules() []TeamTransferRule {
+	return []TeamTransferRule{TeamTransferPointsStay, TeamTransferPointsMove, TeamTransferPointsForfeit}
+}
+
+// ChampionshipTeamScoring are the rules for how drivers' points count towards the Team and Manufacturer Standings.
+type ChampionshipTeamScoring struct {
+	// CountBestCars is the number of each team's highest scoring cars in a session whose points count. 0 counts every car.
+	CountBestCars int
+
+	// NominatedDriversOnly only counts the points of each team's nominated drivers.
+	NominatedDriversOnly bool
+
+	// Transfers decides which team the points of a driver who has changed teams count for.
+	Transfers TeamTransferRule
+
+	// Manufacturers enables the Manufacturer Standings, where points are scored for the brand of each car.
+	Manufacturers bool
+
+	// ManufacturerCountBestCars is the number of each manufacturer's highest scoring cars in a session whose points
+	// count. 0 counts every car.
+	ManufacturerCountBestCars int
+}
+
+// Summary describes the team scoring rules.
+func (ts ChampionshipTeamScoring) Summary() string {
+	var rules []string
+
+	if ts.CountBestCars > 0 {
+		rules = append(rules, fmt.Sprintf("Each team's best %d car(s) in each session score points", ts.CountBestCars))
+	}
+
+	if ts.NominatedDriversOnly {
+		rules = append(rules, "Only each team's nominated drivers score points")
+	}
+
+	if ts.Transfers != TeamTransferPointsStay {
+		rules = append(rules, ts.Transfers.Description())
+	}
+
+	if len(rules) == 0 {
+		return ""
+	}
+
+	return strings.Join(rules, ". ") + "."
+}
+
+// FindTeam finds a team of the Championship by name.
+func (c *Championship) FindTeam(name string) *ChampionshipTeam {
+	for _, team := range c.Teams {
+		if team.Name == name {
+			return team
+		}
+	}
+
+	return nil
+}
+
+// TeamNames are the names of every team in the Championship, whether they are set up as a team or are just the Team
+// of an entrant or result.
+func (c *Championship) TeamNames() []string {
+	teams := make(map[string]bool)
+
+	for _, team := range c.Teams {
+		teams[team.Name] = true
+	}
+
+	for _, entrant := range c.AllEntrants() {
+		if entrant.Team != "" {
+			teams[entrant.Team] = true
+		}
+	}
+
+	for _, event := range ExtractRaceWeekendSessionsIntoIndividualEvents(c.Events) {
+		for _, session := range event.Sessions {
+			if session.Results == nil {
+				continue
+			}
+
+			for _, car := range session.Results.Cars {
+				if car.Driver.Team != "" {
+					teams[car.Driver.Team] = true
+				}
+			}
+		}
+	}
+
+	var out []string
+
+	for team := range teams {
+		out = append(out, team)
+	}
+
+	sort.Strings(out)
+
+	return out
+}
+
+// TeamRoster is the entrants of every class who drive for a team.
+func (c *Championship) TeamRoster(team string) []*Entrant {
+	var roster []*Entrant
+
+	for _, class := range c.Classes {
+		for _, entrant := range class.Entrants.AsSlice() {
+			if entrant.Team == team && (entrant.GUID != "" || entrant.Name != "") {
+				roster = append(roster, entrant)
+			}
+		}
+	}
+
+	return roster
+}
+
+// numScoringCars is the most cars of a team in a class which can score points in a session.
+func (c *Championship) numScoringCars(class *ChampionshipClass, team string) int {
+	numCars := 0
+
+	for _, entrant := range class.Entrants {
+		if entrant.Team != team {
+			continue
+		}
+
+		if c.TeamScoring.NominatedDriversOnly {
+			if !c.FindTeam(team).IsNominated(entrant.GUID) {
+				continue
+			}
+		}
+
+		numCars++
+	}
+
+	if c.TeamScoring.CountBestCars > 0 && numCars > c.TeamScoring.CountBestCars {
+		numCars = c.TeamScoring.CountBestCars
+	}
+
+	return numCars
+}
+
+// numScoringManufacturerCars is the most cars of a manufacturer in a class which can score points in a session.
+func (c *Championship) numScoringManufacturerCars(class *ChampionshipClass, manufacturer string) int {
+	numCars := 0
+
+	for _, entrant := range class.Entrants {
+		if entrant.Model != "" && carManufacturer(entrant.Model) == manufacturer {
+			numCars++
+		}
+	}
+
+	if c.TeamScoring.ManufacturerCountBestCars > 0 && numCars > c.TeamScoring.ManufacturerCountBestCars {
+		numCars = c.TeamScoring.ManufacturerCountBestCars
+	}
+
+	return numCars
+}
+
+// currentTeam is the team a driver is entered in the class with.
+func (c *ChampionshipClass) currentTeam(driverGUID string) (team string, ok bool) {
+	for _, entrant := range c.Entrants {
+		if entrant.GUID == "" {
+			continue
+		}
+
+		if entrant.GUID == driverGUID || NormaliseEntrantGUIDs(strings.Split(entrant.GUID, driverSwapEntrantSeparator)) == driverGUID {
+			return entrant.Team, true
+		}
+	}
+
+	return "", false
+}
+
+// teamForPoints is the team that points a driver scored while driving for a team count for, following the transfer
+// rules of the championship. ok is false if the points don't count for any team.
+func (c *ChampionshipClass) teamForPoints(championship *Championship, driverGUID, team string) (string, bool) {
+	if currentTeam, entered := c.currentTeam(driverGUID); entered && currentTeam != team {
+		switch championship.TeamScoring.Transfers {
+		case TeamTransferPointsMove:
+			team = currentTeam
+		case TeamTransferPointsForfeit:
+			return "", false
+		}
+	}
+
+	if championship.TeamScoring.NominatedDriversOnly {
+		if !championship.FindTeam(team).IsNominated(driverGUID) {
+			return "", false
+		}
+	}
+
+	return team, true
+}
+
+// teamInEvent is the team a driver was in for an event.
+func teamInEvent(event *ChampionshipEvent, driverGUID string) string {
+	for _, session := range event.Sessions {
+		if session.Results == nil {
+			continue
+		}
+
+		for _, car := range session.Results.Cars {
+			if car.Driver.GUID == driverGUID || NormaliseEntrantGUIDs(car.Driver.GuidsList) == driverGUID {
+				return car.Driver.Team
+			}
+		}
+
+		break
+	}
+
+	return ""
+}
+
+// carInEvent is the car a driver drove in an event.
+func carInEvent(event *ChampionshipEvent, driverGUID string) string {
+	for _, session := range event.Sessions {
+		if session.Results == nil {
+			continue
+		}
+
+		for _, car := range session.Results.Cars {
+			if car.Driver.GUID == driverGUID || NormaliseEntrantGUIDs(car.Driver.GuidsList) == driverGUID {
+				return car.Model
+			}
+		}
+	}
+
+	return ""
+}
+
+// sessionPoints are the points each driver scored for a group (a team or manufacturer) in each session.
+type sessionPoints map[string]map[string]map[string]float64
+
+func (sp sessionPoints) add(session, group, driverGUID string, points float64) {
+	if _, ok := sp[session]; !ok {
+		sp[session] = make(map[string]map[string]float64)
+	}
+
+	if _, ok := sp[session][group]; !ok {
+		sp[session][group] = make(map[string]float64)
+	}
+
+	sp[session][group][driverGUID] += points
+}
+
+// total adds up the points of each group, counting only the best countBestCars drivers of each group in a session
+// (or every driver if countBestCars is 0).
+func (sp sessionPoints) total(countBestCars int) map[string]float64 {
+	totals := make(map[string]float64)
+
+	for _, groups := range sp {
+		for group, drivers := range groups {
+			var points []float64
+
+			for _, driverPoints := range drivers {
+				points = append(points, driverPoints)
+			}
+
+			sort.Sort(sort.Reverse(sort.Float64Slice(points)))
+
+			if countBestCars > 0 && len(points) > countBestCars {
+				points = points[:countBestCars]
+			}
+
+			if _, ok := totals[group]; !ok {
+				totals[group] = 0
+			}
+
+			for _, driverPoints := range points {
+				totals[group] += driverPoints
+			}
+		}
+	}
+
+	return totals
+}
+
+// ManufacturerStanding is the current number of Points a car manufacturer has.
+type ManufacturerStanding struct {
+	Manufacturer string
+	Points       float64
+
+	// Cars are the models of the manufacturer which have scored points.
+	Cars []string
+}
+
+// ManufacturerStandings returns the current position of car manufacturers in the class. Each car scores points for
+// the brand in its ui_car.json.
+func (c *ChampionshipClass) ManufacturerStandings(championship *Championship, inEvents []*ChampionshipEvent) []*ManufacturerStanding {
+	points := make(sessionPoints)
+	cars := make(map[string]map[string]bool)
+
+	// make a copy of events so we do not persist race weekend sessions
+	events := ExtractRaceWeekendSessionsIntoIndividualEvents(inEvents)
+
+	c.standings(championship, events, func(event *ChampionshipEvent, award *PointsAward) {
+		model := carInEvent(event, award.DriverGUID)
+
+		if model == "" {
+			return
+		}
+
+		manufacturer := carManufacturer(model)
+
+		if _, ok := cars[manufacturer]; !ok {
+			cars[manufacturer] = make(map[string]bool)
+		}
+
+		cars[manufacturer][model] = true
+
+		points.add(event.ID.String()+"/"+award.Session.String(), manufacturer, award.DriverGUID, award.Points)
+	})
+
+	var out []*ManufacturerStanding
+
+	for manufacturer, manufacturerPoints := range points.total(championship.TeamScoring.ManufacturerCountBestCars) {
+		standing := &ManufacturerStanding{
+			Manufacturer: manufacturer,
+			Points:       manufacturerPoints,
+		}
+
+		for model := range cars[manufacturer] {
+			standing.Cars = append(standing.Cars, model)
+		}
+
+		sort.Strings(standing.Cars)
+
+		out = append(out, standing)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Points == out[j].Points {
+			return out[i].Manufacturer < out[j].Manufacturer
+		}
+
+		return out[i].Points > out[j].Points
+	})
+
+	return out
+}
+
+var (
+	// carManufacturerCache is a map of car key -> the brand of the car.
+	carManufacturerCache = make(map[string]string)
+	carManufacturerMutex sync.RWMutex
+)
+
+// carManufacturer is the brand of a car from its ui_car.json, or the name of the car if it doesn't have one.
+func carManufacturer(model string) string {
+	carManufacturerMutex.RLock()
+	manufacturer, ok := carManufacturerCache[model]
+	carManufacturerMutex.RUnlock()
+
+	if ok {
+		return manufacturer
+	}
+
+	var details CarDetails
+
+	if err := details.Load(model); err == nil {
+		manufacturer = strings.TrimSpace(details.Brand)
+	}
+
+	if manufacturer == "" {
+		manufacturer = prettifyName(model, true)
+	}
+
+	carManufacturerMutex.Lock()
+	carManufacturerCache[model] = manufacturer
+	carManufacturerMutex.Unlock()
+
+	return manufacturer
+}
+
+// forgetCarManufacturer removes a car from the manufacturer cache, e.g. when its details have changed.
+func forgetCarManufacturer(model string) {
+	carManufacturerMutex.Lock()
+	defer carManufacturerMutex.Unlock()
+
+	delete(carManufacturerCache, model)
+}
+
+// applyTeamLiveries gives the entrants of each team which don't have a skin of their own the team's livery.
+func (c *Championship) applyTeamLiveries(entryList EntryList) {
+	for _, entrant := range entryList {
+		if entrant.Skin != "" || entrant.Team == "" {
+			continue
+		}
+
+		if team := c.FindTeam(entrant.Team); team != nil && team.Livery != "" {
+			entrant.Skin = team.Livery
+		}
+	}
+}
+
+// SaveTeams updates the teams and team scoring rules of a championship from the teams form.
+func (cm *ChampionshipManager) SaveTeams(championshipID string, r *http.Request) (*Championship, error) {
+	championship, err := cm.LoadChampionship(championshipID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	championship.TeamScoring = ChampionshipTeamScoring{
+		CountBestCars:             formValueAsInt(r.FormValue("TeamScoring.CountBestCars")),
+		NominatedDriversOnly:      r.FormValue("TeamScoring.NominatedDriversOnly") == "on" || r.FormValue("TeamScoring.NominatedDriversOnly") == "1",
+		Transfers:                 TeamTransferRule(r.FormValue("TeamScoring.Transfers")),
+		Manufacturers:             r.FormValue("TeamScoring.Manufacturers") == "on" || r.FormValue("TeamScoring.Manufacturers") == "1",
+		ManufacturerCountBestCars: formValueAsInt(r.FormValue("TeamScoring.ManufacturerCountBestCars")),
+	}
+
+	var teams []*ChampionshipTeam
+
+	for i, name := range r.Form["Team.Name"] {
+		if name == "" || r.FormValue(fmt.Sprintf("Team.%d.Remove", i)) == "on" {
+			continue
+		}
+
+		team := championship.FindTeam(name)
+
+		if team == nil {
+			team = &ChampionshipTeam{ID: uuid.New(), Name: name}
+		}
+
+		team.Logo = strings.TrimSpace(r.Form["Team.Logo"][i])
+		team.Livery = strings.TrimSpace(r.Form["Team.Livery"][i])
+		team.NominatedDrivers = r.Form[fmt.Sprintf("Team.%d.NominatedDrivers", i)]
+
+		teams = append(teams, team)
+	}
+
+	championship.Teams = teams
+
+	return championship, cm.UpsertChampionship(championship)
+}
+
+type championshipTeamsTemplateVars struct {
+	BaseTemplateVars
+
+	Championship  *Championship
+	TransferRules []TeamTransferRule
+}
+
+// teams shows the teams of a championship, with their rosters and the team scoring rules.
+func (ch *ChampionshipsHandler) teams(w http.ResponseWriter, r *http.Request) {
+	championship, err := ch.championshipManager.LoadChampionship(chi.URLParam(r, "championshipID"))
+
+	if err != nil {
+		logrus.WithError(err).Error("couldn't load championship")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	ch.viewRenderer.MustLoadTemplate(w, r, "championships/teams.html", &championshipTeamsTemplateVars{
+		Championship:  championship,
+		TransferRules: TeamTransferRules(),
+	})
+}
+
+func (ch *ChampionshipsHandler) saveTeams(w http.ResponseWriter, r *http.Request) {
+	championship, err := ch.championshipManager.SaveTeams(chi.URLParam(r, "championshipID"), r)
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't save championship teams")
+		AddErrorFlash(w, r, "Couldn't save teams")
+		http.Redirect(w, r, r.Referer(), http.StatusFound)
+		return
+	}
+
+	AddFlash(w, r, "Teams successfully saved!")
+	http.Redirect(w, r, "/championship/"+championship.ID.String(), http.StatusFound)
+}
@@ -0,0 +1,186 @@
+package servermanager
+
+import (
+	"testing"
+)
+
+func teamStandingsPoints(standings []*TeamStanding) map[string]float64 {
+	points := make(map[string]float64)
+
+	for _, standing := range standings {
+		points[standing.Team] = standing.Points
+	}
+
+	return points
+}
+
+func TestChampionshipClass_TeamStandings(t *testing.T) {
+	t.Run("Team scoring", func(t *testing.T) {
+		entrants := map[string]string{"A": "X", "B": "X", "C": "X", "D": "Y"}
+
+		for _, testCase := range []struct {
+			name     string
+			scoring  ChampionshipTeamScoring
+			expected map[string]float64
+		}{
+			{
+				name:     "Every car scores",
+				expected: map[string]float64{"X": 20, "Y": 3},
+			},
+			{
+				name:     "Best two cars score",
+				scoring:  ChampionshipTeamScoring{CountBestCars: 2},
+				expected: map[string]float64{"X": 16, "Y": 3},
+			},
+			{
+				name:     "Nominated drivers score",
+				scoring:  ChampionshipTeamScoring{NominatedDriversOnly: true},
+				expected: map[string]float64{"X": 14},
+			},
+		} {
+			t.Run(testCase.name, func(t *testing.T) {
+				championship, class := newTestChampionship().Places(10, 6, 4, 3).Race("A", "B", "C", "D").Teams(entrants).Entrants(entrants).Build()
+				championship.TeamScoring = testCase.scoring
+				championship.Teams = []*ChampionshipTeam{{Name: "X", NominatedDrivers: []string{"A", "C"}}}
+
+				points := teamStandingsPoints(class.TeamStandings(championship, championship.Events))
+
+				if len(points) != len(testCase.expected) {
+					t.Errorf("Expected %d teams, got %v", len(testCase.expected), points)
+				}
+
+				for team, expected := range testCase.expected {
+					if points[team] != expected {
+						t.Errorf("Expected %s to have %.0f points, got %.0f", team, expected, points[team])
+					}
+				}
+			})
+		}
+	})
+
+	t.Run("Transfers", func(t *testing.T) {
+		for _, testCase := range []struct {
+			rule     TeamTransferRule
+			expected map[string]float64
+		}{
+			{rule: TeamTransferPointsStay, expected: map[string]float64{"X": 22, "Y": 10}},
+			{rule: TeamTransferPointsMove, expected: map[string]float64{"X": 16, "Y": 16}},
+			{rule: TeamTransferPointsForfeit, expected: map[string]float64{"X": 16, "Y": 10}},
+		} {
+			t.Run(testCase.rule.Description(), func(t *testing.T) {
+				// D drives for X in the first round, then moves to Y.
+				championship, class := newTestChampionship().Places(10, 6).
+					Race("A", "D").Teams(map[string]string{"A": "X", "D": "X"}).
+					Race("D", "A").Teams(map[string]string{"A": "X", "D": "Y"}).
+					Entrants(map[string]string{"A": "X", "D": "Y"}).
+					Build()
+				championship.TeamScoring.Transfers = testCase.rule
+
+				points := teamStandingsPoints(class.TeamStandings(championship, championship.Events))
+
+				for team, expected := range testCase.expected {
+					if points[team] != expected {
+						t.Errorf("Expected %s to have %.0f points, got %.0f", team, expected, points[team])
+					}
+				}
+			})
+		}
+	})
+}
+
+func TestChampionshipClass_ManufacturerStandings(t *testing.T) {
+	carManufacturerMutex.Lock()
+	carManufacturerCache["ks_audi_r8_lms"] = "Audi"
+	carManufacturerCache["ks_bmw_m6_gt3"] = "BMW"
+	carManufacturerMutex.Unlock()
+
+	defer forgetCarManufacturer("ks_audi_r8_lms")
+	defer forgetCarManufacturer("ks_bmw_m6_gt3")
+
+	for _, testCase := range []struct {
+		name          string
+		countBestCars int
+		expected      map[string]float64
+	}{
+		{name: "Every car scores", expected: map[string]float64{"Audi": 26, "BMW": 14}},
+		{name: "Best car scores", countBestCars: 1, expected: map[string]float64{"Audi": 16, "BMW": 14}},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			championship, class := newTestChampionship().Places(10, 6, 4).Car("D", "ks_bmw_m6_gt3").Race("A", "B", "D").Race("D", "A", "B").Build()
+			championship.TeamScoring.ManufacturerCountBestCars = testCase.countBestCars
+
+			standings := class.ManufacturerStandings(championship, championship.Events)
+
+			if len(standings) != 2 || standings[0].Manufacturer != "Audi" || len(standings[1].Cars) != 1 || standings[1].Cars[0] != "ks_bmw_m6_gt3" {
+				t.Fatalf("Incorrect manufacturer standings: %+v", standings)
+			}
+
+			for _, standing := range standings {
+				if expected := testCase.expected[standing.Manufacturer]; standing.Points != expected {
+					t.Errorf("Expected %s to have %.0f points, got %.0f", standing.Manufacturer, expected, standing.Points)
+				}
+			}
+		})
+	}
+}
+
+func TestChampionship_applyTeamLiveries(t *testing.T) {
+	championship := NewChampionship("Liveries")
+	championship.Teams = []*ChampionshipTeam{{Name: "X", Livery: "team_x"}}
+
+	entryList := make(EntryList)
+
+	for _, skin := range []string{"", "own_skin"} {
+		entrant := NewEntrant()
+		entrant.Team = "X"
+		entrant.Skin = skin
+
+		entryList.AddToBackOfGrid(entrant)
+	}
+
+	championship.applyTeamLiveries(entryList)
+
+	entrants := entryList.AsSlice()
+
+	if entrants[0].Skin != "team_x" || entrants[1].Skin != "own_skin" {
+		t.Errorf("Expected only entrants without a skin to use the team livery, got: %s, %s", entrants[0].Skin, entrants[1].Skin)
+	}
+}
+
+func TestChampionshipClass_ManufacturerTitlePermutations(t *testing.T) {
+	carManufacturerMutex.Lock()
+	carManufacturerCache["ks_audi_r8_lms"] = "Audi"
+	carManufacturerCache["ks_bmw_m6_gt3"] = "BMW"
+	carManufacturerCache["ks_mercedes_amg_gt3"] = "Mercedes-Benz"
+	carManufacturerMutex.Unlock()
+
+	defer forgetCarManufacturer("ks_audi_r8_lms")
+	defer forgetCarManufacturer("ks_bmw_m6_gt3")
+	defer forgetCarManufacturer("ks_mercedes_amg_gt3")
+
+	championship, class := newTestChampionship().Places(10, 6, 4).
+		Car("D", "ks_bmw_m6_gt3").
+		Car("E", "ks_mercedes_amg_gt3").
+		Race("A", "B", "D").
+		RemainingRaces(1).
+		Entrants(map[string]string{"A": "", "B": "", "D": "", "E": ""}).
+		Build()
+	championship.TeamScoring.Manufacturers = true
+
+	permutations := class.TitlePermutations(championship)
+
+	maxPoints := make(map[string]float64)
+
+	for _, manufacturer := range permutations.Manufacturers {
+		maxPoints[manufacturer.Name] = manufacturer.MaxPoints
+	}
+
+	// Audi has two cars which can score 10 points each in the last round, BMW and Mercedes-Benz one each.
+	if len(maxPoints) != 3 || maxPoints["Audi"] != 36 || maxPoints["BMW"] != 14 || maxPoints["Mercedes-Benz"] != 10 {
+		t.Fatalf("Incorrect manufacturers: %v", maxPoints)
+	}
+
+	if contenders := permutations.ManufacturerContenders(); len(contenders) != 1 || contenders[0].Name != "Audi" || permutations.ManufacturerChampion() != nil {
+		t.Errorf("Expected only Audi to be able to win the manufacturers' title")
+	}
+}
@@ -0,0 +1,206 @@
+package servermanager
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// testChampionshipBuilder builds a championship with a single class, "GT3", for testing standings. Events are added in
+// the order they're built, and every driver keeps the same car ID throughout the championship.
+type testChampionshipBuilder struct {
+	championship *Championship
+	class        *ChampionshipClass
+
+	carIDs map[string]int
+	models map[string]string
+}
+
+func newTestChampionship() *testChampionshipBuilder {
+	championship := NewChampionship("Test Championship")
+	class := NewChampionshipClass("GT3")
+	championship.AddClass(class)
+
+	return &testChampionshipBuilder{
+		championship: championship,
+		class:        class,
+		carIDs:       make(map[string]int),
+		models:       make(map[string]string),
+	}
+}
+
+// Places sets the points for each finishing position. The class's default points are used otherwise.
+func (b *testChampionshipBuilder) Places(places ...int) *testChampionshipBuilder {
+	b.class.Points.Places = places
+
+	return b
+}
+
+func (b *testChampionshipBuilder) CountedResults(countedResults ChampionshipCountedResults) *testChampionshipBuilder {
+	b.championship.CountedResults = countedResults
+
+	return b
+}
+
+func (b *testChampionshipBuilder) PointsScheme(scheme PointsSchemeType) *testChampionshipBuilder {
+	b.class.PointsScheme = scheme
+
+	return b
+}
+
+// Car sets the car model a driver drives in the events and entry list built after it. Drivers drive the
+// ks_audi_r8_lms otherwise.
+func (b *testChampionshipBuilder) Car(guid, model string) *testChampionshipBuilder {
+	b.models[guid] = model
+
+	return b
+}
+
+// Race adds a completed event, at Monza, whose race was finished in the given order of driver GUIDs. Drivers whose GUID
+// is prefixed with "DSQ:" are disqualified.
+func (b *testChampionshipBuilder) Race(order ...string) *testChampionshipBuilder {
+	event := NewChampionshipEvent()
+	event.RaceSetup.Track = "monza"
+	event.CompletedTime = time.Now().Add(time.Duration(len(b.championship.Events)) * time.Hour)
+
+	b.championship.Events = append(b.championship.Events, event)
+
+	return b.session(SessionTypeRace, order)
+}
+
+// Races adds a completed event for each finishing order.
+func (b *testChampionshipBuilder) Races(orders ...[]string) *testChampionshipBuilder {
+	for _, order := range orders {
+		b.Race(order...)
+	}
+
+	return b
+}
+
+// Qualifying adds a qualifying session, finished in the given order of driver GUIDs, to the last event.
+func (b *testChampionshipBuilder) Qualifying(order ...string) *testChampionshipBuilder {
+	return b.session(SessionTypeQualifying, order)
+}
+
+func (b *testChampionshipBuilder) session(sessionType SessionType, order []string) *testChampionshipBuilder {
+	event := b.lastEvent()
+	results := &SessionResults{}
+
+	for i, guid := range order {
+		disqualified := strings.HasPrefix(guid, "DSQ:")
+		guid = strings.TrimPrefix(guid, "DSQ:")
+
+		results.Result = append(results.Result, &SessionResult{
+			CarID:        b.carID(guid),
+			CarModel:     b.model(guid),
+			DriverGUID:   guid,
+			DriverName:   guid,
+			TotalTime:    100000 + i,
+			ClassID:      b.class.ID,
+			Disqualified: disqualified,
+		})
+
+		results.Cars = append(results.Cars, &SessionCar{
+			CarID:  b.carID(guid),
+			Model:  b.model(guid),
+			Driver: SessionDriver{GUID: guid, Name: guid, ClassID: b.class.ID},
+		})
+	}
+
+	event.Sessions[sessionType] = &ChampionshipSession{CompletedTime: event.CompletedTime, Results: results}
+
+	return b
+}
+
+// Teams sets the team each driver drove for in the last event.
+func (b *testChampionshipBuilder) Teams(teams map[string]string) *testChampionshipBuilder {
+	for _, session := range b.lastEvent().Sessions {
+		for _, car := range session.Results.Cars {
+			car.Driver.Team = teams[car.Driver.GUID]
+		}
+	}
+
+	return b
+}
+
+// Entrants adds each driver to the class's entry list, in the given team, in order of GUID.
+func (b *testChampionshipBuilder) Entrants(teams map[string]string) *testChampionshipBuilder {
+	var guids []string
+
+	for guid := range teams {
+		guids = append(guids, guid)
+	}
+
+	sort.Strings(guids)
+
+	for _, guid := range guids {
+		entrant := NewEntrant()
+		entrant.GUID = guid
+		entrant.Name = guid
+		entrant.Team = teams[guid]
+		entrant.Model = b.model(guid)
+
+		b.class.Entrants.AddToBackOfGrid(entrant)
+	}
+
+	return b
+}
+
+// RemainingRaces adds events, at Spa, which haven't been run yet.
+func (b *testChampionshipBuilder) RemainingRaces(numRemaining int) *testChampionshipBuilder {
+	for i := 0; i < numRemaining; i++ {
+		event := NewChampionshipEvent()
+		event.RaceSetup.Track = "spa"
+		event.RaceSetup.Sessions = Sessions{
+			SessionTypeRace: &SessionConfig{Name: "Race", Laps: 10},
+		}
+
+		b.championship.Events = append(b.championship.Events, event)
+	}
+
+	return b
+}
+
+// Lap adds a lap by a driver to the race of the last event, completed at the given timestamp.
+func (b *testChampionshipBuilder) Lap(guid string, lapTime, timestamp int) *testChampionshipBuilder {
+	race := b.lastEvent().Sessions[SessionTypeRace].Results
+
+	race.Laps = append(race.Laps, &SessionLap{
+		CarID:      b.carID(guid),
+		CarModel:   b.model(guid),
+		DriverGUID: guid,
+		DriverName: guid,
+		LapTime:    lapTime,
+		Timestamp:  timestamp,
+		ClassID:    b.class.ID,
+	})
+
+	return b
+}
+
+func (b *testChampionshipBuilder) Build() (*Championship, *ChampionshipClass) {
+	return b.championship, b.class
+}
+
+func (b *testChampionshipBuilder) lastEvent() *ChampionshipEvent {
+	return b.championship.Events[len(b.championship.Events)-1]
+}
+
+func (b *testChampionshipBuilder) model(guid string) string {
+	if model, ok := b.models[guid]; ok {
+		return model
+	}
+
+	return "ks_audi_r8_lms"
+}
+
+func (b *testChampionshipBuilder) carID(guid string) int {
+	carID, ok := b.carIDs[guid]
+
+	if !ok {
+		carID = len(b.carIDs)
+		b.carIDs[guid] = carID
+	}
+
+	return carID
+}
@@ -0,0 +1,668 @@
+package servermanager
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// ChampionshipTitlePermutations are who can still win the driver, team and manufacturer titles of a ChampionshipClass,
+// given the points still available in the sessions which haven't been run yet.
+type ChampionshipTitlePermutations struct {
+	ClassID   uuid.UUID
+	ClassName string
+
+	// Available is false if the points scheme of the class can't say how many points are still available, e.g. if
+	// points are scored by a Lua script.
+	Available bool
+
+	RemainingRounds    int
+	MaxPointsRemaining float64
+
+	// NextRound is the next round of the championship (in championship order) in which points can be scored.
+	NextRound          string
+	MaxPointsNextRound float64
+
+	Drivers       []*ChampionshipTitleContender
+	Teams         []*ChampionshipTitleContender
+	Manufacturers []*ChampionshipTitleContender
+}
+
+// A ChampionshipTitleContender is a driver (or team, or manufacturer) in the standings of a class.
+type ChampionshipTitleContender struct {
+	DriverGUID string `json:",omitempty"`
+	Name       string
+	Position   int
+	Points     float64
+
+	// MinPoints is the fewest points the contender will finish the championship with, MaxPoints is the most. Both take
+	// counted results rules into account.
+	MinPoints float64
+	MaxPoints float64
+
+	CanWin   bool
+	Champion bool
+
+	// CanClinchNextRound is true if the contender can win the title in the next round, as long as they beat their rivals
+	// by the margins in NextRound. It is only worked out for championships without counted results rules.
+	CanClinchNextRound bool
+	NextRound          []*ChampionshipClinchRequirement
+
+	maxPointsNextRound      float64
+	maxPointsAfterNextRound float64
+}
+
+// A ChampionshipClinchRequirement is a result needed against a rival in the next round to clinch the title. The
+// contender must score more than Margin points more than the rival. A negative Margin means that the contender can
+// finish up to that many points behind the rival.
+type ChampionshipClinchRequirement struct {
+	RivalGUID string `json:",omitempty"`
+	Rival     string
+	Margin    float64
+}
+
+func (cr *ChampionshipClinchRequirement) String() string {
+	rival := cr.Rival
+
+	if cr.RivalGUID != "" {
+		rival = driverName(rival)
+	}
+
+	switch {
+	case cr.Margin < 0:
+		return fmt.Sprintf("Finish less than %g points behind %s", -cr.Margin, rival)
+	case cr.Margin == 0:
+		return fmt.Sprintf("Outscore %s", rival)
+	default:
+		return fmt.Sprintf("Outscore %s by more than %g points", rival, cr.Margin)
+	}
+}
+
+// Decided is true once a driver has clinched the title.
+func (p *ChampionshipTitlePermutations) Decided() bool {
+	return p.Champion() != nil
+}
+
+// Tied is true if there are no points left to score and the drivers' title is tied, even after the tie-break.
+func (p *ChampionshipTitlePermutations) Tied() bool {
+	return titleTied(p, p.Drivers)
+}
+
+// TeamTied is true if there are no points left to score and the teams' title is tied, even after the tie-break.
+func (p *ChampionshipTitlePermutations) TeamTied() bool {
+	return titleTied(p, p.Teams)
+}
+
+// ManufacturerTied is true if there are no points left to score and the manufacturers' title is tied.
+func (p *ChampionshipTitlePermutations) ManufacturerTied() bool {
+	return titleTied(p, p.Manufacturers)
+}
+
+func titleTied(p *ChampionshipTitlePermutations, contenders []*ChampionshipTitleContender) bool {
+	return p.Available && p.MaxPointsRemaining == 0 && titleChampion(contenders) == nil && len(titleContenders(contenders)) > 1
+}
+
+// Champion is the driver who has clinched the title, if any.
+func (p *ChampionshipTitlePermutations) Champion() *ChampionshipTitleContender {
+	return titleChampion(p.Drivers)
+}
+
+// TeamChampion is the team that has clinched the title, if any.
+func (p *ChampionshipTitlePermutations) TeamChampion() *ChampionshipTitleContender {
+	return titleChampion(p.Teams)
+}
+
+// ManufacturerChampion is the manufacturer that has clinched the title, if any.
+func (p *ChampionshipTitlePermutations) ManufacturerChampion() *ChampionshipTitleContender {
+	return titleChampion(p.Manufacturers)
+}
+
+func titleChampion(contenders []*ChampionshipTitleContender) *ChampionshipTitleContender {
+	for _, contender := range contenders {
+		if contender.Champion {
+			return contender
+		}
+	}
+
+	return nil
+}
+
+// Contenders are the drivers who can still win the title.
+func (p *ChampionshipTitlePermutations) Contenders() []*ChampionshipTitleContender {
+	return titleContenders(p.Drivers)
+}
+
+// TeamContenders are the teams that can still win the title.
+func (p *ChampionshipTitlePermutations) TeamContenders() []*ChampionshipTitleContender {
+	return titleContenders(p.Teams)
+}
+
+// ManufacturerContenders are the manufacturers that can still win the title.
+func (p *ChampionshipTitlePermutations) ManufacturerContenders() []*ChampionshipTitleContender {
+	return titleContenders(p.Manufacturers)
+}
+
+func titleContenders(contenders []*ChampionshipTitleContender) []*ChampionshipTitleContender {
+	var out []*ChampionshipTitleContender
+
+	for _, contender := range contenders {
+		if contender.CanWin {
+			out = append(out, contender)
+		}
+	}
+
+	return out
+}
+
+// TitlePermutations works out who can still win the titles of each class of the Championship.
+func (c *Championship) TitlePermutations() []*ChampionshipTitlePermutations {
+	var out []*ChampionshipTitlePermutations
+
+	for _, class := range c.Classes {
+		out = append(out, class.TitlePermutations(c))
+	}
+
+	return out
+}
+
+// TitlePermutations works out who can still win the driver, team and manufacturer titles of the class. Every driver is
+// assumed to be able to score the most points available in each remaining session, so the calculator never says that a
+// title is decided before it is. Team and manufacturer points are assumed to be the most a driver can score, for each
+// of the team's (or manufacturer's) cars which can score.
+func (c *ChampionshipClass) TitlePermutations(championship *Championship) *ChampionshipTitlePermutations {
+	permutations := &ChampionshipTitlePermutations{
+		ClassID:   c.ID,
+		ClassName: c.Name,
+	}
+
+	remainingPoints, ok := c.remainingPoints(championship)
+
+	if !ok {
+		return permutations
+	}
+
+	permutations.Available = true
+
+	var nextRound *ChampionshipEvent
+
+	for _, event := range championship.Events {
+		points, ok := remainingPoints[event.ID]
+
+		if !ok {
+			continue
+		}
+
+		permutations.RemainingRounds++
+		permutations.MaxPointsRemaining += points
+
+		if nextRound == nil && points > 0 {
+			nextRound = event
+			permutations.MaxPointsNextRound = points
+		}
+	}
+
+	if nextRound != nil {
+		round, _ := newChampionshipRound(championship, nextRound)
+		permutations.NextRound = round.Name
+	}
+
+	// drivers
+	rounds := championshipSeasonRounds(championship)
+	disqualified := roundsDisqualifiedFrom(rounds, ExtractRaceWeekendSessionsIntoIndividualEvents(championship.Events))
+	standings := c.Standings(championship, championship.Events)
+	standingsByGUID := make(map[string]*ChampionshipStanding)
+
+	for _, standing := range standings {
+		standingsByGUID[standing.Car.Driver.GUID] = standing
+	}
+
+	// entrants who haven't scored yet can still win the title, so they are contenders too.
+	for _, entrant := range c.Entrants.AsSlice() {
+		if entrant.GUID == "" || standingsByGUID[entrant.GUID] != nil {
+			continue
+		}
+
+		standing := &ChampionshipStanding{Car: &SessionCar{Driver: SessionDriver{GUID: entrant.GUID, Name: entrant.Name}}}
+		standingsByGUID[entrant.GUID] = standing
+		standings = append(standings, standing)
+	}
+
+	for position, standing := range standings {
+		permutations.Drivers = append(permutations.Drivers, &ChampionshipTitleContender{
+			DriverGUID:              standing.Car.Driver.GUID,
+			Name:                    standing.Car.Driver.Name,
+			Position:                position + 1,
+			Points:                  standing.Points,
+			MinPoints:               championship.finalPoints(rounds, standing, disqualified[standing.Car.Driver.GUID], nil),
+			MaxPoints:               championship.finalPoints(rounds, standing, disqualified[standing.Car.Driver.GUID], remainingPoints),
+			maxPointsNextRound:      permutations.MaxPointsNextRound,
+			maxPointsAfterNextRound: permutations.MaxPointsRemaining - permutations.MaxPointsNextRound,
+		})
+	}
+
+	driverTieBreak := func(a, b *ChampionshipTitleContender) bool {
+		if len(championship.Events) <= 1 {
+			return false
+		}
+
+		return c.hadBetterFinishingPositions(a.DriverGUID, standingsByGUID[a.DriverGUID].countedEvents(championship.Events), b.DriverGUID, standingsByGUID[b.DriverGUID].countedEvents(championship.Events), len(standings))
+	}
+
+	decideTitle(permutations.Drivers, permutations.MaxPointsRemaining, permutations.MaxPointsNextRound, !championship.CountedResults.Enabled(), driverTieBreak)
+
+	// teams
+	if championship.HasTeamNames() {
+		teamStandings := c.TeamStandings(championship, championship.Events)
+		teams := make(map[string]bool)
+
+		for _, standing := range teamStandings {
+			teams[standing.Team] = true
+		}
+
+		for _, entrant := range c.Entrants.AsSlice() {
+			if entrant.Team != "" && !teams[entrant.Team] {
+				teams[entrant.Team] = true
+				teamStandings = append(teamStandings, &TeamStanding{Team: entrant.Team})
+			}
+		}
+
+		// the most points a team can score depends on how many of its cars can score, so the most points that any
+		// team can score is that of the team with the most scoring cars.
+		mostScoringCars := 1
+
+		for position, standing := range teamStandings {
+			numDrivers := championship.numScoringCars(c, standing.Team)
+
+			if numDrivers == 0 {
+				numDrivers = 1
+			}
+
+			if numDrivers > mostScoringCars {
+				mostScoringCars = numDrivers
+			}
+
+			permutations.Teams = append(permutations.Teams, &ChampionshipTitleContender{
+				Name:                    standing.Team,
+				Position:                position + 1,
+				Points:                  standing.Points,
+				MinPoints:               standing.Points,
+				MaxPoints:               standing.Points + permutations.MaxPointsRemaining*float64(numDrivers),
+				maxPointsNextRound:      permutations.MaxPointsNextRound * float64(numDrivers),
+				maxPointsAfterNextRound: (permutations.MaxPointsRemaining - permutations.MaxPointsNextRound) * float64(numDrivers),
+			})
+		}
+
+		teamTieBreak := func(a, b *ChampionshipTitleContender) bool {
+			if len(championship.Events) <= 1 {
+				return false
+			}
+
+			return c.teamHadBetterFinishingPositions(a.Name, b.Name, championship.Events, len(teamStandings))
+		}
+
+		decideTitle(permutations.Teams, permutations.MaxPointsRemaining*float64(mostScoringCars), permutations.MaxPointsNextRound*float64(mostScoringCars), true, teamTieBreak)
+	}
+
+	// manufacturers
+	if championship.TeamScoring.Manufacturers {
+		var manufacturerStandings []*ManufacturerStanding
+		manufacturers := make(map[string]bool)
+
+		for _, standing := range c.ManufacturerStandings(championship, championship.Events) {
+			manufacturers[standing.Manufacturer] = true
+			manufacturerStandings = append(manufacturerStandings, standing)
+		}
+
+		for _, entrant := range c.Entrants.AsSlice() {
+			if entrant.Model == "" {
+				continue
+			}
+
+			if manufacturer := carManufacturer(entrant.Model); !manufacturers[manufacturer] {
+				manufacturers[manufacturer] = true
+				manufacturerStandings = append(manufacturerStandings, &ManufacturerStanding{Manufacturer: manufacturer})
+			}
+		}
+
+		mostScoringCars := 1
+
+		for position, standing := range manufacturerStandings {
+			numCars := championship.numScoringManufacturerCars(c, standing.Manufacturer)
+
+			if numCars == 0 {
+				numCars = 1
+			}
+
+			if numCars > mostScoringCars {
+				mostScoringCars = numCars
+			}
+
+			permutations.Manufacturers = append(permutations.Manufacturers, &ChampionshipTitleContender{
+				Name:                    standing.Manufacturer,
+				Position:                position + 1,
+				Points:                  standing.Points,
+				MinPoints:               standing.Points,
+				MaxPoints:               standing.Points + permutations.MaxPointsRemaining*float64(numCars),
+				maxPointsNextRound:      permutations.MaxPointsNextRound * float64(numCars),
+				maxPointsAfterNextRound: (permutations.MaxPointsRemaining - permutations.MaxPointsNextRound) * float64(numCars),
+			})
+		}
+
+		// the manufacturer standings have no tie-break, so manufacturers level on points at the end are tied.
+		noTieBreak := func(a, b *ChampionshipTitleContender) bool {
+			return false
+		}
+
+		decideTitle(permutations.Manufacturers, permutations.MaxPointsRemaining*float64(mostScoringCars), permutations.MaxPointsNextRound*float64(mostScoringCars), true, noTieBreak)
+	}
+
+	return permutations
+}
+
+// teamHadBetterFinishingPositions is TeamHadBetterFinishingPositions, without falling back to the order of the teams'
+// names if their finishing positions are the same.
+func (c *ChampionshipClass) teamHadBetterFinishingPositions(teamA, teamB string, inEvents []*ChampionshipEvent, numPositions int) bool {
+	for i := 1; i <= numPositions; i++ {
+		countTeamA := c.CountPositionsForTeam(i, teamA, inEvents)
+		countTeamB := c.CountPositionsForTeam(i, teamB, inEvents)
+
+		if countTeamA != countTeamB {
+			return countTeamA > countTeamB
+		}
+	}
+
+	return false
+}
+
+// decideTitle works out which contenders can still win the title, whether it has been won, and (if withNextRound is
+// true) what each contender needs to do to clinch it in the next round. maxPointsRemaining and maxPointsNextRound are
+// the most points any contender of the standings type can score, which contenders who aren't in the standings yet
+// could still score. tieBreak reports whether a finishes ahead of b when they are level on points.
+func decideTitle(contenders []*ChampionshipTitleContender, maxPointsRemaining, maxPointsNextRound float64, withNextRound bool, tieBreak func(a, b *ChampionshipTitleContender) bool) {
+	if maxPointsRemaining == 0 {
+		decideFinalTitle(contenders, tieBreak)
+		return
+	}
+
+	for _, contender := range contenders {
+		rivalsMinPoints, rivalsMaxPoints := 0.0, maxPointsRemaining
+
+		for _, rival := range contenders {
+			if rival != contender {
+				rivalsMinPoints = math.Max(rivalsMinPoints, rival.MinPoints)
+				rivalsMaxPoints = math.Max(rivalsMaxPoints, rival.MaxPoints)
+			}
+		}
+
+		contender.CanWin = contender.MaxPoints >= rivalsMinPoints
+		contender.Champion = contender.MinPoints > rivalsMaxPoints
+
+		if !withNextRound || !contender.CanWin || contender.Champion || contender.maxPointsNextRound == 0 {
+			continue
+		}
+
+		// to clinch the title in the next round, the contender must have more points after it than each rival could
+		// finish with. with c and r points scored by the contender and rival in the next round, that is:
+		// c - r > rival points + rival points available after the next round - contender points
+		contender.CanClinchNextRound = true
+
+		// contenders who aren't in the standings yet
+		if maxPointsRemaining-maxPointsNextRound-contender.Points >= contender.maxPointsNextRound {
+			contender.CanClinchNextRound = false
+		}
+
+		for _, rival := range contenders {
+			if rival == contender {
+				continue
+			}
+
+			margin := rival.Points + rival.maxPointsAfterNextRound - contender.Points
+
+			if margin < -rival.maxPointsNextRound {
+				// the rival can't catch the contender, whatever happens in the next round
+				continue
+			}
+
+			if margin >= contender.maxPointsNextRound {
+				contender.CanClinchNextRound = false
+				break
+			}
+
+			contender.NextRound = append(contender.NextRound, &ChampionshipClinchRequirement{
+				RivalGUID: rival.DriverGUID,
+				Rival:     rival.Name,
+				Margin:    margin,
+			})
+		}
+
+		if !contender.CanClinchNextRound {
+			contender.NextRound = nil
+		}
+	}
+}
+
+// decideFinalTitle decides the title once there are no points left to score. The contender with the most points is
+// champion, with ties broken by tieBreak. If the tie-break can't separate the leaders, the title is tied: each of them
+// is shown as able to win, but none of them is champion.
+func decideFinalTitle(contenders []*ChampionshipTitleContender, tieBreak func(a, b *ChampionshipTitleContender) bool) {
+	for _, contender := range contenders {
+		contender.CanWin = true
+
+		for _, rival := range contenders {
+			if rival != contender && (rival.Points > contender.Points || (rival.Points == contender.Points && tieBreak(rival, contender))) {
+				contender.CanWin = false
+				break
+			}
+		}
+	}
+
+	if leaders := titleContenders(contenders); len(leaders) == 1 {
+		leaders[0].Champion = true
+	}
+}
+
+// finalPoints is the points a driver would finish the championship with if they scored extraPoints in each round (and
+// nothing else). Counted results rules are applied as if every round of the championship had been completed.
+func (c *Championship) finalPoints(rounds []*championshipRound, standing *ChampionshipStanding, disqualified map[uuid.UUID]bool, extraPoints map[uuid.UUID]float64) float64 {
+	if !c.CountedResults.Enabled() {
+		points := standing.Points
+
+		for _, extra := range extraPoints {
+			points += extra
+		}
+
+		return points
+	}
+
+	var points float64
+	roundPoints := make(map[uuid.UUID]float64)
+
+	// awards include the points of dropped rounds and championship points penalties, which aren't scored in a round.
+	for _, award := range standing.Awards {
+		points += award.Points
+
+		if award.round != uuid.Nil {
+			roundPoints[award.round] += award.Points
+		}
+	}
+
+	for roundID, extra := range extraPoints {
+		points += extra
+		roundPoints[roundID] += extra
+	}
+
+	for _, droppedRound := range c.CountedResults.dropRounds(rounds, len(rounds), roundPoints, disqualified) {
+		points -= droppedRound.Points
+	}
+
+	return points
+}
+
+// remainingPoints are the most points a driver of the class can still score in each round of the championship which
+// has sessions that haven't been run yet. ok is false if the points scheme can't work it out.
+func (c *ChampionshipClass) remainingPoints(championship *Championship) (remainingPoints map[uuid.UUID]float64, ok bool) {
+	scheme := c.GetPointsScheme()
+	remainingPoints = make(map[uuid.UUID]float64)
+
+	var grid []string
+
+	for _, entrant := range c.Entrants {
+		grid = append(grid, entrant.GUID)
+	}
+
+	for _, event := range championship.Events {
+		for _, sessionEvent := range ExtractRaceWeekendSessionsIntoIndividualEvents([]*ChampionshipEvent{event}) {
+			for _, sessionType := range remainingSessionTypes(sessionEvent) {
+				pointsSession := &PointsSession{
+					Type:    sessionType,
+					ClassID: c.ID,
+					Grid:    grid,
+					Points:  c.Points,
+				}
+
+				if session, ok := sessionEvent.Sessions[sessionType]; ok && session.IsRaceWeekend() {
+					pointsSession.RaceWeekend = true
+
+					if classPoints, ok := session.RaceWeekendSession.Points[c.ID]; ok {
+						pointsSession.Points = *classPoints
+					}
+				}
+
+				configSessionType := sessionType
+
+				if sessionType == SessionTypeSecondRace {
+					configSessionType = SessionTypeRace
+				}
+
+				if sessionConfig, ok := sessionEvent.RaceSetup.Sessions[configSessionType]; ok && sessionConfig.Laps == 0 {
+					pointsSession.Duration = time.Duration(sessionConfig.Time) * time.Minute
+				}
+
+				points, ok := scheme.MaxPoints(pointsSession)
+
+				if !ok {
+					return nil, false
+				}
+
+				remainingPoints[event.ID] += points
+			}
+		}
+	}
+
+	return remainingPoints, true
+}
+
+// remainingSessionTypes are the sessions of an event (or race weekend session) which haven't been completed.
+func remainingSessionTypes(event *ChampionshipEvent) []SessionType {
+	if event.Completed() {
+		return nil
+	}
+
+	for sessionType, session := range event.Sessions {
+		if session.IsRaceWeekend() {
+			// race weekend sessions are completed with their event.
+			return []SessionType{sessionType}
+		}
+	}
+
+	var sessionTypes []SessionType
+
+	for sessionType := range event.RaceSetup.Sessions {
+		sessionTypes = append(sessionTypes, sessionType)
+	}
+
+	if event.RaceSetup.HasMultipleRaces() {
+		sessionTypes = append(sessionTypes, SessionTypeSecondRace)
+	}
+
+	var remaining []SessionType
+
+	for _, sessionType := range sessionTypes {
+		if session, ok := event.Sessions[sessionType]; ok && session.Completed() {
+			continue
+		}
+
+		remaining = append(remaining, sessionType)
+	}
+
+	return remaining
+}
+
+// championshipTitle is a driver or team title of a class which has been decided.
+type championshipTitle struct {
+	permutations *ChampionshipTitlePermutations
+	champion     *ChampionshipTitleContender
+}
+
+// decidedTitles are the titles of the championship which have been won, keyed by class and standings type.
+func decidedTitles(championship *Championship) map[string]*championshipTitle {
+	decided := make(map[string]*championshipTitle)
+
+	for _, permutations := range championship.TitlePermutations() {
+		if champion := permutations.Champion(); champion != nil {
+			decided[permutations.ClassID.String()+"/drivers"] = &championshipTitle{permutations: permutations, champion: champion}
+		}
+
+		if champion := permutations.TeamChampion(); champion != nil {
+			decided[permutations.ClassID.String()+"/teams"] = &championshipTitle{permutations: permutations, champion: champion}
+		}
+
+		if champion := permutations.ManufacturerChampion(); champion != nil {
+			decided[permutations.ClassID.String()+"/manufacturers"] = &championshipTitle{permutations: permutations, champion: champion}
+		}
+	}
+
+	return decided
+}
+
+// notifyDecidedTitles sends a notification for each title of the championship which has been won since decidedBefore
+// was worked out.
+func (cm *ChampionshipManager) notifyDecidedTitles(championship *Championship, decidedBefore map[string]*championshipTitle) {
+	for key, title := range decidedTitles(championship) {
+		if _, ok := decidedBefore[key]; ok {
+			continue
+		}
+
+		title := title
+
+		go panicCapture(func() {
+			if err := cm.notificationManager.SendChampionshipTitleDecidedMessage(championship, title.permutations, title.champion); err != nil {
+				logrus.WithError(err).Errorf("Could not send championship title notification")
+			}
+		})
+	}
+}
+
+// titlePermutations serves who can still win the titles of each class of a Championship as JSON.
+func (ch *ChampionshipsHandler) titlePermutations(w http.ResponseWriter, r *http.Request) {
+	championship, err := ch.championshipManager.LoadChampionship(chi.URLParam(r, "championshipID"))
+
+	if err == ErrChampionshipNotFound || os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Error("couldn't load championship")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	permutations := championship.TitlePermutations()
+
+	for _, classPermutations := range permutations {
+		for _, driver := range classPermutations.Drivers {
+			driver.Name = driverName(driver.Name)
+
+			for _, requirement := range driver.NextRound {
+				requirement.Rival = driverName(requirement.Rival)
+			}
+		}
+	}
+
+	writeStandingsJSON(w, permutations)
+}
@@ -0,0 +1,195 @@
+package servermanager
+
+import (
+	"testing"
+)
+
+func titleContender(contenders []*ChampionshipTitleContender, guid string) *ChampionshipTitleContender {
+	for _, contender := range contenders {
+		if contender.DriverGUID == guid {
+			return contender
+		}
+	}
+
+	return nil
+}
+
+func TestChampionshipClass_TitlePermutations(t *testing.T) {
+	t.Run("Title is undecided", func(t *testing.T) {
+		championship, class := newTestChampionship().Places(25, 18).Race("A", "B").Race("A", "B").RemainingRaces(2).Build()
+
+		permutations := class.TitlePermutations(championship)
+
+		if !permutations.Available || permutations.RemainingRounds != 2 || permutations.MaxPointsRemaining != 50 || permutations.MaxPointsNextRound != 25 {
+			t.Fatalf("Incorrect remaining points: %+v", permutations)
+		}
+
+		if permutations.NextRound != "Round 3 (Spa)" {
+			t.Errorf("Expected next round to be Round 3 (Spa), got: %s", permutations.NextRound)
+		}
+
+		a, b := titleContender(permutations.Drivers, "A"), titleContender(permutations.Drivers, "B")
+
+		if a.MinPoints != 50 || a.MaxPoints != 100 || b.MinPoints != 36 || b.MaxPoints != 86 {
+			t.Errorf("Incorrect final points, A: %.0f-%.0f, B: %.0f-%.0f", a.MinPoints, a.MaxPoints, b.MinPoints, b.MaxPoints)
+		}
+
+		if !a.CanWin || !b.CanWin || a.Champion || b.Champion || permutations.Decided() {
+			t.Errorf("Expected both drivers to be able to win")
+		}
+
+		// B can finish on 36 + 25 points after the next round, so A must finish more than 11 points ahead of them.
+		if !a.CanClinchNextRound || len(a.NextRound) != 1 || a.NextRound[0].Margin != 11 || a.NextRound[0].String() != "Outscore B by more than 11 points" {
+			t.Errorf("Incorrect requirements for A to clinch the title: %+v", a.NextRound)
+		}
+
+		if b.CanClinchNextRound || len(b.NextRound) != 0 {
+			t.Errorf("Expected B to be unable to clinch the title in the next round")
+		}
+	})
+
+	t.Run("Final round", func(t *testing.T) {
+		championship, class := newTestChampionship().Places(25, 18).Race("A", "B").Race("A", "B").RemainingRaces(1).Build()
+
+		permutations := class.TitlePermutations(championship)
+		a, b := titleContender(permutations.Drivers, "A"), titleContender(permutations.Drivers, "B")
+
+		if !a.CanClinchNextRound || len(a.NextRound) != 1 || a.NextRound[0].Margin != -14 || a.NextRound[0].String() != "Finish less than 14 points behind B" {
+			t.Errorf("Incorrect requirements for A to clinch the title: %+v", a.NextRound)
+		}
+
+		if !b.CanClinchNextRound || len(b.NextRound) != 1 || b.NextRound[0].Margin != 14 {
+			t.Errorf("Incorrect requirements for B to clinch the title: %+v", b.NextRound)
+		}
+	})
+
+	t.Run("Title is decided", func(t *testing.T) {
+		championship, class := newTestChampionship().Places(25, 10).Race("A", "B").Race("A", "B").Race("A", "B").RemainingRaces(1).Build()
+
+		permutations := class.TitlePermutations(championship)
+		a, b := titleContender(permutations.Drivers, "A"), titleContender(permutations.Drivers, "B")
+
+		if !a.Champion || !a.CanWin || b.CanWin || b.Champion {
+			t.Errorf("Expected A to have won the title")
+		}
+
+		if champion := permutations.Champion(); champion == nil || champion.DriverGUID != "A" {
+			t.Errorf("Expected A to be the champion, got: %+v", champion)
+		}
+
+		if _, ok := decidedTitles(championship)[class.ID.String()+"/drivers"]; !ok {
+			t.Errorf("Expected the drivers' title to be decided")
+		}
+	})
+
+	t.Run("Every round is complete", func(t *testing.T) {
+		championship, class := newTestChampionship().Places(25, 18).Race("A", "B").Race("A", "B").Build()
+
+		permutations := class.TitlePermutations(championship)
+
+		if permutations.RemainingRounds != 0 || permutations.NextRound != "" || len(permutations.Contenders()) != 1 || permutations.Champion() != titleContender(permutations.Drivers, "A") || permutations.Tied() {
+			t.Errorf("Expected the leader to be champion, got: %+v", permutations)
+		}
+	})
+
+	t.Run("Every round is complete, with the leaders level on points", func(t *testing.T) {
+		// A and B both score 9 points, with a win each, but B also has a second place.
+		championship, class := newTestChampionship().Places(6, 3, 3).Race("A", "B", "C").Race("B", "C", "A").Build()
+
+		permutations := class.TitlePermutations(championship)
+
+		if champion := permutations.Champion(); champion == nil || champion.DriverGUID != "B" || permutations.Tied() {
+			t.Errorf("Expected B to win the title on countback, got: %+v", champion)
+		}
+	})
+
+	t.Run("Every round is complete, with the title tied", func(t *testing.T) {
+		championship, class := newTestChampionship().Places(25, 18).Race("A", "B").Race("B", "A").Build()
+
+		permutations := class.TitlePermutations(championship)
+
+		if permutations.Decided() || !permutations.Tied() || len(permutations.Contenders()) != 2 {
+			t.Errorf("Expected the title to be tied, got: %+v", permutations.Champion())
+		}
+
+		if _, ok := decidedTitles(championship)[class.ID.String()+"/drivers"]; ok {
+			t.Errorf("Expected a tied title not to be decided")
+		}
+	})
+
+	t.Run("Entrants who haven't scored", func(t *testing.T) {
+		championship, class := newTestChampionship().Places(25, 18).Race("A", "B").Race("A", "B").RemainingRaces(2).Build()
+		class.Entrants.AddInPitBox(&Entrant{GUID: "C", Name: "C"}, 2)
+
+		permutations := class.TitlePermutations(championship)
+		a, c := titleContender(permutations.Drivers, "A"), titleContender(permutations.Drivers, "C")
+
+		if c == nil || c.Points != 0 || c.MaxPoints != 50 || !c.CanWin {
+			t.Fatalf("Expected C to be able to win the title, got: %+v", c)
+		}
+
+		// C can finish on 25 points after the next round, so A must stop them scoring more than 25 points more.
+		if !a.CanClinchNextRound || len(a.NextRound) != 2 || a.NextRound[1].Rival != "C" || a.NextRound[1].Margin != -25 {
+			t.Errorf("Incorrect requirements for A to clinch the title: %+v", a.NextRound)
+		}
+	})
+
+	t.Run("Counted results", func(t *testing.T) {
+		championship, class := newTestChampionship().CountedResults(ChampionshipCountedResults{DropWorstRounds: 1}).Places(25, 10).
+			Race("A", "B").
+			Race("A", "B").
+			Race("B", "A").
+			RemainingRaces(1).
+			Build()
+
+		permutations := class.TitlePermutations(championship)
+		a, b := titleContender(permutations.Drivers, "A"), titleContender(permutations.Drivers, "B")
+
+		// A scores 25, 25, 10 and B 10, 10, 25. Their worst round is dropped once the final round has been run.
+		if a.MinPoints != 60 || a.MaxPoints != 75 || b.MinPoints != 45 || b.MaxPoints != 60 {
+			t.Errorf("Incorrect final points, A: %.0f-%.0f, B: %.0f-%.0f", a.MinPoints, a.MaxPoints, b.MinPoints, b.MaxPoints)
+		}
+
+		if !a.CanWin || !b.CanWin || a.Champion {
+			t.Errorf("Expected both drivers to be able to win")
+		}
+
+		if a.CanClinchNextRound || b.CanClinchNextRound {
+			t.Errorf("Expected next round requirements to be skipped for counted results")
+		}
+	})
+}
+
+func TestPointsScheme_MaxPoints(t *testing.T) {
+	for _, schemeType := range []PointsSchemeType{PointsSchemeCustom, PointsSchemeF1, PointsSchemeIndyCar, PointsSchemeWEC, PointsSchemePositionsGained} {
+		championship, class := addPointsSchemeTestRace(newTestChampionship().PointsScheme(schemeType)).Build()
+		scheme := class.GetPointsScheme()
+
+		for sessionType := range championship.Events[0].Sessions {
+			points := make(map[string]float64)
+
+			class.standings(championship, []*ChampionshipEvent{championship.Events[0]}, func(event *ChampionshipEvent, award *PointsAward) {
+				if award.Session == sessionType {
+					points[award.DriverGUID] += award.Points
+				}
+			})
+
+			maxPoints, ok := scheme.MaxPoints(&PointsSession{
+				Type:    sessionType,
+				ClassID: class.ID,
+				Grid:    []string{"A", "B", "C"},
+				Points:  class.Points,
+			})
+
+			if !ok {
+				t.Errorf("%s: expected max points to be available", schemeType)
+			}
+
+			for guid, driverPoints := range points {
+				if driverPoints > maxPoints {
+					t.Errorf("%s: %s scored %.0f points in %s, more than the maximum of %.0f", schemeType, guid, driverPoints, sessionType, maxPoints)
+				}
+			}
+		}
+	}
+}
@@ -131,6 +131,16 @@ type Championship struct {
 	SpectatorCar        Entrant
 	SpectatorCarEnabled bool
 
+	// GridPenalties move entrants back on the grid of the next event they start.
+	GridPenalties GridPenalties
+
+	// CountedResults decide which rounds count towards the driver standings.
+	CountedResults ChampionshipCountedResults
+
+	// Teams are the teams set up for the Championship, and TeamScoring the rules for how their points are scored.
+	Teams       []*ChampionshipTeam
+	TeamScoring ChampionshipTeamScoring
+
 	DefaultTab ChampionshipTab
 }
 
@@ -139,9 +149,15 @@ func (c *Championship) HasSpectatorCar() bool {
 }
 
 func (c *ChampionshipClass) HadBetterFinishingPositions(guidA, guidB string, inEvents []*ChampionshipEvent, numPositions int) bool {
+	return c.hadBetterFinishingPositions(guidA, inEvents, guidB, inEvents, numPositions)
+}
+
+// hadBetterFinishingPositions compares the finishing positions of two drivers, each in their own events (i.e. the
+// rounds which count for them).
+func (c *ChampionshipClass) hadBetterFinishingPositions(guidA string, eventsA []*ChampionshipEvent, guidB string, eventsB []*ChampionshipEvent, numPositions int) bool {
 	for i := 1; i <= numPositions; i++ {
-		countDriverA := c.CountPositionsForDriver(i, guidA, inEvents)
-		countDriverB := c.CountPositionsForDriver(i, guidB, inEvents)
+		countDriverA := c.CountPositionsForDriver(i, guidA, eventsA)
+		countDriverB := c.CountPositionsForDriver(i, guidB, eventsB)
 
 		if countDriverA == countDriverB {
 			continue
@@ -527,6 +543,9 @@ type ChampionshipClass struct {
 	Points        ChampionshipPoints
 	AvailableCars []string
 
+	// PointsScheme is how points are scored in the class. The custom scheme (the default) uses Points.
+	PointsScheme PointsSchemeType
+
 	DriverPenalties, TeamPenalties map[string]int
 }
 
@@ -944,6 +963,38 @@ type ChampionshipStanding struct {
 	// Teams is a map of Team Name to how many Events per team were completed.
 	Teams  map[string]int
 	Points float64
+
+	// Awards explain how the driver's points were scored.
+	Awards []*PointsAward
+
+	// DroppedRounds are the rounds which don't count towards the driver's points.
+	DroppedRounds []*ChampionshipDroppedRound
+}
+
+// countedEvents are the events of inEvents whose points count for the driver.
+func (cs *ChampionshipStanding) countedEvents(inEvents []*ChampionshipEvent) []*ChampionshipEvent {
+	if len(cs.DroppedRounds) == 0 {
+		return inEvents
+	}
+
+	var counted []*ChampionshipEvent
+
+	for _, event := range inEvents {
+		dropped := false
+
+		for _, droppedRound := range cs.DroppedRounds {
+			if droppedRound.RoundID == event.ID {
+				dropped = true
+				break
+			}
+		}
+
+		if !dropped {
+			counted = append(counted, event)
+		}
+	}
+
+	return counted
 }
 
 func (cs *ChampionshipStanding) AddEventForTeam(team string) {
@@ -1076,9 +1127,13 @@ const (
 	PointsCollisionWithCar
 	PointsCollisionWithEnvironment
 	PointsCutTrack
+	PointsPenalty
+	PointsLapsLed
+	PointsPositionsGained
+	PointsBonus
 )
 
-func (c *ChampionshipClass) standings(championship *Championship, events []*ChampionshipEvent, givePoints func(event *ChampionshipEvent, driverGUID string, points float64, reason PointsReason)) {
+func (c *ChampionshipClass) standings(championship *Championship, events []*ChampionshipEvent, givePoints func(event *ChampionshipEvent, award *PointsAward)) {
 	eventsReverseCompletedOrder := make([]*ChampionshipEvent, len(events))
 
 	copy(eventsReverseCompletedOrder, events)
@@ -1087,14 +1142,50 @@ func (c *ChampionshipClass) standings(championship *Championship, events []*Cham
 		return eventsReverseCompletedOrder[i].CompletedTime.After(eventsReverseCompletedOrder[j].CompletedTime)
 	})
 
+	scheme := c.GetPointsScheme()
+
 	for _, event := range eventsReverseCompletedOrder {
+		eventName := prettifyName(event.RaceSetup.Track, false)
+
 		for sessionType, session := range event.Sessions {
 			if !session.Completed() || session.Results == nil {
 				continue
 			}
 
-			points := c.Points
-			pointsMultiplier := 1.0
+			sessionName := eventName
+
+			if session.IsRaceWeekend() {
+				sessionName += " - " + session.RaceWeekendSession.Name()
+			}
+
+			give := func(driverGUID string, points float64, reason PointsReason, description string) {
+				givePoints(event, &PointsAward{
+					DriverGUID:     driverGUID,
+					Event:          sessionName,
+					Session:        sessionType,
+					Points:         points,
+					Reason:         reason,
+					Description:    description,
+					eventCompleted: event.CompletedTime,
+				})
+			}
+
+			// points deductions are given in any session, even to drivers who are disqualified.
+			for _, driver := range c.ResultsForClass(session.Results.Result, championship) {
+				if deduction := driver.PointsDeduction(); deduction > 0 {
+					give(driver.DriverGUID, deduction*-1, PointsPenalty, "Points deduction")
+				}
+			}
+
+			pointsSession := &PointsSession{
+				Type:         sessionType,
+				RaceWeekend:  session.IsRaceWeekend(),
+				ClassID:      c.ID,
+				Results:      session.Results,
+				ClassResults: c.ResultsForClass(session.Results.Result, championship),
+				Grid:         c.grid(championship, event, sessionType),
+				Points:       c.Points,
+			}
 
 			if session.IsRaceWeekend() {
 				// race weekend sessions are valid points, as specified by the session itself.
@@ -1103,53 +1194,44 @@ func (c *ChampionshipClass) standings(championship *Championship, events []*Cham
 				if !ok {
 					logrus.Warnf("Could not find points for Race Weekend Session class: %s", c.ID)
 				} else {
-					points = *classPoints
+					pointsSession.Points = *classPoints
 				}
-			} else {
-				switch sessionType {
-				case SessionTypeQualifying:
-					// non race weekend qualifying results get pole position points
-					for pos, driver := range c.ResultsForClass(session.Results.Result, championship) {
-						if pos != 0 {
-							continue
-						}
-
-						givePoints(event, driver.DriverGUID, float64(points.PolePosition)*pointsMultiplier, PointsPolePosition)
-					}
+			}
 
-					continue
+			scheme.Score(pointsSession, give)
+		}
+	}
+}
 
-				case SessionTypeSecondRace:
-					pointsMultiplier = points.SecondRaceMultiplier
-				case SessionTypeBooking, SessionTypePractice:
-					continue
+// grid is the GUIDs of the class's drivers in the order they started a session of the event. Race sessions start in
+// qualifying order if the event had a qualifying session, otherwise drivers start in entry list order.
+func (c *ChampionshipClass) grid(championship *Championship, event *ChampionshipEvent, sessionType SessionType) []string {
+	var grid []string
 
-				default:
-					// race sessions fall through
-				}
-			}
+	if qualifying, ok := event.Sessions[SessionTypeQualifying]; ok && sessionType != SessionTypeQualifying && qualifying.Results != nil {
+		for _, result := range c.ResultsForClass(qualifying.Results.Result, championship) {
+			grid = append(grid, result.DriverGUID)
+		}
 
-			fastestLap := session.Results.FastestLapInClass(c.ID)
+		return grid
+	}
 
-			for pos, driver := range c.ResultsForClass(session.Results.Result, championship) {
-				if driver.TotalTime <= 0 || driver.Disqualified {
-					continue
-				}
+	session := event.Sessions[sessionType]
 
-				givePoints(event, driver.DriverGUID, points.ForPos(pos)*pointsMultiplier, PointsEventFinish)
+	results := make([]*SessionResult, len(session.Results.Result))
+	copy(results, session.Results.Result)
 
-				if fastestLap.DriverGUID == driver.DriverGUID {
-					givePoints(event, driver.DriverGUID, float64(points.BestLap)*pointsMultiplier, PointsFastestLap)
-				}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].CarID < results[j].CarID
+	})
 
-				if sessionType == SessionTypeRace || sessionType == SessionTypeSecondRace {
-					givePoints(event, driver.DriverGUID, float64(points.CollisionWithDriver*session.Results.GetCrashesOfType(driver.DriverGUID, driver.CarModel, "COLLISION_WITH_CAR"))*pointsMultiplier*-1, PointsCollisionWithCar)
-					givePoints(event, driver.DriverGUID, float64(points.CollisionWithEnv*session.Results.GetCrashesOfType(driver.DriverGUID, driver.CarModel, "COLLISION_WITH_ENV"))*pointsMultiplier*-1, PointsCollisionWithEnvironment)
-					givePoints(event, driver.DriverGUID, float64(points.CutTrack*session.Results.GetCuts(driver.DriverGUID, driver.CarModel))*pointsMultiplier*-1, PointsCutTrack)
-				}
-			}
+	for _, result := range results {
+		if c.DriverInClass(result) {
+			grid = append(grid, result.DriverGUID)
 		}
 	}
+
+	return grid
 }
 
 var championshipStandingSessionOrder = []SessionType{
@@ -1164,6 +1246,7 @@ type StandingsOption int
 
 const (
 	StandingsNoPointsPenalties StandingsOption = iota
+	StandingsNoDroppedRounds
 )
 
 // Standings returns the current Driver Standings for the Championship.
@@ -1208,7 +1291,12 @@ func (c *ChampionshipClass) Standings(championship *Championship, inEvents []*Ch
 
 	standings := make(map[string]*ChampionshipStanding)
 
-	c.standings(championship, events, func(event *ChampionshipEvent, driverGUID string, points float64, reason PointsReason) {
+	rounds := championshipRounds(championship, inEvents)
+	roundPoints := make(map[string]map[uuid.UUID]float64)
+
+	c.standings(championship, events, func(event *ChampionshipEvent, award *PointsAward) {
+		driverGUID := award.DriverGUID
+
 		var car *SessionCar
 
 		for _, sessionType := range championshipStandingSessionOrder {
@@ -1239,31 +1327,76 @@ func (c *ChampionshipClass) Standings(championship *Championship, inEvents []*Ch
 			standings[driverGUID] = NewChampionshipStanding(car)
 		}
 
-		standings[driverGUID].Points += points
+		standings[driverGUID].Points += award.Points
+
+		if award.Points != 0 {
+			standings[driverGUID].Awards = append(standings[driverGUID].Awards, award)
+		}
+
+		if round := roundForEvent(rounds, event.ID); round != nil {
+			if _, ok := roundPoints[driverGUID]; !ok {
+				roundPoints[driverGUID] = make(map[uuid.UUID]float64)
+			}
+
+			roundPoints[driverGUID][round.ID] += award.Points
+			award.round = round.ID
+		}
 
-		if reason == PointsEventFinish {
+		if award.Reason == PointsEventFinish {
 			// only increment team finishes for a 'finish' reason
 			standings[driverGUID].AddEventForTeam(car.Driver.Team)
 		}
 	})
 
 	skipPointsPenalties := false
+	skipDroppedRounds := !championship.CountedResults.Enabled()
 
 	for _, opt := range standingOpts {
-		if opt == StandingsNoPointsPenalties {
+		switch opt {
+		case StandingsNoPointsPenalties:
 			skipPointsPenalties = true
+		case StandingsNoDroppedRounds:
+			skipDroppedRounds = true
 		}
 	}
 
-	for _, standing := range standings {
+	var disqualifiedRounds map[string]map[uuid.UUID]bool
+
+	if !skipDroppedRounds {
+		disqualifiedRounds = roundsDisqualifiedFrom(rounds, events)
+	}
+
+	for driverGUID, standing := range standings {
 		if standing.Car.Driver.Name == "" {
 			continue
 		}
 
-		if !skipPointsPenalties {
-			standing.Points -= float64(c.PenaltyForGUID(standing.Car.Driver.GUID))
+		if penalty := c.PenaltyForGUID(standing.Car.Driver.GUID); !skipPointsPenalties && penalty != 0 {
+			standing.Points -= float64(penalty)
+			standing.Awards = append(standing.Awards, &PointsAward{
+				DriverGUID:  standing.Car.Driver.GUID,
+				Points:      float64(penalty) * -1,
+				Reason:      PointsPenalty,
+				Description: "Championship points penalty",
+			})
 		}
 
+		if !skipDroppedRounds {
+			standing.DroppedRounds = championship.CountedResults.dropRounds(rounds, len(championship.Events), roundPoints[driverGUID], disqualifiedRounds[driverGUID])
+
+			for _, droppedRound := range standing.DroppedRounds {
+				standing.Points -= droppedRound.Points
+
+				for _, award := range standing.Awards {
+					if award.round == droppedRound.RoundID {
+						award.Dropped = true
+					}
+				}
+			}
+		}
+
+		sortPointsAwards(standing.Awards)
+
 		out = append(out, standing)
 	}
 
@@ -1271,9 +1404,9 @@ func (c *ChampionshipClass) Standings(championship *Championship, inEvents []*Ch
 
 	sort.Slice(out, func(i, j int) bool {
 		if out[i].Points == out[j].Points {
-			// sort by number of wins
+			// sort by number of wins, in the rounds which count
 			if len(inEvents) > 1 {
-				return c.HadBetterFinishingPositions(out[i].Car.Driver.GUID, out[j].Car.Driver.GUID, inEvents, numPositions)
+				return c.hadBetterFinishingPositions(out[i].Car.Driver.GUID, out[i].countedEvents(inEvents), out[j].Car.Driver.GUID, out[j].countedEvents(inEvents), numPositions)
 			}
 
 			return out[i].Car.GetName() < out[j].Car.GetName()
@@ -1287,7 +1420,7 @@ func (c *ChampionshipClass) Standings(championship *Championship, inEvents []*Ch
 
 // StandingsForEvent reports the standings for a single event, not including any generic points penalties applied to the championship.
 func (c *ChampionshipClass) StandingsForEvent(championship *Championship, event *ChampionshipEvent) []*ChampionshipStanding {
-	return c.Standings(championship, []*ChampionshipEvent{event}, StandingsNoPointsPenalties)
+	return c.Standings(championship, []*ChampionshipEvent{event}, StandingsNoPointsPenalties, StandingsNoDroppedRounds)
 }
 
 type ChampionshipStandingWithTableInfo struct {
@@ -1380,39 +1513,24 @@ type TeamStanding struct {
 
 // TeamStandings returns the current position of Teams in the Championship.
 func (c *ChampionshipClass) TeamStandings(championship *Championship, inEvents []*ChampionshipEvent) []*TeamStanding {
-	teams := make(map[string]float64)
+	points := make(sessionPoints)
 
 	// make a copy of events so we do not persist race weekend sessions
 	events := ExtractRaceWeekendSessionsIntoIndividualEvents(inEvents)
 
-	c.standings(championship, events, func(event *ChampionshipEvent, driverGUID string, points float64, reason PointsReason) {
-		var team string
-
+	c.standings(championship, events, func(event *ChampionshipEvent, award *PointsAward) {
 		// find the team the driver was in for this race.
-		for _, session := range event.Sessions {
-			if session.Results != nil {
-				for _, car := range session.Results.Cars {
-					if car.Driver.GUID == driverGUID {
-						team = car.Driver.Team
-						break
-					}
+		team, ok := c.teamForPoints(championship, award.DriverGUID, teamInEvent(event, award.DriverGUID))
 
-					if NormaliseEntrantGUIDs(car.Driver.GuidsList) == driverGUID {
-						team = car.Driver.Team
-						break
-					}
-				}
-				break
-			}
+		if !ok {
+			return
 		}
 
-		if _, ok := teams[team]; !ok {
-			teams[team] = points
-		} else {
-			teams[team] += points
-		}
+		points.add(event.ID.String()+"/"+award.Session.String(), team, award.DriverGUID, award.Points)
 	})
 
+	teams := points.total(championship.TeamScoring.CountBestCars)
+
 	var out []*TeamStanding
 
 	for name, pts := range teams {
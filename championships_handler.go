@@ -123,6 +123,9 @@ type championshipViewTemplateVars struct {
 	RaceWeekends    map[uuid.UUID]*RaceWeekend
 	DriverRatings   map[string]*ACSRDriverRating
 	AccountRating   *ACSRDriverRating
+
+	StandingsHistory  *ChampionshipStandingsHistory
+	TitlePermutations []*ChampionshipTitlePermutations
 }
 
 // view shows details of a given Championship
@@ -175,13 +178,21 @@ func (ch *ChampionshipsHandler) view(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	standingsHistory, err := ch.championshipManager.StandingsHistory(championship)
+
+	if err != nil {
+		logrus.WithError(err).Errorf("Couldn't load standings history for championship: %s", championship.ID.String())
+	}
+
 	ch.viewRenderer.MustLoadTemplate(w, r, "championships/view.html", &championshipViewTemplateVars{
-		Championship:    championship,
-		EventInProgress: eventInProgress,
-		Account:         account,
-		RaceWeekends:    raceWeekends,
-		DriverRatings:   ratings,
-		AccountRating:   rating,
+		Championship:      championship,
+		EventInProgress:   eventInProgress,
+		Account:           account,
+		RaceWeekends:      raceWeekends,
+		DriverRatings:     ratings,
+		AccountRating:     rating,
+		StandingsHistory:  standingsHistory,
+		TitlePermutations: championship.TitlePermutations(),
 	})
 }
 
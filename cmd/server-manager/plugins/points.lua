@@ -0,0 +1,88 @@
+json = require "json"
+utils = require "utils"
+
+-- these are lua hooks related to championship points, for help please view lua_readme.md!
+-- there are some example functions here to give you an idea of what is possible, feel free to write your own!
+-- if you do and think other people would be interested in them consider making a pull request at https://github.com/JustaPenguin/assetto-server-manager
+
+-- points reasons, only one award per driver per session should be a PointsEventFinish as it counts as an event for
+-- the driver's team. use PointsBonus for any other points.
+PointsEventFinish = 0
+PointsPolePosition = 1
+PointsFastestLap = 2
+PointsPenalty = 6
+PointsLapsLed = 7
+PointsPositionsGained = 8
+PointsBonus = 9
+
+-- called for each completed session of a championship (or race weekend) for classes using the "Lua Script" points
+-- scheme. championship standings are worked out whenever they are viewed, so this is called a lot. keep it fast!
+function onChampionshipSessionScore(encodedSession)
+    -- Decode block, you probably shouldn't touch these!
+    local session = json.decode(encodedSession)
+
+    -- Uncomment these lines and view a championship to print out the structure of each object.
+    --print("Session:", utils.dump(session))
+
+    -- Function block NOTE: this hook BLOCKS, make sure your functions don't loop forever!
+    -- each award is a table of DriverGUID, Points, Reason and Description. the Description is shown to drivers on
+    -- the championship standings page, so they can see how their points were scored.
+    local awards = {}
+
+    awards = pointsForEveryFinisher(session, awards)
+    --awards = pointsForCleanRaces(session, awards, 2)
+
+    -- Encode block, you probably shouldn't touch these either!
+    return json.encode(awards)
+end
+
+-- every driver who finishes a race scores a point for each driver they beat, plus one
+function pointsForEveryFinisher(session, awards)
+    if (session["Type"] ~= "RACE" and session["Type"] ~= "RACEx2") or session["ClassResults"] == nil then
+        return awards
+    end
+
+    local numFinishers = #session["ClassResults"]
+
+    for pos, result in ipairs(session["ClassResults"]) do
+        if not result["Disqualified"] then
+            table.insert(awards, {
+                DriverGUID = result["DriverGuid"],
+                Points = numFinishers - pos + 1,
+                Reason = PointsEventFinish,
+                Description = "Finished " .. pos,
+            })
+        end
+    end
+
+    return awards
+end
+
+-- drivers who finish a race without any collisions score bonusPoints
+function pointsForCleanRaces(session, awards, bonusPoints)
+    if (session["Type"] ~= "RACE" and session["Type"] ~= "RACEx2") or session["ClassResults"] == nil then
+        return awards
+    end
+
+    for _, result in ipairs(session["ClassResults"]) do
+        local clean = true
+
+        for _, event in pairs(session["Results"]["Events"] or {}) do
+            if event["Driver"]["Guid"] == result["DriverGuid"] then
+                clean = false
+                break
+            end
+        end
+
+        if clean and not result["Disqualified"] then
+            table.insert(awards, {
+                DriverGUID = result["DriverGuid"],
+                Points = bonusPoints,
+                Reason = PointsBonus,
+                Description = "Clean race",
+            })
+        end
+    end
+
+    return awards
+end
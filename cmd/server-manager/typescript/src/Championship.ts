@@ -1,4 +1,5 @@
 import dragula from "dragula";
+import {randomColor} from "randomcolor/randomColor";
 import ClickEvent = JQuery.ClickEvent;
 
 declare var ChampionshipID: string;
@@ -19,6 +20,39 @@ interface ACSRDriverRating {
     is_provisional: boolean;
 }
 
+interface StandingsSnapshotEntry {
+    Position: number;
+    PreviousPosition: number;
+    DriverGUID?: string;
+    Name: string;
+    Team?: string;
+    Points: number;
+    PreviousPoints: number;
+}
+
+interface ClassStandingsSnapshot {
+    ClassID: string;
+    Name: string;
+    Drivers: StandingsSnapshotEntry[] | null;
+    Teams: StandingsSnapshotEntry[] | null;
+}
+
+interface StandingsSnapshot {
+    RoundID: string;
+    Round: number;
+    Name: string;
+    CompletedTime: string;
+    Classes: ClassStandingsSnapshot[] | null;
+}
+
+interface StandingsHistory {
+    ChampionshipID: string;
+    Updated: string;
+    Snapshots: StandingsSnapshot[] | null;
+}
+
+const svgNamespace = "http://www.w3.org/2000/svg";
+
 export namespace Championship {
 
     export class View {
@@ -26,6 +60,8 @@ export namespace Championship {
             this.initDraggableCards();
             this.initEventDetailsButtons();
             this.initACSRRatingWatcher();
+
+            new StandingsProgressionCharts();
         }
 
         private initDraggableCards(): void {
@@ -175,4 +211,158 @@ export namespace Championship {
             }
         }
     }
+
+    // StandingsProgressionCharts draws the position and points of each driver (or team) in a class after each round.
+    class StandingsProgressionCharts {
+        private readonly $positionChart: JQuery<HTMLElement>;
+        private readonly $pointsChart: JQuery<HTMLElement>;
+        private readonly $legend: JQuery<HTMLElement>;
+        private readonly $class: JQuery<HTMLSelectElement>;
+        private readonly $type: JQuery<HTMLSelectElement>;
+
+        private snapshots: StandingsSnapshot[] = [];
+
+        private static readonly chartWidth = 600;
+        private static readonly chartHeight = 300;
+        private static readonly chartPadding = 25;
+
+        public constructor() {
+            this.$positionChart = $("#standings-position-chart");
+            this.$pointsChart = $("#standings-points-chart");
+            this.$legend = $("#standings-progression-legend");
+            this.$class = $("#standings-progression-class");
+            this.$type = $("#standings-progression-type");
+
+            if (!this.$positionChart.length) {
+                return;
+            }
+
+            this.$class.on("change", this.draw.bind(this));
+            this.$type.on("change", this.draw.bind(this));
+
+            $.getJSON(`/api/championship/${ChampionshipID}/standings/history`).then((history: StandingsHistory) => {
+                this.snapshots = history.Snapshots || [];
+                this.draw();
+            });
+        }
+
+        private entries(snapshot: StandingsSnapshot): StandingsSnapshotEntry[] {
+            const classID = this.$class.val() as string;
+
+            for (const classSnapshot of snapshot.Classes || []) {
+                if (classSnapshot.ClassID === classID) {
+                    return (this.$type.val() === "teams" ? classSnapshot.Teams : classSnapshot.Drivers) || [];
+                }
+            }
+
+            return [];
+        }
+
+        private static key(entry: StandingsSnapshotEntry): string {
+            return entry.DriverGUID || entry.Name;
+        }
+
+        private draw(): void {
+            this.$positionChart.empty();
+            this.$pointsChart.empty();
+            this.$legend.empty();
+
+            const lines = new Map<string, { name: string, positions: string[], points: string[] }>();
+
+            let maxPosition = 1;
+            let maxPoints = 1;
+
+            for (const snapshot of this.snapshots) {
+                for (const entry of this.entries(snapshot)) {
+                    maxPosition = Math.max(maxPosition, entry.Position);
+                    maxPoints = Math.max(maxPoints, entry.Points);
+                }
+            }
+
+            const numRounds = Math.max(this.snapshots.length - 1, 1);
+
+            // positions are drawn top to bottom, with the leader at the top.
+            const x = (round: number) => StandingsProgressionCharts.scale(round, 0, numRounds, StandingsProgressionCharts.chartWidth);
+            const yPosition = (position: number) => StandingsProgressionCharts.scale(maxPosition + 1 - position, 1, maxPosition, StandingsProgressionCharts.chartHeight);
+            const yPoints = (points: number) => StandingsProgressionCharts.scale(points, 0, maxPoints, StandingsProgressionCharts.chartHeight);
+
+            this.snapshots.forEach((snapshot: StandingsSnapshot, round: number) => {
+                for (const entry of this.entries(snapshot)) {
+                    const key = StandingsProgressionCharts.key(entry);
+                    const line = lines.get(key) || {name: entry.Name, positions: [], points: []};
+
+                    line.positions.push(x(round) + "," + yPosition(entry.Position));
+                    line.points.push(x(round) + "," + yPoints(entry.Points));
+
+                    lines.set(key, line);
+                }
+            });
+
+            this.drawAxes(this.$positionChart, "Position");
+            this.drawAxes(this.$pointsChart, "Points (max " + maxPoints + ")");
+
+            this.snapshots.forEach((snapshot: StandingsSnapshot, round: number) => {
+                for (const $chart of [this.$positionChart, this.$pointsChart]) {
+                    $(document.createElementNS(svgNamespace, "text")).attr({
+                        "x": x(round),
+                        "y": StandingsProgressionCharts.chartHeight - 5,
+                        "class": "chart-label",
+                    }).text(snapshot.Round).append($(document.createElementNS(svgNamespace, "title")).text(snapshot.Name)).appendTo($chart);
+                }
+            });
+
+            lines.forEach((line, key: string) => {
+                const color = randomColor({seed: key});
+
+                this.drawLine(this.$positionChart, line.positions, color, line.name);
+                this.drawLine(this.$pointsChart, line.points, color, line.name);
+
+                $("<span>").addClass("badge mr-1 text-white").css("background", color).text(line.name).appendTo(this.$legend);
+            });
+        }
+
+        private static scale(value: number, min: number, max: number, size: number): number {
+            if (max === min) {
+                return StandingsProgressionCharts.chartPadding;
+            }
+
+            return StandingsProgressionCharts.chartPadding + ((value - min) / (max - min)) * (size - StandingsProgressionCharts.chartPadding * 2);
+        }
+
+        private drawAxes($chart: JQuery<HTMLElement>, yLabel: string): void {
+            const padding = StandingsProgressionCharts.chartPadding;
+            const width = StandingsProgressionCharts.chartWidth;
+            const height = StandingsProgressionCharts.chartHeight;
+
+            $(document.createElementNS(svgNamespace, "polyline")).attr({
+                "points": padding + "," + padding + " " + padding + "," + (height - padding) + " " + (width - padding) + "," + (height - padding),
+                "class": "chart-axis",
+            }).appendTo($chart);
+
+            $(document.createElementNS(svgNamespace, "text")).attr({
+                "x": 5,
+                "y": 15,
+                "class": "chart-label",
+            }).text(yLabel).appendTo($chart);
+        }
+
+        private drawLine($chart: JQuery<HTMLElement>, points: string[], color: string, title: string): void {
+            // the chart is drawn with the y axis going up, svg coordinates go down.
+            const flippedPoints = points.map(point => {
+                const [x, y] = point.split(",");
+
+                return x + "," + (StandingsProgressionCharts.chartHeight - parseFloat(y));
+            });
+
+            const $line = $(document.createElementNS(svgNamespace, "polyline")).attr({
+                "points": flippedPoints.join(" "),
+                "stroke": color,
+                "class": "chart-line",
+            });
+
+            $(document.createElementNS(svgNamespace, "title")).text(title).appendTo($line);
+
+            $line.appendTo($chart);
+        }
+    }
 }
@@ -32,13 +32,64 @@ const EventCollisionWithCar = 10,
     EventError = 60,
     EventLapCompleted = 73,
     EventClientEvent = 130,
-    EventRaceControl = 200
+    EventRaceControl = 200,
+    EventRaceControlFlag = 203,
+    EventRaceControlHistory = 204,
+    EventRaceControlLapHistory = 205,
+    EventRaceControlPitLane = 206
 ;
 
 interface SimpleCollision {
     WorldPos: CarUpdateVec
 }
 
+interface RaceControlFlag {
+    Type: string;
+    Active: boolean;
+    CarID: number;
+    DriverGUID: string;
+    DriverName: string;
+    OtherDriverName: string;
+    WorldPos: CarUpdateVec;
+}
+
+interface PitStop {
+    DriverName: string;
+    SwapDriverGUID: string;
+    SwapDriverName: string;
+    Lap: number;
+    PitLaneTime: number;
+    StationaryTime: number;
+}
+
+interface RaceControlPitLane {
+    CarID: number;
+    DriverGUID: string;
+    InPitLane: boolean;
+    NumPitStops: number;
+    PitStop: PitStop | null;
+}
+
+interface SpeedSample {
+    SplinePos: number;
+    Speed: number;
+}
+
+interface LapHistory {
+    DriverGUID: string;
+    CarID: number;
+    CarModel: string;
+    Lap: number;
+    Position: number;
+    LapTime: number;
+    Cuts: number;
+    SpeedSamples: SpeedSample[] | null;
+}
+
+interface RaceControlHistory {
+    Drivers: { [driverGUID: string]: LapHistory[] };
+}
+
 interface WebsocketHandler {
     handleWebsocketMessage(message: WSMessage): void;
 
@@ -48,6 +99,7 @@ interface WebsocketHandler {
 export class RaceControl {
     private readonly liveMap: LiveMap = new LiveMap(this);
     private readonly liveTimings: LiveTimings = new LiveTimings(this, this.liveMap);
+    private readonly lapHistoryCharts: LapHistoryCharts = new LapHistoryCharts(this);
     private readonly $eventTitle: JQuery<HTMLHeadElement>;
     public status: RaceControlData;
     private firstLoad: boolean = true;
@@ -63,7 +115,9 @@ export class RaceControl {
             return;
         }
 
-        let ws = new ReconnectingWebSocket(((window.location.protocol === "https:") ? "wss://" : "ws://") + window.location.host + "/api/race-control", [], {
+        let websocketPath = this.$eventTitle.data("websocket-path") || "/api/race-control";
+
+        let ws = new ReconnectingWebSocket(((window.location.protocol === "https:") ? "wss://" : "ws://") + window.location.host + websocketPath, [], {
             minReconnectionDelay: 0,
         });
 
@@ -167,6 +221,7 @@ export class RaceControl {
 
         this.liveMap.handleWebsocketMessage(message);
         this.liveTimings.handleWebsocketMessage(message);
+        this.lapHistoryCharts.handleWebsocketMessage(message);
     }
 
     private static getSessionType(sessionIndex: number): string {
@@ -249,6 +304,12 @@ export class RaceControl {
 
         $("#event-name").text(this.status.SessionInfo.Name);
         $("#event-type").text(RaceControl.getSessionType(this.status.SessionInfo.Type));
+
+        const fullCourseYellows = this.status.FullCourseYellows;
+        // a full course yellow which hasn't ended has a zero (year 1) end time.
+        const fullCourseYellowActive = fullCourseYellows.length > 0 && fullCourseYellows[fullCourseYellows.length - 1].End.getUTCFullYear() <= 1;
+
+        $("#full-course-yellow").toggleClass("d-none", !fullCourseYellowActive);
     }
 
     private showTrackWeatherImage(): void {
@@ -378,6 +439,10 @@ class LiveMap implements WebsocketHandler {
                         let $driverDot = this.buildDriverDot(driver.CarInfo, driver.LastPos as CarUpdateVec).show();
                         this.dots.set(driver.CarInfo.DriverGUID, $driverDot);
                     }
+
+                    this.dots.get(driver.CarInfo.DriverGUID)!
+                        .toggleClass("dot-blue-flag", !!driver.BlueFlag)
+                        .toggleClass("dot-slow-car", !!driver.SlowCar);
                 }
 
                 $(".dot").css({"transition": this.raceControl.status.CurrentRealtimePosInterval + "ms linear"});
@@ -464,6 +529,30 @@ class LiveMap implements WebsocketHandler {
 
             case EventNewSession:
                 this.loadTrackMapImage();
+                this.$map.find(".yellow-flag").remove();
+
+                break;
+
+            case EventRaceControlFlag:
+                const flag = message.Message as RaceControlFlag;
+                const $flaggedDot = this.dots.get(flag.DriverGUID);
+
+                if ($flaggedDot) {
+                    $flaggedDot.toggleClass(flag.Type === FlagType.Blue ? "dot-blue-flag" : "dot-slow-car", flag.Active);
+                }
+
+                if (flag.Type === FlagType.Yellow) {
+                    this.$map.find(".yellow-flag[data-car-id='" + flag.CarID + "']").remove();
+
+                    if (flag.Active) {
+                        let flagMapPoint = this.translateToTrackCoordinate(flag.WorldPos);
+
+                        $("<div class='yellow-flag' />").attr("data-car-id", flag.CarID).css({
+                            'left': flagMapPoint.X,
+                            'top': flagMapPoint.Z,
+                        }).appendTo(this.$map);
+                    }
+                }
 
                 break;
 
@@ -607,6 +696,11 @@ enum Collision {
     WithEnvironment = "with environment",
 }
 
+enum FlagType {
+    Blue = "Blue",
+    Yellow = "Yellow",
+}
+
 class LiveTimings implements WebsocketHandler {
     private readonly raceControl: RaceControl;
     private readonly liveMap: LiveMap;
@@ -715,9 +809,42 @@ class LiveTimings implements WebsocketHandler {
             const connectedDriver = new SessionCarInfo(message.Message);
 
             this.addDriverToAdminSelects(connectedDriver);
+        } else if (message.EventType === EventRaceControlPitLane) {
+            const pitLane = message.Message as RaceControlPitLane;
+
+            if (this.raceControl.status.ConnectedDrivers) {
+                const driver = this.raceControl.status.ConnectedDrivers.Drivers[pitLane.DriverGUID];
+
+                if (driver) {
+                    driver.InPitLane = pitLane.InPitLane;
+                    driver.PitStops = pitLane.NumPitStops;
+                }
+            }
+
+            if (pitLane.PitStop) {
+                this.showPitStop(pitLane.DriverGUID, pitLane.PitStop);
+            }
         }
     }
 
+    private showPitStop(driverGUID: string, pitStop: PitStop): void {
+        const $tdEvents = this.$connectedDriversTable.find("tr[data-guid='" + driverGUID + "'] .events");
+
+        let text = "Pit stop: " + msToTime(pitStop.StationaryTime / 1000000, false) + " stationary, " + msToTime(pitStop.PitLaneTime / 1000000, false) + " in pit lane";
+
+        if (pitStop.SwapDriverGUID && pitStop.SwapDriverGUID !== driverGUID) {
+            text += " (driver swap from " + pitStop.DriverName + ")";
+        }
+
+        let $tag = $("<span/>").attr({'class': 'badge badge-info live-badge'}).text(text);
+
+        $tdEvents.append($tag);
+
+        setTimeout(() => {
+            $tag.remove();
+        }, 10000);
+    }
+
     public onTrackChange(track: string, trackLayout: string): void {
 
     }
@@ -921,6 +1048,14 @@ class LiveTimings implements WebsocketHandler {
                 }, 10000);
             }
 
+            LiveTimings.toggleFlagBadge($tdEvents, driver.CarInfo.DriverGUID + "-blue-flag", driver.BlueFlag, "badge-primary", "Blue Flag");
+            LiveTimings.toggleFlagBadge($tdEvents, driver.CarInfo.DriverGUID + "-slow-car", driver.SlowCar, "badge-warning", "Slow Car");
+            LiveTimings.toggleFlagBadge($tdEvents, driver.CarInfo.DriverGUID + "-pit-lane", driver.InPitLane, "badge-secondary", "Pit Lane");
+
+            const pitStopsID = driver.CarInfo.DriverGUID + "-pit-stops";
+            LiveTimings.toggleFlagBadge($tdEvents, pitStopsID, driver.PitStops > 0, "badge-light", "");
+            $tdEvents.find("#" + pitStopsID).text(driver.PitStops + (driver.PitStops === 1 ? " Pit Stop" : " Pit Stops"));
+
             if (driver.Collisions) {
                 for (const collision of driver.Collisions) {
                     const collisionID = driver.CarInfo.DriverGUID + "-collision-" + collision.ID;
@@ -983,6 +1118,20 @@ class LiveTimings implements WebsocketHandler {
         }
     }
 
+    private static toggleFlagBadge($tdEvents: JQuery<HTMLElement>, id: string, active: boolean, badgeClass: string, text: string): void {
+        const $existing = $tdEvents.find("#" + id);
+
+        if (active && !$existing.length) {
+            let $tag = $("<span/>").attr("id", id);
+            $tag.attr({'class': 'badge ' + badgeClass + ' live-badge'});
+            $tag.text(text);
+
+            $tdEvents.prepend($tag);
+        } else if (!active) {
+            $existing.remove();
+        }
+    }
+
     private sortTable($table: JQuery<HTMLTableElement>) {
         const $tbody = $table.find("tbody");
         const that = this;
@@ -1086,6 +1235,231 @@ class LiveTimings implements WebsocketHandler {
     }
 }
 
+// keep the same amount of history as the server.
+const maxHistoryLaps = 200,
+    maxSpeedTraceLaps = 5;
+
+const svgNamespace = "http://www.w3.org/2000/svg";
+
+class LapHistoryCharts implements WebsocketHandler {
+    private readonly raceControl: RaceControl;
+
+    private readonly $container: JQuery<HTMLDivElement>;
+    private readonly $positionChart: JQuery<HTMLElement>;
+    private readonly $speedTraceChart: JQuery<HTMLElement>;
+    private readonly $speedTraceDriver: JQuery<HTMLSelectElement>;
+
+    private laps: Map<string, LapHistory[]> = new Map<string, LapHistory[]>();
+
+    private static readonly chartWidth = 600;
+    private static readonly chartHeight = 240;
+    private static readonly chartPadding = 25;
+
+    constructor(raceControl: RaceControl) {
+        this.raceControl = raceControl;
+        this.$container = $("#lap-history");
+        this.$positionChart = $("#position-history-chart");
+        this.$speedTraceChart = $("#speed-trace-chart");
+        this.$speedTraceDriver = $("#speed-trace-driver");
+
+        this.$speedTraceDriver.on("change", this.drawSpeedTrace.bind(this));
+    }
+
+    public handleWebsocketMessage(message: WSMessage): void {
+        if (!this.$container.length) {
+            return;
+        }
+
+        switch (message.EventType) {
+            case EventRaceControlHistory:
+                const history = message.Message as RaceControlHistory;
+
+                this.laps = new Map<string, LapHistory[]>();
+
+                for (const driverGUID in history.Drivers) {
+                    this.laps.set(driverGUID, history.Drivers[driverGUID]);
+                }
+
+                this.draw();
+                break;
+
+            case EventRaceControlLapHistory:
+                const lap = message.Message as LapHistory;
+
+                let driverLaps = this.laps.get(lap.DriverGUID) || [];
+                driverLaps.push(lap);
+
+                if (driverLaps.length > maxHistoryLaps) {
+                    driverLaps = driverLaps.slice(driverLaps.length - maxHistoryLaps);
+                }
+
+                if (driverLaps.length > maxSpeedTraceLaps) {
+                    driverLaps[driverLaps.length - maxSpeedTraceLaps - 1].SpeedSamples = null;
+                }
+
+                this.laps.set(lap.DriverGUID, driverLaps);
+                this.draw();
+                break;
+
+            case EventNewSession:
+                this.laps = new Map<string, LapHistory[]>();
+                this.draw();
+                break;
+        }
+    }
+
+    public onTrackChange(track: string, trackLayout: string): void {
+
+    }
+
+    private driverName(driverGUID: string): string {
+        const status = this.raceControl.status;
+
+        for (const driverMap of [status.ConnectedDrivers, status.DisconnectedDrivers]) {
+            if (driverMap && driverMap.Drivers[driverGUID]) {
+                return driverMap.Drivers[driverGUID].CarInfo.DriverName;
+            }
+        }
+
+        return driverGUID;
+    }
+
+    private draw(): void {
+        this.$container.toggleClass("d-none", this.laps.size === 0);
+
+        this.updateSpeedTraceDrivers();
+        this.drawPositionHistory();
+        this.drawSpeedTrace();
+    }
+
+    private updateSpeedTraceDrivers(): void {
+        const selected = this.$speedTraceDriver.val();
+
+        this.$speedTraceDriver.empty();
+
+        this.laps.forEach((laps: LapHistory[], driverGUID: string) => {
+            $("<option>").val(driverGUID).text(this.driverName(driverGUID)).appendTo(this.$speedTraceDriver);
+        });
+
+        if (selected && this.laps.has(selected as string)) {
+            this.$speedTraceDriver.val(selected);
+        }
+    }
+
+    private drawPositionHistory(): void {
+        this.$positionChart.empty();
+
+        let maxLap = 1;
+        let maxPosition = 1;
+
+        this.laps.forEach((laps: LapHistory[]) => {
+            for (const lap of laps) {
+                maxLap = Math.max(maxLap, lap.Lap);
+                maxPosition = Math.max(maxPosition, lap.Position);
+            }
+        });
+
+        // positions are drawn top to bottom, with the leader at the top.
+        const x = (lap: number) => LapHistoryCharts.scale(lap, 0, maxLap, LapHistoryCharts.chartWidth);
+        const y = (position: number) => LapHistoryCharts.scale(maxPosition + 1 - position, 0, maxPosition, LapHistoryCharts.chartHeight);
+
+        this.drawAxes(this.$positionChart, "Lap", "Position");
+
+        this.laps.forEach((laps: LapHistory[], driverGUID: string) => {
+            const points = laps.filter(lap => lap.Position > 0).map(lap => x(lap.Lap) + "," + y(lap.Position));
+
+            this.drawLine(this.$positionChart, points, randomColorForDriver(driverGUID), this.driverName(driverGUID));
+        });
+    }
+
+    private drawSpeedTrace(): void {
+        this.$speedTraceChart.empty();
+
+        const driverLaps = this.laps.get(this.$speedTraceDriver.val() as string);
+
+        if (!driverLaps) {
+            return;
+        }
+
+        const laps = driverLaps.filter(lap => lap.SpeedSamples && lap.SpeedSamples.length > 0);
+
+        let maxSpeed = 1;
+
+        for (const lap of laps) {
+            for (const sample of lap.SpeedSamples!) {
+                maxSpeed = Math.max(maxSpeed, sample.Speed);
+            }
+        }
+
+        const speedMultiplier = useMPH ? 0.621371 : 1;
+
+        const x = (splinePos: number) => LapHistoryCharts.scale(splinePos, 0, 1, LapHistoryCharts.chartWidth);
+        const y = (speed: number) => LapHistoryCharts.scale(speed, 0, maxSpeed, LapHistoryCharts.chartHeight);
+
+        this.drawAxes(this.$speedTraceChart, "Lap Distance", "Speed (max " + Math.round(maxSpeed * speedMultiplier) + (useMPH ? " MPH)" : " Km/h)"));
+
+        laps.forEach((lap: LapHistory, index: number) => {
+            const points = lap.SpeedSamples!.map(sample => x(sample.SplinePos) + "," + y(sample.Speed));
+
+            // the most recent lap is drawn on top, earlier laps fade out.
+            this.drawLine(this.$speedTraceChart, points, randomColorForDriver(lap.DriverGUID), "Lap " + lap.Lap + ": " + msToTime(lap.LapTime / 1000000))
+                .attr("opacity", (index + 1) / laps.length);
+        });
+    }
+
+    private static scale(value: number, min: number, max: number, size: number): number {
+        if (max === min) {
+            return LapHistoryCharts.chartPadding;
+        }
+
+        return LapHistoryCharts.chartPadding + ((value - min) / (max - min)) * (size - LapHistoryCharts.chartPadding * 2);
+    }
+
+    private drawAxes($chart: JQuery<HTMLElement>, xLabel: string, yLabel: string): void {
+        const padding = LapHistoryCharts.chartPadding;
+        const width = LapHistoryCharts.chartWidth;
+        const height = LapHistoryCharts.chartHeight;
+
+        $chart.attr("viewBox", "0 0 " + width + " " + height);
+
+        $(document.createElementNS(svgNamespace, "polyline")).attr({
+            "points": padding + "," + padding + " " + padding + "," + (height - padding) + " " + (width - padding) + "," + (height - padding),
+            "class": "chart-axis",
+        }).appendTo($chart);
+
+        $(document.createElementNS(svgNamespace, "text")).attr({
+            "x": width / 2,
+            "y": height - 5,
+            "class": "chart-label",
+        }).text(xLabel).appendTo($chart);
+
+        $(document.createElementNS(svgNamespace, "text")).attr({
+            "x": 5,
+            "y": 15,
+            "class": "chart-label",
+        }).text(yLabel).appendTo($chart);
+    }
+
+    private drawLine($chart: JQuery<HTMLElement>, points: string[], color: string, title: string): JQuery<Element> {
+        // the chart is drawn with the y axis going up, svg coordinates go down.
+        const flippedPoints = points.map(point => {
+            const [x, y] = point.split(",");
+
+            return x + "," + (LapHistoryCharts.chartHeight - parseFloat(y));
+        });
+
+        const $line = $(document.createElementNS(svgNamespace, "polyline")).attr({
+            "points": flippedPoints.join(" "),
+            "stroke": color,
+            "class": "chart-line",
+        });
+
+        $(document.createElementNS(svgNamespace, "title")).text(title).appendTo($line);
+
+        return $line.appendTo($chart);
+    }
+}
+
 function randomColorForDriver(driverGUID: string): string {
     return randomColor({
         seed: driverGUID,
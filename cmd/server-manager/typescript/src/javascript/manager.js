@@ -319,6 +319,8 @@ class RaceSetup {
         this.initPickupModeWatcher();
         this.initTimeAttackWatcher();
         this.initDriverSwapToggle();
+        this.initTrackLimitsToggle();
+        this.initPitLaneToggle();
     }
 
     initPickupModeWatcher() {
@@ -457,6 +459,60 @@ class RaceSetup {
         }
     }
 
+    initTrackLimitsToggle() {
+        let $trackLimitsSwitch = $("#TrackLimitsEnabled");
+
+        if (!$trackLimitsSwitch.length) {
+            return;
+        }
+
+        this.toggleTrackLimitsOptions();
+
+        let that = this;
+
+        $trackLimitsSwitch.on('switchChange.bootstrapSwitch', function (event, state) {
+            that.toggleTrackLimitsOptions();
+        });
+    }
+
+    toggleTrackLimitsOptions() {
+        let $trackLimitsSwitch = $("#TrackLimitsEnabled");
+        let $trackLimitsOptionPanel = $(".visible-track-limits-enabled");
+
+        if ($trackLimitsSwitch.bootstrapSwitch('state')) {
+            $trackLimitsOptionPanel.show();
+        } else {
+            $trackLimitsOptionPanel.hide();
+        }
+    }
+
+    initPitLaneToggle() {
+        let $pitLaneSwitch = $("#PitLaneEnabled");
+
+        if (!$pitLaneSwitch.length) {
+            return;
+        }
+
+        this.togglePitLaneOptions();
+
+        let that = this;
+
+        $pitLaneSwitch.on('switchChange.bootstrapSwitch', function (event, state) {
+            that.togglePitLaneOptions();
+        });
+    }
+
+    togglePitLaneOptions() {
+        let $pitLaneSwitch = $("#PitLaneEnabled");
+        let $pitLaneOptionPanel = $(".visible-pit-lane-enabled");
+
+        if ($pitLaneSwitch.bootstrapSwitch('state')) {
+            $pitLaneOptionPanel.show();
+        } else {
+            $pitLaneOptionPanel.hide();
+        }
+    }
+
     updateWeatherGraphics() {
         let $this = $(this);
 
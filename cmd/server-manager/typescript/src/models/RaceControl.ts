@@ -306,6 +306,10 @@ class RaceControlDriverMapRaceControlDriver {
     Split: string;
     LastSeen: Date;
     LastPos: RaceControlDriverMapRaceControlDriverVec;
+    BlueFlag: boolean;
+    SlowCar: boolean;
+    InPitLane: boolean;
+    PitStops: number;
     Collisions: RaceControlDriverMapRaceControlDriverCollision[];
     Cars: { [key: string]: RaceControlDriverMapRaceControlDriverRaceControlCarLapInfo };
 
@@ -319,6 +323,10 @@ class RaceControlDriverMapRaceControlDriver {
         this.Split = ('Split' in d) ? d.Split as string : '';
         this.LastSeen = ('LastSeen' in d) ? ParseDate(d.LastSeen) : new Date();
         this.LastPos = new RaceControlDriverMapRaceControlDriverVec(d.LastPos);
+        this.BlueFlag = ('BlueFlag' in d) ? d.BlueFlag as boolean : false;
+        this.SlowCar = ('SlowCar' in d) ? d.SlowCar as boolean : false;
+        this.InPitLane = ('InPitLane' in d) ? d.InPitLane as boolean : false;
+        this.PitStops = ('PitStops' in d) ? d.PitStops as number : 0;
         this.Collisions = Array.isArray(d.Collisions) ? d.Collisions.map((v: any) => new RaceControlDriverMapRaceControlDriverCollision(v)) : [];
         this.Cars = ('Cars' in d) ? d.Cars as { [key: string]: RaceControlDriverMapRaceControlDriverRaceControlCarLapInfo } : {};
     }
@@ -334,6 +342,36 @@ class RaceControlDriverMapRaceControlDriver {
     }
 }
 
+// struct2ts:github.com/JustaPenguin/assetto-server-manager.RaceControlFullCourseYellow
+class RaceControlFullCourseYellow {
+    Start: Date;
+    End: Date;
+    StartedBy: string;
+    SessionStart: number;
+    SessionEnd: number;
+    SpeedLimit: number;
+
+    constructor(data?: any) {
+        const d: any = (data && typeof data === 'object') ? ToObject(data) : {};
+        this.Start = ('Start' in d) ? ParseDate(d.Start) : new Date();
+        this.End = ('End' in d) ? ParseDate(d.End) : new Date();
+        this.StartedBy = ('StartedBy' in d) ? d.StartedBy as string : '';
+        this.SessionStart = ('SessionStart' in d) ? d.SessionStart as number : 0;
+        this.SessionEnd = ('SessionEnd' in d) ? d.SessionEnd as number : 0;
+        this.SpeedLimit = ('SpeedLimit' in d) ? d.SpeedLimit as number : 0;
+    }
+
+    toObject(): any {
+        const cfg: any = {};
+        cfg.Start = 'string';
+        cfg.End = 'string';
+        cfg.SessionStart = 'number';
+        cfg.SessionEnd = 'number';
+        cfg.SpeedLimit = 'number';
+        return ToObject(this, cfg);
+    }
+}
+
 // struct2ts:github.com/JustaPenguin/assetto-server-manager.RaceControlDriverMap
 class RaceControlDriverMap {
     Drivers: { [key: string]: RaceControlDriverMapRaceControlDriver };
@@ -361,6 +399,7 @@ class RaceControl {
     ConnectedDrivers: RaceControlDriverMap | null;
     DisconnectedDrivers: RaceControlDriverMap | null;
     CarIDToGUID: { [key: number]: string };
+    FullCourseYellows: RaceControlFullCourseYellow[];
 
     constructor(data?: any) {
         const d: any = (data && typeof data === 'object') ? ToObject(data) : {};
@@ -372,6 +411,7 @@ class RaceControl {
         this.ConnectedDrivers = ('ConnectedDrivers' in d) ? new RaceControlDriverMap(d.ConnectedDrivers) : null;
         this.DisconnectedDrivers = ('DisconnectedDrivers' in d) ? new RaceControlDriverMap(d.DisconnectedDrivers) : null;
         this.CarIDToGUID = ('CarIDToGUID' in d) ? d.CarIDToGUID as { [key: number]: string } : {};
+        this.FullCourseYellows = Array.isArray(d.FullCourseYellows) ? d.FullCourseYellows.map((v: any) => new RaceControlFullCourseYellow(v)) : [];
     }
 
     toObject(): any {
@@ -392,6 +432,7 @@ export {
     RaceControlDriverMapRaceControlDriverCollision,
     RaceControlDriverMapRaceControlDriverRaceControlCarLapInfo,
     RaceControlDriverMapRaceControlDriver,
+    RaceControlFullCourseYellow,
     RaceControlDriverMap,
     RaceControl,
     ParseDate,
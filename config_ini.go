@@ -211,6 +211,20 @@ type GlobalServerConfig struct {
 	LogACServerOutputToFile           bool                 `ini:"-" show:"open" help:"When on, Server Manager will output each Assetto Corsa session into a log file in the logs folder."`
 	NumberOfACServerLogsToKeep        int                  `ini:"-" show:"open" help:"The number of AC Server logs to keep in the logs folder. (Oldest files will be deleted first. 0 = keep all files)"`
 	ShowEventDetailsPopup             bool                 `ini:"-" help:"Allows all users to view a popup that describes in detail the setup of Custom Races, Championship Events and Race Weekend Sessions."`
+	RecordSessionReplays              formulate.BoolNumber `ini:"-" help:"When on, Server Manager will record the Live Timing data of every session, so that it can be replayed from the session's results page. Recordings are saved in the 'replays' folder of your Assetto Corsa Server install. Live Timings must be enabled (i.e. performance mode must be off) for sessions to be recorded."`
+	AnonymiseLeaderboards             formulate.BoolNumber `ini:"-" help:"When on, driver GUIDs are hidden and driver names are shortened on the Leaderboards page and in the Leaderboards API."`
+
+	Stewarding                   FormHeading          `ini:"-" json:"-"`
+	EnableStewarding             formulate.BoolNumber `ini:"-" help:"When on, contacts between cars are grouped into incidents which can be reviewed (and penalised) by stewards on the Stewards page. Live Timings must be enabled (i.e. performance mode must be off) for incidents to be detected."`
+	StewardingMinimumImpactSpeed int                  `ini:"-" min:"0" help:"Contacts between cars with an impact speed (in km/h) lower than this are ignored by the stewards."`
+
+	LapAnomalyDetection           FormHeading          `ini:"-" json:"-"`
+	LapAnomalySectorMargin        float64              `ini:"-" min:"0" help:"After each session, a sector time this many percent faster than the fastest time in that sector by any other driver in the same car is flagged in the session's Lap Anomalies report. 0 disables this check."`
+	LapAnomalyTrackRecordMargin   float64              `ini:"-" min:"0" help:"A lap this many percent faster than the track record in the same car (from previous sessions) is flagged in the session's Lap Anomalies report. 0 disables this check. Laps whose sectors don't add up to the lap time are always flagged, as are cars that teleport in the session replay (if session replays are recorded)."`
+	LapAnomalyDiscordNotification formulate.BoolNumber `ini:"-" help:"If Discord is enabled, a message is sent when a session's Lap Anomalies report finds any suspicious laps."`
+
+	RaceControlAPI     FormHeading `ini:"-" json:"-" name:"Race Control API"`
+	RaceControlAPIKeys string      `ini:"-" name:"Race Control API Keys" help:"A comma separated list of keys which can be used to access the Race Control API, a versioned API for overlays, bots and other tools. The API is disabled if no keys are set. The current session is available as JSON at /api/v1/race-control, and laps, collisions, chat messages and session changes are streamed as Server-Sent Events from /api/v1/race-control/events (use ?events=lap,collision,chat,session to choose which). Send a key in the 'X-API-Key' header or the 'api_key' query parameter. Live Timings must be enabled (i.e. performance mode must be off) to use the API."`
 
 	// Discord Integration
 	DiscordIntegration FormHeading `ini:"-" json:"-"`
@@ -223,12 +237,26 @@ type GlobalServerConfig struct {
 	NotificationReminderTimers  string               `ini:"-" help:"If Discord is enabled, a reminder will be sent this many minutes prior to race start.  If 0 or empty, only race start messages will be sent.  You may schedule multiple reminders by using a comma separated list like 120,15."`
 	ShowPasswordInNotifications formulate.BoolNumber `ini:"-" help:"Show the server password in race start notifications."`
 	NotifyWhenScheduled         formulate.BoolNumber `ini:"-" help:"Send a notification when a race is scheduled (or cancelled)."`
+	NotifyWhenTitleDecided      formulate.BoolNumber `ini:"-" help:"Send a notification when a Championship title is decided."`
 
 	// Messages
 	ContentManagerWelcomeMessage string `ini:"-" show:"-"`
 	ServerJoinMessage            string `ini:"-" show:"-"`
 }
 
+// RaceControlAPIKeyList returns the keys which can be used to access the Race Control API.
+func (gsc GlobalServerConfig) RaceControlAPIKeyList() []string {
+	var keys []string
+
+	for _, key := range strings.Split(gsc.RaceControlAPIKeys, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
 func (gsc GlobalServerConfig) GetName() string {
 	split := strings.Split(gsc.Name, fmt.Sprintf(" %c", contentManagerWrapperSeparator))
 
@@ -349,6 +377,12 @@ type CurrentRaceConfig struct {
 	ExportSecondRaceToACSR bool `ini:"-"`
 
 	DynamicTrack DynamicTrackConfig `ini:"-"`
+	TrackLimits  TrackLimitsConfig  `ini:"-"`
+
+	// FullCourseYellowSpeedLimit is the speed (in km/h) that drivers must stay below during a Full Course Yellow.
+	FullCourseYellowSpeedLimit int `ini:"-"`
+
+	PitLane PitLaneConfig `ini:"-"`
 
 	Sessions Sessions                  `ini:"-"`
 	Weather  map[string]*WeatherConfig `ini:"-"`
@@ -481,6 +515,47 @@ type DynamicTrackConfig struct {
 	LapGain         int `ini:"LAP_GAIN" help:"how many laps are needed to add 1% grip"`
 }
 
+// TrackLimitsConfig configures the track limits rules which Server Manager enforces in race sessions. Each rule
+// is triggered when a driver's total number of cuts in the session reaches its threshold. A threshold of 0 disables
+// that rule.
+type TrackLimitsConfig struct {
+	Enabled bool
+
+	WarningCuts        int
+	TimePenaltyCuts    int
+	TimePenaltySeconds int
+
+	// Assetto Corsa cannot enforce drive-through penalties itself, so drivers are told that they have been given a
+	// drive-through, and it is added to their race time as DriveThroughSeconds.
+	DriveThroughCuts    int
+	DriveThroughSeconds int
+	DisqualifyCuts      int
+}
+
+// PitLaneConfig describes the pit lane of the event's track, so that Server Manager can detect pit stops. A car is
+// in the pit lane when it is between the pit lane entry and exit and below the pit lane speed limit.
+type PitLaneConfig struct {
+	Enabled bool
+
+	// EntrySplinePos and ExitSplinePos are how far round the lap (from 0 to 1) the pit lane entry and exit are. If
+	// the pit lane crosses the start/finish line, EntrySplinePos is greater than ExitSplinePos.
+	EntrySplinePos float64
+	ExitSplinePos  float64
+
+	// SpeedLimit is the pit lane speed limit, in km/h.
+	SpeedLimit int
+}
+
+func (p PitLaneConfig) containsSplinePos(splinePos float32) bool {
+	pos := float64(splinePos)
+
+	if p.EntrySplinePos > p.ExitSplinePos {
+		return pos >= p.EntrySplinePos || pos <= p.ExitSplinePos
+	}
+
+	return pos >= p.EntrySplinePos && pos <= p.ExitSplinePos
+}
+
 const (
 	weatherPractice = "weatherPractice"
 	weatherEvent    = "weatherEvent"
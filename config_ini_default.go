@@ -30,6 +30,9 @@ func ConfigIniDefault() ServerConfig {
 			RestartEventOnServerManagerLaunch: 1,
 			ContentManagerWelcomeMessage:      defaultContentManagerDescription,
 			ShowEventDetailsPopup:             true,
+			StewardingMinimumImpactSpeed:      defaultStewardingMinimumImpactSpeed,
+			LapAnomalySectorMargin:            defaultLapAnomalySectorMargin,
+			LapAnomalyTrackRecordMargin:       defaultLapAnomalyTrackRecordMargin,
 		},
 
 		CurrentRaceConfig: CurrentRaceConfig{
@@ -98,6 +101,25 @@ func ConfigIniDefault() ServerConfig {
 				LapGain:         10,
 			},
 
+			TrackLimits: TrackLimitsConfig{
+				Enabled:             false,
+				WarningCuts:         3,
+				TimePenaltyCuts:     5,
+				TimePenaltySeconds:  5,
+				DriveThroughCuts:    8,
+				DriveThroughSeconds: 20,
+				DisqualifyCuts:      0,
+			},
+
+			FullCourseYellowSpeedLimit: defaultFullCourseYellowSpeedLimit,
+
+			PitLane: PitLaneConfig{
+				Enabled:        false,
+				EntrySplinePos: 0.95,
+				ExitSplinePos:  0.05,
+				SpeedLimit:     80,
+			},
+
 			Weather: map[string]*WeatherConfig{
 				"WEATHER_0": {
 					Graphics:                    "3_clear",
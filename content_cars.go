@@ -244,10 +244,11 @@ type CarManager struct {
 	tyreUpdateMutex sync.Mutex
 
 	trackManager *TrackManager
+	resultsIndex *ResultsIndex
 }
 
-func NewCarManager(trackManager *TrackManager, watchForCarChanges, useCarNameCache bool) *CarManager {
-	cm := &CarManager{trackManager: trackManager, watchFilesystemForCarChanges: watchForCarChanges}
+func NewCarManager(trackManager *TrackManager, resultsIndex *ResultsIndex, watchForCarChanges, useCarNameCache bool) *CarManager {
+	cm := &CarManager{trackManager: trackManager, resultsIndex: resultsIndex, watchFilesystemForCarChanges: watchForCarChanges}
 
 	if useCarNameCache {
 		cm.initCarNames()
@@ -493,30 +494,9 @@ func (cm *CarManager) RandomSkin(model string) string {
 
 // ResultsForCar finds results for a given car.
 func (cm *CarManager) ResultsForCar(car string) ([]SessionResults, error) {
-	results, err := ListAllResults()
-
-	if err != nil {
-		return nil, err
-	}
-
-	var out []SessionResults
-
-	for _, result := range results {
-		hasCar := false
-
-		for _, driver := range result.Result {
-			if driver.CarModel == car {
-				hasCar = true
-				break
-			}
-		}
-
-		if hasCar {
-			out = append(out, result)
-		}
-	}
-
-	return out, nil
+	return cm.resultsIndex.LoadResults(func(entry *ResultsIndexEntry) bool {
+		return entry.HasCar(car)
+	})
 }
 
 // DeleteCar removes a car from the file system and search index.
@@ -622,6 +602,7 @@ func (cm *CarManager) UpdateTyres(car *Car) error {
 // IndexCar indexes an individual car.
 func (cm *CarManager) IndexCar(car *Car) error {
 	carNameCache.add(car)
+	forgetCarManufacturer(car.Name)
 
 	if err := cm.UpdateTyres(car); err != nil {
 		logrus.WithError(err).Errorf("Could not update tyres for car: %s", car.Name)
@@ -633,6 +614,7 @@ func (cm *CarManager) IndexCar(car *Car) error {
 // DeIndexCar removes a car from the index.
 func (cm *CarManager) DeIndexCar(name string) error {
 	carNameCache.remove(name)
+	forgetCarManufacturer(name)
 
 	return cm.carIndex.Delete(name)
 }
@@ -341,10 +341,11 @@ func (th *TracksHandler) trackInfo(w http.ResponseWriter, r *http.Request) {
 }
 
 type TrackManager struct {
+	resultsIndex *ResultsIndex
 }
 
-func NewTrackManager() *TrackManager {
-	return &TrackManager{}
+func NewTrackManager(resultsIndex *ResultsIndex) *TrackManager {
+	return &TrackManager{resultsIndex: resultsIndex}
 }
 
 type trackDetailsTemplateVars struct {
@@ -399,22 +400,11 @@ func (tm *TrackManager) loadTrackDetailsForTemplate(trackName string) (*trackDet
 	}, nil
 }
 
+// ResultsForLayout finds results for a given track layout.
 func (tm *TrackManager) ResultsForLayout(trackName, layout string) ([]SessionResults, error) {
-	results, err := ListAllResults()
-
-	if err != nil {
-		return nil, err
-	}
-
-	var out []SessionResults
-
-	for _, result := range results {
-		if result.TrackName == trackName && result.TrackConfig == layout {
-			out = append(out, result)
-		}
-	}
-
-	return out, nil
+	return tm.resultsIndex.LoadResults(func(entry *ResultsIndexEntry) bool {
+		return entry.TrackName == trackName && entry.TrackConfig == layout
+	})
 }
 
 func (tm *TrackManager) ListTracks() ([]Track, error) {
@@ -633,6 +623,7 @@ func (tm *TrackManager) UpdateTrackMetadata(name string, r *http.Request) error
 type TrackDataGateway interface {
 	TrackInfo(name, layout string) (*TrackInfo, error)
 	TrackMap(name, layout string) (*TrackMapData, error)
+	TrackSectors(name, layout string) (sectors []float32, approximate bool, err error)
 }
 
 type filesystemTrackData struct{}
@@ -641,6 +632,10 @@ func (filesystemTrackData) TrackMap(name, layout string) (*TrackMapData, error)
 	return LoadTrackMapData(name, layout)
 }
 
+func (filesystemTrackData) TrackSectors(name, layout string) ([]float32, bool, error) {
+	return LoadTrackSectors(name, layout)
+}
+
 func (filesystemTrackData) TrackInfo(name, layout string) (*TrackInfo, error) {
 	trackInfo, err := GetTrackInfo(name, layout)
 
@@ -705,6 +700,96 @@ func LoadTrackMapData(track, trackLayout string) (*TrackMapData, error) {
 	return &mapData, nil
 }
 
+const (
+	trackSectorsFilename   = "sectors.ini"
+	defaultNumTrackSectors = 3
+)
+
+// LoadTrackSectors returns the normalised spline position at which each sector of a track layout starts.
+//
+// Assetto Corsa times sectors using timing objects in the track's 3D model, which Server Manager can't read, so the
+// sectors are read from data/sectors.ini in the track layout folder instead. sectors.ini is not part of Assetto Corsa
+// and isn't shipped with tracks, it must be written by hand. It has a section for each sector, in the same format as
+// drs_zones.ini, where START is the normalised spline position (0 at the start/finish line, up to 1) at which the
+// sector starts, e.g. for a track whose second and third sectors start 35.3% and 71% of the way around the lap:
+//
+//	[SECTOR_0]
+//	START=0
+//
+//	[SECTOR_1]
+//	START=0.353
+//
+//	[SECTOR_2]
+//	START=0.71
+//
+// The first sector always starts at the start/finish line. For live sector times to match the sectors in results
+// files, there must be one section for each of the track's sectors, starting at the track's timing lines.
+//
+// If the track has no sectors.ini, the lap is split into defaultNumTrackSectors sectors of equal length, and approximate
+// is true. Approximate sectors won't match the sectors in results files.
+func LoadTrackSectors(track, trackLayout string) (sectors []float32, approximate bool, err error) {
+	p := filepath.Join(ServerInstallPath, "content", "tracks", track)
+
+	if trackLayout != "" {
+		p = filepath.Join(p, trackLayout)
+	}
+
+	p = filepath.Join(p, "data", trackSectorsFilename)
+
+	f, err := os.Open(p)
+
+	if os.IsNotExist(err) {
+		return defaultTrackSectors(), true, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	defer f.Close()
+
+	i, err := ini.Load(f)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	for _, section := range i.Sections() {
+		if !strings.HasPrefix(section.Name(), "SECTOR_") {
+			continue
+		}
+
+		start, err := section.Key("START").Float64()
+
+		if err != nil {
+			return nil, false, err
+		}
+
+		sectors = append(sectors, float32(start))
+	}
+
+	if len(sectors) == 0 {
+		return defaultTrackSectors(), true, nil
+	}
+
+	sort.Slice(sectors, func(i, j int) bool {
+		return sectors[i] < sectors[j]
+	})
+
+	// the first sector always starts at the start/finish line
+	sectors[0] = 0
+
+	return sectors, false, nil
+}
+
+func defaultTrackSectors() []float32 {
+	sectors := make([]float32, defaultNumTrackSectors)
+
+	for i := range sectors {
+		sectors[i] = float32(i) / defaultNumTrackSectors
+	}
+
+	return sectors
+}
+
 func TrackMapImageURL(track, trackLayout string) string {
 	p := "/content/tracks/" + track
 
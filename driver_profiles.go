@@ -0,0 +1,433 @@
+package servermanager
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// numDriverProfileFavourites is the number of favourite cars and tracks shown on a driver's profile.
+	numDriverProfileFavourites = 5
+
+	// numDriverProfileRecentSessions is the number of most recent sessions shown on a driver's profile.
+	numDriverProfileRecentSessions = 10
+)
+
+var ErrDriverProfileNotFound = errors.New("servermanager: driver profile not found")
+
+// DriverProfileSession is a driver's part in a single session. Distance is in kilometres, and is zero if the length
+// of the track is not known.
+type DriverProfileSession struct {
+	SessionFile    string      `json:"SessionFile"`
+	Date           time.Time   `json:"Date"`
+	SessionType    SessionType `json:"SessionType"`
+	Track          string      `json:"Track"`
+	TrackLayout    string      `json:"TrackLayout"`
+	ChampionshipID string      `json:"ChampionshipID"`
+	RaceWeekendID  string      `json:"RaceWeekendID"`
+
+	DriverGUID string `json:"DriverGUID"`
+	DriverName string `json:"DriverName"`
+	CarModel   string `json:"CarModel"`
+
+	Position     int  `json:"Position"`
+	NumEntrants  int  `json:"NumEntrants"`
+	Disqualified bool `json:"Disqualified"`
+
+	// FastestLap is true if the driver set the fastest lap without cuts in the session.
+	FastestLap bool          `json:"FastestLap"`
+	BestLap    time.Duration `json:"BestLap"`
+	NumLaps    int           `json:"NumLaps"`
+	Incidents  int           `json:"Incidents"`
+	Distance   float64       `json:"Distance"`
+}
+
+func (s *DriverProfileSession) IsRace() bool {
+	return s.SessionType == SessionTypeRace
+}
+
+func (s *DriverProfileSession) IsPole() bool {
+	return s.SessionType == SessionTypeQualifying && s.Position == 1 && !s.Disqualified
+}
+
+// DriverProfileFavourite is a car or track, and how much a driver has used it. Layout is only set for tracks.
+type DriverProfileFavourite struct {
+	Name     string `json:"Name"`
+	Layout   string `json:"Layout"`
+	Sessions int    `json:"Sessions"`
+	NumLaps  int    `json:"NumLaps"`
+}
+
+// DriverProfileChampionship is a driver's standing in a championship. Position is zero if the driver has not scored
+// in the championship.
+type DriverProfileChampionship struct {
+	ID          string  `json:"ID"`
+	Name        string  `json:"Name"`
+	Class       string  `json:"Class"`
+	Position    int     `json:"Position"`
+	Points      float64 `json:"Points"`
+	NumEntrants int     `json:"NumEntrants"`
+	Progress    float64 `json:"Progress"`
+	Sessions    int     `json:"Sessions"`
+}
+
+// DriverProfile is a driver's career statistics, across every results file.
+type DriverProfile struct {
+	GUID      string    `json:"GUID"`
+	Name      string    `json:"Name"`
+	FirstSeen time.Time `json:"FirstSeen"`
+	LastSeen  time.Time `json:"LastSeen"`
+
+	NumSessions  int     `json:"NumSessions"`
+	RacesEntered int     `json:"RacesEntered"`
+	Wins         int     `json:"Wins"`
+	Podiums      int     `json:"Podiums"`
+	Poles        int     `json:"Poles"`
+	FastestLaps  int     `json:"FastestLaps"`
+	NumLaps      int     `json:"NumLaps"`
+	Incidents    int     `json:"Incidents"`
+	Distance     float64 `json:"Distance"`
+
+	// AverageFinishingPosition is the driver's average finishing position in races that they were not
+	// disqualified from.
+	AverageFinishingPosition float64 `json:"AverageFinishingPosition"`
+
+	FavouriteCars   []*DriverProfileFavourite    `json:"FavouriteCars"`
+	FavouriteTracks []*DriverProfileFavourite    `json:"FavouriteTracks"`
+	Championships   []*DriverProfileChampionship `json:"Championships"`
+	RecentSessions  []*DriverProfileSession      `json:"RecentSessions"`
+}
+
+// IncidentsPer100KM is the number of collisions the driver has per 100 km driven. It is only known for tracks with
+// a known length.
+func (p *DriverProfile) IncidentsPer100KM() float64 {
+	if p.Distance == 0 {
+		return 0
+	}
+
+	return float64(p.Incidents) / p.Distance * 100
+}
+
+// newDriverProfile calculates a driver's statistics from the sessions they have taken part in.
+func newDriverProfile(guid string, sessions []*DriverProfileSession) *DriverProfile {
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Date.After(sessions[j].Date)
+	})
+
+	profile := &DriverProfile{
+		GUID:        guid,
+		NumSessions: len(sessions),
+	}
+
+	if len(sessions) == 0 {
+		return profile
+	}
+
+	profile.Name = sessions[0].DriverName
+	profile.LastSeen = sessions[0].Date
+	profile.FirstSeen = sessions[len(sessions)-1].Date
+
+	cars := make(map[string]*DriverProfileFavourite)
+	tracks := make(map[string]*DriverProfileFavourite)
+
+	var totalFinishingPositions, numFinishes int
+
+	for _, session := range sessions {
+		profile.NumLaps += session.NumLaps
+		profile.Incidents += session.Incidents
+		profile.Distance += session.Distance
+
+		if session.IsPole() {
+			profile.Poles++
+		}
+
+		if session.IsRace() {
+			profile.RacesEntered++
+
+			if session.FastestLap {
+				profile.FastestLaps++
+			}
+
+			if !session.Disqualified {
+				totalFinishingPositions += session.Position
+				numFinishes++
+
+				if session.Position == 1 {
+					profile.Wins++
+				}
+
+				if session.Position <= 3 {
+					profile.Podiums++
+				}
+			}
+		}
+
+		car, ok := cars[session.CarModel]
+
+		if !ok {
+			car = &DriverProfileFavourite{Name: session.CarModel}
+			cars[session.CarModel] = car
+		}
+
+		car.Sessions++
+		car.NumLaps += session.NumLaps
+
+		trackKey := session.Track + "/" + session.TrackLayout
+		track, ok := tracks[trackKey]
+
+		if !ok {
+			track = &DriverProfileFavourite{Name: session.Track, Layout: session.TrackLayout}
+			tracks[trackKey] = track
+		}
+
+		track.Sessions++
+		track.NumLaps += session.NumLaps
+	}
+
+	if numFinishes > 0 {
+		profile.AverageFinishingPosition = float64(totalFinishingPositions) / float64(numFinishes)
+	}
+
+	profile.FavouriteCars = driverProfileFavourites(cars)
+	profile.FavouriteTracks = driverProfileFavourites(tracks)
+
+	if len(sessions) > numDriverProfileRecentSessions {
+		profile.RecentSessions = sessions[:numDriverProfileRecentSessions]
+	} else {
+		profile.RecentSessions = sessions
+	}
+
+	return profile
+}
+
+func driverProfileFavourites(favourites map[string]*DriverProfileFavourite) []*DriverProfileFavourite {
+	var out []*DriverProfileFavourite
+
+	for _, favourite := range favourites {
+		out = append(out, favourite)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].NumLaps == out[j].NumLaps {
+			if out[i].Sessions == out[j].Sessions {
+				return out[i].Name+out[i].Layout < out[j].Name+out[j].Layout
+			}
+
+			return out[i].Sessions > out[j].Sessions
+		}
+
+		return out[i].NumLaps > out[j].NumLaps
+	})
+
+	if len(out) > numDriverProfileFavourites {
+		out = out[:numDriverProfileFavourites]
+	}
+
+	return out
+}
+
+// driverProfileSessions returns each driver's part in a session. Drivers without a valid result in the session
+// are skipped.
+func driverProfileSessions(results *SessionResults, trackLength float64) []*DriverProfileSession {
+	var sessions []*DriverProfileSession
+
+	fastestLap := results.FastestLap()
+	seen := make(map[string]bool)
+
+	for i, result := range results.Result {
+		if result.DriverGUID == "" || seen[result.DriverGUID] {
+			continue
+		}
+
+		seen[result.DriverGUID] = true
+
+		session := &DriverProfileSession{
+			SessionFile:    results.SessionFile,
+			Date:           results.Date,
+			SessionType:    results.Type,
+			Track:          results.TrackName,
+			TrackLayout:    results.TrackConfig,
+			ChampionshipID: results.ChampionshipID,
+			RaceWeekendID:  results.RaceWeekendID,
+			DriverGUID:     result.DriverGUID,
+			DriverName:     result.DriverName,
+			CarModel:       result.CarModel,
+			Position:       i + 1,
+			NumEntrants:    len(results.Result),
+			Disqualified:   result.Disqualified,
+			BestLap:        time.Duration(result.BestLap) * time.Millisecond,
+		}
+
+		if fastestLap != nil && fastestLap.Cuts == 0 && fastestLap.DriverGUID == result.DriverGUID {
+			session.FastestLap = true
+		}
+
+		for _, lap := range results.Laps {
+			if lap.DriverGUID == result.DriverGUID {
+				session.NumLaps++
+			}
+		}
+
+		for _, event := range results.Events {
+			if event.Driver != nil && event.Driver.GUID == result.DriverGUID {
+				session.Incidents++
+			}
+		}
+
+		session.Distance = float64(session.NumLaps) * trackLength
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions
+}
+
+var trackLengthRegex = regexp.MustCompile(`([0-9]+(?:[.,][0-9]+)*)\s*(km|mi|m)?`)
+
+// parseTrackLength parses the length of a track from its ui_track.json, e.g. "3602m", "5.8 km" or "2.2 miles",
+// and returns it in kilometres.
+func parseTrackLength(length string) (float64, error) {
+	match := trackLengthRegex.FindStringSubmatch(strings.ToLower(length))
+
+	if match == nil {
+		return 0, strconv.ErrSyntax
+	}
+
+	number := match[1]
+
+	if strings.Count(number, ",") == 1 && !strings.Contains(number, ".") && len(number)-strings.Index(number, ",")-1 != 3 {
+		// a decimal comma, e.g. 5,8 km
+		number = strings.Replace(number, ",", ".", 1)
+	} else {
+		number = strings.Replace(number, ",", "", -1)
+	}
+
+	value, err := strconv.ParseFloat(number, 64)
+
+	if err != nil {
+		return 0, err
+	}
+
+	switch match[2] {
+	case "km":
+		return value, nil
+	case "mi":
+		return value * 1.609344, nil
+	case "m":
+		return value / 1000, nil
+	default:
+		// no unit, assume metres for large numbers and kilometres otherwise.
+		if value > 100 {
+			return value / 1000, nil
+		}
+
+		return value, nil
+	}
+}
+
+// DriverProfileManager builds driver profiles from the driver profile sessions in the results index.
+type DriverProfileManager struct {
+	store        Store
+	resultsIndex *ResultsIndex
+}
+
+func NewDriverProfileManager(store Store, resultsIndex *ResultsIndex) *DriverProfileManager {
+	return &DriverProfileManager{
+		store:        store,
+		resultsIndex: resultsIndex,
+	}
+}
+
+// LoadProfile returns the career statistics of the driver with the given GUID.
+func (dpm *DriverProfileManager) LoadProfile(guid string) (*DriverProfile, error) {
+	var sessions []*DriverProfileSession
+
+	entries, err := dpm.resultsIndex.Find(nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		for _, session := range entry.DriverSessions {
+			if session.DriverGUID == guid {
+				sessionCopy := *session
+				sessions = append(sessions, &sessionCopy)
+			}
+		}
+	}
+
+	if len(sessions) == 0 {
+		return nil, ErrDriverProfileNotFound
+	}
+
+	profile := newDriverProfile(guid, sessions)
+	profile.Championships = dpm.championshipHistory(guid, sessions)
+
+	return profile, nil
+}
+
+// championshipHistory finds the driver's standings in each championship that they have taken part in.
+func (dpm *DriverProfileManager) championshipHistory(guid string, sessions []*DriverProfileSession) []*DriverProfileChampionship {
+	var championships []*DriverProfileChampionship
+
+	championshipSessions := make(map[string]int)
+
+	for _, session := range sessions {
+		if session.ChampionshipID != "" {
+			championshipSessions[session.ChampionshipID]++
+		}
+	}
+
+	for championshipID, numSessions := range championshipSessions {
+		championship, err := dpm.store.LoadChampionship(championshipID)
+
+		if err != nil {
+			logrus.WithError(err).Debugf("Could not load championship: %s", championshipID)
+			continue
+		}
+
+		if !championship.Deleted.IsZero() {
+			continue
+		}
+
+		driverChampionship := &DriverProfileChampionship{
+			ID:       championshipID,
+			Name:     championship.Name,
+			Progress: championship.Progress(),
+			Sessions: numSessions,
+		}
+
+	classes:
+		for _, class := range championship.Classes {
+			standings := class.Standings(championship, championship.Events)
+
+			for i, standing := range standings {
+				if standing.Car == nil || standing.Car.Driver.GUID != guid {
+					continue
+				}
+
+				driverChampionship.Class = class.Name
+				driverChampionship.Position = i + 1
+				driverChampionship.Points = standing.Points
+				driverChampionship.NumEntrants = len(standings)
+
+				break classes
+			}
+		}
+
+		championships = append(championships, driverChampionship)
+	}
+
+	sort.Slice(championships, func(i, j int) bool {
+		return championships[i].Name < championships[j].Name
+	})
+
+	return championships
+}
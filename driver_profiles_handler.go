@@ -0,0 +1,93 @@
+package servermanager
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+)
+
+type DriverProfilesHandler struct {
+	*BaseHandler
+
+	store                Store
+	driverProfileManager *DriverProfileManager
+}
+
+func NewDriverProfilesHandler(baseHandler *BaseHandler, store Store, driverProfileManager *DriverProfileManager) *DriverProfilesHandler {
+	return &DriverProfilesHandler{
+		BaseHandler:          baseHandler,
+		store:                store,
+		driverProfileManager: driverProfileManager,
+	}
+}
+
+type driverProfileTemplateVars struct {
+	BaseTemplateVars
+
+	Profile *DriverProfile
+	UseMPH  bool
+}
+
+func (dph *DriverProfilesHandler) loadProfile(w http.ResponseWriter, r *http.Request) (*DriverProfile, bool) {
+	profile, err := dph.driverProfileManager.LoadProfile(chi.URLParam(r, "guid"))
+
+	if err == ErrDriverProfileNotFound {
+		http.NotFound(w, r)
+		return nil, false
+	} else if err != nil {
+		logrus.WithError(err).Error("couldn't load driver profile")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return nil, false
+	}
+
+	return profile, true
+}
+
+// view shows a driver's career statistics.
+func (dph *DriverProfilesHandler) view(w http.ResponseWriter, r *http.Request) {
+	profile, ok := dph.loadProfile(w, r)
+
+	if !ok {
+		return
+	}
+
+	serverOpts, err := dph.store.LoadServerOptions()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't load server options")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	dph.viewRenderer.MustLoadTemplate(w, r, "drivers/profile.html", &driverProfileTemplateVars{
+		Profile: profile,
+		UseMPH:  serverOpts.UseMPH == 1,
+	})
+}
+
+// json serves a driver's career statistics as JSON.
+func (dph *DriverProfilesHandler) json(w http.ResponseWriter, r *http.Request) {
+	profile, ok := dph.loadProfile(w, r)
+
+	if !ok {
+		return
+	}
+
+	if UseShortenedDriverNames {
+		profile.Name = driverName(profile.Name)
+
+		for _, session := range profile.RecentSessions {
+			session.DriverName = driverName(session.DriverName)
+		}
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	enc := json.NewEncoder(w)
+	if Debug {
+		enc.SetIndent("", "    ")
+	}
+	_ = enc.Encode(profile)
+}
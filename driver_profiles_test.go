@@ -0,0 +1,145 @@
+package servermanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTrackLength(t *testing.T) {
+	lengths := map[string]float64{
+		"3602m":       3.602,
+		"3602 m":      3.602,
+		"3,602 m":     3.602,
+		"5.8 km":      5.8,
+		"5,8km":       5.8,
+		"2 miles":     3.218688,
+		"4200":        4.2,
+		"4.2":         4.2,
+		"Length: 7km": 7,
+	}
+
+	for length, expected := range lengths {
+		km, err := parseTrackLength(length)
+
+		if err != nil {
+			t.Errorf("Could not parse track length %q: %s", length, err)
+			continue
+		}
+
+		if km < expected-0.0001 || km > expected+0.0001 {
+			t.Errorf("Expected %q to be %.3f km, got %.3f km", length, expected, km)
+		}
+	}
+
+	if _, err := parseTrackLength("unknown"); err == nil {
+		t.Error("Expected an error for a track length without a number")
+	}
+}
+
+// useResultsFixtures copies the results fixtures into a temporary ServerInstallPath, returning the path and a
+// function which removes it and restores the ServerInstallPath.
+func useResultsFixtures(t *testing.T) (string, func()) {
+	serverInstallPath := ServerInstallPath
+
+	dir, err := ioutil.TempDir("", "asm-results")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cleanup := func() {
+		ServerInstallPath = serverInstallPath
+		_ = os.RemoveAll(dir)
+	}
+
+	ServerInstallPath = dir
+
+	if err := os.Mkdir(filepath.Join(dir, "results"), 0755); err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	fixtures, err := filepath.Glob(filepath.Join("cmd", "server-manager", "assetto", "results", "*.json"))
+
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	for _, fixture := range fixtures {
+		data, err := ioutil.ReadFile(fixture)
+
+		if err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+
+		if err := ioutil.WriteFile(filepath.Join(dir, "results", filepath.Base(fixture)), data, 0644); err != nil {
+			cleanup()
+			t.Fatal(err)
+		}
+	}
+
+	return dir, cleanup
+}
+
+func TestDriverProfileManager_LoadProfile(t *testing.T) {
+	dir, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	resultsIndex := NewResultsIndex(NewJSONStore(filepath.Join(dir, "store"), filepath.Join(dir, "store-shared")))
+	defer resultsIndex.Close()
+
+	driverProfileManager := NewDriverProfileManager(testStore, resultsIndex)
+
+	t.Run("Career statistics", func(t *testing.T) {
+		profile, err := driverProfileManager.LoadProfile("76561198029578060")
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if profile.Name != "Joseph Elton" || profile.NumSessions != 14 || profile.NumLaps != 86 {
+			t.Errorf("Incorrect profile: %s, %d sessions, %d laps", profile.Name, profile.NumSessions, profile.NumLaps)
+		}
+
+		if profile.RacesEntered != 6 || profile.Wins != 5 || profile.Podiums != 6 || profile.Poles != 6 {
+			t.Errorf("Incorrect race statistics: %d races, %d wins, %d podiums, %d poles", profile.RacesEntered, profile.Wins, profile.Podiums, profile.Poles)
+		}
+
+		if profile.AverageFinishingPosition < 1.16 || profile.AverageFinishingPosition > 1.17 {
+			t.Errorf("Expected an average finishing position of 1.17, got %.2f", profile.AverageFinishingPosition)
+		}
+
+		if len(profile.FavouriteCars) == 0 || len(profile.FavouriteTracks) == 0 || len(profile.RecentSessions) != numDriverProfileRecentSessions {
+			t.Errorf("Expected favourite cars, tracks and recent sessions, got: %+v", profile)
+		}
+	})
+
+	t.Run("Deleted results files are forgotten", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(dir, "results", "2019_3_2_22_28_RACE.json")); err != nil {
+			t.Error(err)
+			return
+		}
+
+		profile, err := driverProfileManager.LoadProfile("76561198029578060")
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if profile.RacesEntered != 5 || profile.Wins != 5 || profile.Podiums != 5 {
+			t.Errorf("Incorrect race statistics: %d races, %d wins, %d podiums", profile.RacesEntered, profile.Wins, profile.Podiums)
+		}
+	})
+
+	t.Run("Unknown drivers", func(t *testing.T) {
+		if _, err := driverProfileManager.LoadProfile("not-a-guid"); err != ErrDriverProfileNotFound {
+			t.Errorf("Expected driver profile not found, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+PRAGMA foreign_keys=OFF;
+BEGIN TRANSACTION;
+CREATE TABLE Players(PlayerId INTEGER PRIMARY KEY, SteamGuid TEXT UNIQUE, Name TEXT, ArtInt INTEGER);
+INSERT INTO Players VALUES(1,'76561198000000001','Alice',0);
+INSERT INTO Players VALUES(2,'76561198000000002','Bob',0);
+INSERT INTO Players VALUES(3,'76561198000000003','Charlie',0);
+INSERT INTO Players VALUES(4,NULL,'AI Driver',1);
+CREATE TABLE Tracks(TrackId INTEGER PRIMARY KEY, Track TEXT UNIQUE, UiTrackName TEXT, Length FLOAT, MapData BLOB);
+INSERT INTO Tracks VALUES(1,'ks_barcelona-layout_gp','Barcelona GP',4655.0,NULL);
+CREATE TABLE Cars(CarId INTEGER PRIMARY KEY, Car TEXT UNIQUE, UiCarName TEXT, Brand TEXT);
+INSERT INTO Cars VALUES(1,'ks_mazda_mx5_cup','Mazda MX5 Cup','Mazda');
+CREATE TABLE TyreCompounds(TyreCompoundId INTEGER PRIMARY KEY, TyreCompound TEXT UNIQUE);
+INSERT INTO TyreCompounds VALUES(1,'SM');
+CREATE TABLE Session(SessionId INTEGER PRIMARY KEY, TrackId INTEGER, SessionType TEXT, Multiplayer INTEGER, NumberOfLaps INTEGER, Duration INTEGER, ServerIpPort INTEGER, StartTimeDate INTEGER, EndTimeDate INTEGER, ServerName TEXT);
+INSERT INTO Session VALUES(1,1,'Qualify',1,0,900,9600,1577880000,1577880900,'Test Server');
+INSERT INTO Session VALUES(2,1,'Race',1,20,0,9600,1577882000,1577884000,'Test Server');
+CREATE TABLE PlayerInSession(PlayerInSessionId INTEGER PRIMARY KEY, SessionId INTEGER, PlayerId INTEGER, CarId INTEGER, RaceFinished INTEGER, FinishPosition INTEGER);
+INSERT INTO PlayerInSession VALUES(1,1,1,1,0,0);
+INSERT INTO PlayerInSession VALUES(2,1,2,1,0,0);
+INSERT INTO PlayerInSession VALUES(3,1,3,1,0,0);
+INSERT INTO PlayerInSession VALUES(4,2,1,1,1,2);
+INSERT INTO PlayerInSession VALUES(5,2,2,1,1,1);
+INSERT INTO PlayerInSession VALUES(6,2,3,1,0,0);
+CREATE TABLE Lap(LapId INTEGER PRIMARY KEY, PlayerInSessionId INTEGER, TyreCompoundId INTEGER, LapTime INTEGER, SectorTime0 INTEGER, SectorTime1 INTEGER, SectorTime2 INTEGER, SectorTime3 INTEGER, Valid INTEGER, Timestamp INTEGER, GripLevel FLOAT, Cuts INTEGER);
+INSERT INTO Lap VALUES(1,1,1,101748,33734,33957,34057,NULL,1,1577880001,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(2,1,1,101220,33698,33796,33726,NULL,1,1577880101,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(3,1,1,101870,33919,34055,33896,NULL,1,1577880201,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(4,1,1,101766,33907,33999,33860,NULL,1,1577880301,0.979999999999999983,2);
+INSERT INTO Lap VALUES(5,1,1,101402,33773,33714,33915,NULL,1,1577880401,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(6,2,1,100934,33514,33699,33721,NULL,1,1577880002,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(7,2,1,101593,33811,33890,33892,NULL,1,1577880102,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(8,2,1,101085,33501,33856,33728,NULL,0,1577880202,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(9,2,1,101122,33636,33869,33617,NULL,1,1577880302,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(10,2,1,101016,33802,33552,33662,NULL,1,1577880402,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(11,3,1,102039,34015,34011,34013,NULL,1,1577880003,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(12,3,1,102613,34332,34277,34004,NULL,1,1577880103,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(13,3,1,102656,34195,34351,34110,NULL,1,1577880203,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(14,3,1,102601,34216,34371,34014,NULL,1,1577880303,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(15,3,1,102774,34270,34113,34391,NULL,1,1577880403,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(16,4,1,101758,33890,33919,33949,NULL,1,1577880001,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(17,4,1,101411,33785,33842,33784,NULL,1,1577880101,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(18,4,1,101845,34012,33778,34055,NULL,1,1577880201,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(19,4,1,101392,33901,33814,33677,NULL,1,1577880301,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(20,4,1,101823,33879,33950,33994,NULL,1,1577880401,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(21,4,1,101466,33717,33761,33988,NULL,1,1577880501,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(22,4,1,101580,34036,33817,33727,NULL,1,1577880601,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(23,4,1,101917,34046,33836,34035,NULL,1,1577880701,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(24,4,1,101834,34030,33922,33882,NULL,1,1577880801,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(25,4,1,101697,33925,34009,33763,NULL,1,1577880901,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(26,4,1,101598,33821,33811,33966,NULL,1,1577881001,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(27,4,1,101712,33921,33924,33867,NULL,1,1577881101,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(28,4,1,101561,33967,33683,33911,NULL,1,1577881201,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(29,4,1,101708,33790,34046,33872,NULL,1,1577881301,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(30,4,1,101638,33878,34006,33754,NULL,1,1577881401,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(31,4,1,101824,33853,33946,34025,NULL,1,1577881501,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(32,4,1,102117,34063,34011,34043,NULL,1,1577881601,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(33,4,1,101457,33857,33710,33890,NULL,1,1577881701,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(34,4,1,101652,34005,33926,33721,NULL,1,1577881801,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(35,4,1,101745,34064,33749,33932,NULL,1,1577881901,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(36,5,1,101140,33701,33689,33750,NULL,1,1577880002,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(37,5,1,101130,33875,33515,33740,NULL,1,1577880102,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(38,5,1,101039,33522,33657,33860,NULL,0,1577880202,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(39,5,1,101413,33814,33803,33796,NULL,1,1577880302,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(40,5,1,101119,33701,33831,33587,NULL,1,1577880402,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(41,5,1,100959,33586,33757,33616,NULL,1,1577880502,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(42,5,1,101002,33506,33894,33602,NULL,1,1577880602,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(43,5,1,101174,33776,33780,33618,NULL,1,1577880702,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(44,5,1,101146,33707,33763,33676,NULL,1,1577880802,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(45,5,1,101210,33795,33680,33735,NULL,1,1577880902,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(46,5,1,101254,33637,33837,33780,NULL,1,1577881002,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(47,5,1,101186,33811,33873,33502,NULL,1,1577881102,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(48,5,1,101337,33696,33879,33762,NULL,1,1577881202,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(49,5,1,101229,33566,33765,33898,NULL,1,1577881302,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(50,5,1,101110,33787,33605,33718,NULL,1,1577881402,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(51,5,1,100960,33528,33746,33686,NULL,1,1577881502,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(52,5,1,101176,33791,33783,33602,NULL,1,1577881602,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(53,5,1,101217,33758,33711,33748,NULL,1,1577881702,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(54,5,1,101071,33682,33712,33677,NULL,1,1577881802,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(55,5,1,101051,33500,33775,33776,NULL,1,1577881902,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(56,6,1,102801,34319,34313,34169,NULL,1,1577880003,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(57,6,1,102555,34234,34307,34014,NULL,1,1577880103,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(58,6,1,102532,34117,34325,34090,NULL,1,1577880203,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(59,6,1,102672,34281,34299,34092,NULL,1,1577880303,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(60,6,1,102458,34046,34282,34130,NULL,1,1577880403,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(61,6,1,102396,34016,34344,34036,NULL,1,1577880503,0.979999999999999983,NULL);
+INSERT INTO Lap VALUES(62,6,1,102281,34042,34008,34231,NULL,1,1577880603,0.979999999999999983,NULL);
+COMMIT;
@@ -0,0 +1,495 @@
+package servermanager
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/etcd-io/bbolt"
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp/replay"
+)
+
+const (
+	// defaultLapAnomalySectorMargin and defaultLapAnomalyTrackRecordMargin are the default percentages by which a sector
+	// or lap must beat the field or track record to be flagged.
+	defaultLapAnomalySectorMargin      = 10
+	defaultLapAnomalyTrackRecordMargin = 2
+
+	// lapAnomalySectorSumTolerance allows for sector times being rounded separately to the lap time.
+	lapAnomalySectorSumTolerance = 50 * time.Millisecond
+
+	// a car which moves further than lapAnomalyTeleportMinimumDistance (in metres) between two car updates, at a speed
+	// faster than lapAnomalyTeleportMinimumSpeed (in metres per second), is considered to have teleported. Car updates
+	// further apart than lapAnomalyTeleportMaximumInterval are ignored, as the gap is more likely lost UDP messages.
+	lapAnomalyTeleportMinimumDistance = 50
+	lapAnomalyTeleportMinimumSpeed    = 150
+	lapAnomalyTeleportMaximumInterval = 5 * time.Second
+
+	// cars which are going slower than lapAnomalyTeleportStoppedSpeed (in metres per second) after a teleport have most
+	// likely been returned to the pits, which is allowed.
+	lapAnomalyTeleportStoppedSpeed = 1
+)
+
+type LapAnomalyType string
+
+const (
+	LapAnomalySectorTime  LapAnomalyType = "Impossible Sector Time"
+	LapAnomalyTrackRecord LapAnomalyType = "Faster Than Track Record"
+	LapAnomalySectorSum   LapAnomalyType = "Sectors Don't Match Lap Time"
+	LapAnomalyTeleport    LapAnomalyType = "Teleport"
+)
+
+// LapAnomaly is a suspicious lap in a session. Anomalies are not proof of cheating, they are a starting point for
+// stewards to review.
+type LapAnomaly struct {
+	Type       LapAnomalyType
+	DriverName string
+	DriverGUID string
+	CarModel   string
+
+	// Lap is the driver's lap number in the session, starting at 1.
+	Lap     int
+	LapTime time.Duration
+
+	Description string
+}
+
+// LapAnomalyOptions configure which anomalies are detected. Margins are percentages, a margin of 0 disables the check.
+type LapAnomalyOptions struct {
+	SectorMargin      float64
+	TrackRecordMargin float64
+
+	// TrackRecord returns the fastest clean lap in a car at the track, not including the session being checked. It
+	// returns 0 if there is no record.
+	TrackRecord func(carModel string) time.Duration
+}
+
+// DetectLapAnomalies checks every lap in the results for sector times which are impossibly fast compared to the rest of
+// the field, laps which beat the track record by too much and laps whose sectors don't add up to the lap time.
+func DetectLapAnomalies(results *SessionResults, opts LapAnomalyOptions) []*LapAnomaly {
+	var anomalies []*LapAnomaly
+
+	lapNumbers := make(map[string]int)
+	fastestSectors := fastestSectorsByDriver(results)
+	trackRecords := make(map[string]time.Duration)
+
+	for _, lap := range results.Laps {
+		key := lap.DriverGUID + lap.CarModel
+		lapNumbers[key]++
+
+		lapTime := lap.GetLapTime()
+
+		anomaly := func(anomalyType LapAnomalyType, format string, args ...interface{}) {
+			anomalies = append(anomalies, &LapAnomaly{
+				Type:        anomalyType,
+				DriverName:  lap.DriverName,
+				DriverGUID:  lap.DriverGUID,
+				CarModel:    lap.CarModel,
+				Lap:         lapNumbers[key],
+				LapTime:     lapTime,
+				Description: fmt.Sprintf(format, args...),
+			})
+		}
+
+		if lap.LapTime <= 0 {
+			continue
+		}
+
+		if len(lap.Sectors) > 0 {
+			var sectorSum time.Duration
+
+			for i := range lap.Sectors {
+				sectorSum += lap.GetSector(i)
+			}
+
+			if difference := sectorSum - lapTime; difference > lapAnomalySectorSumTolerance || difference < -lapAnomalySectorSumTolerance {
+				anomaly(LapAnomalySectorSum, "Sectors add up to %s, but the lap time is %s", formatDuration(sectorSum, true), formatDuration(lapTime, true))
+			}
+		}
+
+		if lap.Cuts > 0 {
+			// cut laps don't count, so there is nothing to gain from them.
+			continue
+		}
+
+		if opts.SectorMargin > 0 {
+			for i := range lap.Sectors {
+				sector := lap.GetSector(i)
+				fieldBest := fastestSectorOfOtherDrivers(fastestSectors, lap, i)
+
+				if sector <= 0 || fieldBest <= 0 || !beatsByMargin(sector, fieldBest, opts.SectorMargin) {
+					continue
+				}
+
+				anomaly(LapAnomalySectorTime, "Sector %d of %s is %.1f%% faster than anyone else in the same car (%s)", i+1, formatDuration(sector, true), percentFaster(sector, fieldBest), formatDuration(fieldBest, true))
+			}
+		}
+
+		if opts.TrackRecordMargin > 0 && opts.TrackRecord != nil {
+			record, ok := trackRecords[lap.CarModel]
+
+			if !ok {
+				record = opts.TrackRecord(lap.CarModel)
+				trackRecords[lap.CarModel] = record
+			}
+
+			if record > 0 && beatsByMargin(lapTime, record, opts.TrackRecordMargin) {
+				anomaly(LapAnomalyTrackRecord, "%.1f%% faster than the track record in this car (%s)", percentFaster(lapTime, record), formatDuration(record, true))
+			}
+		}
+	}
+
+	return anomalies
+}
+
+// fastestSectorsByDriver returns the fastest of each clean sector of every driver, keyed by car model then driver GUID.
+func fastestSectorsByDriver(results *SessionResults) map[string]map[string][]time.Duration {
+	fastest := make(map[string]map[string][]time.Duration)
+
+	for _, lap := range results.Laps {
+		if lap.Cuts > 0 || lap.LapTime <= 0 {
+			continue
+		}
+
+		if _, ok := fastest[lap.CarModel]; !ok {
+			fastest[lap.CarModel] = make(map[string][]time.Duration)
+		}
+
+		sectors := fastest[lap.CarModel][lap.DriverGUID]
+
+		for i := range lap.Sectors {
+			sector := lap.GetSector(i)
+
+			if sector <= 0 {
+				continue
+			}
+
+			for len(sectors) <= i {
+				sectors = append(sectors, 0)
+			}
+
+			if sectors[i] == 0 || sector < sectors[i] {
+				sectors[i] = sector
+			}
+		}
+
+		fastest[lap.CarModel][lap.DriverGUID] = sectors
+	}
+
+	return fastest
+}
+
+// fastestSectorOfOtherDrivers is the fastest time in a sector by any driver in the same car as the lap, other than the
+// driver of the lap. It returns 0 if fewer than two other drivers set a time in the sector, as there is no field to
+// compare against.
+func fastestSectorOfOtherDrivers(fastestSectors map[string]map[string][]time.Duration, lap *SessionLap, sector int) time.Duration {
+	var fastest time.Duration
+
+	numDrivers := 0
+
+	for driverGUID, sectors := range fastestSectors[lap.CarModel] {
+		if driverGUID == lap.DriverGUID || len(sectors) <= sector || sectors[sector] <= 0 {
+			continue
+		}
+
+		numDrivers++
+
+		if fastest == 0 || sectors[sector] < fastest {
+			fastest = sectors[sector]
+		}
+	}
+
+	if numDrivers < 2 {
+		return 0
+	}
+
+	return fastest
+}
+
+func beatsByMargin(t, comparison time.Duration, margin float64) bool {
+	return float64(t) < float64(comparison)*(1-margin/100)
+}
+
+func percentFaster(t, comparison time.Duration) float64 {
+	return (1 - float64(t)/float64(comparison)) * 100
+}
+
+// DetectTeleports looks through the car updates of a session replay for cars which moved further between two updates
+// than they could possibly have driven. Cars which are stationary after the jump are ignored, as they have been
+// returned to the pits.
+func DetectTeleports(results *SessionResults, entries replay.Entries) []*LapAnomaly {
+	type carState struct {
+		driver   udp.SessionCarInfo
+		lap      int
+		lastPos  udp.Vec
+		lastSeen time.Time
+	}
+
+	var anomalies []*LapAnomaly
+
+	cars := make(map[udp.CarID]*carState)
+
+	for _, entry := range entries {
+		switch message := entry.Data.(type) {
+		case udp.SessionCarInfo:
+			if message.Event() == udp.EventNewConnection {
+				cars[message.CarID] = &carState{driver: message, lap: 1}
+			} else {
+				delete(cars, message.CarID)
+			}
+		case udp.LapCompleted:
+			if car, ok := cars[message.CarID]; ok {
+				car.lap++
+			}
+		case udp.CarUpdate:
+			car, ok := cars[message.CarID]
+
+			if !ok {
+				continue
+			}
+
+			if !car.lastSeen.IsZero() {
+				interval := entry.Received.Sub(car.lastSeen)
+				distance := vecDistance(car.lastPos, message.Pos)
+				speed := math.Sqrt(math.Pow(float64(message.Velocity.X), 2) + math.Pow(float64(message.Velocity.Z), 2))
+
+				if interval > 0 && interval <= lapAnomalyTeleportMaximumInterval && distance > lapAnomalyTeleportMinimumDistance &&
+					distance/interval.Seconds() > lapAnomalyTeleportMinimumSpeed && speed > lapAnomalyTeleportStoppedSpeed {
+					anomalies = append(anomalies, &LapAnomaly{
+						Type:        LapAnomalyTeleport,
+						DriverName:  car.driver.DriverName,
+						DriverGUID:  string(car.driver.DriverGUID),
+						CarModel:    car.driver.CarModel,
+						Lap:         car.lap,
+						LapTime:     lapTimeForDriver(results, string(car.driver.DriverGUID), car.driver.CarModel, car.lap),
+						Description: fmt.Sprintf("Moved %.0fm in %s (%.0f km/h)", distance, interval.Round(time.Millisecond), metersPerSecondToKilometersPerHour(distance/interval.Seconds())),
+					})
+				}
+			}
+
+			car.lastPos = message.Pos
+			car.lastSeen = entry.Received
+		}
+	}
+
+	return anomalies
+}
+
+func vecDistance(a, b udp.Vec) float64 {
+	return math.Sqrt(math.Pow(float64(a.X-b.X), 2) + math.Pow(float64(a.Y-b.Y), 2) + math.Pow(float64(a.Z-b.Z), 2))
+}
+
+// lapTimeForDriver is the time of a driver's nth lap in the results, or 0 if the lap was not completed.
+func lapTimeForDriver(results *SessionResults, driverGUID, carModel string, lap int) time.Duration {
+	n := 0
+
+	for _, sessionLap := range results.Laps {
+		if sessionLap.DriverGUID != driverGUID || sessionLap.CarModel != carModel {
+			continue
+		}
+
+		n++
+
+		if n == lap {
+			return sessionLap.GetLapTime()
+		}
+	}
+
+	return 0
+}
+
+// sortLapAnomalies orders anomalies by driver then lap.
+func sortLapAnomalies(anomalies []*LapAnomaly) {
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		if anomalies[i].DriverName != anomalies[j].DriverName {
+			return anomalies[i].DriverName < anomalies[j].DriverName
+		}
+
+		return anomalies[i].Lap < anomalies[j].Lap
+	})
+}
+
+// LapAnomalyReport is the result of checking a session for suspicious laps.
+type LapAnomalyReport struct {
+	SessionFile string
+	Results     *SessionResults
+	Anomalies   []*LapAnomaly
+
+	// HasReplay is true if the session replay was checked for teleports.
+	HasReplay bool
+}
+
+// LapAnomalyManager checks the laps of finished sessions for anything suspicious, optionally notifying Discord.
+type LapAnomalyManager struct {
+	store               Store
+	leaderboardManager  *LeaderboardManager
+	notificationManager NotificationDispatcher
+}
+
+func NewLapAnomalyManager(store Store, leaderboardManager *LeaderboardManager, notificationManager NotificationDispatcher) *LapAnomalyManager {
+	return &LapAnomalyManager{
+		store:               store,
+		leaderboardManager:  leaderboardManager,
+		notificationManager: notificationManager,
+	}
+}
+
+// Report checks the laps of a results file, and its session replay if there is one.
+func (lam *LapAnomalyManager) Report(sessionFile string) (*LapAnomalyReport, error) {
+	results, err := LoadResult(sessionFile + ".json")
+
+	if err != nil {
+		return nil, err
+	}
+
+	serverOpts, err := lam.store.LoadServerOptions()
+
+	if err != nil {
+		return nil, err
+	}
+
+	report := &LapAnomalyReport{
+		SessionFile: sessionFile,
+		Results:     results,
+	}
+
+	report.Anomalies = DetectLapAnomalies(results, LapAnomalyOptions{
+		SectorMargin:      serverOpts.LapAnomalySectorMargin,
+		TrackRecordMargin: serverOpts.LapAnomalyTrackRecordMargin,
+		TrackRecord: func(carModel string) time.Duration {
+			record, err := lam.leaderboardManager.TrackRecord(results.TrackName, results.TrackConfig, carModel, sessionFile+".json")
+
+			if err != nil {
+				logrus.WithError(err).Errorf("Could not load track record for lap anomaly report")
+				return 0
+			}
+
+			if record == nil {
+				return 0
+			}
+
+			return record.LapTime
+		},
+	})
+
+	if ReplayExists(sessionFile) {
+		entries, err := loadReplayEntries(sessionFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		report.HasReplay = true
+		report.Anomalies = append(report.Anomalies, DetectTeleports(results, entries)...)
+	}
+
+	sortLapAnomalies(report.Anomalies)
+
+	return report, nil
+}
+
+func (lam *LapAnomalyManager) UDPCallback(message udp.Message) {
+	if m, ok := message.(udp.EndSession); ok {
+		sessionFile := strings.TrimSuffix(filepath.Base(string(m)), ".json")
+
+		go panicCapture(func() {
+			lam.notify(sessionFile)
+		})
+	}
+}
+
+// lapAnomalyReplayWait is how long to wait for the replay recorder to save the session replay before checking it.
+const lapAnomalyReplayWait = 10 * time.Second
+
+// notify sends a Discord notification if the session has any lap anomalies.
+func (lam *LapAnomalyManager) notify(sessionFile string) {
+	serverOpts, err := lam.store.LoadServerOptions()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("Couldn't load server options")
+		return
+	}
+
+	if serverOpts.LapAnomalyDiscordNotification != 1 {
+		return
+	}
+
+	if serverOpts.RecordSessionReplays == 1 {
+		// the replay recorder saves the replay in its own goroutine once it has seen the end of the session.
+		for waited := time.Duration(0); !ReplayExists(sessionFile) && waited < lapAnomalyReplayWait; waited += time.Second {
+			time.Sleep(time.Second)
+		}
+	}
+
+	report, err := lam.Report(sessionFile)
+
+	if err != nil {
+		logrus.WithError(err).Errorf("Could not check session for lap anomalies: %s", sessionFile)
+		return
+	}
+
+	if len(report.Anomalies) == 0 {
+		return
+	}
+
+	if err := lam.notificationManager.SendLapAnomalyReportMessage(report); err != nil {
+		logrus.WithError(err).Errorf("Could not send lap anomaly notification")
+	}
+}
+
+func loadReplayEntries(sessionFile string) (replay.Entries, error) {
+	db, err := bbolt.Open(replayPath(sessionFile), 0644, &bbolt.Options{Timeout: time.Second, ReadOnly: true})
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer db.Close()
+
+	return replay.LoadEntries(db)
+}
+
+type lapAnomalyReportTemplateVars struct {
+	BaseTemplateVars
+
+	Report *LapAnomalyReport
+}
+
+type LapAnomaliesHandler struct {
+	*BaseHandler
+
+	lapAnomalyManager *LapAnomalyManager
+}
+
+func NewLapAnomaliesHandler(baseHandler *BaseHandler, lapAnomalyManager *LapAnomalyManager) *LapAnomaliesHandler {
+	return &LapAnomaliesHandler{
+		BaseHandler:       baseHandler,
+		lapAnomalyManager: lapAnomalyManager,
+	}
+}
+
+func (lah *LapAnomaliesHandler) report(w http.ResponseWriter, r *http.Request) {
+	report, err := lah.lapAnomalyManager.Report(chi.URLParam(r, "fileName"))
+
+	if os.IsNotExist(err) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Errorf("could not check session for lap anomalies")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	lah.viewRenderer.MustLoadTemplate(w, r, "results/anomalies.html", &lapAnomalyReportTemplateVars{
+		Report: report,
+	})
+}
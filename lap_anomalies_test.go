@@ -0,0 +1,122 @@
+package servermanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp/replay"
+)
+
+func lapAnomalyTestLap(driver string, sectors ...int) *SessionLap {
+	lap := &SessionLap{
+		DriverGUID: driver,
+		DriverName: driver,
+		CarModel:   "ks_mazda_mx5_cup",
+		Sectors:    sectors,
+	}
+
+	for _, sector := range sectors {
+		lap.LapTime += sector
+	}
+
+	return lap
+}
+
+func TestDetectLapAnomalies(t *testing.T) {
+	t.Run("Sector times and track records", func(t *testing.T) {
+		results := &SessionResults{
+			Laps: []*SessionLap{
+				lapAnomalyTestLap("A", 30000, 30000, 30000),
+				lapAnomalyTestLap("B", 30500, 30200, 30100),
+				lapAnomalyTestLap("C", 30200, 20000, 30300),
+				lapAnomalyTestLap("D", 30100, 30100, 30100),
+			},
+		}
+
+		anomalies := DetectLapAnomalies(results, LapAnomalyOptions{
+			SectorMargin:      10,
+			TrackRecordMargin: 2,
+			TrackRecord: func(carModel string) time.Duration {
+				return 90 * time.Second
+			},
+		})
+
+		if len(anomalies) != 2 {
+			t.Fatalf("Expected 2 anomalies, got %d", len(anomalies))
+		}
+
+		for _, anomaly := range anomalies {
+			if anomaly.DriverGUID != "C" || anomaly.Lap != 1 {
+				t.Errorf("Expected only driver C's first lap to be flagged, got: %+v", anomaly)
+			}
+		}
+
+		if anomalies[0].Type != LapAnomalySectorTime || anomalies[1].Type != LapAnomalyTrackRecord {
+			t.Errorf("Incorrect anomaly types: %s, %s", anomalies[0].Type, anomalies[1].Type)
+		}
+	})
+
+	t.Run("Sectors don't add up to the lap time", func(t *testing.T) {
+		lap := lapAnomalyTestLap("A", 30000, 30000, 30000)
+		lap.LapTime = 85000
+
+		anomalies := DetectLapAnomalies(&SessionResults{Laps: []*SessionLap{lap}}, LapAnomalyOptions{})
+
+		if len(anomalies) != 1 || anomalies[0].Type != LapAnomalySectorSum {
+			t.Errorf("Expected a sector sum anomaly, got: %+v", anomalies)
+		}
+	})
+
+	t.Run("Cut laps are not compared", func(t *testing.T) {
+		lap := lapAnomalyTestLap("A", 30000, 10000, 30000)
+		lap.Cuts = 1
+
+		anomalies := DetectLapAnomalies(&SessionResults{Laps: []*SessionLap{
+			lap,
+			lapAnomalyTestLap("B", 30000, 30000, 30000),
+			lapAnomalyTestLap("C", 30000, 30000, 30000),
+		}}, LapAnomalyOptions{SectorMargin: 10})
+
+		if len(anomalies) != 0 {
+			t.Errorf("Expected no anomalies, got: %+v", anomalies)
+		}
+	})
+}
+
+func TestDetectTeleports(t *testing.T) {
+	start := time.Now()
+
+	entries := replay.Entries{
+		{Received: start, Data: udp.SessionCarInfo{CarID: 1, DriverName: "A", DriverGUID: "A", CarModel: "ks_mazda_mx5_cup", EventType: udp.EventNewConnection}},
+		{Received: start, Data: udp.SessionCarInfo{CarID: 2, DriverName: "B", DriverGUID: "B", CarModel: "ks_mazda_mx5_cup", EventType: udp.EventNewConnection}},
+
+		// both cars driving normally
+		{Received: start.Add(100 * time.Millisecond), Data: udp.CarUpdate{CarID: 1, Pos: udp.Vec{X: 0}, Velocity: udp.Vec{X: 40}}},
+		{Received: start.Add(100 * time.Millisecond), Data: udp.CarUpdate{CarID: 2, Pos: udp.Vec{X: 0}, Velocity: udp.Vec{X: 40}}},
+		{Received: start.Add(200 * time.Millisecond), Data: udp.CarUpdate{CarID: 1, Pos: udp.Vec{X: 4}, Velocity: udp.Vec{X: 40}}},
+		{Received: start.Add(200 * time.Millisecond), Data: udp.CarUpdate{CarID: 2, Pos: udp.Vec{X: 4}, Velocity: udp.Vec{X: 40}}},
+		{Received: start.Add(250 * time.Millisecond), Data: udp.LapCompleted{CarID: 1}},
+
+		// car 1 jumps 500m and keeps going, car 2 jumps back to the pits and is stationary
+		{Received: start.Add(300 * time.Millisecond), Data: udp.CarUpdate{CarID: 1, Pos: udp.Vec{X: 504}, Velocity: udp.Vec{X: 40}}},
+		{Received: start.Add(300 * time.Millisecond), Data: udp.CarUpdate{CarID: 2, Pos: udp.Vec{X: -500}, Velocity: udp.Vec{}}},
+	}
+
+	results := &SessionResults{
+		Laps: []*SessionLap{
+			lapAnomalyTestLap("A", 30000, 30000, 30000),
+			lapAnomalyTestLap("A", 29000, 30000, 30000),
+		},
+	}
+
+	anomalies := DetectTeleports(results, entries)
+
+	if len(anomalies) != 1 {
+		t.Fatalf("Expected 1 teleport, got %d", len(anomalies))
+	}
+
+	if anomalies[0].DriverGUID != "A" || anomalies[0].Lap != 2 || anomalies[0].LapTime != 89*time.Second || anomalies[0].Type != LapAnomalyTeleport {
+		t.Errorf("Incorrect teleport: %+v", anomalies[0])
+	}
+}
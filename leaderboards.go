@@ -0,0 +1,431 @@
+package servermanager
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"time"
+)
+
+// dateInputFormat is the format of dates from date inputs, e.g. the date range of leaderboard filters.
+const dateInputFormat = "2006-01-02"
+
+var ErrLeaderboardNoTrack = errors.New("servermanager: a track must be chosen for a leaderboard")
+
+// LeaderboardLap is a clean lap (i.e. a lap without cuts) from a results file. Gap is the time to the fastest lap on
+// a Leaderboard.
+type LeaderboardLap struct {
+	Track       string `json:"Track"`
+	TrackLayout string `json:"TrackLayout"`
+	CarModel    string `json:"CarModel"`
+	DriverGUID  string `json:"DriverGUID"`
+	DriverName  string `json:"DriverName"`
+
+	LapTime time.Duration   `json:"LapTime"`
+	Gap     time.Duration   `json:"Gap"`
+	Sectors []time.Duration `json:"Sectors"`
+	Tyre    string          `json:"Tyre"`
+
+	BallastKG  int `json:"BallastKG"`
+	Restrictor int `json:"Restrictor"`
+
+	SessionType SessionType `json:"SessionType"`
+	SessionFile string      `json:"SessionFile"`
+	Date        time.Time   `json:"Date"`
+}
+
+// leaderboardLaps returns the clean laps of a results file. Laps by drivers who were disqualified are not counted.
+func leaderboardLaps(results *SessionResults) []*LeaderboardLap {
+	disqualified := make(map[string]bool)
+
+	for _, result := range results.Result {
+		if result.Disqualified {
+			disqualified[result.DriverGUID+result.CarModel] = true
+		}
+	}
+
+	var laps []*LeaderboardLap
+
+	for _, lap := range results.Laps {
+		if lap.Cuts != 0 || lap.LapTime <= 0 || lap.DriverGUID == "" || disqualified[lap.DriverGUID+lap.CarModel] {
+			continue
+		}
+
+		leaderboardLap := &LeaderboardLap{
+			Track:       results.TrackName,
+			TrackLayout: results.TrackConfig,
+			CarModel:    lap.CarModel,
+			DriverGUID:  lap.DriverGUID,
+			DriverName:  lap.DriverName,
+			LapTime:     time.Duration(lap.LapTime) * time.Millisecond,
+			Tyre:        lap.Tyre,
+			BallastKG:   lap.BallastKG,
+			Restrictor:  lap.Restrictor,
+			SessionType: results.Type,
+			SessionFile: results.SessionFile,
+			Date:        results.Date,
+		}
+
+		for _, sector := range lap.Sectors {
+			leaderboardLap.Sectors = append(leaderboardLap.Sectors, time.Duration(sector)*time.Millisecond)
+		}
+
+		laps = append(laps, leaderboardLap)
+	}
+
+	return laps
+}
+
+// LeaderboardFilter chooses which laps are shown on a leaderboard. Empty fields match all laps.
+type LeaderboardFilter struct {
+	Track       string      `json:"Track"`
+	TrackLayout string      `json:"TrackLayout"`
+	Car         string      `json:"Car"`
+	SessionType SessionType `json:"SessionType"`
+	Tyre        string      `json:"Tyre"`
+
+	// NoBallast excludes laps set with ballast or a restrictor.
+	NoBallast bool `json:"NoBallast"`
+
+	From time.Time `json:"From"`
+	To   time.Time `json:"To"`
+}
+
+// NewLeaderboardFilter reads a LeaderboardFilter from the query parameters: track, layout, car, session, tyre,
+// no_ballast, from and to. Dates are in the format YYYY-MM-DD.
+func NewLeaderboardFilter(query url.Values) (LeaderboardFilter, error) {
+	filter := LeaderboardFilter{
+		Track:       query.Get("track"),
+		TrackLayout: query.Get("layout"),
+		Car:         query.Get("car"),
+		SessionType: SessionType(query.Get("session")),
+		Tyre:        query.Get("tyre"),
+		NoBallast:   formValueAsInt(query.Get("no_ballast")) == 1,
+	}
+
+	var err error
+
+	if from := query.Get("from"); from != "" {
+		filter.From, err = time.ParseInLocation(dateInputFormat, from, time.Local)
+
+		if err != nil {
+			return filter, err
+		}
+	}
+
+	if to := query.Get("to"); to != "" {
+		filter.To, err = time.ParseInLocation(dateInputFormat, to, time.Local)
+
+		if err != nil {
+			return filter, err
+		}
+	}
+
+	return filter, nil
+}
+
+// Matches reports whether a lap is on the track and layout of the filter, and meets its other conditions.
+func (f LeaderboardFilter) Matches(lap *LeaderboardLap) bool {
+	if lap.Track != f.Track || lap.TrackLayout != f.TrackLayout {
+		return false
+	}
+
+	if f.Car != "" && lap.CarModel != f.Car {
+		return false
+	}
+
+	if f.SessionType != "" && lap.SessionType != f.SessionType {
+		return false
+	}
+
+	if f.Tyre != "" && lap.Tyre != f.Tyre {
+		return false
+	}
+
+	if f.NoBallast && (lap.BallastKG != 0 || lap.Restrictor != 0) {
+		return false
+	}
+
+	if !f.From.IsZero() && lap.Date.Before(f.From) {
+		return false
+	}
+
+	// the To date is inclusive.
+	if !f.To.IsZero() && !lap.Date.Before(f.To.AddDate(0, 0, 1)) {
+		return false
+	}
+
+	return true
+}
+
+// Query returns the filter as query parameters, for use with NewLeaderboardFilter.
+func (f LeaderboardFilter) Query() url.Values {
+	query := make(url.Values)
+
+	for key, value := range map[string]string{
+		"track":   f.Track,
+		"layout":  f.TrackLayout,
+		"car":     f.Car,
+		"session": string(f.SessionType),
+		"tyre":    f.Tyre,
+		"from":    f.FromString(),
+		"to":      f.ToString(),
+	} {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+
+	if f.NoBallast {
+		query.Set("no_ballast", "1")
+	}
+
+	return query
+}
+
+// APIURL is the URL of the leaderboard in the Leaderboards API.
+func (f LeaderboardFilter) APIURL() string {
+	return "/api/leaderboards?" + f.Query().Encode()
+}
+
+func (f LeaderboardFilter) FromString() string {
+	if f.From.IsZero() {
+		return ""
+	}
+
+	return f.From.Format(dateInputFormat)
+}
+
+func (f LeaderboardFilter) ToString() string {
+	if f.To.IsZero() {
+		return ""
+	}
+
+	return f.To.Format(dateInputFormat)
+}
+
+// Leaderboard is the fastest clean lap of each driver in each car, fastest first.
+type Leaderboard struct {
+	Filter LeaderboardFilter `json:"Filter"`
+	Laps   []*LeaderboardLap `json:"Laps"`
+}
+
+func (l *Leaderboard) Anonymize() {
+	for _, lap := range l.Laps {
+		lap.DriverGUID = AnonymiseDriverGUID(lap.DriverGUID)
+		lap.DriverName = shortenDriverName(lap.DriverName)
+	}
+}
+
+func (l *Leaderboard) MaskDriverNames() {
+	for _, lap := range l.Laps {
+		lap.DriverName = driverName(lap.DriverName)
+	}
+}
+
+// LeaderboardTrack is a track layout which has clean laps, the cars and tyres they were set in, and its lap record.
+type LeaderboardTrack struct {
+	Track       string   `json:"Track"`
+	TrackLayout string   `json:"TrackLayout"`
+	Cars        []string `json:"Cars"`
+	Tyres       []string `json:"Tyres"`
+
+	Record *LeaderboardLap `json:"Record"`
+}
+
+// LeaderboardTracks are the lap records of every track layout.
+type LeaderboardTracks []*LeaderboardTrack
+
+func (t LeaderboardTracks) Anonymize() {
+	for _, track := range t {
+		track.Record.DriverGUID = AnonymiseDriverGUID(track.Record.DriverGUID)
+		track.Record.DriverName = shortenDriverName(track.Record.DriverName)
+	}
+}
+
+func (t LeaderboardTracks) MaskDriverNames() {
+	for _, track := range t {
+		track.Record.DriverName = driverName(track.Record.DriverName)
+	}
+}
+
+// Find returns the LeaderboardTrack for a track layout, or nil if it has no clean laps.
+func (t LeaderboardTracks) Find(track, layout string) *LeaderboardTrack {
+	for _, leaderboardTrack := range t {
+		if leaderboardTrack.Track == track && leaderboardTrack.TrackLayout == layout {
+			return leaderboardTrack
+		}
+	}
+
+	return nil
+}
+
+// LeaderboardManager builds leaderboards from the clean laps in the results index.
+type LeaderboardManager struct {
+	resultsIndex *ResultsIndex
+}
+
+func NewLeaderboardManager(resultsIndex *ResultsIndex) *LeaderboardManager {
+	return &LeaderboardManager{
+		resultsIndex: resultsIndex,
+	}
+}
+
+// laps calls fn with the clean laps of every results file.
+func (lm *LeaderboardManager) laps(fn func(fileName string, laps []*LeaderboardLap)) error {
+	entries, err := lm.resultsIndex.Find(nil)
+
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		fn(entry.FileName, entry.Laps)
+	}
+
+	return nil
+}
+
+// Tracks returns every track layout which has clean laps, ordered by track and layout.
+func (lm *LeaderboardManager) Tracks() (LeaderboardTracks, error) {
+	tracks := make(map[string]*LeaderboardTrack)
+	cars := make(map[string]map[string]bool)
+	tyres := make(map[string]map[string]bool)
+
+	err := lm.laps(func(fileName string, laps []*LeaderboardLap) {
+		for _, lap := range laps {
+			key := lap.Track + "/" + lap.TrackLayout
+			track, ok := tracks[key]
+
+			if !ok {
+				track = &LeaderboardTrack{Track: lap.Track, TrackLayout: lap.TrackLayout}
+				tracks[key] = track
+				cars[key] = make(map[string]bool)
+				tyres[key] = make(map[string]bool)
+			}
+
+			if track.Record == nil || lap.LapTime < track.Record.LapTime {
+				lapCopy := *lap
+				track.Record = &lapCopy
+			}
+
+			cars[key][lap.CarModel] = true
+
+			if lap.Tyre != "" {
+				tyres[key][lap.Tyre] = true
+			}
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	var out LeaderboardTracks
+
+	for key, track := range tracks {
+		for car := range cars[key] {
+			track.Cars = append(track.Cars, car)
+		}
+
+		for tyre := range tyres[key] {
+			track.Tyres = append(track.Tyres, tyre)
+		}
+
+		sort.Strings(track.Cars)
+		sort.Strings(track.Tyres)
+
+		out = append(out, track)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Track == out[j].Track {
+			return out[i].TrackLayout < out[j].TrackLayout
+		}
+
+		return out[i].Track < out[j].Track
+	})
+
+	return out, nil
+}
+
+// TrackRecord returns the fastest clean lap in a car at a track layout, ignoring the laps in excludeFile. It returns
+// nil if no clean laps have been set.
+func (lm *LeaderboardManager) TrackRecord(track, layout, carModel, excludeFile string) (*LeaderboardLap, error) {
+	var record *LeaderboardLap
+
+	err := lm.laps(func(fileName string, laps []*LeaderboardLap) {
+		if fileName == excludeFile {
+			return
+		}
+
+		for _, lap := range laps {
+			if lap.Track != track || lap.TrackLayout != layout || lap.CarModel != carModel {
+				continue
+			}
+
+			if record == nil || lap.LapTime < record.LapTime {
+				lapCopy := *lap
+				record = &lapCopy
+			}
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// Leaderboard returns the fastest lap of each driver in each car which matches the filter.
+func (lm *LeaderboardManager) Leaderboard(filter LeaderboardFilter) (*Leaderboard, error) {
+	if filter.Track == "" {
+		return nil, ErrLeaderboardNoTrack
+	}
+
+	fastestLaps := make(map[string]*LeaderboardLap)
+
+	err := lm.laps(func(fileName string, laps []*LeaderboardLap) {
+		for _, lap := range laps {
+			if !filter.Matches(lap) {
+				continue
+			}
+
+			key := lap.DriverGUID + "/" + lap.CarModel
+
+			if fastest, ok := fastestLaps[key]; !ok || lap.LapTime < fastest.LapTime || (lap.LapTime == fastest.LapTime && lap.Date.Before(fastest.Date)) {
+				lapCopy := *lap
+				fastestLaps[key] = &lapCopy
+			}
+		}
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	leaderboard := &Leaderboard{
+		Filter: filter,
+		Laps:   []*LeaderboardLap{},
+	}
+
+	for _, lap := range fastestLaps {
+		leaderboard.Laps = append(leaderboard.Laps, lap)
+	}
+
+	sort.Slice(leaderboard.Laps, func(i, j int) bool {
+		if leaderboard.Laps[i].LapTime == leaderboard.Laps[j].LapTime {
+			// the lap which was set first is ahead.
+			return leaderboard.Laps[i].Date.Before(leaderboard.Laps[j].Date)
+		}
+
+		return leaderboard.Laps[i].LapTime < leaderboard.Laps[j].LapTime
+	})
+
+	for _, lap := range leaderboard.Laps {
+		lap.Gap = lap.LapTime - leaderboard.Laps[0].LapTime
+	}
+
+	return leaderboard, nil
+}
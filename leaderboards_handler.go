@@ -0,0 +1,154 @@
+package servermanager
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/sirupsen/logrus"
+)
+
+type LeaderboardsHandler struct {
+	*BaseHandler
+
+	store              Store
+	leaderboardManager *LeaderboardManager
+}
+
+func NewLeaderboardsHandler(baseHandler *BaseHandler, store Store, leaderboardManager *LeaderboardManager) *LeaderboardsHandler {
+	return &LeaderboardsHandler{
+		BaseHandler:        baseHandler,
+		store:              store,
+		leaderboardManager: leaderboardManager,
+	}
+}
+
+type leaderboardsTemplateVars struct {
+	BaseTemplateVars
+
+	Tracks      LeaderboardTracks
+	Track       *LeaderboardTrack
+	Leaderboard *Leaderboard
+	Filter      LeaderboardFilter
+	Anonymised  bool
+}
+
+// view shows the lap records of every track layout, or the leaderboard of a track layout if one is chosen.
+func (lh *LeaderboardsHandler) view(w http.ResponseWriter, r *http.Request) {
+	serverOpts, err := lh.store.LoadServerOptions()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't load server options")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := NewLeaderboardFilter(r.URL.Query())
+
+	if err != nil {
+		http.Error(w, "invalid leaderboard filter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tracks, err := lh.leaderboardManager.Tracks()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't load leaderboard tracks")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	var leaderboard *Leaderboard
+
+	if filter.Track != "" {
+		leaderboard, err = lh.leaderboardManager.Leaderboard(filter)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("couldn't load leaderboard")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		if serverOpts.AnonymiseLeaderboards == 1 {
+			leaderboard.Anonymize()
+		}
+	}
+
+	if serverOpts.AnonymiseLeaderboards == 1 {
+		tracks.Anonymize()
+	}
+
+	lh.viewRenderer.MustLoadTemplate(w, r, "leaderboards/index.html", &leaderboardsTemplateVars{
+		Tracks:      tracks,
+		Track:       tracks.Find(filter.Track, filter.TrackLayout),
+		Leaderboard: leaderboard,
+		Filter:      filter,
+		Anonymised:  serverOpts.AnonymiseLeaderboards == 1,
+	})
+}
+
+// json serves the leaderboard of a track layout as JSON, or the lap records of every track layout if no track is
+// chosen.
+func (lh *LeaderboardsHandler) json(w http.ResponseWriter, r *http.Request) {
+	serverOpts, err := lh.store.LoadServerOptions()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't load server options")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	filter, err := NewLeaderboardFilter(r.URL.Query())
+
+	if err != nil {
+		http.Error(w, "invalid leaderboard filter: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var out interface{}
+
+	if filter.Track == "" {
+		tracks, err := lh.leaderboardManager.Tracks()
+
+		if err != nil {
+			logrus.WithError(err).Errorf("couldn't load leaderboard tracks")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		if serverOpts.AnonymiseLeaderboards == 1 {
+			tracks.Anonymize()
+		} else if UseShortenedDriverNames {
+			tracks.MaskDriverNames()
+		}
+
+		if tracks == nil {
+			tracks = LeaderboardTracks{}
+		}
+
+		out = tracks
+	} else {
+		leaderboard, err := lh.leaderboardManager.Leaderboard(filter)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("couldn't load leaderboard")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+
+		if serverOpts.AnonymiseLeaderboards == 1 {
+			leaderboard.Anonymize()
+		} else if UseShortenedDriverNames {
+			leaderboard.MaskDriverNames()
+		}
+
+		out = leaderboard
+	}
+
+	w.Header().Add("Content-Type", "application/json")
+
+	enc := json.NewEncoder(w)
+	if Debug {
+		enc.SetIndent("", "    ")
+	}
+	_ = enc.Encode(out)
+}
@@ -0,0 +1,168 @@
+package servermanager
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestNewLeaderboardFilter(t *testing.T) {
+	filter, err := NewLeaderboardFilter(url.Values{
+		"track":      {"suzuka"},
+		"layout":     {"suzukaeast"},
+		"car":        {"ks_alfa_33_stradale"},
+		"session":    {"RACE"},
+		"tyre":       {"V"},
+		"no_ballast": {"1"},
+		"from":       {"2019-03-01"},
+		"to":         {"2019-03-02"},
+	})
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	if filter.Track != "suzuka" || filter.TrackLayout != "suzukaeast" || filter.Car != "ks_alfa_33_stradale" || filter.SessionType != SessionTypeRace || filter.Tyre != "V" || !filter.NoBallast {
+		t.Errorf("Incorrect filter: %+v", filter)
+	}
+
+	if roundTrip, err := NewLeaderboardFilter(filter.Query()); err != nil || roundTrip != filter {
+		t.Errorf("Expected the filter's query to give the same filter, got: %+v (%v)", roundTrip, err)
+	}
+
+	lap := &LeaderboardLap{
+		Track:       "suzuka",
+		TrackLayout: "suzukaeast",
+		CarModel:    "ks_alfa_33_stradale",
+		Tyre:        "V",
+		SessionType: SessionTypeRace,
+		Date:        time.Date(2019, 3, 2, 22, 28, 0, 0, time.Local),
+	}
+
+	if !filter.Matches(lap) {
+		t.Error("Expected a lap on the last day of the filter to match")
+	}
+
+	lap.BallastKG = 10
+
+	if filter.Matches(lap) {
+		t.Error("Expected a lap with ballast not to match")
+	}
+
+	if _, err := NewLeaderboardFilter(url.Values{"from": {"2nd March"}}); err == nil {
+		t.Error("Expected an error for an invalid date")
+	}
+}
+
+func TestLeaderboardManager(t *testing.T) {
+	dir, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	resultsIndex := NewResultsIndex(NewJSONStore(filepath.Join(dir, "store"), filepath.Join(dir, "store-shared")))
+	defer resultsIndex.Close()
+
+	leaderboardManager := NewLeaderboardManager(resultsIndex)
+
+	t.Run("Track records", func(t *testing.T) {
+		tracks, err := leaderboardManager.Tracks()
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(tracks) != 6 {
+			t.Errorf("Expected 6 track layouts, got %d", len(tracks))
+			return
+		}
+
+		track := tracks.Find("ks_red_bull_ring", "layout_national")
+
+		if track == nil || track.Record.LapTime != 51060*time.Millisecond || len(track.Cars) != 5 {
+			t.Errorf("Incorrect track record: %+v", track)
+		}
+	})
+
+	t.Run("Fastest lap of each driver in each car", func(t *testing.T) {
+		leaderboard, err := leaderboardManager.Leaderboard(LeaderboardFilter{Track: "suzuka", TrackLayout: "suzukaeast"})
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(leaderboard.Laps) == 0 || leaderboard.Laps[0].LapTime != 58127*time.Millisecond || leaderboard.Laps[0].Gap != 0 {
+			t.Errorf("Incorrect leaderboard: %+v", leaderboard.Laps)
+			return
+		}
+
+		seen := make(map[string]bool)
+
+		for i, lap := range leaderboard.Laps {
+			if lap.Track != "suzuka" || lap.TrackLayout != "suzukaeast" {
+				t.Errorf("Lap on the wrong track: %+v", lap)
+			}
+
+			if i > 0 && lap.LapTime < leaderboard.Laps[i-1].LapTime {
+				t.Errorf("Laps are not ordered fastest first")
+			}
+
+			if seen[lap.DriverGUID+lap.CarModel] {
+				t.Errorf("More than one lap for %s in %s", lap.DriverName, lap.CarModel)
+			}
+
+			seen[lap.DriverGUID+lap.CarModel] = true
+		}
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		leaderboard, err := leaderboardManager.Leaderboard(LeaderboardFilter{Track: "suzuka", TrackLayout: "suzukaeast", SessionType: SessionTypeQualifying})
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(leaderboard.Laps) == 0 || leaderboard.Laps[0].LapTime != 58216*time.Millisecond {
+			t.Errorf("Incorrect qualifying leaderboard: %+v", leaderboard.Laps)
+		}
+
+		leaderboard, err = leaderboardManager.Leaderboard(LeaderboardFilter{Track: "suzuka", TrackLayout: "suzukaeast", To: time.Date(2019, 3, 1, 0, 0, 0, 0, time.Local)})
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(leaderboard.Laps) != 0 {
+			t.Errorf("Expected no laps before the date range, got %d", len(leaderboard.Laps))
+		}
+	})
+
+	t.Run("Deleted results files are forgotten", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(dir, "results", "2019_3_2_22_28_RACE.json")); err != nil {
+			t.Error(err)
+			return
+		}
+
+		leaderboard, err := leaderboardManager.Leaderboard(LeaderboardFilter{Track: "suzuka", TrackLayout: "suzukaeast"})
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(leaderboard.Laps) == 0 || leaderboard.Laps[0].LapTime != 58216*time.Millisecond {
+			t.Errorf("Incorrect leaderboard: %+v", leaderboard.Laps)
+		}
+	})
+
+	t.Run("A track is required", func(t *testing.T) {
+		if _, err := leaderboardManager.Leaderboard(LeaderboardFilter{}); err != ErrLeaderboardNoTrack {
+			t.Errorf("Expected a track to be required, got: %v", err)
+		}
+	})
+}
@@ -89,6 +89,8 @@ var (
 		addSplitTypeToRaceWeekends,
 		fixCarDuplicationInRaceSetups,
 		addRealPenaltyAppUDPPort,
+		addStewardingDefaults,
+		addLapAnomalyDetectionDefaults,
 	}
 )
 
@@ -1196,3 +1198,32 @@ func addRealPenaltyAppUDPPort(s Store) error {
 
 	return s.UpsertRealPenaltyOptions(rpOpts)
 }
+
+func addStewardingDefaults(s Store) error {
+	logrus.Infof("Running migration: Add Stewarding Defaults")
+
+	opts, err := s.LoadServerOptions()
+
+	if err != nil {
+		return err
+	}
+
+	opts.StewardingMinimumImpactSpeed = defaultStewardingMinimumImpactSpeed
+
+	return s.UpsertServerOptions(opts)
+}
+
+func addLapAnomalyDetectionDefaults(s Store) error {
+	logrus.Infof("Running migration: Add Lap Anomaly Detection Defaults")
+
+	opts, err := s.LoadServerOptions()
+
+	if err != nil {
+		return err
+	}
+
+	opts.LapAnomalySectorMargin = defaultLapAnomalySectorMargin
+	opts.LapAnomalyTrackRecordMargin = defaultLapAnomalyTrackRecordMargin
+
+	return s.UpsertServerOptions(opts)
+}
@@ -23,6 +23,8 @@ type NotificationDispatcher interface {
 	SendRaceReminderMessage(event *CustomRace, timer int) error
 	SendChampionshipReminderMessage(championship *Championship, event *ChampionshipEvent, timer int) error
 	SendRaceWeekendReminderMessage(raceWeekend *RaceWeekend, session *RaceWeekendSession, timer int) error
+	SendLapAnomalyReportMessage(report *LapAnomalyReport) error
+	SendChampionshipTitleDecidedMessage(championship *Championship, permutations *ChampionshipTitlePermutations, champion *ChampionshipTitleContender) error
 	SaveServerOptions(oldServerOpts *GlobalServerConfig, newServerOpts *GlobalServerConfig) error
 }
 
@@ -314,3 +316,89 @@ func (nm *NotificationManager) SendRaceWeekendReminderMessage(raceWeekend *RaceW
 	msg := fmt.Sprintf("%s at %s (%s Race Weekend) starts in %s", session.Name(), raceWeekend.Name, trackInfo, reminder)
 	return nm.SendMessage(title, msg)
 }
+
+// SendLapAnomalyReportMessage sends a summary of the suspicious laps found in a session
+func (nm *NotificationManager) SendLapAnomalyReportMessage(report *LapAnomalyReport) error {
+	trackInfo := nm.GetTrackInfo(report.Results.TrackName, report.Results.TrackConfig, false)
+	title := fmt.Sprintf("Lap anomalies - %s at %s", report.Results.Type.String(), trackInfo)
+
+	var lines []string
+
+	// discord limits the length of messages, the rest can be seen in the report.
+	const maxLines = 10
+
+	for i, anomaly := range report.Anomalies {
+		if i == maxLines {
+			lines = append(lines, fmt.Sprintf("...and %d more", len(report.Anomalies)-maxLines))
+			break
+		}
+
+		lines = append(lines, fmt.Sprintf("**%s** lap %d: %s - %s", driverName(anomaly.DriverName), anomaly.Lap, anomaly.Type, anomaly.Description))
+	}
+
+	msg := strings.Join(lines, "\n")
+
+	if config != nil && config.HTTP.BaseURL != "" {
+		link, err := url.Parse(config.HTTP.BaseURL + "/results/" + report.SessionFile + "/anomalies")
+
+		if err == nil {
+			return nm.SendMessageWithLink(title, msg, "View the report", link)
+		}
+	}
+
+	return nm.SendMessage(title, msg)
+}
+
+// SendChampionshipTitleDecidedMessage sends a notification when a driver or team clinches a Championship title
+func (nm *NotificationManager) SendChampionshipTitleDecidedMessage(championship *Championship, permutations *ChampionshipTitlePermutations, champion *ChampionshipTitleContender) error {
+	serverOpts, err := nm.store.LoadServerOptions()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't load server options, skipping notification")
+		return err
+	}
+
+	if serverOpts.NotifyWhenTitleDecided != 1 {
+		return nil
+	}
+
+	title := fmt.Sprintf("%s - Championship decided", championship.Name)
+	name := champion.Name
+
+	if champion.DriverGUID != "" {
+		name = driverName(name)
+	}
+
+	msg := fmt.Sprintf("**%s** has won the", name)
+
+	if championship.IsMultiClass() {
+		msg += " " + permutations.ClassName
+	}
+
+	switch {
+	case champion.DriverGUID != "":
+		msg += " Drivers' Championship"
+	case champion == permutations.ManufacturerChampion():
+		msg += " Manufacturers' Championship"
+	default:
+		msg += " Teams' Championship"
+	}
+
+	msg += fmt.Sprintf(" with %.2f points", champion.Points)
+
+	if permutations.RemainingRounds > 0 {
+		msg += fmt.Sprintf(", with %d round(s) remaining", permutations.RemainingRounds)
+	}
+
+	msg += "!"
+
+	if championshipURL := championship.GetURL(); championshipURL != "" {
+		link, err := url.Parse(championshipURL)
+
+		if err == nil {
+			return nm.SendMessageWithLink(title, msg, "View the standings", link)
+		}
+	}
+
+	return nm.SendMessage(title, msg)
+}
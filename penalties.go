@@ -1,6 +1,7 @@
 package servermanager
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
@@ -9,9 +10,214 @@ import (
 	"time"
 
 	"github.com/go-chi/chi"
+	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
 
+var (
+	ErrPenaltyNotFound       = errors.New("servermanager: penalty not found")
+	ErrPenaltyDriverNotFound = errors.New("servermanager: driver not found in results")
+	ErrInvalidPenalty        = errors.New("servermanager: invalid penalty")
+)
+
+type PenaltyType string
+
+const (
+	PenaltyTypeTime             PenaltyType = "Time Penalty"
+	PenaltyTypeLap              PenaltyType = "Lap Penalty"
+	PenaltyTypeGridDrop         PenaltyType = "Grid Drop"
+	PenaltyTypePoints           PenaltyType = "Points Deduction"
+	PenaltyTypeDisqualification PenaltyType = "Disqualification"
+	PenaltyTypeWarning          PenaltyType = "Warning"
+	PenaltyTypeReprimand        PenaltyType = "Reprimand"
+)
+
+var PenaltyTypes = []PenaltyType{
+	PenaltyTypeTime,
+	PenaltyTypeLap,
+	PenaltyTypeGridDrop,
+	PenaltyTypePoints,
+	PenaltyTypeDisqualification,
+	PenaltyTypeWarning,
+	PenaltyTypeReprimand,
+}
+
+func (t PenaltyType) valid() bool {
+	for _, penaltyType := range PenaltyTypes {
+		if t == penaltyType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Penalty is given to a driver in a session. Penalties are kept in the session results, including those which have
+// been revoked, so that the penalties given in a session can be reviewed.
+type Penalty struct {
+	ID      uuid.UUID   `json:"ID"`
+	Created time.Time   `json:"Created"`
+	Type    PenaltyType `json:"Type"`
+
+	// Time is the time added to the driver's result for a time penalty.
+	Time time.Duration `json:"Time,omitempty"`
+	// Laps is the number of laps removed from the driver's result for a lap penalty.
+	Laps int `json:"Laps,omitempty"`
+	// GridPlaces is the number of places the driver is moved back on the grid of the next event for a grid drop.
+	GridPlaces int `json:"GridPlaces,omitempty"`
+	// Points is the number of points taken from the driver in a championship for a points deduction.
+	Points float64 `json:"Points,omitempty"`
+
+	Reason  string `json:"Reason"`
+	Steward string `json:"Steward"`
+
+	// IncidentID is the stewards' incident which the penalty was given for, if any.
+	IncidentID string `json:"IncidentID,omitempty"`
+
+	Revoked *PenaltyRevocation `json:"Revoked,omitempty"`
+}
+
+// PenaltyRevocation records who revoked a penalty, and why.
+type PenaltyRevocation struct {
+	Time    time.Time `json:"Time"`
+	Steward string    `json:"Steward"`
+	Reason  string    `json:"Reason"`
+}
+
+func (p *Penalty) IsRevoked() bool {
+	return p.Revoked != nil
+}
+
+func (p *Penalty) validate() error {
+	if !p.Type.valid() {
+		return ErrInvalidPenalty
+	}
+
+	switch {
+	case p.Type == PenaltyTypeTime && p.Time <= 0,
+		p.Type == PenaltyTypeLap && p.Laps <= 0,
+		p.Type == PenaltyTypeGridDrop && p.GridPlaces <= 0,
+		p.Type == PenaltyTypePoints && p.Points <= 0:
+		return ErrInvalidPenalty
+	}
+
+	return nil
+}
+
+// String describes the penalty, e.g. "5s Time Penalty".
+func (p *Penalty) String() string {
+	switch p.Type {
+	case PenaltyTypeTime:
+		return fmt.Sprintf("%s %s", p.Time.String(), p.Type)
+	case PenaltyTypeLap:
+		return fmt.Sprintf("%d %s", p.Laps, p.Type)
+	case PenaltyTypeGridDrop:
+		return fmt.Sprintf("%d Place %s", p.GridPlaces, p.Type)
+	case PenaltyTypePoints:
+		return fmt.Sprintf("%s %s", strconv.FormatFloat(p.Points, 'f', -1, 64), p.Type)
+	default:
+		return string(p.Type)
+	}
+}
+
+// ActivePenalties are the penalties given to the driver which have not been revoked.
+func (s *SessionResult) ActivePenalties() []*Penalty {
+	var penalties []*Penalty
+
+	for _, penalty := range s.Penalties {
+		if !penalty.IsRevoked() {
+			penalties = append(penalties, penalty)
+		}
+	}
+
+	return penalties
+}
+
+// PointsDeduction is the total of the driver's points deductions.
+func (s *SessionResult) PointsDeduction() float64 {
+	var points float64
+
+	for _, penalty := range s.ActivePenalties() {
+		if penalty.Type == PenaltyTypePoints {
+			points += penalty.Points
+		}
+	}
+
+	return points
+}
+
+// GridPenalty is the total number of grid places the driver should be moved back in the next event.
+func (s *SessionResult) GridPenalty() int {
+	var places int
+
+	for _, penalty := range s.ActivePenalties() {
+		if penalty.Type == PenaltyTypeGridDrop {
+			places += penalty.GridPlaces
+		}
+	}
+
+	return places
+}
+
+// HasPenalties reports whether any driver in the session has been given a penalty, including penalties which have
+// since been revoked.
+func (s *SessionResults) HasPenalties() bool {
+	for _, result := range s.Result {
+		if len(result.Penalties) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addLegacyPenalties records the penalty given to a result before penalties were kept as a list, so that it is kept
+// when another penalty is added.
+func (s *SessionResult) addLegacyPenalties() {
+	if len(s.Penalties) > 0 {
+		return
+	}
+
+	if s.Disqualified {
+		s.Penalties = append(s.Penalties, &Penalty{ID: uuid.New(), Type: PenaltyTypeDisqualification})
+	} else if s.HasPenalty && s.PenaltyTime > 0 {
+		s.Penalties = append(s.Penalties, &Penalty{ID: uuid.New(), Type: PenaltyTypeTime, Time: s.PenaltyTime})
+	}
+}
+
+// applyPenalties sets the result's penalty time, lap penalty and disqualification from its active penalties.
+// Penalty times greater than the driver's last lap also give a lap penalty.
+func (s *SessionResult) applyPenalties(lastLapTime time.Duration) {
+	s.HasPenalty = false
+	s.Disqualified = false
+	s.PenaltyTime = 0
+	s.LapPenalty = 0
+
+	laps := 0
+
+	for _, penalty := range s.ActivePenalties() {
+		switch penalty.Type {
+		case PenaltyTypeTime:
+			s.PenaltyTime += penalty.Time
+		case PenaltyTypeLap:
+			laps += penalty.Laps
+		case PenaltyTypeDisqualification:
+			s.Disqualified = true
+		}
+	}
+
+	if s.Disqualified {
+		return
+	}
+
+	if s.PenaltyTime > 0 && lastLapTime > 0 && s.PenaltyTime > lastLapTime {
+		s.LapPenalty = int(s.PenaltyTime / lastLapTime)
+	}
+
+	s.LapPenalty += laps
+	s.HasPenalty = s.PenaltyTime > 0 || s.LapPenalty > 0
+}
+
 type PenaltiesHandler struct {
 	*BaseHandler
 
@@ -38,57 +244,173 @@ func (ph *PenaltiesHandler) managePenalty(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	add := r.FormValue("action") == "add"
+	steward := AccountFromRequest(r).Name
 
-	penalty := 0.0
+	switch r.FormValue("action") {
+	case "add":
+		penalty, err := penaltyFromForm(r)
 
-	if add {
-		penaltyString := r.FormValue("time-penalty")
+		if err == nil {
+			penalty.Steward = steward
+			err = ph.penaltiesManager.AddPenalty(jsonFileName, guid, carModel, penalty)
+		}
 
-		if penaltyString == "" {
-			penalty = 0
-		} else {
-			pen, err := strconv.ParseFloat(penaltyString, 64)
+		if err != nil {
+			logrus.WithError(err).Errorf("could not add penalty")
+			AddErrorFlash(w, r, "Could not add penalty")
+			http.Redirect(w, r, r.Referer(), http.StatusFound)
+			return
+		}
 
-			if err != nil {
-				logrus.WithError(err).Errorf("could not parse penalty time")
-				AddErrorFlash(w, r, "Could not parse penalty time")
-				http.Redirect(w, r, r.Referer(), http.StatusFound)
-				return
-			}
+		AddFlash(w, r, "Penalty Added!")
+	case "revoke":
+		penaltyID, err := uuid.Parse(r.FormValue("penalty"))
+
+		if err == nil {
+			err = ph.penaltiesManager.RevokePenalty(jsonFileName, guid, carModel, penaltyID, steward, r.FormValue("reason"))
+		}
+
+		if err != nil {
+			logrus.WithError(err).Errorf("could not revoke penalty")
+			AddErrorFlash(w, r, "Could not revoke penalty")
+			http.Redirect(w, r, r.Referer(), http.StatusFound)
+			return
+		}
+
+		AddFlash(w, r, "Penalty Revoked!")
+	default:
+		err := ph.penaltiesManager.RevokeAllPenalties(jsonFileName, guid, carModel, steward, r.FormValue("reason"))
 
-			penalty = pen
+		if err != nil {
+			logrus.WithError(err).Errorf("could not remove penalties")
+			AddErrorFlash(w, r, "Could not remove penalties")
+			http.Redirect(w, r, r.Referer(), http.StatusFound)
+			return
 		}
+
+		AddFlash(w, r, "Penalties Removed!")
 	}
 
-	err = ph.penaltiesManager.applyPenalty(jsonFileName, guid, carModel, penalty, add)
+	http.Redirect(w, r, r.Referer(), http.StatusFound)
+}
 
-	if err != nil {
-		AddErrorFlash(w, r, "Could not add/remove penalty")
-		http.Redirect(w, r, r.Referer(), http.StatusFound)
-		return
+// penaltyFromForm reads a penalty from the penalty form. The amount is in seconds, laps, grid places or points,
+// depending on the type of penalty.
+func penaltyFromForm(r *http.Request) (*Penalty, error) {
+	penalty := &Penalty{
+		Type:   PenaltyType(r.FormValue("type")),
+		Reason: r.FormValue("reason"),
 	}
 
-	if !add {
-		AddFlash(w, r, "Penalty Removed!")
-	} else {
-		AddFlash(w, r, "Penalty Added!")
+	var amount float64
+
+	if amountString := r.FormValue("amount"); amountString != "" {
+		var err error
+
+		amount, err = strconv.ParseFloat(amountString, 64)
+
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	http.Redirect(w, r, r.Referer(), http.StatusFound)
+	switch penalty.Type {
+	case PenaltyTypeTime:
+		penalty.Time = time.Duration(amount * float64(time.Second)).Round(time.Millisecond * 100)
+	case PenaltyTypeLap:
+		penalty.Laps = int(amount)
+	case PenaltyTypeGridDrop:
+		penalty.GridPlaces = int(amount)
+	case PenaltyTypePoints:
+		penalty.Points = amount
+	}
+
+	return penalty, nil
 }
 
 type PenaltiesManager struct {
-	store Store
+	store                  Store
+	resultsRevisionManager *ResultsRevisionManager
 }
 
-func NewPenaltiesManager(store Store) *PenaltiesManager {
+func NewPenaltiesManager(store Store, resultsRevisionManager *ResultsRevisionManager) *PenaltiesManager {
 	return &PenaltiesManager{
-		store: store,
+		store:                  store,
+		resultsRevisionManager: resultsRevisionManager,
+	}
+}
+
+// AddPenalty gives a penalty to a driver in a results file. Penalties stack with any the driver already has.
+func (pm *PenaltiesManager) AddPenalty(jsonFileName, guid, carModel string, penalty *Penalty) error {
+	if err := penalty.validate(); err != nil {
+		return err
 	}
+
+	penalty.ID = uuid.New()
+	penalty.Created = time.Now()
+
+	return pm.updatePenalties(jsonFileName, guid, carModel, penalty.Steward, func(result *SessionResult) (string, error) {
+		result.addLegacyPenalties()
+		result.Penalties = append(result.Penalties, penalty)
+
+		logrus.Infof("%s given to driver: %s", penalty, guid)
+
+		return fmt.Sprintf("%s given to %s", penalty, result.DriverName), nil
+	})
+}
+
+// RevokePenalty revokes one of a driver's penalties. The penalty is kept in the results file.
+func (pm *PenaltiesManager) RevokePenalty(jsonFileName, guid, carModel string, penaltyID uuid.UUID, steward, reason string) error {
+	return pm.revokePenalties(jsonFileName, guid, carModel, func(penalty *Penalty) bool {
+		return penalty.ID == penaltyID
+	}, steward, reason)
+}
+
+// RevokeAllPenalties revokes all of a driver's penalties in a results file.
+func (pm *PenaltiesManager) RevokeAllPenalties(jsonFileName, guid, carModel string, steward, reason string) error {
+	return pm.revokePenalties(jsonFileName, guid, carModel, nil, steward, reason)
+}
+
+// RevokeIncidentPenalties revokes the penalties given to a driver for a stewards' incident.
+func (pm *PenaltiesManager) RevokeIncidentPenalties(jsonFileName, guid, carModel string, incidentID string, steward, reason string) error {
+	return pm.revokePenalties(jsonFileName, guid, carModel, func(penalty *Penalty) bool {
+		return penalty.IncidentID == incidentID
+	}, steward, reason)
 }
 
-func (pm *PenaltiesManager) applyPenalty(jsonFileName, guid, carModel string, penalty float64, add bool) error {
+func (pm *PenaltiesManager) revokePenalties(jsonFileName, guid, carModel string, match func(penalty *Penalty) bool, steward, reason string) error {
+	return pm.updatePenalties(jsonFileName, guid, carModel, steward, func(result *SessionResult) (string, error) {
+		result.addLegacyPenalties()
+
+		var revoked []string
+
+		for _, penalty := range result.ActivePenalties() {
+			if match != nil && !match(penalty) {
+				continue
+			}
+
+			penalty.Revoked = &PenaltyRevocation{
+				Time:    time.Now(),
+				Steward: steward,
+				Reason:  reason,
+			}
+
+			revoked = append(revoked, penalty.String())
+
+			logrus.Infof("%s revoked from driver: %s", penalty, guid)
+		}
+
+		if len(revoked) == 0 && match != nil {
+			return "", ErrPenaltyNotFound
+		}
+
+		return fmt.Sprintf("%s revoked for %s", strings.Join(revoked, ", "), result.DriverName), nil
+	})
+}
+
+// updatePenalties changes the penalties of a driver in a results file, then re-orders the results and saves them as a
+// new revision of the results file. update returns a description of the change for the revision history.
+func (pm *PenaltiesManager) updatePenalties(jsonFileName, guid, carModel, account string, update func(result *SessionResult) (string, error)) error {
 	var results *SessionResults
 
 	var fullFileName string
@@ -108,50 +430,29 @@ func (pm *PenaltiesManager) applyPenalty(jsonFileName, guid, carModel string, pe
 		return err
 	}
 
-	for _, result := range results.Result {
-		if result.DriverGUID == guid && result.CarModel == carModel {
-			if !add {
-				result.HasPenalty = false
-				result.Disqualified = false
-				result.PenaltyTime = 0
-				result.LapPenalty = 0
-
-				logrus.Infof("All penalties cleared from Driver: %s", guid)
-			} else {
-				if penalty == 0 {
-					result.Disqualified = true
-					result.HasPenalty = false
-					result.LapPenalty = 0
-
-					logrus.Infof("Driver: %s disqualified", guid)
-				} else {
-					result.HasPenalty = true
-					result.Disqualified = false
-
-					timeParsed, err := time.ParseDuration(fmt.Sprintf("%.1fs", penalty))
-
-					if err != nil {
-						logrus.WithError(err).Errorf("could not parse penalty time")
-						return err
-					}
-
-					result.PenaltyTime = timeParsed
+	found := false
 
-					// If penalty time is greater than a lap then add a lap penalty and change penalty time by one lap
-					lastLapTime := results.GetLastLapTime(result.DriverGUID, result.CarModel)
+	var description string
 
-					if result.PenaltyTime > lastLapTime {
-						result.LapPenalty = int(result.PenaltyTime / lastLapTime)
-					}
+	for _, result := range results.Result {
+		if result.DriverGUID == guid && result.CarModel == carModel {
+			description, err = update(result)
 
-					logrus.Infof("%s penalty applied to driver: %s", timeParsed.String(), guid)
-				}
+			if err != nil {
+				return err
 			}
 
+			result.applyPenalties(results.GetLastLapTime(result.DriverGUID, result.CarModel))
+
+			found = true
 			break
 		}
 	}
 
+	if !found {
+		return ErrPenaltyDriverNotFound
+	}
+
 	switch results.Type {
 	case SessionTypePractice, SessionTypeQualifying:
 		sort.Slice(results.Result, func(i, j int) bool {
@@ -207,90 +508,12 @@ func (pm *PenaltiesManager) applyPenalty(jsonFileName, guid, carModel string, pe
 		})
 	}
 
-	err = saveResults(fullFileName, results)
+	err = pm.resultsRevisionManager.SaveResults(jsonFileName, results, account, description)
 
 	if err != nil {
-		logrus.WithError(err).Errorf("could not encode to session result file")
+		logrus.WithError(err).Errorf("could not save session result file")
 		return err
 	}
 
-	if results.ChampionshipID != "" {
-		championship, err := pm.store.LoadChampionship(results.ChampionshipID)
-
-		if err != nil {
-			logrus.WithError(err).Errorf("Couldn't load championship with ID: %s", results.ChampionshipID)
-			return err
-		}
-
-	champEvents:
-		for i, event := range championship.Events {
-			if event.IsRaceWeekend() {
-				raceWeekend, err := pm.store.LoadRaceWeekend(event.RaceWeekendID.String())
-
-				if err != nil {
-					return err
-				}
-
-				for key, session := range raceWeekend.Sessions {
-					if !session.Completed() {
-						continue
-					}
-
-					if session.Results.SessionFile == jsonFileName {
-						raceWeekend.Sessions[key].Results = results
-
-						break champEvents
-					}
-				}
-			} else {
-				for key, session := range event.Sessions {
-					if !session.Completed() {
-						continue
-					}
-
-					if session.Results.SessionFile == jsonFileName {
-						championship.Events[i].Sessions[key].Results = results
-
-						break champEvents
-					}
-				}
-			}
-		}
-
-		err = pm.store.UpsertChampionship(championship)
-
-		if err != nil {
-			logrus.WithError(err).Errorf("Couldn't save championship with ID: %s", results.ChampionshipID)
-			return err
-		}
-	}
-
-	if results.RaceWeekendID != "" {
-		raceWeekend, err := pm.store.LoadRaceWeekend(results.RaceWeekendID)
-
-		if err != nil {
-			logrus.WithError(err).Errorf("Couldn't load race weekend with id: %s", results.RaceWeekendID)
-			return err
-		}
-
-		for _, session := range raceWeekend.Sessions {
-			if !session.Completed() {
-				continue
-			}
-
-			if session.Results.SessionFile == jsonFileName {
-				session.Results = results
-				break
-			}
-		}
-
-		err = pm.store.UpsertRaceWeekend(raceWeekend)
-
-		if err != nil {
-			logrus.WithError(err).Errorf("Could not update race weekend: %s", raceWeekend.ID.String())
-			return err
-		}
-	}
-
 	return nil
 }
@@ -0,0 +1,252 @@
+package servermanager
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPenaltiesManager(t *testing.T) {
+	_, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	const (
+		fileName = "2019_3_2_22_28_RACE.json"
+		winner   = "76561198022717360"
+		second   = "76561198029578060"
+	)
+
+	results, err := LoadResult(fileName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the results should not be exported to a championship
+	results.ChampionshipID = ""
+
+	if err := saveResults(fileName, results); err != nil {
+		t.Fatal(err)
+	}
+
+	resultsRevisionManager := NewResultsRevisionManager(testStore, NewResultsIndex(testStore))
+	penaltiesManager := NewPenaltiesManager(testStore, resultsRevisionManager)
+
+	findResult := func(t *testing.T, guid string) (int, *SessionResult) {
+		results, err := LoadResult(fileName)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for pos, result := range results.Result {
+			if result.DriverGUID == guid {
+				return pos, result
+			}
+		}
+
+		t.Fatalf("Could not find result for driver: %s", guid)
+
+		return 0, nil
+	}
+
+	t.Run("Time penalties stack", func(t *testing.T) {
+		for _, penaltyTime := range []time.Duration{time.Second * 5, time.Second * 10} {
+			err := penaltiesManager.AddPenalty(fileName, winner, "a3dr_lambo_diablo_vt", &Penalty{
+				Type:    PenaltyTypeTime,
+				Time:    penaltyTime,
+				Reason:  "Causing a collision",
+				Steward: "Race Director",
+			})
+
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		_, result := findResult(t, winner)
+
+		if !result.HasPenalty || result.PenaltyTime != time.Second*15 || len(result.Penalties) != 2 {
+			t.Errorf("Expected a 15s penalty from two penalties, got: %s (%d penalties)", result.PenaltyTime, len(result.Penalties))
+		}
+	})
+
+	t.Run("Warnings and reprimands do not change the results", func(t *testing.T) {
+		err := penaltiesManager.AddPenalty(fileName, second, "ks_porsche_911_carrera_rsr", &Penalty{
+			Type:   PenaltyTypeWarning,
+			Reason: "Track limits",
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		pos, result := findResult(t, second)
+
+		if result.HasPenalty || result.Disqualified || len(result.ActivePenalties()) != 1 || pos != 0 {
+			t.Errorf("Unexpected result for a warning: %+v (P%d)", result, pos+1)
+		}
+	})
+
+	t.Run("Disqualified drivers are moved to the back", func(t *testing.T) {
+		err := penaltiesManager.AddPenalty(fileName, second, "ks_porsche_911_carrera_rsr", &Penalty{
+			Type: PenaltyTypeDisqualification,
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results, err := LoadResult(fileName)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if last := results.Result[len(results.Result)-1]; last.DriverGUID != second || !last.Disqualified {
+			t.Errorf("Expected the disqualified driver to be last, got: %s", last.DriverName)
+		}
+	})
+
+	t.Run("Revoked penalties are kept", func(t *testing.T) {
+		_, result := findResult(t, second)
+
+		var disqualification *Penalty
+
+		for _, penalty := range result.Penalties {
+			if penalty.Type == PenaltyTypeDisqualification {
+				disqualification = penalty
+			}
+		}
+
+		if disqualification == nil {
+			t.Fatal("Could not find disqualification")
+		}
+
+		if err := penaltiesManager.RevokePenalty(fileName, second, "ks_porsche_911_carrera_rsr", disqualification.ID, "Race Director", "Appeal upheld"); err != nil {
+			t.Fatal(err)
+		}
+
+		_, result = findResult(t, second)
+
+		if result.Disqualified || len(result.Penalties) != 2 || len(result.ActivePenalties()) != 1 {
+			t.Errorf("Expected the disqualification to be revoked, got: %+v", result)
+		}
+
+		for _, penalty := range result.Penalties {
+			if penalty.ID == disqualification.ID && (penalty.Revoked == nil || penalty.Revoked.Steward != "Race Director" || penalty.Revoked.Reason != "Appeal upheld") {
+				t.Errorf("Incorrect revocation: %+v", penalty.Revoked)
+			}
+		}
+
+		if err := penaltiesManager.RevokePenalty(fileName, second, "ks_porsche_911_carrera_rsr", disqualification.ID, "Race Director", ""); err != ErrPenaltyNotFound {
+			t.Errorf("Expected a revoked penalty not to be found, got: %v", err)
+		}
+	})
+
+	t.Run("All penalties can be revoked", func(t *testing.T) {
+		if err := penaltiesManager.RevokeAllPenalties(fileName, winner, "a3dr_lambo_diablo_vt", "Race Director", ""); err != nil {
+			t.Fatal(err)
+		}
+
+		pos, result := findResult(t, winner)
+
+		if result.HasPenalty || result.PenaltyTime != 0 || len(result.Penalties) != 2 || len(result.ActivePenalties()) != 0 || pos != 0 {
+			t.Errorf("Expected no active penalties, got: %+v (P%d)", result, pos+1)
+		}
+	})
+
+	t.Run("Invalid penalties", func(t *testing.T) {
+		if err := penaltiesManager.AddPenalty(fileName, winner, "a3dr_lambo_diablo_vt", &Penalty{Type: PenaltyTypeTime}); err != ErrInvalidPenalty {
+			t.Errorf("Expected a time penalty with no time to be invalid, got: %v", err)
+		}
+
+		if err := penaltiesManager.AddPenalty(fileName, winner, "a3dr_lambo_diablo_vt", &Penalty{Type: "Stop and Go"}); err != ErrInvalidPenalty {
+			t.Errorf("Expected an unknown type of penalty to be invalid, got: %v", err)
+		}
+
+		if err := penaltiesManager.AddPenalty(fileName, "1234", "a3dr_lambo_diablo_vt", &Penalty{Type: PenaltyTypeWarning}); err != ErrPenaltyDriverNotFound {
+			t.Errorf("Expected the driver not to be found, got: %v", err)
+		}
+	})
+}
+
+func TestSessionResult_applyPenalties(t *testing.T) {
+	t.Run("Penalties given before penalties were kept", func(t *testing.T) {
+		result := &SessionResult{HasPenalty: true, PenaltyTime: time.Second * 30}
+
+		result.addLegacyPenalties()
+		result.Penalties = append(result.Penalties, &Penalty{Type: PenaltyTypeTime, Time: time.Second * 5})
+		result.applyPenalties(time.Minute)
+
+		if result.PenaltyTime != time.Second*35 || result.LapPenalty != 0 {
+			t.Errorf("Expected the existing penalty to be kept, got: %s", result.PenaltyTime)
+		}
+	})
+
+	t.Run("Time penalties longer than a lap and lap penalties", func(t *testing.T) {
+		result := &SessionResult{
+			Penalties: []*Penalty{
+				{Type: PenaltyTypeTime, Time: time.Second * 90},
+				{Type: PenaltyTypeLap, Laps: 2},
+				{Type: PenaltyTypeLap, Laps: 1, Revoked: &PenaltyRevocation{}},
+			},
+		}
+
+		result.applyPenalties(time.Minute)
+
+		if !result.HasPenalty || result.LapPenalty != 3 {
+			t.Errorf("Expected a 3 lap penalty, got: %d", result.LapPenalty)
+		}
+	})
+}
+
+func TestChampionshipClass_StandingsPointsDeduction(t *testing.T) {
+	_, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	results, err := LoadResult("2019_3_2_22_28_RACE.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	championship := NewChampionship("Test")
+	class := NewChampionshipClass("Class")
+	championship.AddClass(class)
+
+	for _, result := range results.Result {
+		result.ClassID = class.ID
+	}
+
+	for _, lap := range results.Laps {
+		lap.ClassID = class.ID
+	}
+
+	event := NewChampionshipEvent()
+	event.Sessions[SessionTypeRace] = &ChampionshipSession{
+		StartedTime:   results.Date,
+		CompletedTime: results.Date,
+		Results:       results,
+	}
+
+	championship.Events = append(championship.Events, event)
+
+	points := func() float64 {
+		for _, standing := range class.Standings(championship, championship.Events) {
+			if standing.Car.Driver.GUID == results.Result[0].DriverGUID {
+				return standing.Points
+			}
+		}
+
+		return 0
+	}
+
+	before := points()
+
+	results.Result[0].Penalties = append(results.Result[0].Penalties, &Penalty{Type: PenaltyTypePoints, Points: 5})
+	results.Result[0].applyPenalties(time.Minute)
+
+	if after := points(); after != before-5 {
+		t.Errorf("Expected a 5 point deduction from %.0f points, got %.0f points", before, after)
+	}
+}
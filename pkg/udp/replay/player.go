@@ -0,0 +1,230 @@
+package replay
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+var ErrInvalidSpeed = errors.New("replay: speed multiplier must be greater than zero")
+
+// Player plays back recorded entries with the same timing as they were received, adjusted by a speed multiplier.
+// Playback can be paused, resumed and moved to any point in the recording.
+type Player struct {
+	entries  Entries
+	callback func(entry *Entry)
+
+	// MaxWait is the longest time that the Player will wait between two entries, so that long periods with no
+	// messages are skipped over.
+	MaxWait time.Duration
+
+	mutex      sync.Mutex
+	position   int
+	multiplier float64
+	playing    bool
+	closed     bool
+	generation int
+
+	changed chan struct{}
+	stopped chan struct{}
+}
+
+// NewPlayer creates a paused Player for the given entries. Each entry is passed to callback as it is played.
+func NewPlayer(entries Entries, callback func(entry *Entry)) *Player {
+	sort.Stable(entries)
+
+	return &Player{
+		entries:    entries,
+		callback:   callback,
+		MaxWait:    time.Second * 10,
+		multiplier: 1,
+		changed:    make(chan struct{}, 1),
+		stopped:    make(chan struct{}),
+	}
+}
+
+// Run plays entries until Stop is called. It should be run in its own goroutine.
+func (p *Player) Run() {
+	for {
+		p.mutex.Lock()
+
+		if p.closed {
+			p.mutex.Unlock()
+			return
+		}
+
+		if !p.playing || p.position >= len(p.entries) {
+			p.playing = false
+			p.mutex.Unlock()
+
+			select {
+			case <-p.changed:
+				continue
+			case <-p.stopped:
+				return
+			}
+		}
+
+		entry := p.entries[p.position]
+		generation := p.generation
+
+		var wait time.Duration
+
+		if p.position > 0 {
+			wait = time.Duration(float64(entry.Received.Sub(p.entries[p.position-1].Received)) / p.multiplier)
+		}
+
+		if wait > p.MaxWait {
+			wait = p.MaxWait
+		}
+
+		p.mutex.Unlock()
+
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+
+			select {
+			case <-timer.C:
+			case <-p.changed:
+				timer.Stop()
+				continue
+			case <-p.stopped:
+				timer.Stop()
+				return
+			}
+		}
+
+		p.mutex.Lock()
+
+		if p.closed || !p.playing || generation != p.generation {
+			// the player was paused, moved or stopped while waiting
+			p.mutex.Unlock()
+			continue
+		}
+
+		p.position++
+		p.callback(entry)
+		p.mutex.Unlock()
+	}
+}
+
+// notify wakes up Run so that it can act on a change to the Player. It must be called with the mutex held.
+func (p *Player) notify() {
+	p.generation++
+
+	select {
+	case p.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Play resumes playback.
+func (p *Player) Play() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.playing = true
+	p.notify()
+}
+
+// Pause stops playback until Play is called. If an entry is currently being played, Pause waits for it to finish.
+func (p *Player) Pause() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.playing = false
+	p.notify()
+}
+
+// SetSpeed changes the speed of playback, e.g. a multiplier of 2 plays back at double speed.
+func (p *Player) SetSpeed(multiplier float64) error {
+	if multiplier <= 0 {
+		return ErrInvalidSpeed
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.multiplier = multiplier
+	p.notify()
+
+	return nil
+}
+
+// Seek moves playback to offset from the start of the recording. Playback state is usually built up from every
+// previous message, so all entries from the start of the recording up to offset are passed to fastForward, which
+// is called before any further entries are played.
+func (p *Player) Seek(offset time.Duration, fastForward func(entries Entries)) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	position := 0
+
+	if len(p.entries) > 0 {
+		target := p.entries[0].Received.Add(offset)
+
+		position = sort.Search(len(p.entries), func(i int) bool {
+			return p.entries[i].Received.After(target)
+		})
+	}
+
+	fastForward(p.entries[:position])
+
+	p.position = position
+	p.notify()
+}
+
+// Stop ends playback. The Player cannot be used after it has been stopped.
+func (p *Player) Stop() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.closed {
+		return
+	}
+
+	p.closed = true
+	p.playing = false
+	close(p.stopped)
+}
+
+// Position is the time from the start of the recording to the most recently played entry.
+func (p *Player) Position() time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if p.position == 0 {
+		return 0
+	}
+
+	return p.entries[p.position-1].Received.Sub(p.entries[0].Received)
+}
+
+// Duration is the total length of the recording.
+func (p *Player) Duration() time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if len(p.entries) == 0 {
+		return 0
+	}
+
+	return p.entries[len(p.entries)-1].Received.Sub(p.entries[0].Received)
+}
+
+// Playing reports whether the Player is currently playing.
+func (p *Player) Playing() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.playing
+}
+
+// Speed is the current speed multiplier.
+func (p *Player) Speed() float64 {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	return p.multiplier
+}
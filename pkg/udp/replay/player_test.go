@@ -0,0 +1,223 @@
+package replay
+
+import (
+	"testing"
+	"time"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+// testEntries creates an entry for each offset from the start of a recording. The data of each entry is its index, so
+// that the order entries are played in can be checked.
+func testEntries(offsets ...time.Duration) Entries {
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var entries Entries
+
+	for i, offset := range offsets {
+		entries = append(entries, &Entry{
+			Received:  start.Add(offset),
+			EventType: udp.EventVersion,
+			Data:      udp.Version(i),
+		})
+	}
+
+	return entries
+}
+
+type playedEntry struct {
+	index  int
+	played time.Time
+}
+
+// runTestPlayer starts a Player for entries, returning it and a channel which receives each entry as it is played.
+func runTestPlayer(entries Entries, maxWait time.Duration) (*Player, chan playedEntry) {
+	played := make(chan playedEntry, len(entries))
+
+	player := NewPlayer(entries, func(entry *Entry) {
+		played <- playedEntry{index: int(entry.Data.(udp.Version)), played: time.Now()}
+	})
+
+	if maxWait > 0 {
+		player.MaxWait = maxWait
+	}
+
+	go player.Run()
+
+	return player, played
+}
+
+func expectPlayed(t *testing.T, played chan playedEntry, indexes ...int) []playedEntry {
+	t.Helper()
+
+	var out []playedEntry
+
+	for _, index := range indexes {
+		select {
+		case entry := <-played:
+			if entry.index != index {
+				t.Fatalf("Expected entry %d to be played, got %d", index, entry.index)
+			}
+
+			out = append(out, entry)
+		case <-time.After(time.Second * 5):
+			t.Fatalf("Timed out waiting for entry %d to be played", index)
+		}
+	}
+
+	return out
+}
+
+func expectNothingPlayed(t *testing.T, played chan playedEntry, wait time.Duration) {
+	t.Helper()
+
+	select {
+	case entry := <-played:
+		t.Fatalf("Expected no entries to be played, got %d", entry.index)
+	case <-time.After(wait):
+	}
+}
+
+func TestPlayer_Play(t *testing.T) {
+	player, played := runTestPlayer(testEntries(0, 100*time.Millisecond, 200*time.Millisecond, 300*time.Millisecond), 0)
+	defer player.Stop()
+
+	// players start paused
+	expectNothingPlayed(t, played, 50*time.Millisecond)
+
+	player.Play()
+
+	entries := expectPlayed(t, played, 0, 1, 2, 3)
+
+	if elapsed := entries[3].played.Sub(entries[0].played); elapsed < 280*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected entries to be played over 300ms, took %s", elapsed)
+	}
+
+	if player.Position() != 300*time.Millisecond || player.Duration() != 300*time.Millisecond {
+		t.Errorf("Expected player to be at the end of the recording, got %s of %s", player.Position(), player.Duration())
+	}
+
+	// the player stops at the end of the recording
+	expectNothingPlayed(t, played, 50*time.Millisecond)
+
+	if player.Playing() {
+		t.Errorf("Expected player to stop playing at the end of the recording")
+	}
+}
+
+func TestPlayer_Pause(t *testing.T) {
+	player, played := runTestPlayer(testEntries(0, 200*time.Millisecond, 400*time.Millisecond), 0)
+	defer player.Stop()
+
+	player.Play()
+	expectPlayed(t, played, 0)
+
+	player.Pause()
+
+	if player.Playing() {
+		t.Errorf("Expected player to be paused")
+	}
+
+	expectNothingPlayed(t, played, 400*time.Millisecond)
+
+	player.Play()
+	expectPlayed(t, played, 1, 2)
+}
+
+func TestPlayer_Seek(t *testing.T) {
+	player, played := runTestPlayer(testEntries(0, 100*time.Millisecond, 200*time.Millisecond, 300*time.Millisecond), 0)
+	defer player.Stop()
+
+	var fastForwarded Entries
+
+	player.Seek(150*time.Millisecond, func(entries Entries) {
+		fastForwarded = entries
+	})
+
+	if len(fastForwarded) != 2 || fastForwarded[0].Data != udp.Version(0) || fastForwarded[1].Data != udp.Version(1) {
+		t.Fatalf("Expected the first two entries to be fast forwarded, got %d", len(fastForwarded))
+	}
+
+	if player.Position() != 100*time.Millisecond {
+		t.Errorf("Expected position to be the last entry fast forwarded, got %s", player.Position())
+	}
+
+	player.Play()
+	expectPlayed(t, played, 2, 3)
+
+	t.Run("Backwards", func(t *testing.T) {
+		player.Seek(0, func(entries Entries) {
+			fastForwarded = entries
+		})
+
+		if len(fastForwarded) != 1 || player.Position() != 0 {
+			t.Fatalf("Expected only the first entry to be fast forwarded, got %d", len(fastForwarded))
+		}
+
+		player.Play()
+		expectPlayed(t, played, 1, 2, 3)
+	})
+
+	t.Run("While playing", func(t *testing.T) {
+		player.Seek(0, func(entries Entries) {})
+		player.Play()
+		expectPlayed(t, played, 1)
+
+		// entry 2 is waiting to be played, it must not be played after the player has moved.
+		player.Seek(250*time.Millisecond, func(entries Entries) {})
+		expectPlayed(t, played, 3)
+	})
+}
+
+func TestPlayer_SetSpeed(t *testing.T) {
+	player, played := runTestPlayer(testEntries(0, 500*time.Millisecond, time.Second), 0)
+	defer player.Stop()
+
+	if err := player.SetSpeed(0); err != ErrInvalidSpeed {
+		t.Errorf("Expected a speed of 0 to be invalid, got: %v", err)
+	}
+
+	if err := player.SetSpeed(5); err != nil {
+		t.Fatal(err)
+	}
+
+	if player.Speed() != 5 {
+		t.Errorf("Expected speed to be 5, got %f", player.Speed())
+	}
+
+	player.Play()
+
+	entries := expectPlayed(t, played, 0, 1, 2)
+
+	if elapsed := entries[2].played.Sub(entries[0].played); elapsed < 180*time.Millisecond || elapsed > 800*time.Millisecond {
+		t.Errorf("Expected entries to be played over 200ms, took %s", elapsed)
+	}
+}
+
+func TestPlayer_MaxWait(t *testing.T) {
+	player, played := runTestPlayer(testEntries(0, time.Hour), 50*time.Millisecond)
+	defer player.Stop()
+
+	player.Play()
+
+	expectPlayed(t, played, 0, 1)
+}
+
+func TestPlayer_Stop(t *testing.T) {
+	player := NewPlayer(testEntries(0, time.Hour), func(entry *Entry) {})
+	done := make(chan struct{})
+
+	go func() {
+		player.Run()
+		close(done)
+	}()
+
+	player.Play()
+	player.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("Expected Run to return once the player is stopped")
+	}
+}
@@ -171,37 +171,74 @@ var BucketName = []byte("sessions")
 
 func RecordUDPMessages(db *bbolt.DB) (callbackFunc udp.CallbackFunc) {
 	return func(message udp.Message) {
-		e := Entry{
-			Received:  time.Now(),
-			EventType: message.Event(),
-			Data:      message,
+		err := RecordUDPMessage(db, time.Now(), message)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("could not record udp message")
 		}
+	}
+}
+
+// RecordUDPMessage saves a single message to db, as if it had been received at the given time.
+func RecordUDPMessage(db *bbolt.DB, received time.Time, message udp.Message) error {
+	e := Entry{
+		Received:  received,
+		EventType: message.Event(),
+		Data:      message,
+	}
+
+	buf := new(bytes.Buffer)
+
+	encoder := json.NewEncoder(buf)
+	encoder.SetIndent("", "  ")
+	err := encoder.Encode(e)
 
-		buf := new(bytes.Buffer)
+	if err != nil {
+		return err
+	}
 
-		encoder := json.NewEncoder(buf)
-		encoder.SetIndent("", "  ")
-		err := encoder.Encode(e)
+	return db.Update(func(tx *bbolt.Tx) error {
+		bkt, err := tx.CreateBucketIfNotExists(BucketName)
 
 		if err != nil {
-			logrus.WithError(err).Errorf("could not encode json")
-			return
+			return err
 		}
 
-		err = db.Update(func(tx *bbolt.Tx) error {
-			bkt, err := tx.CreateBucketIfNotExists(BucketName)
+		return bkt.Put([]byte(e.Received.Format(time.RFC3339Nano)), buf.Bytes())
+	})
+}
+
+// LoadEntries reads all recorded entries from db, in the order that they were received.
+func LoadEntries(db *bbolt.DB) (Entries, error) {
+	var loadedEntries Entries
 
-			if err != nil {
+	err := db.View(func(tx *bbolt.Tx) error {
+		bkt := tx.Bucket(BucketName)
+
+		if bkt == nil {
+			return nil
+		}
+
+		return bkt.ForEach(func(k, v []byte) error {
+			var entry *Entry
+
+			if err := json.Unmarshal(v, &entry); err != nil {
 				return err
 			}
 
-			return bkt.Put([]byte(e.Received.Format(time.RFC3339Nano)), buf.Bytes())
+			loadedEntries = append(loadedEntries, entry)
+
+			return nil
 		})
+	})
 
-		if err != nil {
-			logrus.WithError(err).Errorf("could not save to bucket")
-		}
+	if err != nil {
+		return nil, err
 	}
+
+	sort.Stable(loadedEntries)
+
+	return loadedEntries, nil
 }
 
 func UDPMessages(db *bbolt.DB, multiplier int, callbackFunc udp.CallbackFunc, waitTime time.Duration) error {
@@ -0,0 +1,70 @@
+package replay
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+
+	"github.com/etcd-io/bbolt"
+)
+
+func TestRecordUDPMessage(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	db, err := bbolt.Open(filepath.Join(dir, "replay.db"), 0644, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	messages := []udp.Message{
+		udp.SessionInfo{Track: "monza", Type: udp.SessionTypeRace, Laps: 10, EventType: udp.EventNewSession},
+		udp.SessionCarInfo{CarID: 1, DriverGUID: "driver-1", DriverName: "Driver 1", CarModel: "ks_audi_r8_lms", EventType: udp.EventNewConnection},
+		udp.CarUpdate{CarID: 1, Pos: udp.Vec{X: 1, Y: 2, Z: 3}, NormalisedSplinePos: 0.5},
+		udp.LapCompleted{CarID: 1, LapTime: 100000, Cars: []*udp.LapCompletedCar{{CarID: 1, LapTime: 100000, Laps: 1}}},
+		udp.Chat{CarID: 1, Message: "hello"},
+		udp.EndSession("results/2020_1_1_12_0_RACE.json"),
+	}
+
+	// messages are recorded out of order, and must be loaded in the order they were received.
+	for i := len(messages) - 1; i >= 0; i-- {
+		if err := RecordUDPMessage(db, start.Add(time.Duration(i)*time.Millisecond), messages[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries, err := LoadEntries(db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(entries) != len(messages) {
+		t.Fatalf("Expected %d entries, got %d", len(messages), len(entries))
+	}
+
+	for i, entry := range entries {
+		if !entry.Received.Equal(start.Add(time.Duration(i) * time.Millisecond)) {
+			t.Errorf("Entry %d: incorrect received time: %s", i, entry.Received)
+		}
+
+		if entry.EventType != messages[i].Event() || !reflect.DeepEqual(entry.Data, messages[i]) {
+			t.Errorf("Entry %d: expected %#v, got %#v", i, messages[i], entry.Data)
+		}
+	}
+}
@@ -18,16 +18,26 @@ import (
 )
 
 type RaceControl struct {
-	process          ServerProcess
-	store            Store
-	penaltiesManager *PenaltiesManager
+	process                ServerProcess
+	store                  Store
+	penaltiesManager       *PenaltiesManager
+	resultsRevisionManager *ResultsRevisionManager
 
 	SessionInfo                udp.SessionInfo `json:"SessionInfo"`
 	TrackMapData               TrackMapData    `json:"TrackMapData"`
 	TrackInfo                  TrackInfo       `json:"TrackInfo"`
+	TrackSectors               []float32       `json:"TrackSectors"`
 	SessionStartTime           time.Time       `json:"SessionStartTime"`
 	CurrentRealtimePosInterval int             `json:"CurrentRealtimePosInterval"`
 
+	// ApproximateTrackSectors is true if the track has no sector data, so TrackSectors split the lap into sectors of
+	// equal length. Approximate sectors don't match the sectors in results files, so overall best sectors are not
+	// worked out for them.
+	ApproximateTrackSectors bool `json:"ApproximateTrackSectors"`
+
+	// sessionInfoMutex protects writes to SessionInfo, which is also read outside of the UDP callback.
+	sessionInfoMutex sync.RWMutex
+
 	ChatMessages      []udp.Chat
 	ChatMessagesMutex sync.Mutex
 
@@ -37,6 +47,30 @@ type RaceControl struct {
 	CarIDToGUID      map[udp.CarID]udp.DriverGUID `json:"CarIDToGUID"`
 	carIDToGUIDMutex sync.RWMutex
 
+	BestSectors      []*RaceControlBestSector `json:"BestSectors"`
+	bestSectorsMutex sync.RWMutex
+
+	// personalBestSectors are the personal best sectors of every driver in each of their cars, which the overall
+	// BestSectors are worked out from. They are protected by bestSectorsMutex, so that the best sectors can be
+	// recalculated without locking every driver.
+	personalBestSectors map[raceControlDriverCar]*raceControlPersonalBestSectors
+
+	FullCourseYellows     []*FullCourseYellow `json:"FullCourseYellows"`
+	fullCourseYellowMutex sync.RWMutex
+
+	gapTracker  *raceGapTracker
+	trackLimits *trackLimitsTracker
+	flags       *raceFlagTracker
+	history     *raceHistoryTracker
+	pitStops    *pitStopTracker
+
+	// now is the current time. Replays of previous sessions use the time that messages were originally received.
+	now func() time.Time
+
+	// replaying is true for a RaceControl which is showing a replay of a previous session.
+	replaying bool
+	done      chan struct{}
+
 	carUpdaters          map[udp.CarID]chan udp.CarUpdate
 	serverProcessStopped chan struct{}
 
@@ -56,9 +90,19 @@ type RaceControl struct {
 	driverSwapPenalties      map[udp.DriverGUID]*driverSwapPenalty
 }
 
+const (
+	EventRaceControl                udp.Event = 200
+	EventRaceControlSectorCompleted udp.Event = 201
+	EventRaceControlGaps            udp.Event = 202
+	EventRaceControlFlag            udp.Event = 203
+	EventRaceControlHistory         udp.Event = 204
+	EventRaceControlLapHistory      udp.Event = 205
+	EventRaceControlPitLane         udp.Event = 206
+)
+
 // RaceControl piggyback's on the udp.Message interface so that the entire data can be sent to newly connected clients.
 func (rc *RaceControl) Event() udp.Event {
-	return 200
+	return EventRaceControl
 }
 
 type CollisionType string
@@ -77,27 +121,54 @@ type Collision struct {
 	Speed           float64        `json:"Speed"`
 }
 
-func NewRaceControl(broadcaster Broadcaster, trackDataGateway TrackDataGateway, process ServerProcess, store Store, penaltiesManager *PenaltiesManager) *RaceControl {
+func NewRaceControl(broadcaster Broadcaster, trackDataGateway TrackDataGateway, process ServerProcess, store Store, penaltiesManager *PenaltiesManager, resultsRevisionManager *ResultsRevisionManager) *RaceControl {
+	rc := newRaceControl(broadcaster, trackDataGateway, process, store, penaltiesManager, resultsRevisionManager)
+
+	go panicCapture(rc.watchForTimedOutDrivers)
+	go panicCapture(rc.broadcastGaps)
+	go panicCapture(rc.watchFlags)
+
+	return rc
+}
+
+func newRaceControl(broadcaster Broadcaster, trackDataGateway TrackDataGateway, process ServerProcess, store Store, penaltiesManager *PenaltiesManager, resultsRevisionManager *ResultsRevisionManager) *RaceControl {
 	rc := &RaceControl{
-		broadcaster:          broadcaster,
-		trackDataGateway:     trackDataGateway,
-		process:              process,
-		store:                store,
-		driverSwapTimers:     make(map[int]*time.Timer),
-		penaltiesManager:     penaltiesManager,
-		carUpdaters:          make(map[udp.CarID]chan udp.CarUpdate),
-		serverProcessStopped: make(chan struct{}),
+		broadcaster:            broadcaster,
+		trackDataGateway:       trackDataGateway,
+		process:                process,
+		store:                  store,
+		driverSwapTimers:       make(map[int]*time.Timer),
+		penaltiesManager:       penaltiesManager,
+		resultsRevisionManager: resultsRevisionManager,
+		carUpdaters:            make(map[udp.CarID]chan udp.CarUpdate),
+		serverProcessStopped:   make(chan struct{}),
+		gapTracker:             newRaceGapTracker(),
+		trackLimits:            newTrackLimitsTracker(),
+		flags:                  newRaceFlagTracker(),
+		history:                newRaceHistoryTracker(),
+		pitStops:               newPitStopTracker(),
+		now:                    time.Now,
+		done:                   make(chan struct{}),
 	}
 
 	process.NotifyDone(rc.serverProcessStopped)
 
 	rc.clearAllDrivers()
 
-	go panicCapture(rc.watchForTimedOutDrivers)
-
 	return rc
 }
 
+// close stops all background work for the RaceControl. It must not be called while messages are still being
+// passed to UDPCallback.
+func (rc *RaceControl) close() {
+	close(rc.done)
+
+	for carID, ch := range rc.carUpdaters {
+		close(ch)
+		delete(rc.carUpdaters, carID)
+	}
+}
+
 func (rc *RaceControl) UDPCallback(message udp.Message) {
 	var err error
 
@@ -156,7 +227,7 @@ func (rc *RaceControl) UDPCallback(message udp.Message) {
 			m.DriverName = "Server"
 		}
 
-		m.Time = time.Now()
+		m.Time = rc.now()
 
 		err = rc.OnChatMessage(m)
 	default:
@@ -169,20 +240,25 @@ func (rc *RaceControl) UDPCallback(message udp.Message) {
 	}
 
 	if sendUpdatedRaceControlStatus {
-		// update the current refresh rate
-		rc.CurrentRealtimePosInterval = udp.CurrentRealtimePosIntervalMs
+		rc.broadcastStatus()
+	}
+}
 
-		lastUpdateMessage, err := rc.broadcaster.Send(rc)
+// broadcastStatus sends the entire RaceControl to all clients, and keeps a copy to send to newly connected clients.
+func (rc *RaceControl) broadcastStatus() {
+	// update the current refresh rate
+	rc.CurrentRealtimePosInterval = udp.CurrentRealtimePosIntervalMs
 
-		if err != nil {
-			logrus.WithError(err).Error("Unable to broadcast race control message")
-			return
-		}
+	lastUpdateMessage, err := rc.broadcaster.Send(rc)
 
-		rc.lastUpdateMessageMutex.Lock()
-		rc.lastUpdateMessage = lastUpdateMessage
-		rc.lastUpdateMessageMutex.Unlock()
+	if err != nil {
+		logrus.WithError(err).Error("Unable to broadcast race control message")
+		return
 	}
+
+	rc.lastUpdateMessageMutex.Lock()
+	rc.lastUpdateMessage = lastUpdateMessage
+	rc.lastUpdateMessageMutex.Unlock()
 }
 
 var driverTimeout = time.Minute * 5
@@ -194,8 +270,15 @@ func (rc *RaceControl) watchForTimedOutDrivers() {
 	}
 
 	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rc.done:
+			return
+		case <-ticker.C:
+		}
 
-	for range ticker.C {
 		var driversToDisconnect []*RaceControlDriver
 
 		_ = rc.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
@@ -246,6 +329,12 @@ func (rc *RaceControl) OnVersion(version udp.Version) error {
 // OnCarUpdate occurs every udp.RealTimePosInterval and returns car position, speed, etc.
 // drivers top speeds are recorded per lap, as well as their last seen updated.
 func (rc *RaceControl) OnCarUpdate(update udp.CarUpdate) error {
+	if rc.replaying {
+		// a replay is played (and seeked) from a single goroutine, so car updates must be handled in order with the
+		// other messages, at the time of the replay clock.
+		return rc.handleCarUpdate(update)
+	}
+
 	if ch, ok := rc.carUpdaters[update.CarID]; !ok || ch == nil {
 		rc.carUpdaters[update.CarID] = make(chan udp.CarUpdate, 1000)
 
@@ -283,11 +372,50 @@ func (rc *RaceControl) handleCarUpdate(update udp.CarUpdate) error {
 		driver.CurrentCar().TopSpeedThisLap = speed
 	}
 
-	driver.LastSeen = time.Now()
+	driver.LastSeen = rc.now()
 	driver.LastPos = update.Pos
+	driver.splinePos = update.NormalisedSplinePos
+	driver.speed = speed
+
+	sectorCompleted := rc.updateSectorTiming(driver, update.NormalisedSplinePos, driver.LastSeen)
+
+	pitLane := rc.pitStops.update(
+		rc.process.Event().GetRaceConfig().PitLane,
+		update.CarID,
+		driver.CarInfo,
+		driver.CurrentCar().NumLaps+1,
+		update.NormalisedSplinePos,
+		speed,
+		driver.LastSeen,
+		driver.LastSeen.Sub(rc.SessionStartTime),
+	)
+
+	if pitLane != nil {
+		driver.InPitLane = pitLane.InPitLane
+		driver.PitStops = pitLane.NumPitStops
+
+		if _, err := rc.broadcaster.Send(pitLane); err != nil {
+			logrus.WithError(err).Errorf("Could not broadcast pit lane message")
+		}
+	}
+
+	rc.flags.update(update.CarID, update.Pos, update.NormalisedSplinePos, speed, driver.InPitLane, driver.LastSeen)
+	rc.history.carUpdate(driver.CarInfo.DriverGUID, update.NormalisedSplinePos, speed)
+
+	if rc.currentSessionInfo().Type == udp.SessionTypeRace {
+		rc.gapTracker.update(update.CarID, driver.CarInfo.DriverGUID, update.NormalisedSplinePos, driver.LastSeen)
+	}
 
 	_, err = rc.broadcaster.Send(update)
 
+	if err != nil {
+		return err
+	}
+
+	if sectorCompleted != nil {
+		_, err = rc.broadcaster.Send(sectorCompleted)
+	}
+
 	return err
 }
 
@@ -297,8 +425,10 @@ var emptyCarInfoMutex = sync.Mutex{}
 // then all driver information is cleared.
 func (rc *RaceControl) OnNewSession(sessionInfo udp.SessionInfo) error {
 	oldSessionInfo := rc.SessionInfo
+	rc.sessionInfoMutex.Lock()
 	rc.SessionInfo = sessionInfo
-	rc.SessionStartTime = time.Now()
+	rc.sessionInfoMutex.Unlock()
+	rc.SessionStartTime = rc.now()
 
 	emptyCarInfo := true
 
@@ -306,6 +436,23 @@ func (rc *RaceControl) OnNewSession(sessionInfo udp.SessionInfo) error {
 	rc.driverSwapPenalties = make(map[udp.DriverGUID]*driverSwapPenalty)
 	rc.driverSwapPenaltiesMutex.Unlock()
 
+	rc.gapTracker.reset()
+	rc.trackLimits.reset()
+	rc.flags.reset()
+	rc.history.reset()
+	rc.pitStops.reset()
+	rc.clearFullCourseYellows()
+
+	_ = rc.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
+		driver.mutex.Lock()
+		defer driver.mutex.Unlock()
+
+		driver.BlueFlag = false
+		driver.SlowCar = false
+
+		return nil
+	})
+
 	if (rc.ConnectedDrivers.Len() > 0 || rc.DisconnectedDrivers.Len() > 0) && sessionInfo.Type == udp.SessionTypePractice {
 		if oldSessionInfo.Type == sessionInfo.Type && oldSessionInfo.Track == sessionInfo.Track && oldSessionInfo.TrackConfig == sessionInfo.TrackConfig && oldSessionInfo.Name == sessionInfo.Name {
 			// this is a looped event, keep the cars
@@ -328,12 +475,22 @@ func (rc *RaceControl) OnNewSession(sessionInfo udp.SessionInfo) error {
 		rc.DisconnectedDrivers = NewDriverMap(DisconnectedDrivers, rc.SortDrivers)
 	}
 
+	// clear out the current sector of each driver, they may have been moved back to the pits.
+	_ = rc.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
+		driver.mutex.Lock()
+		defer driver.mutex.Unlock()
+
+		driver.resetSectorTiming()
+
+		return nil
+	})
+
 	// clear out last lap completed time each new session
 	_ = rc.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
 		driver.mutex.Lock()
 		defer driver.mutex.Unlock()
 
-		driver.CurrentCar().LastLapCompletedTime = time.Now()
+		driver.CurrentCar().LastLapCompletedTime = rc.now()
 
 		return nil
 	})
@@ -348,6 +505,17 @@ func (rc *RaceControl) OnNewSession(sessionInfo udp.SessionInfo) error {
 
 	rc.TrackInfo = *trackInfo
 
+	trackSectors, approximateTrackSectors, err := rc.trackDataGateway.TrackSectors(sessionInfo.Track, sessionInfo.TrackConfig)
+
+	if err != nil {
+		logrus.WithError(err).Errorf("Could not load track sectors, sectors will not be timed")
+		rc.TrackSectors = nil
+		rc.ApproximateTrackSectors = false
+	} else {
+		rc.TrackSectors = trackSectors
+		rc.ApproximateTrackSectors = approximateTrackSectors
+	}
+
 	trackMapData, err := rc.trackDataGateway.TrackMap(sessionInfo.Track, sessionInfo.TrackConfig)
 
 	if err != nil {
@@ -382,6 +550,8 @@ func (rc *RaceControl) OnNewSession(sessionInfo udp.SessionInfo) error {
 		logrus.WithError(err).Debugf("Could not load persisted live timings practice data")
 	}
 
+	rc.recalculateBestSectors()
+
 	_, err = rc.broadcaster.Send(sessionInfo)
 
 	return err
@@ -460,16 +630,27 @@ func (rc *RaceControl) disconnectDriver(driver *RaceControlDriver) error {
 	return rc.OnClientDisconnect(carInfo)
 }
 
+// currentSessionInfo returns a copy of the SessionInfo. It must be used instead of reading SessionInfo directly from
+// anywhere other than the UDP callback, as the UDP callback may be updating it.
+func (rc *RaceControl) currentSessionInfo() udp.SessionInfo {
+	rc.sessionInfoMutex.RLock()
+	defer rc.sessionInfoMutex.RUnlock()
+
+	return rc.SessionInfo
+}
+
 // OnSessionUpdate is called every sessionRequestInterval.
 func (rc *RaceControl) OnSessionUpdate(sessionInfo udp.SessionInfo) (bool, error) {
 	oldSessionInfo := rc.SessionInfo
 
 	// we can't just copy over the session information, we must copy individual
 	// parts of it, as the session type is incorrect.
+	rc.sessionInfoMutex.Lock()
 	rc.SessionInfo.AmbientTemp = sessionInfo.AmbientTemp
 	rc.SessionInfo.RoadTemp = sessionInfo.RoadTemp
 	rc.SessionInfo.WeatherGraphics = sessionInfo.WeatherGraphics
 	rc.SessionInfo.ElapsedMilliseconds = sessionInfo.ElapsedMilliseconds
+	rc.sessionInfoMutex.Unlock()
 
 	sessionHasChanged := oldSessionInfo.AmbientTemp != rc.SessionInfo.AmbientTemp || oldSessionInfo.RoadTemp != rc.SessionInfo.RoadTemp || oldSessionInfo.WeatherGraphics != rc.SessionInfo.WeatherGraphics
 
@@ -481,6 +662,19 @@ func (rc *RaceControl) OnEndSession(sessionFile udp.EndSession) error {
 	filename := filepath.Base(string(sessionFile))
 	logrus.Infof("End Session, file outputted at: %s", filename)
 
+	if rc.replaying {
+		// everything below changes the results file, which must not happen again when the session is replayed.
+		return nil
+	}
+
+	if err := rc.saveFullCourseYellows(filename); err != nil {
+		logrus.WithError(err).Errorf("Could not save full course yellows to results file: %s", filename)
+	}
+
+	if err := rc.savePitStops(filename); err != nil {
+		logrus.WithError(err).Errorf("Could not save pit stops to results file: %s", filename)
+	}
+
 	config := rc.process.Event().GetRaceConfig()
 
 	if config.DriverSwapEnabled == 1 {
@@ -532,7 +726,11 @@ func (rc *RaceControl) OnEndSession(sessionFile udp.EndSession) error {
 		}
 
 		for guid, penalty := range rc.driverSwapPenalties {
-			err := rc.penaltiesManager.applyPenalty(filename, string(guid), penalty.carModel, penalty.penalty.Seconds(), true)
+			err := rc.penaltiesManager.AddPenalty(filename, string(guid), penalty.carModel, &Penalty{
+				Type:   PenaltyTypeTime,
+				Time:   penalty.penalty,
+				Reason: "Driver Swap",
+			})
 
 			if err != nil {
 				logrus.WithError(err).Errorf("could not apply driver swap penalty of %s to driver %s", penalty.penalty.String(), guid)
@@ -541,6 +739,10 @@ func (rc *RaceControl) OnEndSession(sessionFile udp.EndSession) error {
 		}
 	}
 
+	if rc.SessionInfo.Type == udp.SessionTypeRace {
+		rc.applyTrackLimitsPenalties(filename)
+	}
+
 	if rc.currentTimeAttackEvent != nil && Premium() {
 		filename := filepath.Base(string(sessionFile))
 
@@ -619,6 +821,14 @@ func (rc *RaceControl) OnClientConnect(client udp.SessionCarInfo) error {
 
 	var driver *RaceControlDriver
 
+	if knownDriver, ok := rc.DisconnectedDrivers.Get(client.DriverGUID); ok && knownDriver.CarInfo.CarID != client.CarID {
+		// the driver has reconnected into a different car slot, their position history moves with them.
+		rc.gapTracker.moveCar(knownDriver.CarInfo.CarID, client.CarID)
+	} else {
+		// the history of the car is kept across driver swaps, but not if an unrelated driver has taken the slot.
+		rc.gapTracker.driverConnected(client.CarID, client.DriverGUID, rc.process.Event().GetRaceConfig().DriverSwapEnabled == 1)
+	}
+
 	if disconnectedDriver, ok := rc.DisconnectedDrivers.Get(client.DriverGUID); ok {
 		driver = disconnectedDriver
 		logrus.Debugf("Driver %s (%s) reconnected in %s (car id: %d)", driver.CarInfo.DriverName, driver.CarInfo.DriverGUID, driver.CarInfo.CarModel, client.CarID)
@@ -641,9 +851,10 @@ func (rc *RaceControl) OnClientConnect(client udp.SessionCarInfo) error {
 		driver.Cars[driver.CarInfo.CarModel] = NewRaceControlCarLapInfo(driver.CarInfo.CarModel)
 	}
 
-	driver.ConnectedTime = time.Now()
+	driver.ConnectedTime = rc.now()
 	driver.LastSeen = time.Time{}
-	driver.CurrentCar().LastLapCompletedTime = time.Now()
+	driver.CurrentCar().LastLapCompletedTime = rc.now()
+	driver.resetSectorTiming()
 
 	rc.ConnectedDrivers.Add(driver.CarInfo.DriverGUID, driver)
 
@@ -670,6 +881,11 @@ func (rc *RaceControl) OnClientDisconnect(client udp.SessionCarInfo) error {
 	logrus.Debugf("Driver %s (%s) disconnected", driver.CarInfo.DriverName, driver.CarInfo.DriverGUID)
 
 	driver.LoadedTime = time.Time{}
+	driver.BlueFlag = false
+	driver.SlowCar = false
+	driver.InPitLane = false
+
+	rc.flags.carLoaded(client.CarID)
 
 	rc.ConnectedDrivers.Del(driver.CarInfo.DriverGUID)
 
@@ -999,7 +1215,10 @@ func (rc *RaceControl) OnClientLoaded(loadedCar udp.ClientLoaded) error {
 
 	logrus.Debugf("Driver: %s (%s) loaded", driver.CarInfo.DriverName, driver.CarInfo.DriverGUID)
 
-	driver.LoadedTime = time.Now()
+	driver.LoadedTime = rc.now()
+
+	rc.flags.carLoaded(driver.CarInfo.CarID)
+	rc.pitStops.carLoaded(driver.CarInfo.CarID, driver.CarInfo.DriverGUID, driver.CarInfo.DriverName)
 
 	_, err = rc.broadcaster.Send(loadedCar)
 
@@ -1090,7 +1309,7 @@ func (rc *RaceControl) OnLapCompleted(lap udp.LapCompleted) error {
 	currentCar.TotalLapTime += lapDuration
 	currentCar.LastLap = lapDuration
 	currentCar.NumLaps++
-	currentCar.LastLapCompletedTime = time.Now()
+	currentCar.LastLapCompletedTime = rc.now()
 
 	if lap.Cuts == 0 && (lapDuration < currentCar.BestLap || currentCar.BestLap == 0) {
 		currentCar.BestLap = lapDuration
@@ -1099,9 +1318,31 @@ func (rc *RaceControl) OnLapCompleted(lap udp.LapCompleted) error {
 
 	currentCar.TopSpeedThisLap = 0
 
+	if sectorCompleted := rc.completeSectorsForLap(driver, lapDuration, int(lap.Cuts)); sectorCompleted != nil {
+		if _, err := rc.broadcaster.Send(sectorCompleted); err != nil {
+			logrus.WithError(err).Errorf("Could not broadcast sector completed message")
+		}
+	}
+
 	rc.ConnectedDrivers.sort()
 
+	lapHistory := rc.history.lapCompleted(driver.CarInfo.DriverGUID, lap.CarID, driver.CarInfo.CarModel, driver.TotalNumLaps, driver.Position, lapDuration, int(lap.Cuts))
+
+	if _, err := rc.broadcaster.Send(lapHistory); err != nil {
+		logrus.WithError(err).Errorf("Could not broadcast lap history message")
+	}
+
 	if rc.SessionInfo.Type == udp.SessionTypeRace {
+		rc.gapTracker.lapCompleted(lap.CarID, lap.Cars)
+
+		trackLimitsMessage := rc.trackLimits.lapCompleted(rc.process.Event().GetRaceConfig().TrackLimits, driver.CarInfo.DriverGUID, driver.CarInfo.CarModel, int(lap.Cuts))
+
+		if trackLimitsMessage != "" {
+			if err := rc.splitAndSendChat(trackLimitsMessage, string(driver.CarInfo.DriverGUID)); err != nil {
+				logrus.WithError(err).Errorf("Could not send track limits message to driver: %s", driver.CarInfo.DriverGUID)
+			}
+		}
+
 		// calculate split
 		if driver.Position == 1 {
 			driver.Split = time.Duration(0).String()
@@ -1179,7 +1420,7 @@ func (rc *RaceControl) OnChatMessage(chat udp.Chat) error {
 
 	rc.ChatMessagesMutex.Unlock()
 
-	if config.Lua.Enabled && Premium() {
+	if config.Lua.Enabled && Premium() && !rc.replaying {
 		go func() {
 			err := chatMessagePlugin(chat)
 
@@ -1256,7 +1497,7 @@ func (rc *RaceControl) OnCollisionWithCar(collision udp.CollisionWithCar) error
 	c := Collision{
 		ID:    uuid.New().String(),
 		Type:  CollisionWithCar,
-		Time:  time.Now(),
+		Time:  rc.now(),
 		Speed: metersPerSecondToKilometersPerHour(float64(collision.ImpactSpeed)),
 	}
 
@@ -1291,7 +1532,7 @@ func (rc *RaceControl) OnCollisionWithEnvironment(collision udp.CollisionWithEnv
 	driver.Collisions = append(driver.Collisions, Collision{
 		ID:    uuid.New().String(),
 		Type:  CollisionWithEnvironment,
-		Time:  time.Now(),
+		Time:  rc.now(),
 		Speed: metersPerSecondToKilometersPerHour(float64(collision.ImpactSpeed)),
 	})
 
@@ -0,0 +1,478 @@
+package servermanager
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+// RaceControlAPIVersion is the version of the Race Control API. The types in this file are part of the API, so any
+// change to them which is not backwards compatible must come with a new version of the API.
+//
+// Unlike the Live Timings websocket (which is built for the Live Timings page, and changes whenever it does), the
+// Race Control API is intended for overlays, bots and other external tools. It is made up of:
+//
+//	GET /api/v1/race-control         a RaceControlAPISnapshot of the current session, as JSON.
+//	GET /api/v1/race-control/events  a stream of RaceControlAPIEvents, as Server-Sent Events.
+//
+// The events stream can be filtered with a comma separated list of event types in the 'events' query parameter,
+// e.g. ?events=lap,collision. All event types are sent if no filter is given. Each Server-Sent Event has its
+// 'event' field set to the event type, and its 'data' field set to the RaceControlAPIEvent as JSON.
+//
+// Both endpoints require one of the API keys configured in the Server Options, either in the 'X-API-Key' header or
+// the 'api_key' query parameter. The API is disabled if no keys are configured.
+const RaceControlAPIVersion = 1
+
+// raceControlAPISubscriberBufferSize is the number of events which can be waiting to be sent to a subscriber
+// before it is considered too slow, and disconnected.
+const raceControlAPISubscriberBufferSize = 100
+
+var ErrRaceControlAPIUnknownEventType = errors.New("servermanager: unknown race control api event type")
+
+type RaceControlAPIEventType string
+
+const (
+	RaceControlAPIEventLap       RaceControlAPIEventType = "lap"
+	RaceControlAPIEventCollision RaceControlAPIEventType = "collision"
+	RaceControlAPIEventChat      RaceControlAPIEventType = "chat"
+	RaceControlAPIEventSession   RaceControlAPIEventType = "session"
+)
+
+var RaceControlAPIEventTypes = []RaceControlAPIEventType{
+	RaceControlAPIEventLap,
+	RaceControlAPIEventCollision,
+	RaceControlAPIEventChat,
+	RaceControlAPIEventSession,
+}
+
+// parseRaceControlAPIEventTypes parses a comma separated list of event types. An empty list means all event types.
+func parseRaceControlAPIEventTypes(list string) (map[RaceControlAPIEventType]bool, error) {
+	eventTypes := make(map[RaceControlAPIEventType]bool)
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name == "" {
+			continue
+		}
+
+		eventType := RaceControlAPIEventType(name)
+		known := false
+
+		for _, t := range RaceControlAPIEventTypes {
+			if t == eventType {
+				known = true
+				break
+			}
+		}
+
+		if !known {
+			return nil, fmt.Errorf("%w: %s", ErrRaceControlAPIUnknownEventType, name)
+		}
+
+		eventTypes[eventType] = true
+	}
+
+	if len(eventTypes) == 0 {
+		for _, t := range RaceControlAPIEventTypes {
+			eventTypes[t] = true
+		}
+	}
+
+	return eventTypes, nil
+}
+
+// RaceControlAPISession describes a session. Time is the length of the session in minutes, and Laps is the number
+// of laps in the session. Only one of them is set.
+type RaceControlAPISession struct {
+	ServerName  string `json:"ServerName"`
+	Name        string `json:"Name"`
+	Type        string `json:"Type"`
+	Track       string `json:"Track"`
+	TrackLayout string `json:"TrackLayout"`
+	Time        int    `json:"Time"`
+	Laps        int    `json:"Laps"`
+	AmbientTemp int    `json:"AmbientTemp"`
+	RoadTemp    int    `json:"RoadTemp"`
+	Weather     string `json:"Weather"`
+
+	StartTime time.Time `json:"StartTime"`
+}
+
+// RaceControlAPIDriver is a driver in the current session. All times are in milliseconds. GapToLeader, Interval
+// and LapsDown are only calculated in race sessions, and InPitLane and PitStops are only known if the event has
+// its pit lane configured.
+type RaceControlAPIDriver struct {
+	GUID      udp.DriverGUID `json:"GUID"`
+	Name      string         `json:"Name"`
+	CarID     udp.CarID      `json:"CarID"`
+	CarModel  string         `json:"CarModel"`
+	CarSkin   string         `json:"CarSkin"`
+	Connected bool           `json:"Connected"`
+
+	Position    int   `json:"Position"`
+	Laps        int   `json:"Laps"`
+	BestLap     int64 `json:"BestLap"`
+	LastLap     int64 `json:"LastLap"`
+	GapToLeader int64 `json:"GapToLeader"`
+	Interval    int64 `json:"Interval"`
+	LapsDown    int   `json:"LapsDown"`
+	InPitLane   bool  `json:"InPitLane"`
+	PitStops    int   `json:"PitStops"`
+}
+
+// RaceControlAPISnapshot is the state of the current session. Drivers are in position order, followed by any
+// drivers who have disconnected from the session.
+type RaceControlAPISnapshot struct {
+	Version int       `json:"Version"`
+	Time    time.Time `json:"Time"`
+
+	Session RaceControlAPISession  `json:"Session"`
+	Drivers []RaceControlAPIDriver `json:"Drivers"`
+}
+
+// RaceControlAPILap is a lap completed by a driver. LapTime is in milliseconds.
+type RaceControlAPILap struct {
+	Driver   RaceControlAPIDriver `json:"Driver"`
+	Lap      int                  `json:"Lap"`
+	LapTime  int64                `json:"LapTime"`
+	Cuts     int                  `json:"Cuts"`
+	Position int                  `json:"Position"`
+}
+
+// RaceControlAPICollision is a collision between a driver and either another car or the environment. OtherDriver
+// is only set for collisions with another car. ImpactSpeed is in km/h.
+type RaceControlAPICollision struct {
+	Type        CollisionType         `json:"Type"`
+	Driver      RaceControlAPIDriver  `json:"Driver"`
+	OtherDriver *RaceControlAPIDriver `json:"OtherDriver"`
+	ImpactSpeed float64               `json:"ImpactSpeed"`
+	WorldPos    udp.Vec               `json:"WorldPos"`
+}
+
+// RaceControlAPIChat is a chat message. DriverGUID is "0" for messages sent by the server.
+type RaceControlAPIChat struct {
+	DriverGUID udp.DriverGUID `json:"DriverGUID"`
+	DriverName string         `json:"DriverName"`
+	CarID      udp.CarID      `json:"CarID"`
+	Message    string         `json:"Message"`
+}
+
+type RaceControlAPISessionChange string
+
+const (
+	RaceControlAPISessionStarted RaceControlAPISessionChange = "started"
+	RaceControlAPISessionEnded   RaceControlAPISessionChange = "ended"
+)
+
+// RaceControlAPISessionEvent is sent when a session starts or ends. ResultsFile is the name of the session's
+// results file, and is only set when the session has ended.
+type RaceControlAPISessionEvent struct {
+	Change      RaceControlAPISessionChange `json:"Change"`
+	Session     RaceControlAPISession       `json:"Session"`
+	ResultsFile string                      `json:"ResultsFile"`
+}
+
+// RaceControlAPIEvent is a single event in the Race Control API events stream. Only the field matching the Type of
+// the event is set.
+type RaceControlAPIEvent struct {
+	Version int                     `json:"Version"`
+	ID      uint64                  `json:"ID"`
+	Type    RaceControlAPIEventType `json:"Type"`
+	Time    time.Time               `json:"Time"`
+
+	Lap       *RaceControlAPILap          `json:"Lap,omitempty"`
+	Collision *RaceControlAPICollision    `json:"Collision,omitempty"`
+	Chat      *RaceControlAPIChat         `json:"Chat,omitempty"`
+	Session   *RaceControlAPISessionEvent `json:"Session,omitempty"`
+}
+
+type raceControlAPISubscriber struct {
+	eventTypes map[RaceControlAPIEventType]bool
+	events     chan *RaceControlAPIEvent
+}
+
+// RaceControlAPI turns the UDP messages handled by RaceControl into RaceControlAPIEvents, and sends them to
+// everyone subscribed to the events stream.
+type RaceControlAPI struct {
+	store       Store
+	raceControl *RaceControl
+
+	subscribers map[*raceControlAPISubscriber]bool
+	lastEventID uint64
+	mutex       sync.Mutex
+}
+
+func NewRaceControlAPI(store Store, raceControl *RaceControl) *RaceControlAPI {
+	return &RaceControlAPI{
+		store:       store,
+		raceControl: raceControl,
+		subscribers: make(map[*raceControlAPISubscriber]bool),
+	}
+}
+
+// UDPCallback must be called after RaceControl has handled the message, so that the drivers' laps and positions
+// are up to date.
+func (api *RaceControlAPI) UDPCallback(message udp.Message) {
+	var event *RaceControlAPIEvent
+
+	switch m := message.(type) {
+	case udp.LapCompleted:
+		event = api.lapEvent(m)
+	case udp.CollisionWithCar:
+		event = api.collisionEvent(CollisionWithCar, m.CarID, &m.OtherCarID, m.ImpactSpeed, m.WorldPos)
+	case udp.CollisionWithEnvironment:
+		event = api.collisionEvent(CollisionWithEnvironment, m.CarID, nil, m.ImpactSpeed, m.WorldPos)
+	case udp.Chat:
+		event = api.chatEvent(m)
+	case udp.SessionInfo:
+		if m.Event() == udp.EventNewSession {
+			event = &RaceControlAPIEvent{
+				Type: RaceControlAPIEventSession,
+				Session: &RaceControlAPISessionEvent{
+					Change:  RaceControlAPISessionStarted,
+					Session: api.session(),
+				},
+			}
+		}
+	case udp.EndSession:
+		event = &RaceControlAPIEvent{
+			Type: RaceControlAPIEventSession,
+			Session: &RaceControlAPISessionEvent{
+				Change:      RaceControlAPISessionEnded,
+				Session:     api.session(),
+				ResultsFile: filepath.Base(string(m)),
+			},
+		}
+	}
+
+	if event != nil {
+		api.publish(event)
+	}
+}
+
+func (api *RaceControlAPI) lapEvent(lap udp.LapCompleted) *RaceControlAPIEvent {
+	driver, err := api.raceControl.findConnectedDriverByCarID(lap.CarID)
+
+	if err != nil {
+		return nil
+	}
+
+	apiDriver := raceControlAPIDriver(driver, true)
+
+	return &RaceControlAPIEvent{
+		Type: RaceControlAPIEventLap,
+		Lap: &RaceControlAPILap{
+			Driver:   apiDriver,
+			Lap:      apiDriver.Laps,
+			LapTime:  int64(lap.LapTime),
+			Cuts:     int(lap.Cuts),
+			Position: apiDriver.Position,
+		},
+	}
+}
+
+func (api *RaceControlAPI) collisionEvent(collisionType CollisionType, carID udp.CarID, otherCarID *udp.CarID, impactSpeed float32, worldPos udp.Vec) *RaceControlAPIEvent {
+	driver, err := api.raceControl.findConnectedDriverByCarID(carID)
+
+	if err != nil {
+		return nil
+	}
+
+	collision := &RaceControlAPICollision{
+		Type:        collisionType,
+		Driver:      raceControlAPIDriver(driver, true),
+		ImpactSpeed: float64(impactSpeed),
+		WorldPos:    worldPos,
+	}
+
+	if otherCarID != nil {
+		otherDriver, err := api.raceControl.findConnectedDriverByCarID(*otherCarID)
+
+		if err != nil {
+			return nil
+		}
+
+		apiOtherDriver := raceControlAPIDriver(otherDriver, true)
+		collision.OtherDriver = &apiOtherDriver
+	}
+
+	return &RaceControlAPIEvent{
+		Type:      RaceControlAPIEventCollision,
+		Collision: collision,
+	}
+}
+
+func (api *RaceControlAPI) chatEvent(chat udp.Chat) *RaceControlAPIEvent {
+	apiChat := &RaceControlAPIChat{
+		CarID:      chat.CarID,
+		Message:    chat.Message,
+		DriverGUID: "0",
+		DriverName: "Server",
+	}
+
+	if driver, err := api.raceControl.findConnectedDriverByCarID(chat.CarID); err == nil {
+		driver.mutex.Lock()
+		apiChat.DriverGUID = driver.CarInfo.DriverGUID
+		apiChat.DriverName = driver.CarInfo.DriverName
+		driver.mutex.Unlock()
+	} else if chat.DriverGUID != "" || chat.DriverName != "" {
+		apiChat.DriverGUID = chat.DriverGUID
+		apiChat.DriverName = chat.DriverName
+	}
+
+	return &RaceControlAPIEvent{
+		Type: RaceControlAPIEventChat,
+		Chat: apiChat,
+	}
+}
+
+func (api *RaceControlAPI) session() RaceControlAPISession {
+	rc := api.raceControl
+	sessionInfo := rc.currentSessionInfo()
+
+	return RaceControlAPISession{
+		ServerName:  sessionInfo.ServerName,
+		Name:        sessionInfo.Name,
+		Type:        sessionInfo.Type.String(),
+		Track:       sessionInfo.Track,
+		TrackLayout: sessionInfo.TrackConfig,
+		Time:        int(sessionInfo.Time),
+		Laps:        int(sessionInfo.Laps),
+		AmbientTemp: int(sessionInfo.AmbientTemp),
+		RoadTemp:    int(sessionInfo.RoadTemp),
+		Weather:     sessionInfo.WeatherGraphics,
+		StartTime:   rc.SessionStartTime,
+	}
+}
+
+// Snapshot returns the current state of the session.
+func (api *RaceControlAPI) Snapshot() *RaceControlAPISnapshot {
+	snapshot := &RaceControlAPISnapshot{
+		Version: RaceControlAPIVersion,
+		Time:    api.raceControl.now(),
+		Session: api.session(),
+		Drivers: []RaceControlAPIDriver{},
+	}
+
+	_ = api.raceControl.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
+		snapshot.Drivers = append(snapshot.Drivers, raceControlAPIDriver(driver, true))
+		return nil
+	})
+
+	_ = api.raceControl.DisconnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
+		snapshot.Drivers = append(snapshot.Drivers, raceControlAPIDriver(driver, false))
+		return nil
+	})
+
+	return snapshot
+}
+
+func raceControlAPIDriver(driver *RaceControlDriver, connected bool) RaceControlAPIDriver {
+	driver.mutex.Lock()
+	defer driver.mutex.Unlock()
+
+	car := driver.CurrentCar()
+
+	return RaceControlAPIDriver{
+		GUID:        driver.CarInfo.DriverGUID,
+		Name:        driver.CarInfo.DriverName,
+		CarID:       driver.CarInfo.CarID,
+		CarModel:    driver.CarInfo.CarModel,
+		CarSkin:     driver.CarInfo.CarSkin,
+		Connected:   connected,
+		Position:    driver.Position,
+		Laps:        car.NumLaps,
+		BestLap:     durationToMilliseconds(car.BestLap),
+		LastLap:     durationToMilliseconds(car.LastLap),
+		GapToLeader: durationToMilliseconds(driver.GapToLeader),
+		Interval:    durationToMilliseconds(driver.Interval),
+		LapsDown:    driver.LapsDown,
+		InPitLane:   driver.InPitLane,
+		PitStops:    driver.PitStops,
+	}
+}
+
+func durationToMilliseconds(d time.Duration) int64 {
+	return int64(d / time.Millisecond)
+}
+
+func (api *RaceControlAPI) publish(event *RaceControlAPIEvent) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	api.lastEventID++
+
+	event.Version = RaceControlAPIVersion
+	event.ID = api.lastEventID
+	event.Time = api.raceControl.now()
+
+	for subscriber := range api.subscribers {
+		if !subscriber.eventTypes[event.Type] {
+			continue
+		}
+
+		select {
+		case subscriber.events <- event:
+		default:
+			// the subscriber isn't keeping up, disconnect them rather than holding up the UDP messages.
+			close(subscriber.events)
+			delete(api.subscribers, subscriber)
+		}
+	}
+}
+
+// subscribe returns a subscriber which is sent every event of the given types. The subscriber's events channel is
+// closed if they are disconnected for not keeping up with events.
+func (api *RaceControlAPI) subscribe(eventTypes map[RaceControlAPIEventType]bool) *raceControlAPISubscriber {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	subscriber := &raceControlAPISubscriber{
+		eventTypes: eventTypes,
+		events:     make(chan *RaceControlAPIEvent, raceControlAPISubscriberBufferSize),
+	}
+
+	api.subscribers[subscriber] = true
+
+	return subscriber
+}
+
+func (api *RaceControlAPI) unsubscribe(subscriber *raceControlAPISubscriber) {
+	api.mutex.Lock()
+	defer api.mutex.Unlock()
+
+	if _, ok := api.subscribers[subscriber]; ok {
+		close(subscriber.events)
+		delete(api.subscribers, subscriber)
+	}
+}
+
+// validKey reports whether key is one of the API keys configured in the server options.
+func (api *RaceControlAPI) validKey(key string) (enabled bool, valid bool, err error) {
+	opts, err := api.store.LoadServerOptions()
+
+	if err != nil {
+		return false, false, err
+	}
+
+	keys := opts.RaceControlAPIKeyList()
+
+	if len(keys) == 0 {
+		return false, false, nil
+	}
+
+	for _, k := range keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			valid = true
+		}
+	}
+
+	return true, valid, nil
+}
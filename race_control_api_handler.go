@@ -0,0 +1,148 @@
+package servermanager
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// raceControlAPIKeepAliveInterval is how often a comment is sent to Race Control API events stream subscribers, so
+// that proxies do not close the connection while there are no events.
+var raceControlAPIKeepAliveInterval = time.Second * 15
+
+type RaceControlAPIHandler struct {
+	raceControlAPI *RaceControlAPI
+}
+
+func NewRaceControlAPIHandler(raceControlAPI *RaceControlAPI) *RaceControlAPIHandler {
+	return &RaceControlAPIHandler{
+		raceControlAPI: raceControlAPI,
+	}
+}
+
+// KeyMiddleware only allows requests with a valid Race Control API key through. The API is hidden if no keys have
+// been configured.
+func (rah *RaceControlAPIHandler) KeyMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-API-Key")
+
+		if key == "" {
+			key = r.URL.Query().Get("api_key")
+		}
+
+		enabled, valid, err := rah.raceControlAPI.validKey(key)
+
+		if err != nil {
+			logrus.WithError(err).Error("couldn't load server options")
+			writeRaceControlAPIError(w, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			return
+		}
+
+		if !enabled {
+			http.NotFound(w, r)
+			return
+		}
+
+		if !valid {
+			writeRaceControlAPIError(w, http.StatusUnauthorized, "invalid or missing api key")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
+
+type raceControlAPIError struct {
+	Version int    `json:"Version"`
+	Error   string `json:"Error"`
+}
+
+func writeRaceControlAPIError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(raceControlAPIError{
+		Version: RaceControlAPIVersion,
+		Error:   message,
+	})
+}
+
+// snapshot serves the current state of the session as JSON.
+func (rah *RaceControlAPIHandler) snapshot(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if err := json.NewEncoder(w).Encode(rah.raceControlAPI.Snapshot()); err != nil {
+		logrus.WithError(err).Error("couldn't encode race control api snapshot")
+	}
+}
+
+// events streams RaceControlAPIEvents to the client as Server-Sent Events until they disconnect.
+func (rah *RaceControlAPIHandler) events(w http.ResponseWriter, r *http.Request) {
+	eventTypes, err := parseRaceControlAPIEventTypes(r.URL.Query().Get("events"))
+
+	if err != nil {
+		writeRaceControlAPIError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		writeRaceControlAPIError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	subscriber := rah.raceControlAPI.subscribe(eventTypes)
+	defer rah.raceControlAPI.unsubscribe(subscriber)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(raceControlAPIKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-subscriber.events:
+			if !ok {
+				// the subscriber was disconnected for not keeping up.
+				return
+			}
+
+			if err := writeServerSentEvent(w, event); err != nil {
+				logrus.WithError(err).Debug("couldn't send race control api event")
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
+
+func writeServerSentEvent(w http.ResponseWriter, event *RaceControlAPIEvent) error {
+	data, err := json.Marshal(event)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+
+	return err
+}
@@ -0,0 +1,185 @@
+package servermanager
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+func TestParseRaceControlAPIEventTypes(t *testing.T) {
+	t.Run("All event types are sent when no filter is given", func(t *testing.T) {
+		eventTypes, err := parseRaceControlAPIEventTypes("")
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(eventTypes) != len(RaceControlAPIEventTypes) {
+			t.Errorf("Expected all event types, got: %v", eventTypes)
+		}
+	})
+
+	t.Run("Filtered event types", func(t *testing.T) {
+		eventTypes, err := parseRaceControlAPIEventTypes("lap, Collision")
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(eventTypes) != 2 || !eventTypes[RaceControlAPIEventLap] || !eventTypes[RaceControlAPIEventCollision] {
+			t.Errorf("Expected lap and collision events, got: %v", eventTypes)
+		}
+	})
+
+	t.Run("Unknown event types", func(t *testing.T) {
+		if _, err := parseRaceControlAPIEventTypes("lap,overtake"); !errors.Is(err, ErrRaceControlAPIUnknownEventType) {
+			t.Errorf("Expected unknown event type error, got: %v", err)
+		}
+	})
+}
+
+func TestRaceControlAPI_UDPCallback(t *testing.T) {
+	raceControl := newRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
+	api := NewRaceControlAPI(testStore, raceControl)
+
+	messages := []udp.Message{
+		udp.SessionInfo{Name: "Race", Type: udp.SessionTypeRace, Track: "ks_laguna_seca", Laps: 10, EventType: udp.EventNewSession},
+		drivers[0],
+		drivers[1],
+	}
+
+	for _, message := range messages {
+		raceControl.UDPCallback(message)
+	}
+
+	subscriber := api.subscribe(map[RaceControlAPIEventType]bool{RaceControlAPIEventLap: true, RaceControlAPIEventCollision: true})
+	defer api.unsubscribe(subscriber)
+
+	messages = []udp.Message{
+		udp.Chat{CarID: drivers[0].CarID, Message: "hello"},
+		udp.LapCompleted{CarID: drivers[0].CarID, LapTime: 95000, Cuts: 1},
+		udp.CollisionWithCar{CarID: drivers[0].CarID, OtherCarID: drivers[1].CarID, ImpactSpeed: 40},
+	}
+
+	for _, message := range messages {
+		raceControl.UDPCallback(message)
+		api.UDPCallback(message)
+	}
+
+	if len(subscriber.events) != 2 {
+		t.Errorf("Expected 2 events, got %d", len(subscriber.events))
+		return
+	}
+
+	lap := <-subscriber.events
+
+	if lap.Type != RaceControlAPIEventLap || lap.Lap == nil || lap.Version != RaceControlAPIVersion {
+		t.Errorf("Expected a lap event, got: %+v", lap)
+		return
+	}
+
+	if lap.Lap.Driver.GUID != drivers[0].DriverGUID || lap.Lap.LapTime != 95000 || lap.Lap.Cuts != 1 || lap.Lap.Lap != 1 {
+		t.Errorf("Incorrect lap: %+v", lap.Lap)
+	}
+
+	collision := <-subscriber.events
+
+	if collision.Type != RaceControlAPIEventCollision || collision.Collision.OtherDriver == nil || collision.Collision.OtherDriver.GUID != drivers[1].DriverGUID {
+		t.Errorf("Expected a collision with another car, got: %+v", collision.Collision)
+	}
+
+	if collision.ID <= lap.ID {
+		t.Errorf("Expected event IDs to increase, got %d then %d", lap.ID, collision.ID)
+	}
+
+	snapshot := api.Snapshot()
+
+	if snapshot.Session.Type != "Race" || len(snapshot.Drivers) != 2 || snapshot.Drivers[0].LastLap != 95000 {
+		t.Errorf("Incorrect snapshot: %+v", snapshot)
+	}
+}
+
+func TestRaceControlAPIHandler_KeyMiddleware(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asm-race-control-api")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	defer os.RemoveAll(dir)
+
+	store := NewJSONStore(dir, dir)
+	raceControl := newRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, store, NewPenaltiesManager(store, NewResultsRevisionManager(store, NewResultsIndex(store))), NewResultsRevisionManager(store, NewResultsIndex(store)))
+	handler := NewRaceControlAPIHandler(NewRaceControlAPI(store, raceControl))
+	router := handler.KeyMiddleware(http.HandlerFunc(handler.snapshot))
+
+	request := func(key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/race-control", nil)
+
+		if key != "" {
+			r.Header.Set("X-API-Key", key)
+		}
+
+		router.ServeHTTP(w, r)
+
+		return w
+	}
+
+	t.Run("The API is disabled when there are no keys", func(t *testing.T) {
+		if w := request("secret"); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	opts, err := store.LoadServerOptions()
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	opts.RaceControlAPIKeys = "overlay, bot"
+
+	if err := store.UpsertServerOptions(opts); err != nil {
+		t.Error(err)
+		return
+	}
+
+	t.Run("Requests without a valid key are rejected", func(t *testing.T) {
+		for _, key := range []string{"", "not-a-key"} {
+			if w := request(key); w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status %d for key %q, got %d", http.StatusUnauthorized, key, w.Code)
+			}
+		}
+	})
+
+	t.Run("Requests with a valid key get a snapshot", func(t *testing.T) {
+		w := request("bot")
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+			return
+		}
+
+		var snapshot RaceControlAPISnapshot
+
+		if err := json.NewDecoder(w.Body).Decode(&snapshot); err != nil {
+			t.Error(err)
+			return
+		}
+
+		if snapshot.Version != RaceControlAPIVersion {
+			t.Errorf("Expected version %d, got %d", RaceControlAPIVersion, snapshot.Version)
+		}
+	})
+}
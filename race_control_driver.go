@@ -16,6 +16,8 @@ func NewRaceControlDriver(carInfo udp.SessionCarInfo) *RaceControlDriver {
 		CarInfo:  carInfo,
 		Cars:     make(map[string]*RaceControlCarLapInfo),
 		LastSeen: time.Now(),
+
+		currentSector: -1,
 	}
 
 	driver.Cars[carInfo.CarModel] = NewRaceControlCarLapInfo(carInfo.CarModel)
@@ -41,11 +43,36 @@ type RaceControlDriver struct {
 	LastSeen time.Time `json:"LastSeen" ts:"date"`
 	LastPos  udp.Vec   `json:"LastPos"`
 
+	// GapToLeader, Interval and LapsDown are only calculated in race sessions.
+	GapToLeader time.Duration `json:"GapToLeader"`
+	Interval    time.Duration `json:"Interval"`
+	LapsDown    int           `json:"LapsDown"`
+
+	// BlueFlag is true while a car that is lapping the driver is close behind them. SlowCar is true while the
+	// driver's car is stopped or crawling on track, and a yellow flag is being shown for it.
+	BlueFlag bool `json:"BlueFlag"`
+	SlowCar  bool `json:"SlowCar"`
+
+	// InPitLane and PitStops are only known if the event has a PitLaneConfig. PitStops is the number of stops made
+	// by the driver's car.
+	InPitLane bool `json:"InPitLane"`
+	PitStops  int  `json:"PitStops"`
+
 	Collisions []Collision `json:"Collisions"`
 
 	driverSwapContext context.Context
 	driverSwapCfn     context.CancelFunc
 
+	// splinePos and speed (in km/h) are taken from the most recent car update.
+	splinePos float32
+	speed     float64
+
+	// sector timing
+	currentSector          int
+	currentSectorStartTime time.Time
+	lastSplinePos          float32
+	lastSplinePosTime      time.Time
+
 	// Cars is a map of CarModel to the information for that car.
 	Cars map[string]*RaceControlCarLapInfo `json:"Cars"`
 
@@ -70,6 +97,15 @@ type RaceControlCarLapInfo struct {
 	LastLapCompletedTime time.Time     `json:"LastLapCompletedTime" ts:"date"`
 	TotalLapTime         time.Duration `json:"TotalLapTime"`
 	CarName              string        `json:"CarName"`
+
+	// CurrentLapSectors are the sectors completed so far in the current lap. LastLapSectors are the sectors of
+	// the previous lap, and BestSectors are the driver's personal best time for each sector in this car.
+	CurrentLapSectors []time.Duration `json:"CurrentLapSectors"`
+	LastLapSectors    []time.Duration `json:"LastLapSectors"`
+	BestSectors       []time.Duration `json:"BestSectors"`
+
+	// bestSectorsAtLapStart is used to revert BestSectors if the lap turns out to have cuts.
+	bestSectorsAtLapStart []time.Duration
 }
 
 type DriverMap struct {
@@ -0,0 +1,470 @@
+package servermanager
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+type RaceControlFlagType string
+
+const (
+	// RaceControlFlagBlue is shown to a car that is about to be lapped.
+	RaceControlFlagBlue RaceControlFlagType = "Blue"
+	// RaceControlFlagYellow is shown for a car which is stopped or crawling on track.
+	RaceControlFlagYellow RaceControlFlagType = "Yellow"
+)
+
+var (
+	flagsCheckInterval = time.Second
+
+	// blueFlagGap is how close (in time) a car must be to a car that it is lapping for a blue flag to be shown.
+	blueFlagGap = 2 * time.Second
+
+	// slowCarSpeed is the speed (in km/h) below which a car is considered slow. A car must be slow for at least
+	// slowCarDuration before a yellow flag is shown. Cars which have not been faster than slowCarSpeed since they
+	// were loaded (or returned to the pits) are ignored, so that cars in the pits and on the grid are not flagged.
+	slowCarSpeed    = 30.0
+	slowCarDuration = 3 * time.Second
+
+	// yellowFlagDistance is the distance (in metres) from a slow car that approaching drivers are warned at.
+	yellowFlagDistance = 300.0
+
+	// flagMessageInterval is the minimum time between repeated chat messages about the same flag.
+	flagMessageInterval = 30 * time.Second
+
+	// flagStaleTime is the time after which a car that has not sent an update is no longer checked for flags.
+	flagStaleTime = 10 * time.Second
+)
+
+// teleportMinimumDistance is the distance (in metres) a car must move further than its speed would allow between
+// two updates to be considered to have been returned to the pits.
+const teleportMinimumDistance = 50.0
+
+// RaceControlFlag is broadcast when a flag is shown or withdrawn. For blue flags, the car is the car that is being
+// lapped and the other car is the car lapping it. For yellow flags, the car is the slow car.
+type RaceControlFlag struct {
+	Type   RaceControlFlagType `json:"Type"`
+	Active bool                `json:"Active"`
+	Time   time.Time           `json:"Time" ts:"date"`
+
+	CarID      udp.CarID      `json:"CarID"`
+	DriverGUID udp.DriverGUID `json:"DriverGUID"`
+	DriverName string         `json:"DriverName"`
+
+	OtherCarID      udp.CarID      `json:"OtherCarID"`
+	OtherDriverGUID udp.DriverGUID `json:"OtherDriverGUID"`
+	OtherDriverName string         `json:"OtherDriverName"`
+
+	WorldPos udp.Vec `json:"WorldPos"`
+}
+
+func (RaceControlFlag) Event() udp.Event {
+	return EventRaceControlFlag
+}
+
+type carFlagState struct {
+	pos        udp.Vec
+	splinePos  float32
+	speed      float64
+	lastUpdate time.Time
+
+	// onTrack is true once the car has been faster than slowCarSpeed since it was loaded.
+	onTrack   bool
+	slowSince time.Time
+	slow      bool
+
+	// lappedBy is the car that the blue flag is currently being shown for, if any.
+	lappedBy    udp.CarID
+	hasBlueFlag bool
+}
+
+type flagMessageKey struct {
+	flag       RaceControlFlagType
+	carID      udp.CarID
+	otherCarID udp.CarID
+}
+
+// raceFlagTracker detects slow cars and keeps track of the flags shown to each car.
+type raceFlagTracker struct {
+	cars         map[udp.CarID]*carFlagState
+	messagesSent map[flagMessageKey]time.Time
+	mutex        sync.Mutex
+}
+
+func newRaceFlagTracker() *raceFlagTracker {
+	return &raceFlagTracker{
+		cars:         make(map[udp.CarID]*carFlagState),
+		messagesSent: make(map[flagMessageKey]time.Time),
+	}
+}
+
+func (t *raceFlagTracker) reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.cars = make(map[udp.CarID]*carFlagState)
+	t.messagesSent = make(map[flagMessageKey]time.Time)
+}
+
+// carLoaded forgets the state of a car, e.g. when it has been (re)loaded into the pits.
+func (t *raceFlagTracker) carLoaded(carID udp.CarID) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	delete(t.cars, carID)
+}
+
+// update records the position and speed (in km/h) of a car. Cars in the pit lane are not on track, so are never
+// slow cars.
+func (t *raceFlagTracker) update(carID udp.CarID, pos udp.Vec, splinePos float32, speed float64, inPitLane bool, updateTime time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	car, ok := t.cars[carID]
+
+	if !ok {
+		car = &carFlagState{}
+		t.cars[carID] = car
+	} else if !car.lastUpdate.IsZero() {
+		elapsed := updateTime.Sub(car.lastUpdate).Seconds()
+		maxTravel := math.Max(car.speed, speed) / 3.6 * elapsed * 2
+
+		if worldDistance(car.pos, pos) > maxTravel+teleportMinimumDistance {
+			// the car has been returned to the pits.
+			car.onTrack = false
+		}
+	}
+
+	car.pos = pos
+	car.splinePos = splinePos
+	car.speed = speed
+	car.lastUpdate = updateTime
+
+	if speed >= slowCarSpeed {
+		car.onTrack = true
+		car.slowSince = time.Time{}
+	} else if car.slowSince.IsZero() {
+		car.slowSince = updateTime
+	}
+
+	if inPitLane {
+		car.onTrack = false
+		car.slowSince = time.Time{}
+	}
+}
+
+// checkSlowCars returns the cars which have become slow and the cars which are no longer slow.
+func (t *raceFlagTracker) checkSlowCars(now time.Time) (slow, cleared []udp.CarID) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for carID, car := range t.cars {
+		isSlow := car.onTrack && now.Sub(car.lastUpdate) < flagStaleTime && !car.slowSince.IsZero() && now.Sub(car.slowSince) >= slowCarDuration
+
+		if isSlow && !car.slow {
+			slow = append(slow, carID)
+		} else if !isSlow && car.slow {
+			cleared = append(cleared, carID)
+		}
+
+		car.slow = isSlow
+	}
+
+	return slow, cleared
+}
+
+// approachingCars returns the cars on track which are within yellowFlagDistance behind the given car.
+func (t *raceFlagTracker) approachingCars(carID udp.CarID, now time.Time) []udp.CarID {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	slowCar, ok := t.cars[carID]
+
+	if !ok {
+		return nil
+	}
+
+	var approaching []udp.CarID
+
+	for otherCarID, car := range t.cars {
+		if otherCarID == carID || !car.onTrack || car.slow || now.Sub(car.lastUpdate) >= flagStaleTime {
+			continue
+		}
+
+		// how far round the lap the slow car is ahead of this car.
+		ahead := slowCar.splinePos - car.splinePos
+
+		if ahead < 0 {
+			ahead++
+		}
+
+		if ahead > 0 && ahead < 0.5 && worldDistance(car.pos, slowCar.pos) <= yellowFlagDistance {
+			approaching = append(approaching, otherCarID)
+		}
+	}
+
+	return approaching
+}
+
+// blueFlagChange is a blue flag being shown to (or withdrawn from) carID, for the car lappedBy.
+type blueFlagChange struct {
+	carID    udp.CarID
+	lappedBy udp.CarID
+	active   bool
+}
+
+// setBlueFlags updates the blue flags shown to each car, where lapping is a map of lapped car to lapping car, and
+// returns the flags that have changed.
+func (t *raceFlagTracker) setBlueFlags(lapping map[udp.CarID]udp.CarID) []blueFlagChange {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	var changes []blueFlagChange
+
+	for carID, car := range t.cars {
+		lappedBy, isLapped := lapping[carID]
+
+		if car.hasBlueFlag && (!isLapped || lappedBy != car.lappedBy) {
+			changes = append(changes, blueFlagChange{carID: carID, lappedBy: car.lappedBy, active: false})
+			car.hasBlueFlag = false
+		}
+
+		if isLapped && !car.hasBlueFlag {
+			changes = append(changes, blueFlagChange{carID: carID, lappedBy: lappedBy, active: true})
+			car.hasBlueFlag = true
+			car.lappedBy = lappedBy
+		}
+	}
+
+	return changes
+}
+
+// position returns the last known position of a car.
+func (t *raceFlagTracker) position(carID udp.CarID) udp.Vec {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if car, ok := t.cars[carID]; ok {
+		return car.pos
+	}
+
+	return udp.Vec{}
+}
+
+// shouldSendMessage returns true if a message for the given flag has not been sent within flagMessageInterval.
+func (t *raceFlagTracker) shouldSendMessage(key flagMessageKey, now time.Time) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if lastSent, ok := t.messagesSent[key]; ok && now.Sub(lastSent) < flagMessageInterval {
+		return false
+	}
+
+	t.messagesSent[key] = now
+
+	return true
+}
+
+func worldDistance(a, b udp.Vec) float64 {
+	return math.Sqrt(math.Pow(float64(a.X-b.X), 2) + math.Pow(float64(a.Y-b.Y), 2) + math.Pow(float64(a.Z-b.Z), 2))
+}
+
+// lappingCars finds the cars which are within maxGap behind a car that they are at least a lap ahead of, returning
+// a map of lapped car to the closest car lapping it.
+func (t *raceGapTracker) lappingCars(carIDs []udp.CarID, maxGap time.Duration) map[udp.CarID]udp.CarID {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	lapping := make(map[udp.CarID]udp.CarID)
+	closest := make(map[udp.CarID]time.Duration)
+
+	for _, lappedCarID := range carIDs {
+		lappedCar, ok := t.cars[lappedCarID]
+
+		if !ok || !lappedCar.hasPosition {
+			continue
+		}
+
+		for _, carID := range carIDs {
+			car, ok := t.cars[carID]
+
+			if carID == lappedCarID || !ok || !car.hasPosition || car.distance() <= lappedCar.distance() {
+				continue
+			}
+
+			carTime, ok := car.points[car.lastPoint]
+
+			if !ok {
+				continue
+			}
+
+			// the point at which the lapped car passed the place on track where car is now.
+			laps := int(math.Ceil(car.distance() - lappedCar.distance()))
+			lappedCarTime, ok := lappedCar.points[car.lastPoint-laps*gapTimingPointsPerLap]
+
+			if !ok {
+				continue
+			}
+
+			gap := carTime.Sub(lappedCarTime)
+
+			if gap < 0 || gap > maxGap {
+				continue
+			}
+
+			if currentGap, ok := closest[lappedCarID]; !ok || gap < currentGap {
+				lapping[lappedCarID] = carID
+				closest[lappedCarID] = gap
+			}
+		}
+	}
+
+	return lapping
+}
+
+// checkFlags shows and withdraws blue and yellow flags, sending chat messages to the affected drivers.
+func (rc *RaceControl) checkFlags() {
+	now := rc.now()
+	changed := false
+
+	slowCars, clearedCars := rc.flags.checkSlowCars(now)
+
+	for _, carID := range slowCars {
+		driver, err := rc.findConnectedDriverByCarID(carID)
+
+		if err != nil {
+			continue
+		}
+
+		changed = true
+		flag := rc.setDriverFlag(driver, RaceControlFlagYellow, true, nil)
+
+		for _, approachingCarID := range rc.flags.approachingCars(carID, now) {
+			approachingDriver, err := rc.findConnectedDriverByCarID(approachingCarID)
+
+			if err != nil || !rc.flags.shouldSendMessage(flagMessageKey{RaceControlFlagYellow, approachingCarID, carID}, now) {
+				continue
+			}
+
+			rc.sendFlagMessage(approachingDriver, fmt.Sprintf("Yellow flag: slow car ahead (%s). Take care!", flag.DriverName))
+		}
+	}
+
+	for _, carID := range clearedCars {
+		driver, err := rc.findConnectedDriverByCarID(carID)
+
+		if err != nil {
+			continue
+		}
+
+		changed = true
+		rc.setDriverFlag(driver, RaceControlFlagYellow, false, nil)
+	}
+
+	var lapping map[udp.CarID]udp.CarID
+
+	if rc.currentSessionInfo().Type == udp.SessionTypeRace {
+		lapping = rc.gapTracker.lappingCars(rc.connectedCarIDs(), blueFlagGap)
+	}
+
+	for _, change := range rc.flags.setBlueFlags(lapping) {
+		driver, err := rc.findConnectedDriverByCarID(change.carID)
+
+		if err != nil {
+			continue
+		}
+
+		lappingDriver, err := rc.findConnectedDriverByCarID(change.lappedBy)
+
+		if err != nil {
+			lappingDriver = nil
+		}
+
+		changed = true
+		rc.setDriverFlag(driver, RaceControlFlagBlue, change.active, lappingDriver)
+
+		if change.active && lappingDriver != nil && rc.flags.shouldSendMessage(flagMessageKey{RaceControlFlagBlue, change.carID, change.lappedBy}, now) {
+			rc.sendFlagMessage(driver, fmt.Sprintf("Blue flag: %s is about to lap you. Please let them past.", lappingDriver.CarInfo.DriverName))
+		}
+	}
+
+	if changed {
+		rc.broadcastStatus()
+	}
+}
+
+// setDriverFlag shows or withdraws a flag for a driver and broadcasts the change. otherDriver is the car lapping
+// the driver, for blue flags.
+func (rc *RaceControl) setDriverFlag(driver *RaceControlDriver, flagType RaceControlFlagType, active bool, otherDriver *RaceControlDriver) *RaceControlFlag {
+	flag := &RaceControlFlag{
+		Type:   flagType,
+		Active: active,
+		Time:   rc.now(),
+	}
+
+	if otherDriver != nil {
+		otherDriver.mutex.Lock()
+		flag.OtherCarID = otherDriver.CarInfo.CarID
+		flag.OtherDriverGUID = otherDriver.CarInfo.DriverGUID
+		flag.OtherDriverName = otherDriver.CarInfo.DriverName
+		otherDriver.mutex.Unlock()
+	}
+
+	driver.mutex.Lock()
+	flag.CarID = driver.CarInfo.CarID
+	flag.DriverGUID = driver.CarInfo.DriverGUID
+	flag.DriverName = driver.CarInfo.DriverName
+
+	switch flagType {
+	case RaceControlFlagBlue:
+		driver.BlueFlag = active
+	case RaceControlFlagYellow:
+		driver.SlowCar = active
+	}
+	driver.mutex.Unlock()
+
+	flag.WorldPos = rc.flags.position(flag.CarID)
+
+	if _, err := rc.broadcaster.Send(flag); err != nil {
+		logrus.WithError(err).Errorf("Could not broadcast %s flag", flagType)
+	}
+
+	return flag
+}
+
+func (rc *RaceControl) sendFlagMessage(driver *RaceControlDriver, message string) {
+	driver.mutex.Lock()
+	guid := driver.CarInfo.DriverGUID
+	driver.mutex.Unlock()
+
+	if err := rc.splitAndSendChat(message, string(guid)); err != nil {
+		logrus.WithError(err).Errorf("Could not send flag message to driver: %s", guid)
+	}
+}
+
+// watchFlags periodically checks for blue and yellow flags.
+func (rc *RaceControl) watchFlags() {
+	if udp.RealtimePosIntervalMs <= 0 {
+		// with no real time pos interval, we have no car positions or speeds to check.
+		return
+	}
+
+	ticker := time.NewTicker(flagsCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rc.done:
+			return
+		case <-ticker.C:
+		}
+
+		rc.checkFlags()
+	}
+}
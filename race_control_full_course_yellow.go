@@ -0,0 +1,213 @@
+package servermanager
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+// defaultFullCourseYellowSpeedLimit is the default speed limit (in km/h) during a Full Course Yellow.
+const defaultFullCourseYellowSpeedLimit = 80
+
+var (
+	ErrFullCourseYellowActive    = errors.New("servermanager: a full course yellow is already active")
+	ErrFullCourseYellowNotActive = errors.New("servermanager: there is no active full course yellow")
+	ErrFullCourseYellowNotRace   = errors.New("servermanager: a full course yellow can only be started in a race session")
+)
+
+// FullCourseYellowDriver is the lap (in their current car) that a driver was on when a Full Course Yellow started
+// and ended.
+type FullCourseYellowDriver struct {
+	DriverGUID udp.DriverGUID `json:"DriverGUID"`
+	CarModel   string         `json:"CarModel"`
+	StartLap   int            `json:"StartLap"`
+	EndLap     int            `json:"EndLap"`
+}
+
+// FullCourseYellow is a period of a race in which drivers must slow down and hold their positions.
+type FullCourseYellow struct {
+	Start     time.Time `json:"Start" ts:"date"`
+	End       time.Time `json:"End" ts:"date"`
+	StartedBy string    `json:"StartedBy"`
+
+	// SessionStart and SessionEnd are the times into the session that the Full Course Yellow started and ended.
+	SessionStart time.Duration `json:"SessionStart"`
+	SessionEnd   time.Duration `json:"SessionEnd"`
+
+	// SpeedLimit is the speed (in km/h) that drivers must stay below. Zero means there is no speed limit.
+	SpeedLimit int `json:"SpeedLimit"`
+
+	Drivers []*FullCourseYellowDriver `json:"Drivers"`
+}
+
+func (f *FullCourseYellow) Active() bool {
+	return f.End.IsZero()
+}
+
+func (f *FullCourseYellow) Duration() time.Duration {
+	return f.SessionEnd - f.SessionStart
+}
+
+// StartFullCourseYellow starts a Full Course Yellow in the current race session, and tells all drivers about it.
+func (rc *RaceControl) StartFullCourseYellow(startedBy string) error {
+	if rc.currentSessionInfo().Type != udp.SessionTypeRace {
+		return ErrFullCourseYellowNotRace
+	}
+
+	rc.fullCourseYellowMutex.Lock()
+
+	if len(rc.FullCourseYellows) > 0 && rc.FullCourseYellows[len(rc.FullCourseYellows)-1].Active() {
+		rc.fullCourseYellowMutex.Unlock()
+		return ErrFullCourseYellowActive
+	}
+
+	now := rc.now()
+
+	fullCourseYellow := &FullCourseYellow{
+		Start:        now,
+		StartedBy:    startedBy,
+		SessionStart: now.Sub(rc.SessionStartTime).Round(time.Second),
+		SpeedLimit:   rc.process.Event().GetRaceConfig().FullCourseYellowSpeedLimit,
+	}
+
+	_ = rc.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
+		driver.mutex.Lock()
+		defer driver.mutex.Unlock()
+
+		lap := driver.CurrentCar().NumLaps + 1
+
+		fullCourseYellow.Drivers = append(fullCourseYellow.Drivers, &FullCourseYellowDriver{
+			DriverGUID: driverGUID,
+			CarModel:   driver.CarInfo.CarModel,
+			StartLap:   lap,
+			EndLap:     lap,
+		})
+
+		return nil
+	})
+
+	rc.FullCourseYellows = append(rc.FullCourseYellows, fullCourseYellow)
+	rc.fullCourseYellowMutex.Unlock()
+
+	logrus.Infof("Full Course Yellow started by %s", startedBy)
+
+	message := "FULL COURSE YELLOW! Hold your position, no overtaking."
+
+	if fullCourseYellow.SpeedLimit > 0 {
+		message += fmt.Sprintf(" Slow down to below %d km/h.", fullCourseYellow.SpeedLimit)
+	}
+
+	if err := rc.splitAndBroadcastChat(message, nil); err != nil {
+		logrus.WithError(err).Error("Could not broadcast full course yellow message")
+	}
+
+	rc.broadcastStatus()
+
+	return nil
+}
+
+// EndFullCourseYellow ends the active Full Course Yellow, and tells all drivers that racing has resumed.
+func (rc *RaceControl) EndFullCourseYellow() error {
+	rc.fullCourseYellowMutex.Lock()
+
+	if len(rc.FullCourseYellows) == 0 || !rc.FullCourseYellows[len(rc.FullCourseYellows)-1].Active() {
+		rc.fullCourseYellowMutex.Unlock()
+		return ErrFullCourseYellowNotActive
+	}
+
+	rc.endFullCourseYellow(rc.FullCourseYellows[len(rc.FullCourseYellows)-1])
+	rc.fullCourseYellowMutex.Unlock()
+
+	logrus.Info("Full Course Yellow ended")
+
+	if err := rc.splitAndBroadcastChat("GREEN FLAG! The Full Course Yellow has ended, racing resumes.", nil); err != nil {
+		logrus.WithError(err).Error("Could not broadcast full course yellow message")
+	}
+
+	rc.broadcastStatus()
+
+	return nil
+}
+
+// endFullCourseYellow records the end of a Full Course Yellow. fullCourseYellowMutex must be held.
+func (rc *RaceControl) endFullCourseYellow(fullCourseYellow *FullCourseYellow) {
+	now := rc.now()
+
+	fullCourseYellow.End = now
+	fullCourseYellow.SessionEnd = now.Sub(rc.SessionStartTime).Round(time.Second)
+
+	_ = rc.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
+		driver.mutex.Lock()
+		defer driver.mutex.Unlock()
+
+		lap := driver.CurrentCar().NumLaps + 1
+
+		for _, fullCourseYellowDriver := range fullCourseYellow.Drivers {
+			if fullCourseYellowDriver.DriverGUID == driverGUID && fullCourseYellowDriver.CarModel == driver.CarInfo.CarModel {
+				fullCourseYellowDriver.EndLap = lap
+				return nil
+			}
+		}
+
+		// the driver joined the session during the Full Course Yellow.
+		fullCourseYellow.Drivers = append(fullCourseYellow.Drivers, &FullCourseYellowDriver{
+			DriverGUID: driverGUID,
+			CarModel:   driver.CarInfo.CarModel,
+			StartLap:   lap,
+			EndLap:     lap,
+		})
+
+		return nil
+	})
+}
+
+// activeFullCourseYellow returns a copy of the active Full Course Yellow, or nil if there isn't one.
+func (rc *RaceControl) activeFullCourseYellow() *FullCourseYellow {
+	rc.fullCourseYellowMutex.RLock()
+	defer rc.fullCourseYellowMutex.RUnlock()
+
+	if len(rc.FullCourseYellows) == 0 || !rc.FullCourseYellows[len(rc.FullCourseYellows)-1].Active() {
+		return nil
+	}
+
+	fullCourseYellow := *rc.FullCourseYellows[len(rc.FullCourseYellows)-1]
+
+	return &fullCourseYellow
+}
+
+func (rc *RaceControl) clearFullCourseYellows() {
+	rc.fullCourseYellowMutex.Lock()
+	defer rc.fullCourseYellowMutex.Unlock()
+
+	rc.FullCourseYellows = nil
+}
+
+// saveFullCourseYellows ends any active Full Course Yellow and stores the session's Full Course Yellows in its
+// results file.
+func (rc *RaceControl) saveFullCourseYellows(filename string) error {
+	rc.fullCourseYellowMutex.Lock()
+	defer rc.fullCourseYellowMutex.Unlock()
+
+	if len(rc.FullCourseYellows) == 0 {
+		return nil
+	}
+
+	if current := rc.FullCourseYellows[len(rc.FullCourseYellows)-1]; current.Active() {
+		rc.endFullCourseYellow(current)
+	}
+
+	results, err := LoadResult(filename, LoadResultWithoutPluginFire)
+
+	if err != nil {
+		return err
+	}
+
+	results.FullCourseYellows = rc.FullCourseYellows
+
+	return rc.resultsRevisionManager.UpdateResults(strings.TrimSuffix(filename, ".json"), results, "Full Course Yellows added")
+}
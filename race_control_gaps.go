@@ -0,0 +1,362 @@
+package servermanager
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+const (
+	// gapTimingPointsPerLap is the number of evenly spaced points around the lap that the time each car passes
+	// through is recorded at. Gaps are calculated by comparing the times that two cars passed the same point.
+	gapTimingPointsPerLap = 200
+
+	// gapHistoryLaps is the number of laps of timing points that are kept for each car. Gaps to cars more than
+	// this many laps behind are shown in laps only.
+	gapHistoryLaps = 3
+)
+
+var gapsBroadcastInterval = time.Second
+
+// RaceControlDriverGap is the live gap of a driver to the race leader and to the car directly ahead of them on track.
+type RaceControlDriverGap struct {
+	DriverGUID  udp.DriverGUID `json:"DriverGUID"`
+	CarID       udp.CarID      `json:"CarID"`
+	Position    int            `json:"Position"`
+	GapToLeader time.Duration  `json:"GapToLeader"`
+	Interval    time.Duration  `json:"Interval"`
+	LapsDown    int            `json:"LapsDown"`
+}
+
+// RaceControlGaps is broadcast periodically during race sessions with the gaps of every connected driver.
+type RaceControlGaps struct {
+	Gaps []*RaceControlDriverGap `json:"Gaps"`
+}
+
+func (RaceControlGaps) Event() udp.Event {
+	return EventRaceControlGaps
+}
+
+// carGapHistory is the position history of a car. It is stored by CarID rather than DriverGUID so that the
+// history carries across driver swaps.
+type carGapHistory struct {
+	driverGUID udp.DriverGUID
+
+	lap         int
+	splinePos   float32
+	lastUpdate  time.Time
+	hasPosition bool
+
+	lastPoint int
+	points    map[int]time.Time
+}
+
+func newCarGapHistory(driverGUID udp.DriverGUID) *carGapHistory {
+	return &carGapHistory{
+		driverGUID: driverGUID,
+		lastPoint:  -1,
+		points:     make(map[int]time.Time),
+	}
+}
+
+func (h *carGapHistory) distance() float64 {
+	return float64(h.lap) + float64(h.splinePos)
+}
+
+// clearPoints forgets all timing points, e.g. when the lap count of the car has been corrected.
+func (h *carGapHistory) clearPoints() {
+	h.lastPoint = -1
+	h.points = make(map[int]time.Time)
+}
+
+type raceGapTracker struct {
+	cars  map[udp.CarID]*carGapHistory
+	mutex sync.Mutex
+}
+
+func newRaceGapTracker() *raceGapTracker {
+	return &raceGapTracker{
+		cars: make(map[udp.CarID]*carGapHistory),
+	}
+}
+
+func (t *raceGapTracker) reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.cars = make(map[udp.CarID]*carGapHistory)
+}
+
+// update records the position of a car, adding timing points for every point it has passed since its last update.
+func (t *raceGapTracker) update(carID udp.CarID, driverGUID udp.DriverGUID, splinePos float32, updateTime time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	h, ok := t.cars[carID]
+
+	if !ok {
+		h = newCarGapHistory(driverGUID)
+		t.cars[carID] = h
+	}
+
+	h.driverGUID = driverGUID
+
+	fromDistance := h.distance()
+	fromTime := h.lastUpdate
+	interpolate := h.hasPosition
+
+	if !h.hasPosition {
+		if h.lap == 0 && splinePos > 0.5 {
+			// cars on the grid are behind the start/finish line, they have not started their first lap yet.
+			h.lap = -1
+		}
+	} else {
+		switch {
+		case h.splinePos > 0.75 && splinePos < 0.25:
+			h.lap++
+			t.prune(h)
+		case h.splinePos < 0.25 && splinePos > 0.75:
+			h.lap--
+		}
+	}
+
+	h.splinePos = splinePos
+	h.lastUpdate = updateTime
+	h.hasPosition = true
+
+	toDistance := h.distance()
+	point := int(toDistance * gapTimingPointsPerLap)
+
+	if toDistance < 0 || point <= h.lastPoint {
+		return
+	}
+
+	if interpolate && h.lastPoint >= 0 && point-h.lastPoint <= gapTimingPointsPerLap/4 && toDistance > fromDistance {
+		// fill in the points that were passed between updates.
+		for p := h.lastPoint + 1; p < point; p++ {
+			fraction := (float64(p)/gapTimingPointsPerLap - fromDistance) / (toDistance - fromDistance)
+
+			if fraction < 0 || fraction > 1 {
+				continue
+			}
+
+			if _, ok := h.points[p]; !ok {
+				h.points[p] = fromTime.Add(time.Duration(fraction * float64(updateTime.Sub(fromTime))))
+			}
+		}
+	}
+
+	if _, ok := h.points[point]; !ok {
+		h.points[point] = updateTime
+	}
+
+	h.lastPoint = point
+}
+
+// prune removes timing points more than gapHistoryLaps old.
+func (t *raceGapTracker) prune(h *carGapHistory) {
+	oldest := (h.lap - gapHistoryLaps) * gapTimingPointsPerLap
+
+	for point := range h.points {
+		if point < oldest {
+			delete(h.points, point)
+		}
+	}
+}
+
+// lapCompleted corrects the lap count of each car using the number of laps reported by the server, when completedCarID
+// has completed a lap.
+func (t *raceGapTracker) lapCompleted(completedCarID udp.CarID, cars []*udp.LapCompletedCar) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for _, car := range cars {
+		h, ok := t.cars[car.CarID]
+
+		if !ok || !h.hasPosition {
+			continue
+		}
+
+		lap := int(car.Laps)
+
+		// a car with N laps completed is N laps plus its spline position around the track, unless it hasn't crossed the
+		// line since its last update: either it's still on the grid, or it's the car which has just completed its lap.
+		if h.splinePos >= 0.5 && (car.Laps == 0 || car.CarID == completedCarID) {
+			lap--
+		}
+
+		if lap != h.lap {
+			h.lap = lap
+			h.clearPoints()
+		}
+	}
+}
+
+// moveCar moves the history of a car to a new CarID, e.g. when a driver reconnects into a different car slot.
+func (t *raceGapTracker) moveCar(from, to udp.CarID) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if h, ok := t.cars[from]; ok {
+		t.cars[to] = h
+		delete(t.cars, from)
+	}
+}
+
+// driverConnected makes sure that the history for a CarID belongs to the driver who is now in the car. If keepHistory
+// is false and the car was previously driven by someone else, the history is cleared.
+func (t *raceGapTracker) driverConnected(carID udp.CarID, driverGUID udp.DriverGUID, keepHistory bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	h, ok := t.cars[carID]
+
+	if !ok || h.driverGUID == driverGUID {
+		return
+	}
+
+	if keepHistory {
+		h.driverGUID = driverGUID
+	} else {
+		delete(t.cars, carID)
+	}
+}
+
+// calculate works out the gap to the leader and interval to the car ahead for each of the given cars. Cars are
+// ordered by the distance they have covered.
+func (t *raceGapTracker) calculate(carIDs []udp.CarID) []*RaceControlDriverGap {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	type carHistory struct {
+		carID udp.CarID
+		*carGapHistory
+	}
+
+	var cars []carHistory
+
+	for _, carID := range carIDs {
+		if h, ok := t.cars[carID]; ok && h.hasPosition {
+			cars = append(cars, carHistory{carID: carID, carGapHistory: h})
+		}
+	}
+
+	sort.SliceStable(cars, func(i, j int) bool {
+		return cars[i].distance() > cars[j].distance()
+	})
+
+	gaps := make([]*RaceControlDriverGap, 0, len(cars))
+
+	for i, car := range cars {
+		gap := &RaceControlDriverGap{
+			DriverGUID: car.driverGUID,
+			CarID:      car.carID,
+			Position:   i + 1,
+		}
+
+		if i > 0 {
+			leader := cars[0]
+			ahead := cars[i-1]
+
+			gap.LapsDown = int(leader.distance() - car.distance())
+			gap.GapToLeader = timeBehind(car.carGapHistory, leader.carGapHistory)
+			gap.Interval = timeBehind(car.carGapHistory, ahead.carGapHistory)
+		}
+
+		gaps = append(gaps, gap)
+	}
+
+	return gaps
+}
+
+// timeBehind is the difference between the time that car passed its most recent timing point and the time that
+// carAhead passed the same point. If carAhead has no record of that point, zero is returned.
+func timeBehind(car, carAhead *carGapHistory) time.Duration {
+	carTime, ok := car.points[car.lastPoint]
+
+	if !ok {
+		return 0
+	}
+
+	aheadTime, ok := carAhead.points[car.lastPoint]
+
+	if !ok || aheadTime.After(carTime) {
+		return 0
+	}
+
+	return carTime.Sub(aheadTime)
+}
+
+// connectedCarIDs returns the car IDs of all connected drivers.
+func (rc *RaceControl) connectedCarIDs() []udp.CarID {
+	var carIDs []udp.CarID
+
+	_ = rc.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
+		driver.mutex.Lock()
+		defer driver.mutex.Unlock()
+
+		carIDs = append(carIDs, driver.CarInfo.CarID)
+
+		return nil
+	})
+
+	return carIDs
+}
+
+// updateGaps calculates the gaps for all connected drivers, storing them on each RaceControlDriver.
+func (rc *RaceControl) updateGaps() *RaceControlGaps {
+	gaps := rc.gapTracker.calculate(rc.connectedCarIDs())
+
+	for _, gap := range gaps {
+		driver, ok := rc.ConnectedDrivers.Get(gap.DriverGUID)
+
+		if !ok {
+			continue
+		}
+
+		driver.mutex.Lock()
+		driver.GapToLeader = gap.GapToLeader
+		driver.Interval = gap.Interval
+		driver.LapsDown = gap.LapsDown
+		driver.mutex.Unlock()
+	}
+
+	return &RaceControlGaps{Gaps: gaps}
+}
+
+// broadcastGaps periodically sends the gaps between drivers during race sessions.
+func (rc *RaceControl) broadcastGaps() {
+	if udp.RealtimePosIntervalMs <= 0 {
+		// with no real time pos interval, we have no driver positions to calculate gaps from.
+		return
+	}
+
+	ticker := time.NewTicker(gapsBroadcastInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-rc.done:
+			return
+		case <-ticker.C:
+		}
+
+		if rc.currentSessionInfo().Type != udp.SessionTypeRace {
+			continue
+		}
+
+		gaps := rc.updateGaps()
+
+		if len(gaps.Gaps) == 0 {
+			continue
+		}
+
+		if _, err := rc.broadcaster.Send(gaps); err != nil {
+			logrus.WithError(err).Error("Could not broadcast race control gaps")
+		}
+	}
+}
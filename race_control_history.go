@@ -0,0 +1,173 @@
+package servermanager
+
+import (
+	"sync"
+	"time"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+const (
+	// maxHistoryLaps is the number of laps of history that are kept for each driver.
+	maxHistoryLaps = 200
+
+	// maxSpeedTraceLaps is the number of each driver's most recent laps that speed samples are kept for.
+	maxSpeedTraceLaps = 5
+
+	// speedSamplesPerLap is the maximum number of speed samples that are taken on each lap, at even intervals
+	// around the track.
+	speedSamplesPerLap = 200
+)
+
+// RaceControlSpeedSample is a car's speed (in km/h) at a point on the track.
+type RaceControlSpeedSample struct {
+	SplinePos float32 `json:"SplinePos"`
+	Speed     float64 `json:"Speed"`
+}
+
+// RaceControlLapHistory is a driver's position when they completed a lap, and the speed samples taken on it.
+type RaceControlLapHistory struct {
+	DriverGUID udp.DriverGUID `json:"DriverGUID"`
+	CarID      udp.CarID      `json:"CarID"`
+	CarModel   string         `json:"CarModel"`
+	Lap        int            `json:"Lap"`
+	Position   int            `json:"Position"`
+	LapTime    time.Duration  `json:"LapTime"`
+	Cuts       int            `json:"Cuts"`
+
+	SpeedSamples []RaceControlSpeedSample `json:"SpeedSamples"`
+}
+
+func (RaceControlLapHistory) Event() udp.Event {
+	return EventRaceControlLapHistory
+}
+
+// RaceControlHistory is the lap history of every driver in the session. It is sent to websocket clients when
+// they connect, and is then kept up to date with a RaceControlLapHistory each time a lap is completed.
+type RaceControlHistory struct {
+	Drivers map[udp.DriverGUID][]*RaceControlLapHistory `json:"Drivers"`
+}
+
+func (RaceControlHistory) Event() udp.Event {
+	return EventRaceControlHistory
+}
+
+type driverRaceHistory struct {
+	laps []*RaceControlLapHistory
+
+	currentLapSamples []RaceControlSpeedSample
+	lastSample        int
+}
+
+// raceHistoryTracker keeps a bounded history of each driver's position at the end of each lap and their speed
+// around the track.
+type raceHistoryTracker struct {
+	drivers map[udp.DriverGUID]*driverRaceHistory
+	mutex   sync.Mutex
+}
+
+func newRaceHistoryTracker() *raceHistoryTracker {
+	return &raceHistoryTracker{
+		drivers: make(map[udp.DriverGUID]*driverRaceHistory),
+	}
+}
+
+func (t *raceHistoryTracker) reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.drivers = make(map[udp.DriverGUID]*driverRaceHistory)
+}
+
+func (t *raceHistoryTracker) driver(guid udp.DriverGUID) *driverRaceHistory {
+	driver, ok := t.drivers[guid]
+
+	if !ok {
+		driver = &driverRaceHistory{lastSample: -1}
+		t.drivers[guid] = driver
+	}
+
+	return driver
+}
+
+// carUpdate takes a speed sample for the driver's current lap if they have reached the next sample point.
+func (t *raceHistoryTracker) carUpdate(guid udp.DriverGUID, splinePos float32, speed float64) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	driver := t.driver(guid)
+	sample := int(splinePos * speedSamplesPerLap)
+
+	if sample <= driver.lastSample || sample >= speedSamplesPerLap {
+		return
+	}
+
+	if driver.lastSample < 0 && splinePos > 0.5 {
+		// the driver hasn't crossed the line since they joined, or since their last lap was completed.
+		return
+	}
+
+	driver.lastSample = sample
+	driver.currentLapSamples = append(driver.currentLapSamples, RaceControlSpeedSample{
+		SplinePos: splinePos,
+		Speed:     speed,
+	})
+}
+
+// lapCompleted adds a lap to the driver's history, and returns a copy of it.
+func (t *raceHistoryTracker) lapCompleted(guid udp.DriverGUID, carID udp.CarID, carModel string, lapNum, position int, lapTime time.Duration, cuts int) *RaceControlLapHistory {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	driver := t.driver(guid)
+
+	lap := &RaceControlLapHistory{
+		DriverGUID:   guid,
+		CarID:        carID,
+		CarModel:     carModel,
+		Lap:          lapNum,
+		Position:     position,
+		LapTime:      lapTime,
+		Cuts:         cuts,
+		SpeedSamples: driver.currentLapSamples,
+	}
+
+	driver.laps = append(driver.laps, lap)
+	driver.currentLapSamples = nil
+	driver.lastSample = -1
+
+	if len(driver.laps) > maxHistoryLaps {
+		driver.laps = driver.laps[len(driver.laps)-maxHistoryLaps:]
+	}
+
+	if len(driver.laps) > maxSpeedTraceLaps {
+		driver.laps[len(driver.laps)-maxSpeedTraceLaps-1].SpeedSamples = nil
+	}
+
+	lapCopy := *lap
+
+	return &lapCopy
+}
+
+// history returns a copy of the lap history of every driver.
+func (t *raceHistoryTracker) history() *RaceControlHistory {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	history := &RaceControlHistory{
+		Drivers: make(map[udp.DriverGUID][]*RaceControlLapHistory, len(t.drivers)),
+	}
+
+	for guid, driver := range t.drivers {
+		laps := make([]*RaceControlLapHistory, 0, len(driver.laps))
+
+		for _, lap := range driver.laps {
+			lapCopy := *lap
+			laps = append(laps, &lapCopy)
+		}
+
+		history.Drivers[guid] = laps
+	}
+
+	return history
+}
@@ -167,6 +167,9 @@ type liveTimingTemplateVars struct {
 	IsKissMyRankEnabled         bool
 	KissMyRankWebStatsPublicURL string
 	STrackerInterfacePublicURL  string
+
+	// Replay is set when Live Timings is showing a replay of a previous session.
+	Replay *ReplayStatus
 }
 
 func (rch *RaceControlHandler) liveTiming(w http.ResponseWriter, r *http.Request) {
@@ -274,6 +277,12 @@ func (rch *RaceControlHandler) saveIFrames(w http.ResponseWriter, r *http.Reques
 }
 
 func (rch *RaceControlHandler) websocket(w http.ResponseWriter, r *http.Request) {
+	serveRaceControlWebsocket(w, r, rch.raceControlHub, rch.raceControl)
+}
+
+// serveRaceControlWebsocket registers a new websocket client with the hub. The client is sent the current state of
+// raceControl, its lap history and its chat messages, followed by every message sent to the hub.
+func serveRaceControlWebsocket(w http.ResponseWriter, r *http.Request, hub *RaceControlHub, raceControl *RaceControl) {
 	c, err := upgrader.Upgrade(w, r, nil)
 
 	if err != nil {
@@ -281,20 +290,27 @@ func (rch *RaceControlHandler) websocket(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	client := &raceControlClient{hub: rch.raceControlHub, conn: c, receive: make(chan []byte, 256)}
+	client := &raceControlClient{hub: hub, conn: c, receive: make(chan []byte, 256)}
 	client.hub.register <- client
 
 	go client.writePump()
 
 	// new client, send them an initial race control message.
-	rch.raceControl.lastUpdateMessageMutex.Lock()
-	client.receive <- rch.raceControl.lastUpdateMessage
-	rch.raceControl.lastUpdateMessageMutex.Unlock()
+	raceControl.lastUpdateMessageMutex.Lock()
+	client.receive <- raceControl.lastUpdateMessage
+	raceControl.lastUpdateMessageMutex.Unlock()
+
+	// send the lap history of the session so far, so that the client can draw its charts.
+	if encoded, err := encodeRaceControlMessage(raceControl.history.history()); err == nil {
+		client.receive <- encoded
+	} else {
+		logrus.WithError(err).Error("Could not encode race control history")
+	}
 
 	// send stored chat messages to new client
-	rch.raceControl.ChatMessagesMutex.Lock()
+	raceControl.ChatMessagesMutex.Lock()
 
-	for _, message := range rch.raceControl.ChatMessages {
+	for _, message := range raceControl.ChatMessages {
 		encoded, err := encodeRaceControlMessage(message)
 
 		if err != nil {
@@ -304,7 +320,7 @@ func (rch *RaceControlHandler) websocket(w http.ResponseWriter, r *http.Request)
 		client.receive <- encoded
 	}
 
-	rch.raceControl.ChatMessagesMutex.Unlock()
+	raceControl.ChatMessagesMutex.Unlock()
 }
 
 func (rch *RaceControlHandler) broadcastChat(w http.ResponseWriter, r *http.Request) {
@@ -409,6 +425,40 @@ func (rch *RaceControlHandler) nextSession(w http.ResponseWriter, r *http.Reques
 	http.Redirect(w, r, "/live-timing", http.StatusFound)
 }
 
+func (rch *RaceControlHandler) startFullCourseYellow(w http.ResponseWriter, r *http.Request) {
+	err := rch.raceControl.StartFullCourseYellow(AccountFromRequest(r).Name)
+
+	switch err {
+	case nil:
+		AddFlash(w, r, "Full Course Yellow started")
+	case ErrFullCourseYellowActive:
+		AddErrorFlash(w, r, "A Full Course Yellow is already in progress")
+	case ErrFullCourseYellowNotRace:
+		AddErrorFlash(w, r, "A Full Course Yellow can only be started during a race session")
+	default:
+		logrus.WithError(err).Errorf("Unable to start full course yellow")
+		AddErrorFlash(w, r, "Unable to start a Full Course Yellow")
+	}
+
+	http.Redirect(w, r, "/live-timing", http.StatusFound)
+}
+
+func (rch *RaceControlHandler) endFullCourseYellow(w http.ResponseWriter, r *http.Request) {
+	err := rch.raceControl.EndFullCourseYellow()
+
+	switch err {
+	case nil:
+		AddFlash(w, r, "Full Course Yellow ended")
+	case ErrFullCourseYellowNotActive:
+		AddErrorFlash(w, r, "There is no Full Course Yellow in progress")
+	default:
+		logrus.WithError(err).Errorf("Unable to end full course yellow")
+		AddErrorFlash(w, r, "Unable to end the Full Course Yellow")
+	}
+
+	http.Redirect(w, r, "/live-timing", http.StatusFound)
+}
+
 func (rch *RaceControlHandler) countdown(w http.ResponseWriter, r *http.Request) {
 
 	// broadcast countdown
@@ -0,0 +1,244 @@
+package servermanager
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+const (
+	// pitLaneSpeedTolerance is how far (in km/h) above the pit lane speed limit a car can be and still be
+	// considered to be in the pit lane.
+	pitLaneSpeedTolerance = 5
+
+	// pitStopStationarySpeed is the speed (in km/h) below which a car in the pit lane is stationary.
+	pitStopStationarySpeed = 2
+)
+
+// SessionPitStop is a stop that a car made in the pit lane.
+type SessionPitStop struct {
+	CarID      udp.CarID      `json:"CarID"`
+	CarModel   string         `json:"CarModel"`
+	DriverGUID udp.DriverGUID `json:"DriverGUID"`
+	DriverName string         `json:"DriverName"`
+
+	// SwapDriverGUID and SwapDriverName are the driver who left the pits, if there was a driver swap during the
+	// pit stop.
+	SwapDriverGUID udp.DriverGUID `json:"SwapDriverGUID"`
+	SwapDriverName string         `json:"SwapDriverName"`
+
+	// Lap is the lap (of the car's driver when it entered the pit lane) that the pit stop was made on.
+	Lap int `json:"Lap"`
+
+	// EntryTime is the time into the session that the car entered the pit lane.
+	EntryTime      time.Duration `json:"EntryTime"`
+	PitLaneTime    time.Duration `json:"PitLaneTime"`
+	StationaryTime time.Duration `json:"StationaryTime"`
+}
+
+func (p *SessionPitStop) IsDriverSwap() bool {
+	return p.SwapDriverGUID != "" && p.SwapDriverGUID != p.DriverGUID
+}
+
+// RaceControlPitLane is sent when a car enters or leaves the pit lane. PitStop is the completed pit stop when a
+// car that stopped in the pit lane leaves it.
+type RaceControlPitLane struct {
+	CarID       udp.CarID       `json:"CarID"`
+	DriverGUID  udp.DriverGUID  `json:"DriverGUID"`
+	InPitLane   bool            `json:"InPitLane"`
+	NumPitStops int             `json:"NumPitStops"`
+	PitStop     *SessionPitStop `json:"PitStop"`
+}
+
+func (RaceControlPitLane) Event() udp.Event {
+	return EventRaceControlPitLane
+}
+
+type carPitLaneState struct {
+	// hasLeftPits is true once the car has gone faster than the pit lane speed limit since it was loaded, so that
+	// cars waiting in the pits or on the grid are not counted as pit stops.
+	hasLeftPits bool
+
+	inPitLane       bool
+	pitLaneEntered  time.Time
+	stationarySince time.Time
+	current         *SessionPitStop
+
+	numPitStops int
+}
+
+// pitStopTracker detects cars entering and stopping in the pit lane of a track described by a PitLaneConfig.
+// Assetto Corsa's UDP plugin does not say when a car is in the pit lane, so a car is in it when it is between the
+// pit lane entry and exit and below the pit lane speed limit. Only stops are counted, drive-throughs are not.
+type pitStopTracker struct {
+	cars     map[udp.CarID]*carPitLaneState
+	pitStops []*SessionPitStop
+	mutex    sync.Mutex
+}
+
+func newPitStopTracker() *pitStopTracker {
+	return &pitStopTracker{
+		cars: make(map[udp.CarID]*carPitLaneState),
+	}
+}
+
+func (t *pitStopTracker) reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.cars = make(map[udp.CarID]*carPitLaneState)
+	t.pitStops = nil
+}
+
+// carLoaded is called when a driver loads into a car. If the car was stopped in the pit lane, a driver swap is
+// taking place and the pit stop continues with the new driver.
+func (t *pitStopTracker) carLoaded(carID udp.CarID, driverGUID udp.DriverGUID, driverName string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	car, ok := t.cars[carID]
+
+	if !ok {
+		return
+	}
+
+	if car.inPitLane && car.current != nil && !car.stationarySince.IsZero() {
+		car.current.SwapDriverGUID = driverGUID
+		car.current.SwapDriverName = driverName
+		return
+	}
+
+	car.hasLeftPits = false
+	car.inPitLane = false
+	car.current = nil
+}
+
+// update records the speed (in km/h) and position of a car. If the car has entered or left the pit lane, a
+// RaceControlPitLane is returned.
+func (t *pitStopTracker) update(config PitLaneConfig, carID udp.CarID, driver udp.SessionCarInfo, lap int, splinePos float32, speed float64, now time.Time, sessionTime time.Duration) *RaceControlPitLane {
+	if !config.Enabled {
+		return nil
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	car, ok := t.cars[carID]
+
+	if !ok {
+		car = &carPitLaneState{}
+		t.cars[carID] = car
+	}
+
+	maxPitLaneSpeed := float64(config.SpeedLimit + pitLaneSpeedTolerance)
+	inPitLane := car.hasLeftPits && config.containsSplinePos(splinePos) && speed <= maxPitLaneSpeed
+
+	if !car.inPitLane && inPitLane {
+		// entering the pit lane
+		car.inPitLane = true
+		car.pitLaneEntered = now
+		car.stationarySince = time.Time{}
+		car.current = nil
+
+		return &RaceControlPitLane{CarID: carID, DriverGUID: driver.DriverGUID, InPitLane: true, NumPitStops: car.numPitStops}
+	}
+
+	if speed > maxPitLaneSpeed {
+		car.hasLeftPits = true
+	}
+
+	if !car.inPitLane {
+		return nil
+	}
+
+	if speed < pitStopStationarySpeed {
+		if car.stationarySince.IsZero() {
+			car.stationarySince = now
+		}
+
+		if car.current == nil {
+			car.current = &SessionPitStop{
+				CarID:      carID,
+				CarModel:   driver.CarModel,
+				DriverGUID: driver.DriverGUID,
+				DriverName: driver.DriverName,
+				Lap:        lap,
+				EntryTime:  sessionTime - now.Sub(car.pitLaneEntered),
+			}
+		}
+
+		return nil
+	}
+
+	if !car.stationarySince.IsZero() {
+		car.current.StationaryTime += now.Sub(car.stationarySince)
+		car.stationarySince = time.Time{}
+	}
+
+	if inPitLane {
+		return nil
+	}
+
+	// leaving the pit lane
+	car.inPitLane = false
+	pitLane := &RaceControlPitLane{CarID: carID, DriverGUID: driver.DriverGUID, InPitLane: false}
+
+	if car.current != nil {
+		car.current.PitLaneTime = now.Sub(car.pitLaneEntered)
+		car.numPitStops++
+		t.pitStops = append(t.pitStops, car.current)
+
+		pitStop := *car.current
+		pitLane.PitStop = &pitStop
+		car.current = nil
+	}
+
+	pitLane.NumPitStops = car.numPitStops
+
+	return pitLane
+}
+
+func (t *pitStopTracker) inPitLane(carID udp.CarID) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	car, ok := t.cars[carID]
+
+	return ok && car.inPitLane
+}
+
+// completedPitStops returns a copy of the pit stops which have been completed in the session.
+func (t *pitStopTracker) completedPitStops() []*SessionPitStop {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	pitStops := make([]*SessionPitStop, 0, len(t.pitStops))
+
+	for _, pitStop := range t.pitStops {
+		pitStopCopy := *pitStop
+		pitStops = append(pitStops, &pitStopCopy)
+	}
+
+	return pitStops
+}
+
+// savePitStops stores the session's pit stops in its results file.
+func (rc *RaceControl) savePitStops(filename string) error {
+	pitStops := rc.pitStops.completedPitStops()
+
+	if len(pitStops) == 0 {
+		return nil
+	}
+
+	results, err := LoadResult(filename, LoadResultWithoutPluginFire)
+
+	if err != nil {
+		return err
+	}
+
+	results.PitStops = pitStops
+
+	return rc.resultsRevisionManager.UpdateResults(strings.TrimSuffix(filename, ".json"), results, "Pit stops added")
+}
@@ -0,0 +1,328 @@
+package servermanager
+
+import (
+	"time"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+// RaceControlBestSector is the fastest time set in a given sector during the current session.
+type RaceControlBestSector struct {
+	Time       time.Duration  `json:"Time"`
+	DriverGUID udp.DriverGUID `json:"DriverGUID"`
+	DriverName string         `json:"DriverName"`
+	CarModel   string         `json:"CarModel"`
+}
+
+// raceControlDriverCar identifies a driver in a given car.
+type raceControlDriverCar struct {
+	DriverGUID udp.DriverGUID
+	CarModel   string
+}
+
+// raceControlPersonalBestSectors are a driver's personal best time for each sector in a car.
+type raceControlPersonalBestSectors struct {
+	DriverName string
+	Sectors    []time.Duration
+}
+
+// RaceControlSectorCompleted is broadcast every time a driver completes a sector.
+type RaceControlSectorCompleted struct {
+	DriverGUID     udp.DriverGUID `json:"DriverGUID"`
+	CarModel       string         `json:"CarModel"`
+	Sector         int            `json:"Sector"`
+	Time           time.Duration  `json:"Time"`
+	IsPersonalBest bool           `json:"IsPersonalBest"`
+	IsOverallBest  bool           `json:"IsOverallBest"`
+}
+
+func (RaceControlSectorCompleted) Event() udp.Event {
+	return EventRaceControlSectorCompleted
+}
+
+// resetSectorTiming forgets where the driver is on track. Sectors are not timed again until the driver has crossed
+// the start of a sector.
+func (rcd *RaceControlDriver) resetSectorTiming() {
+	rcd.currentSector = -1
+	rcd.currentSectorStartTime = time.Time{}
+	rcd.lastSplinePos = 0
+	rcd.lastSplinePosTime = time.Time{}
+
+	car := rcd.CurrentCar()
+	car.CurrentLapSectors = nil
+	car.bestSectorsAtLapStart = copyDurations(car.BestSectors)
+}
+
+// sectorForSplinePos returns the index of the sector that the normalised spline position is in.
+func sectorForSplinePos(sectors []float32, splinePos float32) int {
+	sector := 0
+
+	for i, start := range sectors {
+		if splinePos >= start {
+			sector = i
+		}
+	}
+
+	return sector
+}
+
+// interpolateCrossingTime estimates when a car crossed a boundary between two spline position updates. This gives
+// a much more accurate sector time than just using the time that the update was received.
+func interpolateCrossingTime(boundary, fromPos, toPos float32, fromTime, toTime time.Time) time.Time {
+	if fromTime.IsZero() || toPos <= fromPos {
+		return toTime
+	}
+
+	fraction := float64(boundary-fromPos) / float64(toPos-fromPos)
+
+	if fraction < 0 || fraction > 1 {
+		return toTime
+	}
+
+	return fromTime.Add(time.Duration(fraction * float64(toTime.Sub(fromTime))))
+}
+
+// updateSectorTiming works out whether a driver has crossed into a new sector since their last update, and if so
+// records the time for the sector they just completed. The final sector of a lap is not timed here, it is
+// calculated in completeSectorsForLap from the lap time that the server reports.
+//
+// updateSectorTiming must be called with the driver's mutex held.
+func (rc *RaceControl) updateSectorTiming(driver *RaceControlDriver, splinePos float32, updateTime time.Time) *RaceControlSectorCompleted {
+	sectors := rc.TrackSectors
+
+	if len(sectors) < 2 {
+		return nil
+	}
+
+	defer func() {
+		driver.lastSplinePos = splinePos
+		driver.lastSplinePosTime = updateTime
+	}()
+
+	sector := sectorForSplinePos(sectors, splinePos)
+	previousSector := driver.currentSector
+
+	if sector == previousSector {
+		return nil
+	}
+
+	driver.currentSector = sector
+	car := driver.CurrentCar()
+
+	switch {
+	case previousSector >= 0 && sector == previousSector+1:
+		// the driver has moved into the next sector
+		crossingTime := interpolateCrossingTime(sectors[sector], driver.lastSplinePos, splinePos, driver.lastSplinePosTime, updateTime)
+		sectorStartTime := driver.currentSectorStartTime
+		driver.currentSectorStartTime = crossingTime
+
+		if sectorStartTime.IsZero() || len(car.CurrentLapSectors) != previousSector {
+			// we didn't see the start of this sector, or have missed a sector earlier in the lap.
+			return nil
+		}
+
+		sectorTime := crossingTime.Sub(sectorStartTime)
+
+		car.CurrentLapSectors = append(car.CurrentLapSectors, sectorTime)
+
+		return rc.completeSector(driver, car, previousSector, sectorTime)
+	case previousSector == len(sectors)-1 && sector == 0:
+		// the driver has crossed the start/finish line. the last sector is timed when the lap is completed.
+		driver.currentSectorStartTime = interpolateCrossingTime(1, driver.lastSplinePos, splinePos+1, driver.lastSplinePosTime, updateTime)
+
+		return nil
+	default:
+		// the driver has either just joined, gone backwards or jumped across the track (e.g. back to the pits).
+		driver.currentSectorStartTime = time.Time{}
+		car.CurrentLapSectors = nil
+
+		return nil
+	}
+}
+
+// completeSectorsForLap times the final sector of a lap using the lap time, then moves the current lap's sectors into
+// LastLapSectors. If the lap had cuts, any personal or overall bests that it set are reverted, matching how sectors
+// from cut laps are ignored in the results file.
+//
+// completeSectorsForLap must be called with the driver's mutex held.
+func (rc *RaceControl) completeSectorsForLap(driver *RaceControlDriver, lapTime time.Duration, cuts int) *RaceControlSectorCompleted {
+	var sectorCompleted *RaceControlSectorCompleted
+
+	car := driver.CurrentCar()
+	numSectors := len(rc.TrackSectors)
+
+	if numSectors > 1 && len(car.CurrentLapSectors) == numSectors-1 {
+		finalSector := lapTime
+
+		for _, sector := range car.CurrentLapSectors {
+			finalSector -= sector
+		}
+
+		if finalSector > 0 {
+			car.CurrentLapSectors = append(car.CurrentLapSectors, finalSector)
+
+			if cuts == 0 {
+				sectorCompleted = rc.completeSector(driver, car, numSectors-1, finalSector)
+			}
+		}
+	}
+
+	if cuts > 0 {
+		car.BestSectors = copyDurations(car.bestSectorsAtLapStart)
+
+		rc.bestSectorsMutex.Lock()
+		rc.setPersonalBestSectors(driver.CarInfo.DriverGUID, driver.CarInfo.DriverName, driver.CarInfo.CarModel, car.BestSectors)
+		rc.calculateBestSectors()
+		rc.bestSectorsMutex.Unlock()
+	}
+
+	if len(car.CurrentLapSectors) == numSectors {
+		car.LastLapSectors = car.CurrentLapSectors
+	} else {
+		car.LastLapSectors = nil
+	}
+
+	car.CurrentLapSectors = nil
+	car.bestSectorsAtLapStart = copyDurations(car.BestSectors)
+
+	return sectorCompleted
+}
+
+// completeSector updates the driver's personal best and the overall best for a sector. Overall bests are not set for
+// approximate track sectors.
+func (rc *RaceControl) completeSector(driver *RaceControlDriver, car *RaceControlCarLapInfo, sector int, sectorTime time.Duration) *RaceControlSectorCompleted {
+	sectorCompleted := &RaceControlSectorCompleted{
+		DriverGUID: driver.CarInfo.DriverGUID,
+		CarModel:   driver.CarInfo.CarModel,
+		Sector:     sector,
+		Time:       sectorTime,
+	}
+
+	for len(car.BestSectors) <= sector {
+		car.BestSectors = append(car.BestSectors, 0)
+	}
+
+	if car.BestSectors[sector] == 0 || sectorTime < car.BestSectors[sector] {
+		car.BestSectors[sector] = sectorTime
+		sectorCompleted.IsPersonalBest = true
+	}
+
+	rc.bestSectorsMutex.Lock()
+	defer rc.bestSectorsMutex.Unlock()
+
+	if sectorCompleted.IsPersonalBest {
+		rc.setPersonalBestSectors(driver.CarInfo.DriverGUID, driver.CarInfo.DriverName, driver.CarInfo.CarModel, car.BestSectors)
+	}
+
+	if rc.ApproximateTrackSectors {
+		return sectorCompleted
+	}
+
+	for len(rc.BestSectors) <= sector {
+		rc.BestSectors = append(rc.BestSectors, nil)
+	}
+
+	if best := rc.BestSectors[sector]; best == nil || sectorTime < best.Time {
+		rc.BestSectors[sector] = &RaceControlBestSector{
+			Time:       sectorTime,
+			DriverGUID: driver.CarInfo.DriverGUID,
+			DriverName: driver.CarInfo.DriverName,
+			CarModel:   driver.CarInfo.CarModel,
+		}
+
+		sectorCompleted.IsOverallBest = true
+	}
+
+	return sectorCompleted
+}
+
+// recalculateBestSectors rebuilds the personal and overall best sectors from every driver's personal bests. It locks
+// each driver in turn, so must not be called with any driver's mutex held.
+func (rc *RaceControl) recalculateBestSectors() {
+	personalBestSectors := make(map[raceControlDriverCar]*raceControlPersonalBestSectors)
+
+	findPersonalBestSectors := func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
+		driver.mutex.Lock()
+		defer driver.mutex.Unlock()
+
+		for carModel, car := range driver.Cars {
+			if len(car.BestSectors) == 0 {
+				continue
+			}
+
+			personalBestSectors[raceControlDriverCar{DriverGUID: driverGUID, CarModel: carModel}] = &raceControlPersonalBestSectors{
+				DriverName: driver.CarInfo.DriverName,
+				Sectors:    copyDurations(car.BestSectors),
+			}
+		}
+
+		return nil
+	}
+
+	_ = rc.ConnectedDrivers.Each(findPersonalBestSectors)
+	_ = rc.DisconnectedDrivers.Each(findPersonalBestSectors)
+
+	rc.bestSectorsMutex.Lock()
+	defer rc.bestSectorsMutex.Unlock()
+
+	rc.personalBestSectors = personalBestSectors
+	rc.calculateBestSectors()
+}
+
+// setPersonalBestSectors records a driver's personal best sectors in a car. It must be called with the
+// bestSectorsMutex held.
+func (rc *RaceControl) setPersonalBestSectors(driverGUID udp.DriverGUID, driverName, carModel string, sectors []time.Duration) {
+	if rc.personalBestSectors == nil {
+		rc.personalBestSectors = make(map[raceControlDriverCar]*raceControlPersonalBestSectors)
+	}
+
+	rc.personalBestSectors[raceControlDriverCar{DriverGUID: driverGUID, CarModel: carModel}] = &raceControlPersonalBestSectors{
+		DriverName: driverName,
+		Sectors:    copyDurations(sectors),
+	}
+}
+
+// calculateBestSectors works out the overall best sectors from the personal best sectors. It must be called with
+// the bestSectorsMutex held.
+func (rc *RaceControl) calculateBestSectors() {
+	var bestSectors []*RaceControlBestSector
+
+	if rc.ApproximateTrackSectors {
+		rc.BestSectors = nil
+		return
+	}
+
+	for driverCar, personalBest := range rc.personalBestSectors {
+		for sector, sectorTime := range personalBest.Sectors {
+			if sectorTime == 0 {
+				continue
+			}
+
+			for len(bestSectors) <= sector {
+				bestSectors = append(bestSectors, nil)
+			}
+
+			if best := bestSectors[sector]; best == nil || sectorTime < best.Time {
+				bestSectors[sector] = &RaceControlBestSector{
+					Time:       sectorTime,
+					DriverGUID: driverCar.DriverGUID,
+					DriverName: personalBest.DriverName,
+					CarModel:   driverCar.CarModel,
+				}
+			}
+		}
+	}
+
+	rc.BestSectors = bestSectors
+}
+
+func copyDurations(durations []time.Duration) []time.Duration {
+	if durations == nil {
+		return nil
+	}
+
+	out := make([]time.Duration, len(durations))
+	copy(out, durations)
+
+	return out
+}
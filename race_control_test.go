@@ -1,6 +1,7 @@
 package servermanager
 
 import (
+	"math"
 	"math/rand"
 	"os"
 	"path/filepath"
@@ -59,7 +60,7 @@ func TestRaceControl_OnClientConnect(t *testing.T) {
 	t.Run("Client first connect", func(t *testing.T) {
 		// on first connect, a client is added to connected drivers but does not yet have a loaded time.
 		// their GUID is added to the CarID -> GUID map for future lookup
-		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 
 		err := raceControl.OnClientConnect(drivers[0])
 
@@ -188,7 +189,7 @@ func TestRaceControl_OnClientConnect(t *testing.T) {
 	})
 
 	t.Run("Client disconnects having never connected", func(t *testing.T) {
-		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 
 		// disconnect the driver
 		driver := drivers[0]
@@ -204,7 +205,7 @@ func TestRaceControl_OnClientConnect(t *testing.T) {
 }
 
 func TestRaceControl_OnClientLoaded(t *testing.T) {
-	raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+	raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 
 	for _, driverIndex := range []int{1, 2, 3} {
 		err := raceControl.OnClientConnect(drivers[driverIndex])
@@ -279,9 +280,13 @@ func (nilTrackData) TrackMap(name, layout string) (*TrackMapData, error) {
 	return &TrackMapData{}, nil
 }
 
+func (nilTrackData) TrackSectors(name, layout string) ([]float32, bool, error) {
+	return defaultTrackSectors(), true, nil
+}
+
 func TestRaceControl_OnNewSession(t *testing.T) {
 	t.Run("New session, no previous data", func(t *testing.T) {
-		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 
 		if err := raceControl.OnVersion(udp.Version(4)); err != nil {
 			t.Error(err)
@@ -327,7 +332,7 @@ func TestRaceControl_OnNewSession(t *testing.T) {
 	})
 
 	t.Run("New session, drivers join, then another new session. Drivers should have lap times cleared but not be disconnected", func(t *testing.T) {
-		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 
 		if err := raceControl.OnVersion(udp.Version(4)); err != nil {
 			t.Error(err)
@@ -474,7 +479,7 @@ func TestRaceControl_OnNewSession(t *testing.T) {
 	})
 
 	t.Run("Looped practice event, all cars and session information should be kept", func(t *testing.T) {
-		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 
 		if err := raceControl.OnVersion(udp.Version(4)); err != nil {
 			t.Error(err)
@@ -617,7 +622,7 @@ func TestRaceControl_OnNewSession(t *testing.T) {
 }
 
 func TestRaceControl_OnCarUpdate(t *testing.T) {
-	raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+	raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 
 	if err := raceControl.OnVersion(udp.Version(4)); err != nil {
 		t.Error(err)
@@ -722,6 +727,144 @@ func TestRaceControl_OnCarUpdate(t *testing.T) {
 	})
 }
 
+func TestRaceControl_SectorTiming(t *testing.T) {
+	raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
+	raceControl.TrackSectors = defaultTrackSectors()
+
+	for _, entrant := range drivers[:2] {
+		if err := raceControl.OnClientConnect(entrant); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	driver, ok := raceControl.ConnectedDrivers.Get(drivers[0].DriverGUID)
+
+	if !ok {
+		t.Error("Driver was not correctly added to ConnectedDrivers")
+		return
+	}
+
+	start := time.Now()
+
+	// drive a lap, updating every 10s. sector boundaries are at 1/3 and 2/3 of the lap.
+	splinePositions := []float32{0.9, 0.05, 0.3, 0.36, 0.6, 0.7, 0.95}
+
+	var completedSectors []*RaceControlSectorCompleted
+
+	for i, splinePos := range splinePositions {
+		sectorCompleted := raceControl.updateSectorTiming(driver, splinePos, start.Add(time.Duration(i)*10*time.Second))
+
+		if sectorCompleted != nil {
+			completedSectors = append(completedSectors, sectorCompleted)
+		}
+	}
+
+	if len(completedSectors) != 2 {
+		t.Errorf("Expected 2 completed sectors, got %d", len(completedSectors))
+		return
+	}
+
+	if !completedSectors[0].IsPersonalBest || !completedSectors[0].IsOverallBest {
+		t.Error("Expected first sector to be a personal and overall best")
+		return
+	}
+
+	// the line is crossed at ~6.7s and the first sector boundary at ~25.6s
+	if sector := driver.CurrentCar().CurrentLapSectors[0]; sector < 18*time.Second || sector > 19*time.Second {
+		t.Errorf("Expected interpolated first sector of ~18.9s, got %s", sector)
+		return
+	}
+
+	lapTime := time.Minute + 15*time.Second
+	lastSector := raceControl.completeSectorsForLap(driver, lapTime, 0)
+
+	if lastSector == nil || lastSector.Sector != 2 {
+		t.Error("Expected the final sector to be completed with the lap")
+		return
+	}
+
+	car := driver.CurrentCar()
+
+	if len(car.LastLapSectors) != 3 || car.LastLapSectors[0]+car.LastLapSectors[1]+car.LastLapSectors[2] != lapTime {
+		t.Errorf("Expected last lap sectors to add up to the lap time, got: %v", car.LastLapSectors)
+		return
+	}
+
+	if len(raceControl.BestSectors) != 3 || raceControl.BestSectors[2].DriverGUID != drivers[0].DriverGUID {
+		t.Error("Expected overall best sectors to be set by driver 0")
+		return
+	}
+
+	t.Run("Sectors from laps with cuts are reverted", func(t *testing.T) {
+		bestSectors := copyDurations(car.BestSectors)
+
+		for i, splinePos := range []float32{0.3, 0.34, 0.6, 0.67} {
+			raceControl.updateSectorTiming(driver, splinePos, start.Add(time.Minute*2+time.Duration(i)*time.Second))
+		}
+
+		if car.BestSectors[1] >= bestSectors[1] {
+			t.Error("Expected a new personal best in sector 2")
+			return
+		}
+
+		raceControl.completeSectorsForLap(driver, time.Minute, 2)
+
+		for i := range bestSectors {
+			if car.BestSectors[i] != bestSectors[i] {
+				t.Errorf("Expected best sector %d to be reverted to %s, was %s", i, bestSectors[i], car.BestSectors[i])
+				return
+			}
+
+			if raceControl.BestSectors[i].Time != bestSectors[i] {
+				t.Errorf("Expected overall best sector %d to be reverted to %s, was %s", i, bestSectors[i], raceControl.BestSectors[i].Time)
+				return
+			}
+		}
+	})
+
+	t.Run("Going backwards resets the current lap", func(t *testing.T) {
+		raceControl.updateSectorTiming(driver, 0.5, start.Add(time.Minute*3))
+		raceControl.updateSectorTiming(driver, 0.1, start.Add(time.Minute*3+time.Second*10))
+
+		if sectorCompleted := raceControl.updateSectorTiming(driver, 0.4, start.Add(time.Minute*3+time.Second*20)); sectorCompleted != nil {
+			t.Error("Expected no sector to be completed after going backwards")
+			return
+		}
+	})
+
+	t.Run("Approximate sectors are not overall bests", func(t *testing.T) {
+		raceControl.ApproximateTrackSectors = true
+		defer func() {
+			raceControl.ApproximateTrackSectors = false
+		}()
+
+		otherDriver, ok := raceControl.ConnectedDrivers.Get(drivers[1].DriverGUID)
+
+		if !ok {
+			t.Fatal("Driver was not correctly added to ConnectedDrivers")
+		}
+
+		var completedSectors []*RaceControlSectorCompleted
+
+		for i, splinePos := range []float32{0.9, 0.05, 0.3, 0.36} {
+			if sectorCompleted := raceControl.updateSectorTiming(otherDriver, splinePos, start.Add(time.Minute*4+time.Duration(i)*time.Second)); sectorCompleted != nil {
+				completedSectors = append(completedSectors, sectorCompleted)
+			}
+		}
+
+		if len(completedSectors) != 1 || !completedSectors[0].IsPersonalBest || completedSectors[0].IsOverallBest {
+			t.Errorf("Expected a personal best sector which is not an overall best, got: %+v", completedSectors)
+		}
+
+		raceControl.recalculateBestSectors()
+
+		if len(raceControl.BestSectors) != 0 {
+			t.Errorf("Expected no overall best sectors, got %d", len(raceControl.BestSectors))
+		}
+	})
+}
+
 type driverLapResult struct {
 	Driver        int
 	LapTime       int
@@ -768,8 +911,367 @@ var raceLapTest = []driverLapResult{ // value in comments is 'total lap time (ac
 	{Driver: 3, LapTime: 3, ExpectedPos: 2, ExpectedSplit: "2ms"},   // 32
 }
 
+func TestRaceControl_Gaps(t *testing.T) {
+	raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
+	defer raceControl.close()
+
+	raceControl.SessionInfo.Type = udp.SessionTypeRace
+
+	for _, entrant := range drivers[:3] {
+		if err := raceControl.OnClientConnect(entrant); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	start := time.Now()
+
+	// every car does a 100s lap, starting on the grid just behind the line. car 2 is 2s behind car 1, car 3 starts 110s
+	// behind car 1 (so is a lap down).
+	carStartOffsets := map[udp.CarID]time.Duration{
+		1: 0,
+		2: 2 * time.Second,
+		3: 110 * time.Second,
+	}
+
+	drive := func(from, to int) {
+		for second := from; second <= to; second++ {
+			for carID, offset := range carStartOffsets {
+				elapsed := time.Duration(second)*time.Second - offset
+
+				if elapsed < 0 {
+					continue
+				}
+
+				distance := elapsed.Seconds()/100 - 0.02
+				splinePos := float32(distance - math.Floor(distance))
+
+				raceControl.gapTracker.update(carID, raceControl.CarIDToGUID[carID], splinePos, start.Add(time.Duration(second)*time.Second))
+			}
+		}
+	}
+
+	drive(0, 250)
+
+	gaps := raceControl.updateGaps()
+
+	if len(gaps.Gaps) != 3 {
+		t.Errorf("Expected gaps for 3 drivers, got %d", len(gaps.Gaps))
+		return
+	}
+
+	expected := []struct {
+		CarID       udp.CarID
+		GapToLeader time.Duration
+		Interval    time.Duration
+		LapsDown    int
+	}{
+		{CarID: 1},
+		{CarID: 2, GapToLeader: 2 * time.Second, Interval: 2 * time.Second},
+		{CarID: 3, GapToLeader: 110 * time.Second, Interval: 108 * time.Second, LapsDown: 1},
+	}
+
+	closeTo := func(a, b time.Duration) bool {
+		return a-b < 100*time.Millisecond && b-a < 100*time.Millisecond
+	}
+
+	for i, gap := range gaps.Gaps {
+		if gap.CarID != expected[i].CarID || gap.Position != i+1 {
+			t.Errorf("Expected car %d in position %d, got car %d", expected[i].CarID, i+1, gap.CarID)
+			return
+		}
+
+		if !closeTo(gap.GapToLeader, expected[i].GapToLeader) || !closeTo(gap.Interval, expected[i].Interval) || gap.LapsDown != expected[i].LapsDown {
+			t.Errorf("Car %d: expected gap %s, interval %s, laps down %d. Got gap %s, interval %s, laps down %d", gap.CarID, expected[i].GapToLeader, expected[i].Interval, expected[i].LapsDown, gap.GapToLeader, gap.Interval, gap.LapsDown)
+			return
+		}
+	}
+
+	driver, ok := raceControl.ConnectedDrivers.Get(drivers[1].DriverGUID)
+
+	if !ok || !closeTo(driver.GapToLeader, 2*time.Second) {
+		t.Error("Expected gap to be stored on the race control driver")
+		return
+	}
+
+	t.Run("Lap completed mid-race", func(t *testing.T) {
+		// car 1 is about to complete its third lap, and the server reports it before car 1's next update. cars 2 and 3
+		// are more than half way around their laps.
+		drive(251, 301)
+
+		raceControl.gapTracker.lapCompleted(1, []*udp.LapCompletedCar{
+			{CarID: 1, Laps: 3},
+			{CarID: 2, Laps: 2},
+			{CarID: 3, Laps: 1},
+		})
+
+		gaps := raceControl.gapTracker.calculate([]udp.CarID{1, 2, 3})
+
+		if len(gaps) != 3 {
+			t.Errorf("Expected gaps for 3 drivers, got %d", len(gaps))
+			return
+		}
+
+		for i, gap := range gaps {
+			if gap.CarID != expected[i].CarID || !closeTo(gap.GapToLeader, expected[i].GapToLeader) || !closeTo(gap.Interval, expected[i].Interval) || gap.LapsDown != expected[i].LapsDown {
+				t.Errorf("Car %d: expected gap %s, interval %s, laps down %d. Got car %d, gap %s, interval %s, laps down %d", expected[i].CarID, expected[i].GapToLeader, expected[i].Interval, expected[i].LapsDown, gap.CarID, gap.GapToLeader, gap.Interval, gap.LapsDown)
+			}
+		}
+	})
+
+	t.Run("History is kept across driver swaps", func(t *testing.T) {
+		raceControl.gapTracker.driverConnected(2, "swapped-driver", true)
+
+		for _, gap := range raceControl.gapTracker.calculate([]udp.CarID{1, 2}) {
+			if gap.CarID == 2 && (gap.DriverGUID != "swapped-driver" || !closeTo(gap.GapToLeader, 2*time.Second)) {
+				t.Errorf("Expected swapped driver to keep the gap of the car, got %s (%s)", gap.GapToLeader, gap.DriverGUID)
+				return
+			}
+		}
+	})
+
+	t.Run("History is cleared when another driver takes the car", func(t *testing.T) {
+		raceControl.gapTracker.driverConnected(2, "another-driver", false)
+
+		if len(raceControl.gapTracker.calculate([]udp.CarID{1, 2})) != 1 {
+			t.Error("Expected car 2 history to be cleared")
+			return
+		}
+	})
+
+	t.Run("History moves with a driver who reconnects in another car", func(t *testing.T) {
+		raceControl.gapTracker.moveCar(3, 7)
+
+		gaps := raceControl.gapTracker.calculate([]udp.CarID{1, 7})
+
+		if len(gaps) != 2 || gaps[1].CarID != 7 || gaps[1].LapsDown != 1 {
+			t.Error("Expected car 3 history to be moved to car 7")
+			return
+		}
+	})
+}
+
+func TestRaceControl_Flags(t *testing.T) {
+	raceControl := newRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
+	raceControl.SessionInfo.Type = udp.SessionTypeRace
+
+	for _, entrant := range drivers[:3] {
+		if err := raceControl.OnClientConnect(entrant); err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	start := time.Now()
+	now := start
+
+	raceControl.now = func() time.Time {
+		return now
+	}
+
+	// every car does a 100s lap. car 3 starts 101s behind car 1, so car 1 is 1s behind car 3 on track and about
+	// to lap it.
+	carStartOffsets := map[udp.CarID]time.Duration{
+		1: 0,
+		2: 2 * time.Second,
+		3: 101 * time.Second,
+	}
+
+	for second := 0; second <= 250; second++ {
+		for carID, offset := range carStartOffsets {
+			elapsed := time.Duration(second)*time.Second - offset
+
+			if elapsed < 0 {
+				continue
+			}
+
+			distance := elapsed.Seconds()/100 - 0.02
+			splinePos := float32(distance - math.Floor(distance))
+
+			raceControl.gapTracker.update(carID, raceControl.CarIDToGUID[carID], splinePos, start.Add(time.Duration(second)*time.Second))
+		}
+	}
+
+	now = start.Add(250 * time.Second)
+
+	// car 1 is 200m behind car 2, car 3 is on the other side of the track.
+	raceControl.flags.update(1, udp.Vec{X: 0}, 0.48, 150, false, now)
+	raceControl.flags.update(2, udp.Vec{X: 200}, 0.5, 100, false, now)
+	raceControl.flags.update(3, udp.Vec{X: 5000}, 0.49, 150, false, now)
+
+	raceControl.checkFlags()
+
+	flags := func() (blue, slow []udp.CarID) {
+		_ = raceControl.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
+			if driver.BlueFlag {
+				blue = append(blue, driver.CarInfo.CarID)
+			}
+
+			if driver.SlowCar {
+				slow = append(slow, driver.CarInfo.CarID)
+			}
+
+			return nil
+		})
+
+		return blue, slow
+	}
+
+	t.Run("Blue flag is shown to a car about to be lapped", func(t *testing.T) {
+		blue, slow := flags()
+
+		if len(blue) != 1 || blue[0] != 3 {
+			t.Errorf("Expected a blue flag for car 3 only, got: %v", blue)
+		}
+
+		if len(slow) != 0 {
+			t.Errorf("Expected no slow cars, got: %v", slow)
+		}
+	})
+
+	t.Run("Slow cars are flagged after slowCarDuration", func(t *testing.T) {
+		now = now.Add(time.Second)
+		raceControl.flags.update(2, udp.Vec{X: 201}, 0.5, 5, false, now)
+		raceControl.checkFlags()
+
+		if _, slow := flags(); len(slow) != 0 {
+			t.Errorf("Expected no slow cars yet, got: %v", slow)
+			return
+		}
+
+		now = now.Add(slowCarDuration)
+		raceControl.flags.update(1, udp.Vec{X: 0}, 0.48, 150, false, now)
+		raceControl.flags.update(2, udp.Vec{X: 201}, 0.5, 0, false, now)
+		raceControl.checkFlags()
+
+		if _, slow := flags(); len(slow) != 1 || slow[0] != 2 {
+			t.Errorf("Expected car 2 to be slow, got: %v", slow)
+			return
+		}
+
+		if approaching := raceControl.flags.approachingCars(2, now); len(approaching) != 1 || approaching[0] != 1 {
+			t.Errorf("Expected car 1 to be approaching car 2, got: %v", approaching)
+		}
+	})
+
+	t.Run("Yellow flag is withdrawn when the car speeds up", func(t *testing.T) {
+		now = now.Add(time.Second)
+		raceControl.flags.update(2, udp.Vec{X: 220}, 0.51, 60, false, now)
+		raceControl.checkFlags()
+
+		if _, slow := flags(); len(slow) != 0 {
+			t.Errorf("Expected no slow cars, got: %v", slow)
+		}
+	})
+
+	t.Run("Cars returned to the pits are not flagged", func(t *testing.T) {
+		now = now.Add(time.Second)
+		raceControl.flags.update(2, udp.Vec{X: 3000}, 0.9, 0, false, now)
+
+		now = now.Add(slowCarDuration + time.Second)
+		raceControl.flags.update(2, udp.Vec{X: 3000}, 0.9, 0, false, now)
+		raceControl.checkFlags()
+
+		if _, slow := flags(); len(slow) != 0 {
+			t.Errorf("Expected no slow cars, got: %v", slow)
+		}
+	})
+}
+
+func TestRaceControl_PitStops(t *testing.T) {
+	tracker := newPitStopTracker()
+	config := PitLaneConfig{Enabled: true, EntrySplinePos: 0.9, ExitSplinePos: 0.1, SpeedLimit: 80}
+	carInfo := udp.SessionCarInfo{CarID: 1, DriverGUID: drivers[0].DriverGUID, DriverName: drivers[0].DriverName, CarModel: drivers[0].CarModel}
+
+	start := time.Now()
+
+	update := func(splinePos float32, speed float64, elapsed time.Duration) *RaceControlPitLane {
+		return tracker.update(config, carInfo.CarID, carInfo, 3, splinePos, speed, start.Add(elapsed), elapsed)
+	}
+
+	t.Run("Cars waiting on the grid are not in the pit lane", func(t *testing.T) {
+		if pitLane := update(0.95, 0, 0); pitLane != nil {
+			t.Errorf("Expected no pit lane message, got: %v", pitLane)
+		}
+	})
+
+	update(0.2, 200, time.Second)
+
+	t.Run("A pit stop is timed from pit lane entry to exit", func(t *testing.T) {
+		if pitLane := update(0.92, 70, 10*time.Second); pitLane == nil || !pitLane.InPitLane {
+			t.Errorf("Expected the car to enter the pit lane, got: %v", pitLane)
+			return
+		}
+
+		update(0.98, 0, 20*time.Second)
+		update(0.98, 0, 30*time.Second)
+		update(0.99, 40, 45*time.Second)
+		update(0.05, 75, 50*time.Second)
+
+		pitLane := update(0.12, 100, 55*time.Second)
+
+		if pitLane == nil || pitLane.InPitLane || pitLane.PitStop == nil {
+			t.Errorf("Expected the car to leave the pit lane after a pit stop, got: %v", pitLane)
+			return
+		}
+
+		if pitLane.NumPitStops != 1 || pitLane.PitStop.StationaryTime != 25*time.Second || pitLane.PitStop.PitLaneTime != 45*time.Second || pitLane.PitStop.EntryTime != 10*time.Second {
+			t.Errorf("Incorrect pit stop: %d stops, %+v", pitLane.NumPitStops, pitLane.PitStop)
+		}
+	})
+
+	t.Run("Drive-throughs are not pit stops", func(t *testing.T) {
+		update(0.92, 70, 100*time.Second)
+		pitLane := update(0.12, 100, 120*time.Second)
+
+		if pitLane == nil || pitLane.PitStop != nil || pitLane.NumPitStops != 1 {
+			t.Errorf("Expected the car to leave the pit lane without a pit stop, got: %v", pitLane)
+		}
+	})
+
+	t.Run("Driver swaps continue the pit stop", func(t *testing.T) {
+		update(0.92, 70, 200*time.Second)
+		update(0.98, 0, 210*time.Second)
+
+		tracker.carLoaded(carInfo.CarID, drivers[1].DriverGUID, drivers[1].DriverName)
+		carInfo = udp.SessionCarInfo{CarID: 1, DriverGUID: drivers[1].DriverGUID, DriverName: drivers[1].DriverName, CarModel: drivers[0].CarModel}
+
+		update(0.98, 0, 300*time.Second)
+		update(0.99, 40, 310*time.Second)
+		pitLane := update(0.12, 100, 320*time.Second)
+
+		if pitLane == nil || pitLane.PitStop == nil || !pitLane.PitStop.IsDriverSwap() || pitLane.PitStop.DriverGUID != drivers[0].DriverGUID {
+			t.Errorf("Expected a driver swap pit stop, got: %v", pitLane)
+			return
+		}
+
+		if pitLane.NumPitStops != 2 || pitLane.PitStop.StationaryTime != 100*time.Second {
+			t.Errorf("Incorrect pit stop: %d stops, %+v", pitLane.NumPitStops, pitLane.PitStop)
+		}
+
+		if pitStops := tracker.completedPitStops(); len(pitStops) != 2 {
+			t.Errorf("Expected 2 completed pit stops, got %d", len(pitStops))
+		}
+	})
+
+	t.Run("Cars in the pit lane are not slow cars", func(t *testing.T) {
+		flags := newRaceFlagTracker()
+		now := start
+
+		flags.update(1, udp.Vec{}, 0.5, 150, false, now)
+		now = now.Add(time.Second)
+		flags.update(1, udp.Vec{}, 0.95, 0, true, now)
+		now = now.Add(slowCarDuration * 2)
+		flags.update(1, udp.Vec{}, 0.95, 0, true, now)
+
+		if slow, _ := flags.checkSlowCars(now); len(slow) != 0 {
+			t.Errorf("Expected no slow cars, got: %v", slow)
+		}
+	})
+}
+
 func TestRaceControl_OnLapCompleted(t *testing.T) {
-	raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+	raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 
 	if err := raceControl.OnVersion(udp.Version(4)); err != nil {
 		t.Error(err)
@@ -858,6 +1360,28 @@ func TestRaceControl_OnLapCompleted(t *testing.T) {
 		}
 	}
 
+	t.Run("Position history is kept for each lap", func(t *testing.T) {
+		history := raceControl.history.history()
+		lapIndexes := make(map[udp.DriverGUID]int)
+
+		for _, driver := range raceLapTest {
+			guid := drivers[driver.Driver].DriverGUID
+			laps := history.Drivers[guid]
+			i := lapIndexes[guid]
+
+			if i >= len(laps) {
+				t.Errorf("Expected a lap history for driver %d's lap %d", driver.Driver, i+1)
+				continue
+			}
+
+			if laps[i].Lap != i+1 || laps[i].Position != driver.ExpectedPos {
+				t.Errorf("Expected driver %d to be P%d on lap %d, history has P%d on lap %d", driver.Driver, driver.ExpectedPos, i+1, laps[i].Position, laps[i].Lap)
+			}
+
+			lapIndexes[guid]++
+		}
+	})
+
 	t.Run("Driver not found", func(t *testing.T) {
 		err := raceControl.OnLapCompleted(udp.LapCompleted{
 			CarID:   110,
@@ -871,9 +1395,62 @@ func TestRaceControl_OnLapCompleted(t *testing.T) {
 	})
 }
 
+func TestRaceControl_History(t *testing.T) {
+	tracker := newRaceHistoryTracker()
+	guid := drivers[0].DriverGUID
+
+	t.Run("Speed samples are not taken until the driver crosses the line", func(t *testing.T) {
+		tracker.carUpdate(guid, 0.9, 200)
+		tracker.carUpdate(guid, 0.95, 210)
+
+		lap := tracker.lapCompleted(guid, 1, "ks_mazda_mx5_cup", 1, 1, time.Minute, 0)
+
+		if len(lap.SpeedSamples) != 0 {
+			t.Errorf("Expected no speed samples, got %d", len(lap.SpeedSamples))
+		}
+	})
+
+	t.Run("Speed samples are limited per lap", func(t *testing.T) {
+		for i := 0; i < speedSamplesPerLap*5; i++ {
+			tracker.carUpdate(guid, float32(i)/float32(speedSamplesPerLap*5), 100)
+		}
+
+		// the car's spline position wraps before the lap is completed.
+		tracker.carUpdate(guid, 0.001, 100)
+
+		lap := tracker.lapCompleted(guid, 1, "ks_mazda_mx5_cup", 2, 1, time.Minute, 0)
+
+		if len(lap.SpeedSamples) != speedSamplesPerLap {
+			t.Errorf("Expected %d speed samples, got %d", speedSamplesPerLap, len(lap.SpeedSamples))
+		}
+	})
+
+	t.Run("History is bounded", func(t *testing.T) {
+		for lapNum := 3; lapNum <= maxHistoryLaps+10; lapNum++ {
+			tracker.carUpdate(guid, 0.1, 100)
+			tracker.lapCompleted(guid, 1, "ks_mazda_mx5_cup", lapNum, 1, time.Minute, 0)
+		}
+
+		laps := tracker.history().Drivers[guid]
+
+		if len(laps) != maxHistoryLaps || laps[len(laps)-1].Lap != maxHistoryLaps+10 {
+			t.Errorf("Expected the last %d laps, got %d", maxHistoryLaps, len(laps))
+			return
+		}
+
+		for i, lap := range laps {
+			hasSamples := len(lap.SpeedSamples) > 0
+
+			if hasSamples != (i >= len(laps)-maxSpeedTraceLaps) {
+				t.Errorf("Expected speed samples for the last %d laps only, lap %d has %d", maxSpeedTraceLaps, lap.Lap, len(lap.SpeedSamples))
+			}
+		}
+	})
+}
+
 func TestRaceControl_SortDrivers(t *testing.T) {
 	t.Run("Race, connected drivers", func(t *testing.T) {
-		rc := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+		rc := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 		rc.SessionInfo.Type = udp.SessionTypeRace
 
 		d0 := NewRaceControlDriver(drivers[0])
@@ -911,7 +1488,7 @@ func TestRaceControl_SortDrivers(t *testing.T) {
 
 	t.Run("Non-race, connected drivers", func(t *testing.T) {
 		t.Run("Two drivers with valid laps, two without", func(t *testing.T) {
-			rc := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+			rc := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 			rc.SessionInfo.Type = udp.SessionTypePractice
 
 			d0 := NewRaceControlDriver(drivers[0])
@@ -959,7 +1536,7 @@ func TestRaceControl_SortDrivers(t *testing.T) {
 	})
 
 	t.Run("Race, disconnected drivers", func(t *testing.T) {
-		rc := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+		rc := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 		rc.SessionInfo.Type = udp.SessionTypeRace
 
 		d0 := NewRaceControlDriver(drivers[0])
@@ -998,7 +1575,7 @@ func TestRaceControl_SortDrivers(t *testing.T) {
 	})
 
 	t.Run("Non-Race, disconnected drivers", func(t *testing.T) {
-		rc := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+		rc := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 		rc.SessionInfo.Type = udp.SessionTypeQualifying
 
 		d0 := NewRaceControlDriver(drivers[0])
@@ -1039,7 +1616,7 @@ func TestRaceControl_SortDrivers(t *testing.T) {
 
 func TestRaceControl_OnSessionUpdate(t *testing.T) {
 	t.Run("Session update", func(t *testing.T) {
-		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+		raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 
 		if err := raceControl.OnVersion(udp.Version(4)); err != nil {
 			t.Error(err)
@@ -1111,10 +1688,94 @@ func TestRaceControl_OnSessionUpdate(t *testing.T) {
 }
 
 func TestRaceControl_Event(t *testing.T) {
-	rc := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore))
+	rc := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, testStore, NewPenaltiesManager(testStore, NewResultsRevisionManager(testStore, NewResultsIndex(testStore))), NewResultsRevisionManager(testStore, NewResultsIndex(testStore)))
 
-	if rc.Event() != 200 {
+	if rc.Event() != EventRaceControl {
 		t.Error("Expected Race Control event to be 200")
 		return
 	}
 }
+
+func TestRaceControl_TrackLimits(t *testing.T) {
+	config := TrackLimitsConfig{
+		Enabled:             true,
+		WarningCuts:         3,
+		TimePenaltyCuts:     5,
+		TimePenaltySeconds:  5,
+		DriveThroughCuts:    8,
+		DriveThroughSeconds: 20,
+		DisqualifyCuts:      10,
+	}
+
+	guid := drivers[0].DriverGUID
+	carModel := drivers[0].CarModel
+
+	t.Run("Disabled", func(t *testing.T) {
+		tracker := newTrackLimitsTracker()
+
+		disabledConfig := config
+		disabledConfig.Enabled = false
+
+		if message := tracker.lapCompleted(disabledConfig, guid, carModel, 20); message != "" {
+			t.Errorf("Expected no message, got: %s", message)
+		}
+
+		if len(tracker.penalties()) != 0 {
+			t.Errorf("Expected no penalties")
+		}
+	})
+
+	t.Run("Thresholds", func(t *testing.T) {
+		tracker := newTrackLimitsTracker()
+
+		for lap, expected := range []struct {
+			cuts    int
+			message bool
+			penalty time.Duration
+		}{
+			{cuts: 2, message: false, penalty: 0},
+			{cuts: 1, message: true, penalty: 0},                // 3 cuts, warning
+			{cuts: 1, message: true, penalty: 0},                // 4 cuts, reminder
+			{cuts: 0, message: false, penalty: 0},               // 4 cuts, clean lap
+			{cuts: 1, message: true, penalty: 5 * time.Second},  // 5 cuts, time penalty
+			{cuts: 3, message: true, penalty: 25 * time.Second}, // 8 cuts, drive-through
+		} {
+			message := tracker.lapCompleted(config, guid, carModel, expected.cuts)
+
+			if (message != "") != expected.message {
+				t.Errorf("Lap %d: unexpected message: %q", lap, message)
+			}
+
+			if penalty := tracker.penalties()[guid].penalty; penalty != expected.penalty {
+				t.Errorf("Lap %d: expected penalty %s, got %s", lap, expected.penalty, penalty)
+			}
+		}
+
+		tracker.lapCompleted(config, guid, carModel, 2)
+
+		if driver := tracker.penalties()[guid]; !driver.disqualified || driver.cuts != 10 || driver.carModel != carModel {
+			t.Errorf("Expected driver to be disqualified at 10 cuts, got: %+v", driver)
+		}
+
+		if message := tracker.lapCompleted(config, guid, carModel, 1); message != "" {
+			t.Errorf("Expected no further messages once disqualified, got: %s", message)
+		}
+
+		tracker.reset()
+
+		if len(tracker.penalties()) != 0 {
+			t.Errorf("Expected reset to clear penalties")
+		}
+	})
+
+	t.Run("Most severe action is taken", func(t *testing.T) {
+		tracker := newTrackLimitsTracker()
+
+		// 9 cuts in one lap passes the warning, time penalty and drive-through thresholds.
+		tracker.lapCompleted(config, guid, carModel, 9)
+
+		if penalty := tracker.penalties()[guid].penalty; penalty != 20*time.Second {
+			t.Errorf("Expected only the drive-through penalty, got %s", penalty)
+		}
+	})
+}
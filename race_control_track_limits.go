@@ -0,0 +1,173 @@
+package servermanager
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+type trackLimitsAction int
+
+const (
+	trackLimitsActionNone trackLimitsAction = iota
+	trackLimitsActionWarning
+	trackLimitsActionTimePenalty
+	trackLimitsActionDriveThrough
+	trackLimitsActionDisqualify
+)
+
+// driverTrackLimits is the number of cuts that a driver has made in the current session, and the penalties they
+// have been given for them.
+type driverTrackLimits struct {
+	carModel     string
+	cuts         int
+	penalty      time.Duration
+	disqualified bool
+}
+
+// trackLimitsTracker enforces the TrackLimitsConfig of an event.
+type trackLimitsTracker struct {
+	drivers map[udp.DriverGUID]*driverTrackLimits
+	mutex   sync.Mutex
+}
+
+func newTrackLimitsTracker() *trackLimitsTracker {
+	return &trackLimitsTracker{
+		drivers: make(map[udp.DriverGUID]*driverTrackLimits),
+	}
+}
+
+func (t *trackLimitsTracker) reset() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.drivers = make(map[udp.DriverGUID]*driverTrackLimits)
+}
+
+// lapCompleted adds the cuts from a lap to the driver's total, and returns the message that should be sent to the
+// driver, if any.
+func (t *trackLimitsTracker) lapCompleted(config TrackLimitsConfig, guid udp.DriverGUID, carModel string, cuts int) string {
+	if !config.Enabled || cuts <= 0 {
+		return ""
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	driver, ok := t.drivers[guid]
+
+	if !ok {
+		driver = &driverTrackLimits{}
+		t.drivers[guid] = driver
+	}
+
+	if driver.disqualified {
+		return ""
+	}
+
+	previousCuts := driver.cuts
+	driver.cuts += cuts
+	driver.carModel = carModel
+
+	// if a lap takes the driver past more than one threshold, the most severe action is taken.
+	action := trackLimitsActionNone
+
+	for _, rule := range []struct {
+		cuts   int
+		action trackLimitsAction
+	}{
+		{config.WarningCuts, trackLimitsActionWarning},
+		{config.TimePenaltyCuts, trackLimitsActionTimePenalty},
+		{config.DriveThroughCuts, trackLimitsActionDriveThrough},
+		{config.DisqualifyCuts, trackLimitsActionDisqualify},
+	} {
+		if rule.cuts > 0 && previousCuts < rule.cuts && driver.cuts >= rule.cuts {
+			action = rule.action
+		}
+	}
+
+	switch action {
+	case trackLimitsActionTimePenalty:
+		penalty := time.Duration(config.TimePenaltySeconds) * time.Second
+		driver.penalty += penalty
+
+		return fmt.Sprintf("Track limits: %d cuts. You have been given a %s time penalty.", driver.cuts, penalty)
+	case trackLimitsActionDriveThrough:
+		penalty := time.Duration(config.DriveThroughSeconds) * time.Second
+		driver.penalty += penalty
+
+		return fmt.Sprintf("Track limits: %d cuts. You have been given a drive-through penalty, which will be added to your race time as %s.", driver.cuts, penalty)
+	case trackLimitsActionDisqualify:
+		driver.disqualified = true
+
+		return fmt.Sprintf("Track limits: %d cuts. You have been disqualified.", driver.cuts)
+	case trackLimitsActionWarning:
+		return fmt.Sprintf("Track limits warning: %d cuts. %s", driver.cuts, nextTrackLimitsAction(config, driver.cuts))
+	}
+
+	if config.WarningCuts > 0 && driver.cuts >= config.WarningCuts {
+		return fmt.Sprintf("Track limits: %d cuts. %s", driver.cuts, nextTrackLimitsAction(config, driver.cuts))
+	}
+
+	return ""
+}
+
+// nextTrackLimitsAction describes what will happen to a driver with the given number of cuts if they continue to
+// exceed track limits.
+func nextTrackLimitsAction(config TrackLimitsConfig, cuts int) string {
+	switch {
+	case config.TimePenaltyCuts > cuts:
+		return fmt.Sprintf("A %ds penalty will be given at %d cuts.", config.TimePenaltySeconds, config.TimePenaltyCuts)
+	case config.DriveThroughCuts > cuts:
+		return fmt.Sprintf("A drive-through penalty will be given at %d cuts.", config.DriveThroughCuts)
+	case config.DisqualifyCuts > cuts:
+		return fmt.Sprintf("You will be disqualified at %d cuts.", config.DisqualifyCuts)
+	default:
+		return "Please respect track limits."
+	}
+}
+
+// penalties returns a copy of the track limits of each driver who has been penalised in the current session.
+func (t *trackLimitsTracker) penalties() map[udp.DriverGUID]driverTrackLimits {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	penalties := make(map[udp.DriverGUID]driverTrackLimits)
+
+	for guid, driver := range t.drivers {
+		if driver.penalty > 0 || driver.disqualified {
+			penalties[guid] = *driver
+		}
+	}
+
+	return penalties
+}
+
+// applyTrackLimitsPenalties records the track limits penalties given in a session in its results file.
+func (rc *RaceControl) applyTrackLimitsPenalties(filename string) {
+	for guid, driver := range rc.trackLimits.penalties() {
+		penalty := &Penalty{
+			Type:   PenaltyTypeTime,
+			Time:   driver.penalty,
+			Reason: fmt.Sprintf("Track Limits (%d cuts)", driver.cuts),
+		}
+
+		if driver.disqualified {
+			penalty.Type = PenaltyTypeDisqualification
+			penalty.Time = 0
+		}
+
+		err := rc.penaltiesManager.AddPenalty(filename, string(guid), driver.carModel, penalty)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("could not apply track limits penalty to driver %s", guid)
+			continue
+		}
+
+		logrus.Infof("Applied track limits penalty to driver: %s (%d cuts)", guid, driver.cuts)
+	}
+}
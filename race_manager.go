@@ -660,6 +660,25 @@ func (rm *RaceManager) BuildCustomRaceFromForm(r *http.Request) (*CurrentRaceCon
 		ResultScreenTime:          formValueAsInt(r.FormValue("ResultScreenTime")),
 		DisableDRSZones:           formValueAsInt(r.FormValue("DisableDRSZones")) == 1,
 
+		TrackLimits: TrackLimitsConfig{
+			Enabled:             formValueAsInt(r.FormValue("TrackLimitsEnabled")) == 1,
+			WarningCuts:         formValueAsInt(r.FormValue("TrackLimitsWarningCuts")),
+			TimePenaltyCuts:     formValueAsInt(r.FormValue("TrackLimitsTimePenaltyCuts")),
+			TimePenaltySeconds:  formValueAsInt(r.FormValue("TrackLimitsTimePenaltySeconds")),
+			DriveThroughCuts:    formValueAsInt(r.FormValue("TrackLimitsDriveThroughCuts")),
+			DriveThroughSeconds: formValueAsInt(r.FormValue("TrackLimitsDriveThroughSeconds")),
+			DisqualifyCuts:      formValueAsInt(r.FormValue("TrackLimitsDisqualifyCuts")),
+		},
+
+		FullCourseYellowSpeedLimit: formValueAsInt(r.FormValue("FullCourseYellowSpeedLimit")),
+
+		PitLane: PitLaneConfig{
+			Enabled:        formValueAsInt(r.FormValue("PitLaneEnabled")) == 1,
+			EntrySplinePos: formValueAsFloat(r.FormValue("PitLaneEntrySplinePos")),
+			ExitSplinePos:  formValueAsFloat(r.FormValue("PitLaneExitSplinePos")),
+			SpeedLimit:     formValueAsInt(r.FormValue("PitLaneSpeedLimit")),
+		},
+
 		TimeAttack: timeAttack,
 	}
 
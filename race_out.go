@@ -0,0 +1,263 @@
+package servermanager
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// RaceOut is the race_out.json format written by the Assetto Corsa game client at the end of an offline session, and
+// read by sim racing results sites.
+type RaceOut struct {
+	Extras           []RaceOutExtra   `json:"extras"`
+	NumberOfSessions int              `json:"number_of_sessions"`
+	Players          []RaceOutPlayer  `json:"players"`
+	Sessions         []RaceOutSession `json:"sessions"`
+	Track            string           `json:"track"`
+}
+
+type RaceOutSession struct {
+	BestLaps   []RaceOutBestLap `json:"bestLaps"`
+	Duration   int              `json:"duration"`
+	Event      int              `json:"event"`
+	Laps       []RaceOutLaps    `json:"laps"`
+	LapsCount  int              `json:"lapsCount"`
+	Lapstotal  []int            `json:"lapstotal"`
+	Name       string           `json:"name"`
+	RaceResult []int            `json:"raceResult"`
+	Type       int              `json:"type"`
+}
+
+type RaceOutLaps struct {
+	Car     int    `json:"car"`
+	Cuts    int    `json:"cuts"`
+	Lap     int    `json:"lap"`
+	Sectors []int  `json:"sectors"`
+	Time    int    `json:"time"`
+	Tyre    string `json:"tyre"`
+}
+
+type RaceOutBestLap struct {
+	Car  int `json:"car"`
+	Lap  int `json:"lap"`
+	Time int `json:"time"`
+}
+
+type RaceOutPlayer struct {
+	Car  string `json:"car"`
+	Name string `json:"name"`
+	Skin string `json:"skin"`
+}
+
+type RaceOutExtra struct {
+	Name string `json:"name"`
+	Time int    `json:"time"`
+}
+
+const (
+	raceOutSessionTypePractice   = 1
+	raceOutSessionTypeQualifying = 2
+	raceOutSessionTypeRace       = 3
+)
+
+// NewRaceOut converts SessionResults into a RaceOut with a single session. Cars are numbered in the order of their
+// CarIDs.
+func NewRaceOut(results *SessionResults) *RaceOut {
+	raceOut := &RaceOut{
+		NumberOfSessions: 1,
+		Track:            results.TrackName,
+	}
+
+	cars := make([]*SessionCar, len(results.Cars))
+	copy(cars, results.Cars)
+
+	sort.SliceStable(cars, func(i, j int) bool {
+		return cars[i].CarID < cars[j].CarID
+	})
+
+	carIndexes := make(map[int]int)
+
+	for i, car := range cars {
+		carIndexes[car.CarID] = i
+
+		raceOut.Players = append(raceOut.Players, RaceOutPlayer{
+			Car:  car.Model,
+			Name: car.Driver.Name,
+			Skin: car.Skin,
+		})
+	}
+
+	session := RaceOutSession{
+		Name:      results.Type.String(),
+		Lapstotal: make([]int, len(cars)),
+	}
+
+	switch results.Type {
+	case SessionTypePractice:
+		session.Type = raceOutSessionTypePractice
+	case SessionTypeQualifying:
+		session.Type = raceOutSessionTypeQualifying
+	case SessionTypeRace:
+		session.Type = raceOutSessionTypeRace
+	}
+
+	bestLaps := make(map[int]RaceOutBestLap)
+
+	for _, lap := range results.Laps {
+		car, ok := carIndexes[lap.CarID]
+
+		if !ok {
+			continue
+		}
+
+		raceOutLap := RaceOutLaps{
+			Car:     car,
+			Cuts:    lap.Cuts,
+			Lap:     session.Lapstotal[car],
+			Sectors: lap.Sectors,
+			Time:    lap.LapTime,
+			Tyre:    lap.Tyre,
+		}
+
+		session.Laps = append(session.Laps, raceOutLap)
+		session.Lapstotal[car]++
+
+		if best, ok := bestLaps[car]; lap.Cuts == 0 && (!ok || lap.LapTime < best.Time) {
+			bestLaps[car] = RaceOutBestLap{Car: car, Lap: raceOutLap.Lap, Time: lap.LapTime}
+		}
+	}
+
+	for car := range cars {
+		if best, ok := bestLaps[car]; ok {
+			session.BestLaps = append(session.BestLaps, best)
+		}
+	}
+
+	for _, result := range results.Result {
+		car, ok := carIndexes[result.CarID]
+
+		if !ok {
+			continue
+		}
+
+		session.RaceResult = append(session.RaceResult, car)
+
+		if results.Type == SessionTypeRace && session.Lapstotal[car] > session.LapsCount {
+			session.LapsCount = session.Lapstotal[car]
+		}
+	}
+
+	if fastestLap := results.FastestLap(); fastestLap != nil && fastestLap.Cuts == 0 {
+		raceOut.Extras = append(raceOut.Extras, RaceOutExtra{Name: "bestlap", Time: fastestLap.LapTime})
+	}
+
+	raceOut.Sessions = append(raceOut.Sessions, session)
+
+	return raceOut
+}
+
+// SessionResults converts each session in the RaceOut into SessionResults. The race_out.json format only has driver
+// names, so nameToGUID is used to look up the GUID of each driver. It also does not record the track layout or when
+// the sessions took place, so every session is given the trackConfig and date passed in.
+func (ro *RaceOut) SessionResults(nameToGUID map[string]string, trackConfig string, date time.Time) []*SessionResults {
+	var out []*SessionResults
+
+	for _, session := range ro.Sessions {
+		out = append(out, ro.sessionResults(session, nameToGUID, trackConfig, date))
+	}
+
+	return out
+}
+
+func (ro *RaceOut) sessionResults(session RaceOutSession, nameToGUID map[string]string, trackConfig string, date time.Time) *SessionResults {
+	var sessionType SessionType
+
+	switch session.Type {
+	case raceOutSessionTypePractice:
+		sessionType = SessionTypePractice
+	case raceOutSessionTypeQualifying:
+		sessionType = SessionTypeQualifying
+	case raceOutSessionTypeRace:
+		sessionType = SessionTypeRace
+	}
+
+	results := &SessionResults{
+		TrackName:   ro.Track,
+		TrackConfig: trackConfig,
+		Type:        sessionType,
+	}
+
+	results.UpdateDate(date)
+	results.SessionFile = strings.TrimSuffix(results.SessionFile, ".json")
+
+	for carID, player := range ro.Players {
+		results.Cars = append(results.Cars, &SessionCar{
+			CarID: carID,
+			Driver: SessionDriver{
+				GUID:      nameToGUID[player.Name],
+				GuidsList: []string{nameToGUID[player.Name]},
+				Name:      player.Name,
+			},
+			Model: player.Car,
+			Skin:  player.Skin,
+		})
+	}
+
+	lapTimestamps := make(map[int]int)
+
+	for _, lap := range session.Laps {
+		if lap.Car < 0 || lap.Car >= len(results.Cars) {
+			continue
+		}
+
+		lapTimestamps[lap.Car] += lap.Time
+
+		results.Laps = append(results.Laps, &SessionLap{
+			CarID:      lap.Car,
+			CarModel:   results.Cars[lap.Car].Model,
+			Cuts:       lap.Cuts,
+			DriverGUID: results.Cars[lap.Car].Driver.GUID,
+			DriverName: results.Cars[lap.Car].Driver.Name,
+			LapTime:    lap.Time,
+			Sectors:    lap.Sectors,
+			Timestamp:  lapTimestamps[lap.Car],
+			Tyre:       lap.Tyre,
+		})
+	}
+
+	sort.SliceStable(results.Laps, func(i, j int) bool {
+		return results.Laps[i].Timestamp < results.Laps[j].Timestamp
+	})
+
+	for _, carID := range session.RaceResult {
+		if carID < 0 || carID >= len(results.Cars) {
+			continue
+		}
+
+		totalTime := 0
+		bestLap := 0
+
+		for _, lap := range session.Laps {
+			if lap.Car != carID {
+				continue
+			}
+
+			totalTime += lap.Time
+
+			if lap.Cuts == 0 && (bestLap == 0 || lap.Time < bestLap) {
+				bestLap = lap.Time
+			}
+		}
+
+		results.Result = append(results.Result, &SessionResult{
+			BestLap:    bestLap,
+			CarID:      carID,
+			CarModel:   results.Cars[carID].Model,
+			DriverGUID: results.Cars[carID].Driver.GUID,
+			DriverName: results.Cars[carID].Driver.Name,
+			TotalTime:  totalTime,
+		})
+	}
+
+	return results
+}
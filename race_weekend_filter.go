@@ -109,6 +109,16 @@ func (f RaceWeekendSessionToSessionFilter) Filter(raceWeekend *RaceWeekend, pare
 		}
 	}
 
+	if parentSession.IsBase() {
+		if !childSession.IsBase() {
+			// championship grid penalties are applied to the sessions which start from the race weekend entry list
+			parentSessionResults = applyGridPenalties(parentSessionResults, raceWeekend.championshipGridPenalties())
+		}
+	} else if parentSession.Completed() {
+		// drivers given a grid drop in the parent session are moved back on the grid of the child session
+		parentSessionResults = applyGridPenalties(parentSessionResults, sessionGridPenalties(parentSessionResults))
+	}
+
 	entryListStart := f.EntryListStart - 1
 
 	var split []*RaceWeekendSessionEntrant
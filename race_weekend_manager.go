@@ -29,6 +29,9 @@ type RaceWeekendManager struct {
 	process             ServerProcess
 	acsrClient          *ACSRClient
 
+	// resultsRevisionManager saves the results of race weekend sessions.
+	resultsRevisionManager *ResultsRevisionManager
+
 	activeRaceWeekend *ActiveRaceWeekend
 	mutex             sync.Mutex
 
@@ -44,15 +47,17 @@ func NewRaceWeekendManager(
 	notificationManager NotificationDispatcher,
 	acsrClient *ACSRClient,
 	carManager *CarManager,
+	resultsRevisionManager *ResultsRevisionManager,
 ) *RaceWeekendManager {
 	return &RaceWeekendManager{
-		raceManager:         raceManager,
-		championshipManager: championshipManager,
-		notificationManager: notificationManager,
-		store:               store,
-		process:             process,
-		acsrClient:          acsrClient,
-		carManager:          carManager,
+		raceManager:            raceManager,
+		championshipManager:    championshipManager,
+		notificationManager:    notificationManager,
+		store:                  store,
+		process:                process,
+		acsrClient:             acsrClient,
+		carManager:             carManager,
+		resultsRevisionManager: resultsRevisionManager,
 
 		scheduledSessionTimers:         make(map[string]*when.Timer),
 		scheduledSessionReminderTimers: make(map[string]*when.Timer),
@@ -523,6 +528,12 @@ func (rwm *RaceWeekendManager) StartSession(raceWeekendID string, raceWeekendSes
 		if err := rwm.UpsertRaceWeekend(raceWeekend); err != nil {
 			return err
 		}
+
+		if raceWeekend.HasLinkedChampionship() && session.HasParent(raceWeekend.ID.String()) {
+			if err := rwm.championshipManager.applyRaceWeekendGridPenalties(raceWeekend); err != nil {
+				return err
+			}
+		}
 	}
 
 	raceWeekendEntryList, err := session.GetRaceWeekendEntryList(raceWeekend, nil, "")
@@ -662,13 +673,25 @@ func (rwm *RaceWeekendManager) UDPCallback(message udp.Message) {
 
 		raceWeekend.EnhanceResults(results)
 
-		err = saveResults(filename, results)
+		err = rwm.resultsRevisionManager.UpdateResults(strings.TrimSuffix(filename, ".json"), results, "Race weekend information added")
 
 		if err != nil {
 			logrus.WithError(err).Errorf("Could not update session results %s", filename)
 			return
 		}
 
+		var decidedTitlesBefore map[string]*championshipTitle
+
+		if raceWeekend.HasLinkedChampionship() {
+			championship, err := rwm.championshipManager.LoadChampionship(raceWeekend.ChampionshipID.String())
+
+			if err != nil {
+				logrus.WithError(err).Errorf("Could not load linked championship for race weekend: %s", raceWeekend.ID.String())
+			} else {
+				decidedTitlesBefore = decidedTitles(championship)
+			}
+		}
+
 		session.Results = results
 
 		if err := rwm.UpsertRaceWeekend(raceWeekend); err != nil {
@@ -676,6 +699,16 @@ func (rwm *RaceWeekendManager) UDPCallback(message udp.Message) {
 			return
 		}
 
+		if decidedTitlesBefore != nil {
+			championship, err := rwm.championshipManager.LoadChampionship(raceWeekend.ChampionshipID.String())
+
+			if err != nil {
+				logrus.WithError(err).Errorf("Could not load linked championship for race weekend: %s", raceWeekend.ID.String())
+			} else {
+				rwm.championshipManager.notifyDecidedTitles(championship, decidedTitlesBefore)
+			}
+		}
+
 		if err := rwm.process.Stop(); err != nil {
 			logrus.WithError(err).Error("Could not stop assetto server process")
 		}
@@ -852,29 +885,55 @@ func (rwm *RaceWeekendManager) ImportSession(raceWeekendID string, raceWeekendSe
 		return err
 	}
 
-	filename := r.FormValue("ResultFile") + ".json"
+	sessionFile := r.FormValue("ResultFile")
 
-	session.Results, err = LoadResult(filename)
+	results, err := LoadResult(sessionFile + ".json")
 
 	if err != nil {
 		return err
 	}
 
-	raceWeekend.EnhanceResults(session.Results)
+	return rwm.importSessionResults(raceWeekend, session, sessionFile, results)
+}
 
-	err = saveResults(filename, session.Results)
+// ImportSessionResults attaches results which are not yet saved (e.g. those converted from another source) to a
+// race weekend session.
+func (rwm *RaceWeekendManager) ImportSessionResults(raceWeekendID string, raceWeekendSessionID string, results *SessionResults) error {
+	if !Premium() {
+		return errors.New("servermanager: premium required")
+	}
+
+	raceWeekend, err := rwm.LoadRaceWeekend(raceWeekendID)
 
 	if err != nil {
 		return err
 	}
 
+	session, err := raceWeekend.FindSessionByID(raceWeekendSessionID)
+
+	if err != nil {
+		return err
+	}
+
+	return rwm.importSessionResults(raceWeekend, session, results.SessionFile, results)
+}
+
+func (rwm *RaceWeekendManager) importSessionResults(raceWeekend *RaceWeekend, session *RaceWeekendSession, sessionFile string, results *SessionResults) error {
+	session.Results = results
+
+	raceWeekend.EnhanceResults(session.Results)
+
+	if err := rwm.resultsRevisionManager.UpdateResults(sessionFile, session.Results, "Imported into the race weekend"); err != nil {
+		return err
+	}
+
 	session.CompletedTime = session.Results.Date
 
 	return rwm.UpsertRaceWeekend(raceWeekend)
 }
 
 func (rwm *RaceWeekendManager) ListAvailableResultsFilesForSorting(raceWeekend *RaceWeekend, session *RaceWeekendSession) ([]SessionResults, error) {
-	results, err := ListAllResults()
+	results, err := rwm.raceManager.trackManager.ResultsForLayout(session.RaceConfig.Track, session.RaceConfig.TrackLayout)
 
 	if err != nil {
 		return nil, err
@@ -897,7 +956,7 @@ func (rwm *RaceWeekendManager) ListAvailableResultsFilesForSorting(raceWeekend *
 			}
 		}
 
-		if result.TrackName == session.RaceConfig.Track && result.TrackConfig == session.RaceConfig.TrackLayout && found {
+		if found {
 			filteredResults = append(filteredResults, result)
 		}
 	}
@@ -918,21 +977,13 @@ func (rwm *RaceWeekendManager) ListAvailableResultsFilesForSession(raceWeekendID
 		return nil, nil, err
 	}
 
-	results, err := ListAllResults()
+	results, err := rwm.raceManager.trackManager.ResultsForLayout(session.RaceConfig.Track, session.RaceConfig.TrackLayout)
 
 	if err != nil {
 		return nil, nil, err
 	}
 
-	var filteredResults []SessionResults
-
-	for _, result := range results {
-		if result.TrackName == session.RaceConfig.Track && result.TrackConfig == session.RaceConfig.TrackLayout {
-			filteredResults = append(filteredResults, result)
-		}
-	}
-
-	return session, filteredResults, nil
+	return session, results, nil
 }
 
 func (rwm *RaceWeekendManager) FindSession(raceWeekendID, sessionID string) (*RaceWeekend, *RaceWeekendSession, error) {
@@ -0,0 +1,125 @@
+package servermanager
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+type ReplayHandler struct {
+	*BaseHandler
+
+	store         Store
+	replayManager *ReplayManager
+	replayHub     *RaceControlHub
+}
+
+func NewReplayHandler(baseHandler *BaseHandler, store Store, replayManager *ReplayManager, replayHub *RaceControlHub) *ReplayHandler {
+	return &ReplayHandler{
+		BaseHandler:   baseHandler,
+		store:         store,
+		replayManager: replayManager,
+		replayHub:     replayHub,
+	}
+}
+
+// view loads the replay of a session and shows it in the Live Timings page.
+func (rh *ReplayHandler) view(w http.ResponseWriter, r *http.Request) {
+	fileName := chi.URLParam(r, "fileName")
+
+	err := rh.replayManager.Load(fileName)
+
+	if err == ErrReplayNotFound {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Errorf("could not load replay for: %s", fileName)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	status, err := rh.replayManager.Status(fileName)
+
+	if err != nil {
+		logrus.WithError(err).Errorf("could not get replay status for: %s", fileName)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	serverOpts, err := rh.store.LoadServerOptions()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't load server options")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	rh.viewRenderer.MustLoadTemplate(w, r, "live-timing.html", &liveTimingTemplateVars{
+		BaseTemplateVars: BaseTemplateVars{
+			WideContainer: true,
+		},
+		RaceDetails:     &CustomRace{},
+		CSSDotSmoothing: udp.RealtimePosIntervalMs,
+		UseMPH:          serverOpts.UseMPH == 1,
+		Replay:          status,
+	})
+}
+
+// control plays, pauses, seeks or changes the speed of the loaded replay.
+func (rh *ReplayHandler) control(w http.ResponseWriter, r *http.Request) {
+	fileName := chi.URLParam(r, "fileName")
+
+	if err := r.ParseForm(); err != nil {
+		logrus.WithError(err).Errorf("could not parse replay control form")
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	var err error
+
+	switch chi.URLParam(r, "action") {
+	case "play":
+		err = rh.replayManager.Play(fileName)
+	case "pause":
+		err = rh.replayManager.Pause(fileName)
+	case "seek":
+		minutes, _ := strconv.Atoi(r.FormValue("Minutes"))
+		seconds, _ := strconv.Atoi(r.FormValue("Seconds"))
+
+		err = rh.replayManager.Seek(fileName, time.Duration(minutes)*time.Minute+time.Duration(seconds)*time.Second)
+	case "speed":
+		var speed float64
+
+		speed, err = strconv.ParseFloat(r.FormValue("Speed"), 64)
+
+		if err == nil {
+			err = rh.replayManager.SetSpeed(fileName, speed)
+		}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	if err != nil {
+		logrus.WithError(err).Errorf("could not control replay for: %s", fileName)
+		AddErrorFlash(w, r, "Unable to control the replay, please try again.")
+	}
+
+	http.Redirect(w, r, "/results/"+fileName+"/replay", http.StatusFound)
+}
+
+func (rh *ReplayHandler) websocket(w http.ResponseWriter, r *http.Request) {
+	raceControl := rh.replayManager.RaceControl()
+
+	if raceControl == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	serveRaceControlWebsocket(w, r, rh.replayHub, raceControl)
+}
@@ -0,0 +1,367 @@
+package servermanager
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/etcd-io/bbolt"
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp/replay"
+)
+
+// replayInitialSeek skips over the burst of messages at the start of a recording (the session starting and the
+// drivers that were already connected), so that a replay has something to show before it is played.
+var replayInitialSeek = time.Second
+
+var (
+	ErrReplayNotFound  = errors.New("servermanager: replay not found")
+	ErrReplayNotLoaded = errors.New("servermanager: replay is not loaded")
+)
+
+// ReplayManager plays back recordings of previous sessions through a separate RaceControl, which is broadcast to
+// its own websocket hub. Only one replay can be loaded at a time.
+type ReplayManager struct {
+	store Store
+	hub   *RaceControlHub
+
+	mutex   sync.Mutex
+	current *sessionReplay
+}
+
+func NewReplayManager(store Store, hub *RaceControlHub) *ReplayManager {
+	return &ReplayManager{
+		store: store,
+		hub:   hub,
+	}
+}
+
+type sessionReplay struct {
+	resultsFile string
+	player      *replay.Player
+	clock       *replayClock
+
+	// raceControl and broadcaster are replaced every time the replay is seeked, as RaceControl can only be
+	// built up by playing messages forwards.
+	raceControl *RaceControl
+	broadcaster *replayBroadcaster
+}
+
+// ReplayStatus describes the state of the loaded replay.
+type ReplayStatus struct {
+	ResultsFile string
+	Position    time.Duration
+	Duration    time.Duration
+	Playing     bool
+	Speed       float64
+}
+
+// RoundedPosition and RoundedDuration are rounded to the nearest second for display.
+func (rs ReplayStatus) RoundedPosition() time.Duration {
+	return rs.Position.Round(time.Second)
+}
+
+func (rs ReplayStatus) RoundedDuration() time.Duration {
+	return rs.Duration.Round(time.Second)
+}
+
+// Load opens the replay for resultsFile, unless it is already loaded. The replay starts paused.
+func (rm *ReplayManager) Load(resultsFile string) error {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	if rm.current != nil && rm.current.resultsFile == resultsFile {
+		return nil
+	}
+
+	if !ReplayExists(resultsFile) {
+		return ErrReplayNotFound
+	}
+
+	db, err := bbolt.Open(replayPath(resultsFile), 0644, &bbolt.Options{Timeout: time.Second, ReadOnly: true})
+
+	if err != nil {
+		return err
+	}
+
+	entries, err := replay.LoadEntries(db)
+
+	if closeErr := db.Close(); closeErr != nil {
+		logrus.WithError(closeErr).Errorf("Could not close replay: %s", resultsFile)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	rm.unload()
+
+	sr := &sessionReplay{
+		resultsFile: resultsFile,
+		clock:       &replayClock{},
+	}
+
+	sr.player = replay.NewPlayer(entries, func(entry *replay.Entry) {
+		// the player calls this with its lock held, so the race control cannot be replaced part way through.
+		sr.clock.set(entry.Received)
+		sr.raceControl.UDPCallback(entry.Data)
+	})
+
+	rm.current = sr
+	rm.seek(sr, replayInitialSeek)
+
+	go panicCapture(sr.player.Run)
+
+	logrus.Infof("Loaded replay for results file: %s (%d messages)", resultsFile, len(entries))
+
+	return nil
+}
+
+// unload stops the current replay. It must be called with the mutex held.
+func (rm *ReplayManager) unload() {
+	if rm.current == nil {
+		return
+	}
+
+	rm.current.player.Stop()
+	rm.current.broadcaster.mute(true)
+	rm.current.raceControl.close()
+	rm.current = nil
+}
+
+// seek rebuilds the replay's RaceControl from every message up to offset. It must be called with the mutex held.
+func (rm *ReplayManager) seek(sr *sessionReplay, offset time.Duration) {
+	sr.player.Seek(offset, func(entries replay.Entries) {
+		if sr.raceControl != nil {
+			sr.broadcaster.mute(true)
+			sr.raceControl.close()
+		}
+
+		sr.broadcaster = &replayBroadcaster{hub: rm.hub, muted: true}
+		sr.raceControl = newReplayRaceControl(sr.broadcaster, rm.store, sr.clock)
+
+		// every message is played, as sector times, gaps and flags are all worked out from the car updates between
+		// laps.
+		for _, entry := range entries {
+			sr.clock.set(entry.Received)
+			sr.raceControl.UDPCallback(entry.Data)
+		}
+
+		sr.broadcaster.mute(false)
+		sr.raceControl.broadcastStatus()
+	})
+}
+
+func (rm *ReplayManager) loadedReplay(resultsFile string) (*sessionReplay, error) {
+	if rm.current == nil || rm.current.resultsFile != resultsFile {
+		return nil, ErrReplayNotLoaded
+	}
+
+	return rm.current, nil
+}
+
+func (rm *ReplayManager) Play(resultsFile string) error {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	sr, err := rm.loadedReplay(resultsFile)
+
+	if err != nil {
+		return err
+	}
+
+	sr.player.Play()
+
+	return nil
+}
+
+func (rm *ReplayManager) Pause(resultsFile string) error {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	sr, err := rm.loadedReplay(resultsFile)
+
+	if err != nil {
+		return err
+	}
+
+	sr.player.Pause()
+
+	return nil
+}
+
+func (rm *ReplayManager) Seek(resultsFile string, offset time.Duration) error {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	sr, err := rm.loadedReplay(resultsFile)
+
+	if err != nil {
+		return err
+	}
+
+	rm.seek(sr, offset)
+
+	return nil
+}
+
+func (rm *ReplayManager) SetSpeed(resultsFile string, multiplier float64) error {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	sr, err := rm.loadedReplay(resultsFile)
+
+	if err != nil {
+		return err
+	}
+
+	return sr.player.SetSpeed(multiplier)
+}
+
+func (rm *ReplayManager) Status(resultsFile string) (*ReplayStatus, error) {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	sr, err := rm.loadedReplay(resultsFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &ReplayStatus{
+		ResultsFile: sr.resultsFile,
+		Position:    sr.player.Position(),
+		Duration:    sr.player.Duration(),
+		Playing:     sr.player.Playing(),
+		Speed:       sr.player.Speed(),
+	}, nil
+}
+
+// RaceControl is the RaceControl of the currently loaded replay, if there is one.
+func (rm *ReplayManager) RaceControl() *RaceControl {
+	rm.mutex.Lock()
+	defer rm.mutex.Unlock()
+
+	if rm.current == nil {
+		return nil
+	}
+
+	return rm.current.raceControl
+}
+
+func newReplayRaceControl(broadcaster Broadcaster, store Store, clock *replayClock) *RaceControl {
+	rc := newRaceControl(broadcaster, filesystemTrackData{}, replayServerProcess{}, replayStore{store}, nil, nil)
+	rc.replaying = true
+	rc.now = clock.now
+
+	go panicCapture(rc.broadcastGaps)
+
+	return rc
+}
+
+// replayClock is the time that the message currently being replayed was originally received.
+type replayClock struct {
+	mutex sync.RWMutex
+	time  time.Time
+}
+
+func (c *replayClock) set(t time.Time) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.time = t
+}
+
+func (c *replayClock) now() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.time
+}
+
+// replayBroadcaster sends messages to the replay websocket hub. It is muted while a replay is being seeked so that
+// clients aren't flooded with messages.
+type replayBroadcaster struct {
+	hub *RaceControlHub
+
+	mutex sync.RWMutex
+	muted bool
+}
+
+func (b *replayBroadcaster) mute(muted bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.muted = muted
+}
+
+func (b *replayBroadcaster) Send(message udp.Message) ([]byte, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	if b.muted {
+		// seeking plays every message up to the new position, encoding them all would be far too slow.
+		return nil, nil
+	}
+
+	encoded, err := encodeRaceControlMessage(message)
+
+	if err != nil {
+		return nil, err
+	}
+
+	b.hub.broadcast <- encoded
+
+	return encoded, nil
+}
+
+// replayStore stops a replay from overwriting (or loading) the persisted live timings of the actual server.
+type replayStore struct {
+	Store
+}
+
+func (replayStore) UpsertLiveTimingsData(*LiveTimingsPersistedData) error {
+	return nil
+}
+
+func (replayStore) LoadLiveTimingsData() (*LiveTimingsPersistedData, error) {
+	return nil, nil
+}
+
+// replayServerProcess stands in for the Assetto Corsa server during a replay. Messages that RaceControl would send
+// to the server (e.g. welcome messages) are discarded.
+type replayServerProcess struct{}
+
+func (replayServerProcess) Start(event RaceEvent, udpPluginAddress string, udpPluginLocalPort int, forwardingAddress string, forwardListenPort int) error {
+	return nil
+}
+
+func (replayServerProcess) Stop() error {
+	return nil
+}
+
+func (replayServerProcess) Restart() error {
+	return nil
+}
+
+func (replayServerProcess) IsRunning() bool {
+	return false
+}
+
+func (replayServerProcess) Event() RaceEvent {
+	return &CustomRace{}
+}
+
+func (replayServerProcess) UDPCallback(message udp.Message) {}
+
+func (replayServerProcess) SendUDPMessage(message udp.Message) error {
+	return nil
+}
+
+func (replayServerProcess) NotifyDone(chan struct{}) {}
+
+func (replayServerProcess) Logs() string {
+	return ""
+}
@@ -0,0 +1,179 @@
+package servermanager
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etcd-io/bbolt"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp/replay"
+)
+
+// writeTestReplay records messages as the replay of resultsFile, each received at the given offset from the start of
+// the session.
+func writeTestReplay(t *testing.T, resultsFile string, offsets []time.Duration, messages []udp.Message) {
+	t.Helper()
+
+	if err := os.MkdirAll(replaysPath(), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := bbolt.Open(replayPath(resultsFile), 0644, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	start := time.Date(2019, 3, 2, 21, 0, 0, 0, time.UTC)
+
+	for i, message := range messages {
+		if err := replay.RecordUDPMessage(db, start.Add(offsets[i]), message); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReplayManager_Seek(t *testing.T) {
+	dir, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	const resultsFile = "2019_3_2_21_36_RACE.json"
+
+	writeTestReplay(t, resultsFile, []time.Duration{
+		0,
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		10 * time.Second,
+		20 * time.Second,
+	}, []udp.Message{
+		udp.SessionInfo{Track: "ks_laguna_seca", Type: udp.SessionTypeRace, Laps: 10, EventType: udp.EventNewSession},
+		drivers[0],
+		udp.CarUpdate{CarID: drivers[0].CarID, NormalisedSplinePos: 0.5},
+		udp.LapCompleted{CarID: drivers[0].CarID, LapTime: 100000, Cars: []*udp.LapCompletedCar{{CarID: drivers[0].CarID, LapTime: 100000, Laps: 1}}},
+		udp.EndSession(filepath.Join(dir, "results", resultsFile)),
+	})
+
+	results, err := ioutil.ReadFile(filepath.Join(dir, "results", resultsFile))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	replayManager := NewReplayManager(testStore, newRaceControlHub())
+
+	if err := replayManager.Load(resultsFile); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func() {
+		replayManager.mutex.Lock()
+		replayManager.unload()
+		replayManager.mutex.Unlock()
+	}()
+
+	numLaps := func(t *testing.T) int {
+		t.Helper()
+
+		driver, ok := replayManager.RaceControl().ConnectedDrivers.Get(drivers[0].DriverGUID)
+
+		if !ok {
+			t.Fatal("Expected the driver to be connected in the replay")
+		}
+
+		return driver.TotalNumLaps
+	}
+
+	// the replay starts after the session start and connections.
+	if numLaps(t) != 0 {
+		t.Errorf("Expected the driver to have no laps when the replay is loaded")
+	}
+
+	raceControl := replayManager.RaceControl()
+
+	t.Run("Forwards", func(t *testing.T) {
+		if err := replayManager.Seek(resultsFile, 15*time.Second); err != nil {
+			t.Fatal(err)
+		}
+
+		if replayManager.RaceControl() == raceControl {
+			t.Errorf("Expected the replay race control to be rebuilt")
+		}
+
+		if numLaps(t) != 1 {
+			t.Errorf("Expected the driver to have completed a lap")
+		}
+
+		status, err := replayManager.Status(resultsFile)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if status.Position != 10*time.Second || status.Duration != 20*time.Second || status.Playing {
+			t.Errorf("Incorrect status: %+v", status)
+		}
+	})
+
+	t.Run("Past the end of the session", func(t *testing.T) {
+		if err := replayManager.Seek(resultsFile, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+
+		replayed, err := ioutil.ReadFile(filepath.Join(dir, "results", resultsFile))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(results, replayed) {
+			t.Errorf("Expected the results file not to be changed by the end of the replayed session")
+		}
+
+	})
+
+	t.Run("End of session with a full course yellow", func(t *testing.T) {
+		if err := replayManager.Seek(resultsFile, 15*time.Second); err != nil {
+			t.Fatal(err)
+		}
+
+		// a live race control would save the full course yellow to the results file at the end of the session.
+		raceControl := replayManager.RaceControl()
+
+		if err := raceControl.StartFullCourseYellow("Steward"); err != nil {
+			t.Fatal(err)
+		}
+
+		raceControl.UDPCallback(udp.EndSession(filepath.Join(dir, "results", resultsFile)))
+
+		replayed, err := ioutil.ReadFile(filepath.Join(dir, "results", resultsFile))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(results, replayed) {
+			t.Errorf("Expected the replay race control not to save full course yellows to the results file")
+		}
+	})
+
+	t.Run("Backwards", func(t *testing.T) {
+		if err := replayManager.Seek(resultsFile, time.Second); err != nil {
+			t.Fatal(err)
+		}
+
+		if numLaps(t) != 0 {
+			t.Errorf("Expected the lap to be undone")
+		}
+	})
+
+	if err := replayManager.Seek("another_results_file.json", 0); err != ErrReplayNotLoaded {
+		t.Errorf("Expected a replay that isn't loaded not to be seeked, got: %v", err)
+	}
+}
@@ -0,0 +1,204 @@
+package servermanager
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/etcd-io/bbolt"
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp/replay"
+)
+
+const (
+	replaysFolder       = "replays"
+	replayRecordingFile = "recording.tmp.db"
+)
+
+func replaysPath() string {
+	return filepath.Join(ServerInstallPath, replaysFolder)
+}
+
+// replayPath is the location of the recording of the session that output resultsFile.
+func replayPath(resultsFile string) string {
+	return filepath.Join(replaysPath(), strings.TrimSuffix(filepath.Base(resultsFile), ".json")+".db")
+}
+
+// ReplayExists reports whether there is a recording of the session that output resultsFile.
+func ReplayExists(resultsFile string) bool {
+	_, err := os.Stat(replayPath(resultsFile))
+
+	return err == nil
+}
+
+// deleteReplay removes the recording of the session that output resultsFile, if there is one.
+func deleteReplay(resultsFile string) error {
+	err := os.Remove(replayPath(resultsFile))
+
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+type recordedMessage struct {
+	received time.Time
+	message  udp.Message
+}
+
+// ReplayRecorder records the UDP messages of each session into its own bbolt database, so that the session can be
+// replayed through Live Timings after it has finished. Recordings are renamed to match the results file of the
+// session once it ends.
+type ReplayRecorder struct {
+	store       Store
+	raceControl *RaceControl
+
+	messages chan recordedMessage
+	db       *bbolt.DB
+	stopped  chan struct{}
+}
+
+func NewReplayRecorder(store Store, raceControl *RaceControl) *ReplayRecorder {
+	rr := &ReplayRecorder{
+		store:       store,
+		raceControl: raceControl,
+		messages:    make(chan recordedMessage, 10000),
+		stopped:     make(chan struct{}),
+	}
+
+	go panicCapture(rr.run)
+
+	return rr
+}
+
+// UDPCallback queues a message to be recorded. It must be called after RaceControl has handled the message.
+func (rr *ReplayRecorder) UDPCallback(message udp.Message) {
+	received := time.Now()
+
+	rr.record(received, message)
+
+	if message.Event() != udp.EventNewSession {
+		return
+	}
+
+	// drivers that were connected in the previous session are still connected, but the replay won't know about them
+	// unless the recording starts with their connections.
+	_ = rr.raceControl.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, driver *RaceControlDriver) error {
+		carInfo := driver.CarInfo
+		carInfo.EventType = udp.EventNewConnection
+
+		// entries are keyed by the time they were received, so each must be unique.
+		received = received.Add(time.Nanosecond)
+
+		rr.record(received, carInfo)
+
+		return nil
+	})
+}
+
+func (rr *ReplayRecorder) record(received time.Time, message udp.Message) {
+	select {
+	case rr.messages <- recordedMessage{received: received, message: message}:
+	default:
+		logrus.Warnf("Replay recorder is falling behind, dropped message: %d", message.Event())
+	}
+}
+
+// close records any queued messages, then stops the recorder. It must not be called while messages are still being
+// passed to UDPCallback.
+func (rr *ReplayRecorder) close() {
+	close(rr.messages)
+	<-rr.stopped
+}
+
+func (rr *ReplayRecorder) run() {
+	defer close(rr.stopped)
+
+	for m := range rr.messages {
+		if m.message.Event() == udp.EventNewSession {
+			if err := rr.startRecording(); err != nil {
+				logrus.WithError(err).Errorf("Could not start recording session replay")
+			}
+		}
+
+		if rr.db == nil {
+			continue
+		}
+
+		if err := replay.RecordUDPMessage(rr.db, m.received, m.message); err != nil {
+			logrus.WithError(err).Errorf("Could not record udp message for session replay")
+		}
+
+		if endSession, ok := m.message.(udp.EndSession); ok {
+			if err := rr.finishRecording(string(endSession)); err != nil {
+				logrus.WithError(err).Errorf("Could not save session replay")
+			}
+		}
+	}
+}
+
+func (rr *ReplayRecorder) startRecording() error {
+	if rr.db != nil {
+		// the previous session never ended (e.g. the server was stopped), so there are no results to attach it to.
+		logrus.Debugf("Discarding unfinished session replay")
+
+		if err := rr.db.Close(); err != nil {
+			return err
+		}
+
+		rr.db = nil
+	}
+
+	serverOptions, err := rr.store.LoadServerOptions()
+
+	if err != nil {
+		return err
+	}
+
+	if serverOptions.RecordSessionReplays != 1 {
+		return nil
+	}
+
+	if err := os.MkdirAll(replaysPath(), 0755); err != nil {
+		return err
+	}
+
+	recordingPath := filepath.Join(replaysPath(), replayRecordingFile)
+
+	if err := os.Remove(recordingPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// every message is written in its own transaction, syncing each one would be far too slow.
+	db, err := bbolt.Open(recordingPath, 0644, &bbolt.Options{Timeout: time.Second, NoSync: true})
+
+	if err != nil {
+		return err
+	}
+
+	rr.db = db
+
+	return nil
+}
+
+func (rr *ReplayRecorder) finishRecording(resultsFile string) error {
+	db := rr.db
+	rr.db = nil
+
+	if err := db.Sync(); err != nil {
+		_ = db.Close()
+		return err
+	}
+
+	if err := db.Close(); err != nil {
+		return err
+	}
+
+	logrus.Infof("Session replay saved for results file: %s", filepath.Base(resultsFile))
+
+	return os.Rename(filepath.Join(replaysPath(), replayRecordingFile), replayPath(resultsFile))
+}
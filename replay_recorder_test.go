@@ -0,0 +1,94 @@
+package servermanager
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etcd-io/bbolt"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp/replay"
+)
+
+func TestReplayRecorder(t *testing.T) {
+	dir, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	store := NewJSONStore(filepath.Join(dir, "store"), filepath.Join(dir, "store-shared"))
+
+	serverOptions, err := store.LoadServerOptions()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverOptions.RecordSessionReplays = 1
+
+	if err := store.UpsertServerOptions(serverOptions); err != nil {
+		t.Fatal(err)
+	}
+
+	raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, store, nil, nil)
+	defer raceControl.close()
+
+	// drivers[0] is still connected from the previous session.
+	if err := raceControl.OnClientConnect(drivers[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	const resultsFile = "2019_3_2_21_36_RACE.json"
+
+	messages := []udp.Message{
+		udp.SessionInfo{Track: "ks_laguna_seca", Type: udp.SessionTypeRace, Laps: 10, EventType: udp.EventNewSession},
+		udp.CarUpdate{CarID: drivers[0].CarID, NormalisedSplinePos: 0.5},
+		udp.Chat{CarID: drivers[0].CarID, Message: "hello"},
+		udp.EndSession(filepath.Join(dir, "results", resultsFile)),
+	}
+
+	recorder := NewReplayRecorder(store, raceControl)
+
+	for _, message := range messages {
+		recorder.UDPCallback(message)
+	}
+
+	// messages are recorded in the background, closing the recorder waits for them all to be recorded.
+	recorder.close()
+
+	if !ReplayExists(resultsFile) {
+		t.Fatal("Expected the replay to be saved alongside the results file")
+	}
+
+	db, err := bbolt.Open(replayPath(resultsFile), 0644, &bbolt.Options{Timeout: time.Second, ReadOnly: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	entries, err := replay.LoadEntries(db)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	driver, ok := raceControl.ConnectedDrivers.Get(drivers[0].DriverGUID)
+
+	if !ok {
+		t.Fatal("Expected the driver to be connected")
+	}
+
+	// the connected driver is recorded at the start of the session, so that they are connected in the replay.
+	expected := []udp.Message{messages[0], driver.CarInfo, messages[1], messages[2], messages[3]}
+
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(entries))
+	}
+
+	for i, entry := range entries {
+		if entry.Data != expected[i] {
+			t.Errorf("Entry %d: expected %#v, got %#v", i, expected[i], entry.Data)
+		}
+	}
+}
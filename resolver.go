@@ -21,12 +21,23 @@ type Resolver struct {
 	scheduledRacesManager *ScheduledRacesManager
 	raceWeekendManager    *RaceWeekendManager
 
-	viewRenderer          *Renderer
-	serverProcess         ServerProcess
-	raceControl           *RaceControl
-	raceControlHub        *RaceControlHub
-	contentManagerWrapper *ContentManagerWrapper
-	acsrClient            *ACSRClient
+	viewRenderer           *Renderer
+	serverProcess          ServerProcess
+	raceControl            *RaceControl
+	raceControlHub         *RaceControlHub
+	contentManagerWrapper  *ContentManagerWrapper
+	acsrClient             *ACSRClient
+	replayRecorder         *ReplayRecorder
+	replayManager          *ReplayManager
+	replayHub              *RaceControlHub
+	stewardsManager        *StewardsManager
+	raceControlAPI         *RaceControlAPI
+	driverProfileManager   *DriverProfileManager
+	leaderboardManager     *LeaderboardManager
+	resultsIndex           *ResultsIndex
+	resultsImportManager   *ResultsImportManager
+	resultsRevisionManager *ResultsRevisionManager
+	lapAnomalyManager      *LapAnomalyManager
 
 	// handlers
 	baseHandler                 *BaseHandler
@@ -41,6 +52,7 @@ type Resolver struct {
 	penaltiesHandler            *PenaltiesHandler
 	penaltiesManager            *PenaltiesManager
 	resultsHandler              *ResultsHandler
+	resultsImportHandler        *ResultsImportHandler
 	scheduledRacesHandler       *ScheduledRacesHandler
 	contentUploadHandler        *ContentUploadHandler
 	raceControlHandler          *RaceControlHandler
@@ -50,6 +62,12 @@ type Resolver struct {
 	healthCheck                 *HealthCheck
 	kissMyRankHandler           *KissMyRankHandler
 	realPenaltyHandler          *RealPenaltyHandler
+	replayHandler               *ReplayHandler
+	stewardsHandler             *StewardsHandler
+	raceControlAPIHandler       *RaceControlAPIHandler
+	driverProfilesHandler       *DriverProfilesHandler
+	leaderboardsHandler         *LeaderboardsHandler
+	lapAnomaliesHandler         *LapAnomaliesHandler
 }
 
 func NewResolver(templateLoader TemplateLoader, reloadTemplates bool, store Store) (*Resolver, error) {
@@ -73,6 +91,9 @@ func NewResolver(templateLoader TemplateLoader, reloadTemplates bool, store Stor
 func (r *Resolver) UDPCallback(message udp.Message) {
 	if !config.Server.PerformanceMode {
 		r.ResolveRaceControl().UDPCallback(message)
+		r.resolveReplayRecorder().UDPCallback(message)
+		r.resolveStewardsManager().UDPCallback(message)
+		r.resolveRaceControlAPI().UDPCallback(message)
 	}
 
 	if message.Event() != udp.EventCarUpdate {
@@ -80,6 +101,8 @@ func (r *Resolver) UDPCallback(message udp.Message) {
 		r.resolveRaceWeekendManager().UDPCallback(message)
 		r.resolveRaceManager().LoopCallback(message)
 		r.resolveContentManagerWrapper().UDPCallback(message)
+		r.resolveResultsIndex().UDPCallback(message)
+		r.resolveLapAnomalyManager().UDPCallback(message)
 	}
 }
 
@@ -229,6 +252,7 @@ func (r *Resolver) resolveCarManager() *CarManager {
 
 	r.carManager = NewCarManager(
 		r.resolveTrackManager(),
+		r.resolveResultsIndex(),
 		config.Server.ScanContentFolderForChanges,
 		config.Server.UseCarNameCache,
 	)
@@ -254,6 +278,7 @@ func (r *Resolver) resolveChampionshipManager() *ChampionshipManager {
 	r.championshipManager = NewChampionshipManager(
 		r.resolveRaceManager(),
 		r.acsrClient,
+		r.resolveResultsRevisionManager(),
 	)
 
 	return r.championshipManager
@@ -274,7 +299,7 @@ func (r *Resolver) resolveTrackManager() *TrackManager {
 		return r.trackManager
 	}
 
-	r.trackManager = NewTrackManager()
+	r.trackManager = NewTrackManager(r.resolveResultsIndex())
 
 	return r.trackManager
 }
@@ -310,25 +335,70 @@ func (r *Resolver) resolvePenaltiesHandler() *PenaltiesHandler {
 }
 
 func (r *Resolver) resolvePenaltiesManager() *PenaltiesManager {
-	if r.penaltiesHandler != nil {
+	if r.penaltiesManager != nil {
 		return r.penaltiesManager
 	}
 
-	r.penaltiesManager = NewPenaltiesManager(r.ResolveStore())
+	r.penaltiesManager = NewPenaltiesManager(r.ResolveStore(), r.resolveResultsRevisionManager())
 
 	return r.penaltiesManager
 }
 
+func (r *Resolver) resolveResultsIndex() *ResultsIndex {
+	if r.resultsIndex != nil {
+		return r.resultsIndex
+	}
+
+	r.resultsIndex = NewResultsIndex(r.ResolveStore())
+
+	return r.resultsIndex
+}
+
+func (r *Resolver) resolveResultsRevisionManager() *ResultsRevisionManager {
+	if r.resultsRevisionManager != nil {
+		return r.resultsRevisionManager
+	}
+
+	r.resultsRevisionManager = NewResultsRevisionManager(r.ResolveStore(), r.resolveResultsIndex())
+
+	return r.resultsRevisionManager
+}
+
 func (r *Resolver) resolveResultsHandler() *ResultsHandler {
 	if r.resultsHandler != nil {
 		return r.resultsHandler
 	}
 
-	r.resultsHandler = NewResultsHandler(r.resolveBaseHandler(), r.ResolveStore())
+	r.resultsHandler = NewResultsHandler(r.resolveBaseHandler(), r.ResolveStore(), r.resolveResultsIndex(), r.resolveResultsRevisionManager())
 
 	return r.resultsHandler
 }
 
+func (r *Resolver) resolveResultsImportManager() *ResultsImportManager {
+	if r.resultsImportManager != nil {
+		return r.resultsImportManager
+	}
+
+	r.resultsImportManager = NewResultsImportManager(r.resolveChampionshipManager(), r.resolveRaceWeekendManager(), r.resolveResultsRevisionManager())
+
+	return r.resultsImportManager
+}
+
+func (r *Resolver) resolveResultsImportHandler() *ResultsImportHandler {
+	if r.resultsImportHandler != nil {
+		return r.resultsImportHandler
+	}
+
+	r.resultsImportHandler = NewResultsImportHandler(
+		r.resolveBaseHandler(),
+		r.resolveResultsImportManager(),
+		r.resolveChampionshipManager(),
+		r.resolveRaceWeekendManager(),
+	)
+
+	return r.resultsImportHandler
+}
+
 func (r *Resolver) resolveScheduledRacesManager() *ScheduledRacesManager {
 	if r.scheduledRacesManager != nil {
 		return r.scheduledRacesManager
@@ -399,6 +469,7 @@ func (r *Resolver) ResolveRaceControl() *RaceControl {
 		r.resolveServerProcess(),
 		r.ResolveStore(),
 		r.resolvePenaltiesManager(),
+		r.resolveResultsRevisionManager(),
 	)
 
 	return r.raceControl
@@ -425,6 +496,152 @@ func (r *Resolver) resolveRaceControlHandler() *RaceControlHandler {
 	return r.raceControlHandler
 }
 
+func (r *Resolver) resolveReplayRecorder() *ReplayRecorder {
+	if r.replayRecorder != nil {
+		return r.replayRecorder
+	}
+
+	r.replayRecorder = NewReplayRecorder(r.ResolveStore(), r.ResolveRaceControl())
+
+	return r.replayRecorder
+}
+
+func (r *Resolver) resolveReplayHub() *RaceControlHub {
+	if r.replayHub != nil {
+		return r.replayHub
+	}
+
+	r.replayHub = newRaceControlHub()
+	go panicCapture(r.replayHub.run)
+
+	return r.replayHub
+}
+
+func (r *Resolver) resolveReplayManager() *ReplayManager {
+	if r.replayManager != nil {
+		return r.replayManager
+	}
+
+	r.replayManager = NewReplayManager(r.ResolveStore(), r.resolveReplayHub())
+
+	return r.replayManager
+}
+
+func (r *Resolver) resolveReplayHandler() *ReplayHandler {
+	if r.replayHandler != nil {
+		return r.replayHandler
+	}
+
+	r.replayHandler = NewReplayHandler(
+		r.resolveBaseHandler(),
+		r.ResolveStore(),
+		r.resolveReplayManager(),
+		r.resolveReplayHub(),
+	)
+
+	return r.replayHandler
+}
+
+func (r *Resolver) resolveStewardsManager() *StewardsManager {
+	if r.stewardsManager != nil {
+		return r.stewardsManager
+	}
+
+	r.stewardsManager = NewStewardsManager(r.ResolveStore(), r.ResolveRaceControl(), r.resolvePenaltiesManager())
+
+	return r.stewardsManager
+}
+
+func (r *Resolver) resolveStewardsHandler() *StewardsHandler {
+	if r.stewardsHandler != nil {
+		return r.stewardsHandler
+	}
+
+	r.stewardsHandler = NewStewardsHandler(r.resolveBaseHandler(), r.resolveStewardsManager())
+
+	return r.stewardsHandler
+}
+
+func (r *Resolver) resolveRaceControlAPI() *RaceControlAPI {
+	if r.raceControlAPI != nil {
+		return r.raceControlAPI
+	}
+
+	r.raceControlAPI = NewRaceControlAPI(r.ResolveStore(), r.ResolveRaceControl())
+
+	return r.raceControlAPI
+}
+
+func (r *Resolver) resolveRaceControlAPIHandler() *RaceControlAPIHandler {
+	if r.raceControlAPIHandler != nil {
+		return r.raceControlAPIHandler
+	}
+
+	r.raceControlAPIHandler = NewRaceControlAPIHandler(r.resolveRaceControlAPI())
+
+	return r.raceControlAPIHandler
+}
+
+func (r *Resolver) resolveDriverProfileManager() *DriverProfileManager {
+	if r.driverProfileManager != nil {
+		return r.driverProfileManager
+	}
+
+	r.driverProfileManager = NewDriverProfileManager(r.ResolveStore(), r.resolveResultsIndex())
+
+	return r.driverProfileManager
+}
+
+func (r *Resolver) resolveDriverProfilesHandler() *DriverProfilesHandler {
+	if r.driverProfilesHandler != nil {
+		return r.driverProfilesHandler
+	}
+
+	r.driverProfilesHandler = NewDriverProfilesHandler(r.resolveBaseHandler(), r.ResolveStore(), r.resolveDriverProfileManager())
+
+	return r.driverProfilesHandler
+}
+
+func (r *Resolver) resolveLeaderboardManager() *LeaderboardManager {
+	if r.leaderboardManager != nil {
+		return r.leaderboardManager
+	}
+
+	r.leaderboardManager = NewLeaderboardManager(r.resolveResultsIndex())
+
+	return r.leaderboardManager
+}
+
+func (r *Resolver) resolveLeaderboardsHandler() *LeaderboardsHandler {
+	if r.leaderboardsHandler != nil {
+		return r.leaderboardsHandler
+	}
+
+	r.leaderboardsHandler = NewLeaderboardsHandler(r.resolveBaseHandler(), r.ResolveStore(), r.resolveLeaderboardManager())
+
+	return r.leaderboardsHandler
+}
+
+func (r *Resolver) resolveLapAnomalyManager() *LapAnomalyManager {
+	if r.lapAnomalyManager != nil {
+		return r.lapAnomalyManager
+	}
+
+	r.lapAnomalyManager = NewLapAnomalyManager(r.ResolveStore(), r.resolveLeaderboardManager(), r.resolveNotificationManager())
+
+	return r.lapAnomalyManager
+}
+
+func (r *Resolver) resolveLapAnomaliesHandler() *LapAnomaliesHandler {
+	if r.lapAnomaliesHandler != nil {
+		return r.lapAnomaliesHandler
+	}
+
+	r.lapAnomaliesHandler = NewLapAnomaliesHandler(r.resolveBaseHandler(), r.resolveLapAnomalyManager())
+
+	return r.lapAnomaliesHandler
+}
+
 func (r *Resolver) resolveRaceWeekendManager() *RaceWeekendManager {
 	if r.raceWeekendManager != nil {
 		return r.raceWeekendManager
@@ -438,6 +655,7 @@ func (r *Resolver) resolveRaceWeekendManager() *RaceWeekendManager {
 		r.resolveNotificationManager(),
 		r.acsrClient,
 		r.resolveCarManager(),
+		r.resolveResultsRevisionManager(),
 	)
 
 	return r.raceWeekendManager
@@ -533,6 +751,7 @@ func (r *Resolver) ResolveRouter(fs http.FileSystem) http.Handler {
 		r.resolveWeatherHandler(),
 		r.resolvePenaltiesHandler(),
 		r.resolveResultsHandler(),
+		r.resolveResultsImportHandler(),
 		r.resolveContentUploadHandler(),
 		r.resolveServerAdministrationHandler(),
 		r.resolveRaceControlHandler(),
@@ -542,6 +761,12 @@ func (r *Resolver) ResolveRouter(fs http.FileSystem) http.Handler {
 		r.resolveHealthCheck(),
 		r.resolveKissMyRankHandler(),
 		r.resolveRealPenaltyHandler(),
+		r.resolveReplayHandler(),
+		r.resolveStewardsHandler(),
+		r.resolveRaceControlAPIHandler(),
+		r.resolveDriverProfilesHandler(),
+		r.resolveLeaderboardsHandler(),
+		r.resolveLapAnomaliesHandler(),
 	)
 }
 
@@ -38,6 +38,9 @@ type SessionResults struct {
 	SessionFile    string           `json:"SessionFile"`
 	ChampionshipID string           `json:"ChampionshipID"`
 	RaceWeekendID  string           `json:"RaceWeekendID"`
+
+	FullCourseYellows []*FullCourseYellow `json:"FullCourseYellows"`
+	PitStops          []*SessionPitStop   `json:"PitStops"`
 }
 
 var ErrSessionCarNotFound = errors.New("servermanager: session car not found")
@@ -489,6 +492,33 @@ func (s *SessionResults) IsFastestLap(time, cuts int) bool {
 	return fastest
 }
 
+// GetPitStops returns the pit stops made by the car that a result was for.
+func (s *SessionResults) GetPitStops(carID int) []*SessionPitStop {
+	var pitStops []*SessionPitStop
+
+	for _, pitStop := range s.PitStops {
+		if int(pitStop.CarID) == carID {
+			pitStops = append(pitStops, pitStop)
+		}
+	}
+
+	return pitStops
+}
+
+// IsLapUnderFullCourseYellow is true if any part of the driver's lap (counting from 1) was driven under a Full
+// Course Yellow.
+func (s *SessionResults) IsLapUnderFullCourseYellow(guid, model string, lap int64) bool {
+	for _, fullCourseYellow := range s.FullCourseYellows {
+		for _, driver := range fullCourseYellow.Drivers {
+			if string(driver.DriverGUID) == guid && driver.CarModel == model && lap >= int64(driver.StartLap) && lap <= int64(driver.EndLap) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
 func (s *SessionResults) IsDriversFastestLap(guid, model string, time, cuts int) bool {
 	if cuts != 0 {
 		return false
@@ -1018,6 +1048,10 @@ type SessionResult struct {
 	LapPenalty   int           `json:"LapPenalty"`
 	Disqualified bool          `json:"Disqualified"`
 	ClassID      uuid.UUID     `json:"ClassID"`
+
+	// Penalties are all of the penalties given to the driver, including those which have been revoked. The
+	// penalty time, lap penalty and disqualification above are worked out from them.
+	Penalties []*Penalty `json:"Penalties,omitempty"`
 }
 
 func (s *SessionResult) BestLapTyre(results *SessionResults) string {
@@ -1140,92 +1174,6 @@ type SessionPos struct {
 	Z float64 `json:"Z"`
 }
 
-const pageSize = 25
-
-var ErrResultsPageNotFound = errors.New("servermanager: results page not found")
-
-func listResults(page int) ([]SessionResults, []int, error) {
-	resultsPath := filepath.Join(ServerInstallPath, "results")
-	resultFiles, err := ioutil.ReadDir(resultsPath)
-
-	if err != nil {
-		return nil, nil, err
-	}
-
-	sort.Slice(resultFiles, func(i, j int) bool {
-		d1, _ := GetResultDate(resultFiles[i].Name())
-		d2, _ := GetResultDate(resultFiles[j].Name())
-
-		return d1.After(d2)
-	})
-
-	pages := float64(len(resultFiles)) / float64(pageSize)
-	pagesRound := math.Ceil(pages)
-
-	if page > int(pages) || page < 0 {
-		return nil, nil, ErrResultsPageNotFound
-	}
-
-	var pagesSlice []int
-
-	for x := 0; x < int(pagesRound); x++ {
-		pagesSlice = append(pagesSlice, x)
-	}
-
-	// get result files for selected page probably
-	if len(resultFiles) > page*pageSize+pageSize {
-		resultFiles = resultFiles[page*pageSize : page*pageSize+pageSize]
-	} else {
-		resultFiles = resultFiles[page*pageSize:]
-	}
-
-	var results []SessionResults
-
-	for _, resultFile := range resultFiles {
-		result, err := LoadResult(resultFile.Name())
-
-		if err != nil {
-			logrus.WithError(err).Errorf("Could not load results file: %s", resultFile.Name())
-			continue
-		}
-
-		results = append(results, *result)
-	}
-
-	return results, pagesSlice, nil
-}
-
-func ListAllResults() ([]SessionResults, error) {
-	resultsPath := filepath.Join(ServerInstallPath, "results")
-	resultFiles, err := ioutil.ReadDir(resultsPath)
-
-	if err != nil {
-		return nil, err
-	}
-
-	sort.Slice(resultFiles, func(i, j int) bool {
-		d1, _ := GetResultDate(resultFiles[i].Name())
-		d2, _ := GetResultDate(resultFiles[j].Name())
-
-		return d1.After(d2)
-	})
-
-	var results []SessionResults
-
-	for _, resultFile := range resultFiles {
-		result, err := LoadResult(resultFile.Name(), LoadResultWithoutPluginFire)
-
-		if err != nil {
-			logrus.WithError(err).Errorf("Could not load results file: %s", resultFile.Name())
-			continue
-		}
-
-		results = append(results, *result)
-	}
-
-	return results, nil
-}
-
 func GetResultDate(name string) (time.Time, error) {
 	dateSplit := strings.Split(name, "_")
 	dateSplit = dateSplit[0 : len(dateSplit)-1]
@@ -1337,22 +1285,29 @@ func resultsLoadPlugin(results *SessionResults) error {
 type ResultsHandler struct {
 	*BaseHandler
 
-	store Store
+	store                  Store
+	resultsIndex           *ResultsIndex
+	resultsRevisionManager *ResultsRevisionManager
 }
 
-func NewResultsHandler(baseHandler *BaseHandler, store Store) *ResultsHandler {
+func NewResultsHandler(baseHandler *BaseHandler, store Store, resultsIndex *ResultsIndex, resultsRevisionManager *ResultsRevisionManager) *ResultsHandler {
 	return &ResultsHandler{
-		BaseHandler: baseHandler,
-		store:       store,
+		BaseHandler:            baseHandler,
+		store:                  store,
+		resultsIndex:           resultsIndex,
+		resultsRevisionManager: resultsRevisionManager,
 	}
 }
 
 type resultsListTemplateVars struct {
 	BaseTemplateVars
 
-	Results     []SessionResults
+	Results     []*ResultsIndexEntry
 	Pages       []int
 	CurrentPage int
+
+	Search        *ResultsSearch
+	SearchResults *ResultsSearchResults
 }
 
 func (rh *ResultsHandler) list(w http.ResponseWriter, r *http.Request) {
@@ -1362,7 +1317,26 @@ func (rh *ResultsHandler) list(w http.ResponseWriter, r *http.Request) {
 		page = 0
 	}
 
-	results, pages, err := listResults(page)
+	search, err := NewResultsSearch(r.URL.Query())
+
+	if err != nil {
+		http.Error(w, "invalid results search: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var results []*ResultsIndexEntry
+	var pages []int
+	var searchResults *ResultsSearchResults
+
+	if search.IsEmpty() {
+		results, pages, err = rh.resultsIndex.List(page)
+	} else {
+		searchResults, err = rh.resultsIndex.Search(r.Context(), search, page)
+
+		if err == nil {
+			results, pages = searchResults.Entries, searchResults.Pages
+		}
+	}
 
 	if err == ErrResultsPageNotFound {
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
@@ -1374,9 +1348,11 @@ func (rh *ResultsHandler) list(w http.ResponseWriter, r *http.Request) {
 	}
 
 	rh.viewRenderer.MustLoadTemplate(w, r, "results/index.html", &resultsListTemplateVars{
-		Results:     results,
-		Pages:       pages,
-		CurrentPage: page,
+		Results:       results,
+		Pages:         pages,
+		CurrentPage:   page,
+		Search:        search,
+		SearchResults: searchResults,
 	})
 }
 
@@ -1395,10 +1371,26 @@ func (rh *ResultsHandler) uploadHandler(w http.ResponseWriter, r *http.Request)
 	http.Redirect(w, r, r.Referer(), http.StatusFound)
 }
 
+// rebuildIndex re-reads every results file into the results index. Results files are indexed as they are listed, so
+// this is only needed if the index has become out of date, e.g. if results files were changed without changing their
+// modification time.
+func (rh *ResultsHandler) rebuildIndex(w http.ResponseWriter, r *http.Request) {
+	numResults, err := rh.resultsIndex.Rebuild()
+
+	if err != nil {
+		logrus.WithError(err).Error("could not rebuild results index")
+		AddErrorFlash(w, r, "Could not rebuild the results index, please check the server logs.")
+	} else {
+		AddFlash(w, r, fmt.Sprintf("Successfully indexed %d results files!", numResults))
+	}
+
+	http.Redirect(w, r, r.Referer(), http.StatusFound)
+}
+
 type combineResultsTemplateVars struct {
 	BaseTemplateVars
 
-	Results []SessionResults
+	Results []*ResultsIndexEntry
 }
 
 const combinedSuffix = "-combined"
@@ -1436,7 +1428,7 @@ func (rh *ResultsHandler) combineResults(w http.ResponseWriter, r *http.Request)
 			combinedResult.SessionFile = combinedResult.SessionFile + combinedSuffix
 		}
 
-		err := saveResults(combinedResult.SessionFile+".json", combinedResult)
+		err := rh.resultsRevisionManager.SaveResults(combinedResult.SessionFile, combinedResult, AccountFromRequest(r).Name, "Combined from "+strings.Join(combineResultsSessionFiles, ", "))
 
 		if err != nil {
 			logrus.WithError(err).Error("Combine Results: Couldn't save result")
@@ -1448,7 +1440,7 @@ func (rh *ResultsHandler) combineResults(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	results, err := ListAllResults()
+	results, err := rh.resultsIndex.Find(nil)
 
 	if err != nil {
 		logrus.WithError(err).Error("Combine Results: Couldn't list results")
@@ -1522,6 +1514,8 @@ type resultsViewTemplateVars struct {
 	AutoFillEntrants []*Entrant
 	Account          *Account
 	UseMPH           bool
+	HasReplay        bool
+	Revisions        []*ResultsRevision
 }
 
 func (rh *ResultsHandler) view(w http.ResponseWriter, r *http.Request) {
@@ -1550,6 +1544,12 @@ func (rh *ResultsHandler) view(w http.ResponseWriter, r *http.Request) {
 		logrus.WithError(err).Errorf("couldn't load autofill entrant list")
 	}
 
+	revisions, err := rh.resultsRevisionManager.ListRevisions(fileName)
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't load results revisions")
+	}
+
 	result.ClearKickedGUIDs()
 	result.NormaliseCarIDs()
 
@@ -1561,6 +1561,8 @@ func (rh *ResultsHandler) view(w http.ResponseWriter, r *http.Request) {
 		AutoFillEntrants: autoFillEntrants,
 		Account:          AccountFromRequest(r),
 		UseMPH:           serverOpts.UseMPH == 1,
+		HasReplay:        ReplayExists(fileName),
+		Revisions:        revisions,
 	})
 }
 
@@ -1608,24 +1610,58 @@ func (rh *ResultsHandler) edit(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var renames []string
+
 	for key, vals := range r.Form {
 		if strings.HasPrefix(key, "guid:") {
 			guid := strings.TrimPrefix(key, "guid:")
 			name := vals[0]
 
+			for _, result := range results.Result {
+				if result.DriverGUID == guid && result.DriverName != name {
+					renames = append(renames, fmt.Sprintf("%s to %s", result.DriverName, name))
+					break
+				}
+			}
+
 			results.RenameDriver(guid, name)
 		}
 	}
 
-	err = saveResults(results.SessionFile+".json", results)
+	var moves []string
+
+	positions := make(map[int]int)
+
+	for i, result := range results.Result {
+		position, err := strconv.Atoi(r.FormValue(fmt.Sprintf("position:%d", i)))
+
+		if err != nil || position == i+1 {
+			continue
+		}
+
+		positions[i] = position
+		moves = append(moves, fmt.Sprintf("%s from P%d to P%d", result.DriverName, i+1, position))
+	}
+
+	sort.Strings(renames)
+
+	if len(renames) == 0 && len(moves) == 0 {
+		AddFlash(w, r, "No changes were made to the results")
+		http.Redirect(w, r, r.Referer(), http.StatusFound)
+		return
+	}
+
+	results.MoveResults(positions)
+
+	err = rh.resultsRevisionManager.SaveResults(fileName, results, AccountFromRequest(r).Name, resultsEditDescription(renames, moves))
 
 	if err != nil {
-		logrus.WithError(err).Error("could not load results")
+		logrus.WithError(err).Error("could not save results")
 		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
 		return
 	}
 
-	AddFlash(w, r, "Drivers successfully edited")
+	AddFlash(w, r, "Results successfully edited")
 	http.Redirect(w, r, r.Referer(), http.StatusFound)
 }
 
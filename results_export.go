@@ -0,0 +1,522 @@
+package servermanager
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrUnknownResultsExportFormat = errors.New("servermanager: unknown results export format")
+
+// ResultsExportFormat is a format that SessionResults can be exported to. The format is also the suffix of the file
+// name of the export.
+type ResultsExportFormat string
+
+const (
+	ResultsExportClassificationCSV ResultsExportFormat = "classification.csv"
+	ResultsExportLapsCSV           ResultsExportFormat = "laps.csv"
+	ResultsExportSectorsCSV        ResultsExportFormat = "sectors.csv"
+	ResultsExportRaceOut           ResultsExportFormat = "race_out.json"
+
+	// ResultsExportPrint is a printable classification sheet, rendered as a web page rather than written by
+	// ExportResults.
+	ResultsExportPrint ResultsExportFormat = "print"
+)
+
+var ResultsExportFormats = []ResultsExportFormat{
+	ResultsExportClassificationCSV,
+	ResultsExportLapsCSV,
+	ResultsExportSectorsCSV,
+	ResultsExportRaceOut,
+	ResultsExportPrint,
+}
+
+func (f ResultsExportFormat) Name() string {
+	switch f {
+	case ResultsExportClassificationCSV:
+		return "Classification (CSV)"
+	case ResultsExportLapsCSV:
+		return "Lap by Lap (CSV)"
+	case ResultsExportSectorsCSV:
+		return "Sectors (CSV)"
+	case ResultsExportRaceOut:
+		return "race_out.json"
+	case ResultsExportPrint:
+		return "Printable Classification"
+	default:
+		return string(f)
+	}
+}
+
+func (f ResultsExportFormat) ContentType() string {
+	switch f {
+	case ResultsExportRaceOut:
+		return "application/json"
+	case ResultsExportPrint:
+		return "text/html"
+	default:
+		return "text/csv"
+	}
+}
+
+func (f ResultsExportFormat) valid() bool {
+	for _, format := range ResultsExportFormats {
+		if f == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FileName is the name of the export of a results file.
+func (f ResultsExportFormat) FileName(results *SessionResults) string {
+	return results.SessionFile + "_" + string(f)
+}
+
+// ResultsClassificationEntry is a driver's finishing position in a session, as shown on the results page.
+type ResultsClassificationEntry struct {
+	Position     int
+	DriverName   string
+	DriverGUID   string
+	Team         string
+	CarModel     string
+	NumLaps      int
+	TotalTime    time.Duration
+	BestLap      time.Duration
+	Gap          string
+	Cuts         int
+	Crashes      int
+	Penalties    string
+	Disqualified bool
+}
+
+// Classification returns the finishing order of the session. Gaps in races are to the winner's race time, or the
+// number of laps behind the winner. In other sessions gaps are to the fastest lap.
+func (s *SessionResults) Classification() []*ResultsClassificationEntry {
+	var entries []*ResultsClassificationEntry
+
+	for _, result := range s.Result {
+		if result.DriverGUID == "" {
+			continue
+		}
+
+		entries = append(entries, &ResultsClassificationEntry{
+			Position:     len(entries) + 1,
+			DriverName:   result.DriverName,
+			DriverGUID:   result.DriverGUID,
+			Team:         s.GetTeamName(result.DriverGUID),
+			CarModel:     result.CarModel,
+			NumLaps:      s.GetNumLaps(result.DriverGUID, result.CarModel),
+			TotalTime:    s.GetTime(result.TotalTime, result.DriverGUID, result.CarModel, true),
+			BestLap:      s.GetTime(result.BestLap, result.DriverGUID, result.CarModel, false),
+			Cuts:         s.GetCuts(result.DriverGUID, result.CarModel),
+			Crashes:      s.GetCrashes(result.DriverGUID, result.CarModel),
+			Penalties:    penaltySummary(result),
+			Disqualified: result.Disqualified,
+		})
+	}
+
+	if len(entries) == 0 {
+		return entries
+	}
+
+	leader := entries[0]
+
+	for _, entry := range entries[1:] {
+		switch {
+		case entry.Disqualified:
+			entry.Gap = "DSQ"
+		case s.Type == SessionTypeRace && entry.NumLaps < leader.NumLaps:
+			lapsDown := leader.NumLaps - entry.NumLaps
+
+			if lapsDown == 1 {
+				entry.Gap = "+1 Lap"
+			} else {
+				entry.Gap = fmt.Sprintf("+%d Laps", lapsDown)
+			}
+		case s.Type == SessionTypeRace:
+			entry.Gap = formatGap(entry.TotalTime - leader.TotalTime)
+		case entry.BestLap > 0 && leader.BestLap > 0:
+			entry.Gap = formatGap(entry.BestLap - leader.BestLap)
+		}
+	}
+
+	return entries
+}
+
+func formatGap(d time.Duration) string {
+	if d < time.Minute {
+		return fmt.Sprintf("+%.3fs", d.Seconds())
+	}
+
+	return "+" + formatDuration(d, true)
+}
+
+// penaltySummary describes the driver's active penalties, including penalties given before penalties were kept.
+func penaltySummary(result *SessionResult) string {
+	var penalties []string
+
+	if len(result.Penalties) > 0 {
+		for _, penalty := range result.ActivePenalties() {
+			penalties = append(penalties, penalty.String())
+		}
+	} else {
+		if result.HasPenalty {
+			penalties = append(penalties, fmt.Sprintf("%s %s", result.PenaltyTime, PenaltyTypeTime))
+		}
+
+		if result.Disqualified {
+			penalties = append(penalties, string(PenaltyTypeDisqualification))
+		}
+	}
+
+	return strings.Join(penalties, "; ")
+}
+
+// ExportResults writes the results in the given format. CSV files start with a byte order mark, so that spreadsheet
+// applications (e.g. Excel) read driver names as UTF-8.
+func ExportResults(w io.Writer, results *SessionResults, format ResultsExportFormat) error {
+	var records [][]string
+
+	switch format {
+	case ResultsExportClassificationCSV:
+		records = classificationRecords(results)
+	case ResultsExportLapsCSV:
+		records = lapRecords(results)
+	case ResultsExportSectorsCSV:
+		records = sectorRecords(results)
+	case ResultsExportRaceOut:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(NewRaceOut(results))
+	default:
+		return ErrUnknownResultsExportFormat
+	}
+
+	if _, err := io.WriteString(w, "\ufeff"); err != nil {
+		return err
+	}
+
+	wr := csv.NewWriter(w)
+	wr.UseCRLF = true
+
+	return wr.WriteAll(records)
+}
+
+func classificationRecords(results *SessionResults) [][]string {
+	records := [][]string{{
+		"Position", "Driver", "GUID", "Team", "Car", "Laps", "Total Time", "Gap", "Best Lap", "Cuts", "Crashes", "Penalties",
+	}}
+
+	for _, entry := range results.Classification() {
+		records = append(records, []string{
+			strconv.Itoa(entry.Position),
+			entry.DriverName,
+			entry.DriverGUID,
+			entry.Team,
+			entry.CarModel,
+			strconv.Itoa(entry.NumLaps),
+			formatDuration(entry.TotalTime, false),
+			entry.Gap,
+			formatDuration(entry.BestLap, true),
+			strconv.Itoa(entry.Cuts),
+			strconv.Itoa(entry.Crashes),
+			entry.Penalties,
+		})
+	}
+
+	return records
+}
+
+func numSectors(results *SessionResults) int {
+	if len(results.Laps) == 0 {
+		return 0
+	}
+
+	return len(results.Laps[0].Sectors)
+}
+
+func sectorHeaders(results *SessionResults) []string {
+	var headers []string
+
+	for i := 0; i < numSectors(results); i++ {
+		headers = append(headers, fmt.Sprintf("Sector %d", i+1))
+	}
+
+	return headers
+}
+
+func lapRecords(results *SessionResults) [][]string {
+	headers := []string{"Driver", "GUID", "Car", "Lap", "Position", "Lap Time"}
+	headers = append(headers, sectorHeaders(results)...)
+	headers = append(headers, "Cuts", "Tyre", "Ballast", "Restrictor", "Timestamp")
+
+	records := [][]string{headers}
+	driverLaps := make(map[int]int)
+
+	for _, lap := range results.Laps {
+		driverLaps[lap.CarID]++
+
+		record := []string{
+			lap.DriverName,
+			lap.DriverGUID,
+			lap.CarModel,
+			strconv.Itoa(driverLaps[lap.CarID]),
+			strconv.Itoa(results.GetPosForLap(lap.DriverGUID, lap.CarModel, int64(driverLaps[lap.CarID]))),
+			formatDuration(lap.GetLapTime(), true),
+		}
+
+		for i := 0; i < numSectors(results); i++ {
+			if i < len(lap.Sectors) {
+				record = append(record, formatDuration(lap.GetSector(i), true))
+			} else {
+				record = append(record, "")
+			}
+		}
+
+		record = append(record,
+			strconv.Itoa(lap.Cuts),
+			lap.Tyre,
+			strconv.Itoa(lap.BallastKG),
+			strconv.Itoa(lap.Restrictor),
+			strconv.Itoa(lap.Timestamp),
+		)
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// sectorRecords lists each driver's best sectors from laps without cuts, and the lap time they add up to.
+func sectorRecords(results *SessionResults) [][]string {
+	headers := []string{"Driver", "GUID", "Car"}
+	headers = append(headers, sectorHeaders(results)...)
+	headers = append(headers, "Best Lap", "Theoretical Best Lap")
+
+	records := [][]string{headers}
+
+	for _, result := range results.Result {
+		if result.DriverGUID == "" {
+			continue
+		}
+
+		bestSectors := make([]int, numSectors(results))
+
+		for _, lap := range results.Laps {
+			if lap.DriverGUID != result.DriverGUID || lap.CarModel != result.CarModel || lap.Cuts > 0 {
+				continue
+			}
+
+			for i, sector := range lap.Sectors {
+				if i < len(bestSectors) && (bestSectors[i] == 0 || sector < bestSectors[i]) {
+					bestSectors[i] = sector
+				}
+			}
+		}
+
+		record := []string{result.DriverName, result.DriverGUID, result.CarModel}
+
+		var theoreticalBest time.Duration
+
+		for _, sector := range bestSectors {
+			theoreticalBest += time.Duration(sector) * time.Millisecond
+
+			record = append(record, formatDuration(time.Duration(sector)*time.Millisecond, true))
+		}
+
+		record = append(record,
+			formatDuration(results.GetTime(result.BestLap, result.DriverGUID, result.CarModel, false), true),
+			formatDuration(theoreticalBest, true),
+		)
+
+		records = append(records, record)
+	}
+
+	return records
+}
+
+// ExportResultsZip writes the export of each of the results to a zip file.
+func ExportResultsZip(w io.Writer, results []*SessionResults, format ResultsExportFormat) error {
+	z := zip.NewWriter(w)
+
+	for _, result := range results {
+		f, err := z.Create(format.FileName(result))
+
+		if err != nil {
+			return err
+		}
+
+		if err := ExportResults(f, result, format); err != nil {
+			return err
+		}
+	}
+
+	return z.Close()
+}
+
+// CompletedSessionResults returns the results of every completed session in the Championship, including those in
+// race weekends which have been loaded, oldest first.
+func (c *Championship) CompletedSessionResults() []*SessionResults {
+	var results []*SessionResults
+
+	for _, event := range c.Events {
+		if event.IsRaceWeekend() {
+			if event.RaceWeekend == nil {
+				continue
+			}
+
+			for _, session := range event.RaceWeekend.Sessions {
+				if session.Completed() {
+					results = append(results, session.Results)
+				}
+			}
+		} else {
+			for _, session := range event.Sessions {
+				if session.Completed() {
+					results = append(results, session.Results)
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Date.Before(results[j].Date)
+	})
+
+	return results
+}
+
+type resultsPrintTemplateVars struct {
+	BaseTemplateVars
+
+	Title   string
+	Results []*SessionResults
+}
+
+func (rh *ResultsHandler) export(w http.ResponseWriter, r *http.Request) {
+	fileName := chi.URLParam(r, "fileName")
+	format := ResultsExportFormat(chi.URLParam(r, "format"))
+
+	if !format.valid() {
+		http.NotFound(w, r)
+		return
+	}
+
+	result, err := LoadResult(fileName + ".json")
+
+	if os.IsNotExist(err) {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Errorf("could not get result")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	result.ClearKickedGUIDs()
+	result.NormaliseCarIDs()
+
+	if UseShortenedDriverNames {
+		result.MaskDriverNames()
+	}
+
+	if format == ResultsExportPrint {
+		rh.viewRenderer.MustLoadPartial(w, r, "results/print.html", &resultsPrintTemplateVars{
+			Title:   fmt.Sprintf("%s %s", prettifyName(result.TrackName, false), result.Type),
+			Results: []*SessionResults{result},
+		})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", attachmentContentDisposition(format.FileName(result)))
+
+	if err := ExportResults(w, result, format); err != nil {
+		logrus.WithError(err).Errorf("could not export results: %s", fileName)
+	}
+}
+
+func (ch *ChampionshipsHandler) exportAllResults(w http.ResponseWriter, r *http.Request) {
+	format := ResultsExportFormat(chi.URLParam(r, "format"))
+
+	if !format.valid() {
+		http.NotFound(w, r)
+		return
+	}
+
+	championship, err := ch.championshipManager.LoadChampionship(chi.URLParam(r, "championshipID"))
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't export championship results")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	for _, event := range championship.Events {
+		if !event.IsRaceWeekend() {
+			continue
+		}
+
+		event.RaceWeekend, err = ch.championshipManager.store.LoadRaceWeekend(event.RaceWeekendID.String())
+
+		if err != nil && err != ErrRaceWeekendNotFound {
+			logrus.WithError(err).Errorf("couldn't load race weekend for championship results")
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	results := championship.CompletedSessionResults()
+
+	for _, result := range results {
+		if UseShortenedDriverNames {
+			result.MaskDriverNames()
+		}
+	}
+
+	if format == ResultsExportPrint {
+		ch.viewRenderer.MustLoadPartial(w, r, "results/print.html", &resultsPrintTemplateVars{
+			Title:   championship.Name,
+			Results: results,
+		})
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", attachmentContentDisposition(fmt.Sprintf("%s_%s.zip", championship.Name, strings.Replace(string(format), ".", "_", -1))))
+
+	if err := ExportResultsZip(w, results, format); err != nil {
+		logrus.WithError(err).Errorf("could not export championship results")
+	}
+}
+
+// attachmentContentDisposition returns a Content-Disposition header which downloads a file with the given name. The
+// name is quoted and escaped as needed, as it may come from user input, e.g. a Championship name.
+func attachmentContentDisposition(fileName string) string {
+	contentDisposition := mime.FormatMediaType("attachment", map[string]string{"filename": fileName})
+
+	if contentDisposition == "" {
+		// the file name could not be encoded, let the browser choose one.
+		return "attachment"
+	}
+
+	return contentDisposition
+}
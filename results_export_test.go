@@ -0,0 +1,148 @@
+package servermanager
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"mime"
+	"strings"
+	"testing"
+)
+
+func TestExportResults(t *testing.T) {
+	_, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	results, err := LoadResult("2019_3_2_22_28_RACE.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	readCSV := func(t *testing.T, format ResultsExportFormat) [][]string {
+		var buf bytes.Buffer
+
+		if err := ExportResults(&buf, results, format); err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.HasPrefix(buf.String(), "\ufeff") {
+			t.Error("Expected the CSV to start with a byte order mark")
+		}
+
+		records, err := csv.NewReader(strings.NewReader(strings.TrimPrefix(buf.String(), "\ufeff"))).ReadAll()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return records
+	}
+
+	t.Run("Classification", func(t *testing.T) {
+		records := readCSV(t, ResultsExportClassificationCSV)
+
+		if len(records) != len(results.Result)+1 || records[0][0] != "Position" {
+			t.Fatalf("Expected a header and a row for each driver, got %d rows", len(records))
+		}
+
+		if records[1][0] != "1" || records[1][2] != "76561198022717360" || records[1][7] != "" {
+			t.Errorf("Incorrect winner: %v", records[1])
+		}
+
+		if records[2][2] != "76561198029578060" || !strings.HasPrefix(records[2][7], "+") {
+			t.Errorf("Incorrect second place: %v", records[2])
+		}
+	})
+
+	t.Run("Lap by lap", func(t *testing.T) {
+		records := readCSV(t, ResultsExportLapsCSV)
+
+		if len(records) != len(results.Laps)+1 || records[0][6] != "Sector 1" {
+			t.Errorf("Expected a header and a row for each lap, got %d rows: %v", len(records), records[0])
+		}
+
+		if records[1][3] != "1" {
+			t.Errorf("Expected the first lap to be lap 1, got: %v", records[1])
+		}
+	})
+
+	t.Run("Sectors", func(t *testing.T) {
+		records := readCSV(t, ResultsExportSectorsCSV)
+
+		if len(records) != len(results.Result)+1 || records[0][len(records[0])-1] != "Theoretical Best Lap" {
+			t.Errorf("Expected a header and a row for each driver, got %d rows: %v", len(records), records[0])
+		}
+	})
+
+	t.Run("Race out", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		if err := ExportResults(&buf, results, ResultsExportRaceOut); err != nil {
+			t.Fatal(err)
+		}
+
+		var raceOut RaceOut
+
+		if err := json.NewDecoder(&buf).Decode(&raceOut); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(raceOut.Players) != len(results.Cars) || len(raceOut.Sessions) != 1 || raceOut.Track != "suzuka" {
+			t.Fatalf("Incorrect race out: %+v", raceOut)
+		}
+
+		session := raceOut.Sessions[0]
+
+		if session.Type != raceOutSessionTypeRace || len(session.Laps) != len(results.Laps) || len(session.RaceResult) != len(results.Result) {
+			t.Errorf("Incorrect race out session: %+v", session)
+		}
+
+		if winner := raceOut.Players[session.RaceResult[0]]; winner.Name != results.Result[0].DriverName {
+			t.Errorf("Expected the winner to be %s, got %s", results.Result[0].DriverName, winner.Name)
+		}
+	})
+
+	t.Run("Unknown format", func(t *testing.T) {
+		if err := ExportResults(&bytes.Buffer{}, results, ResultsExportPrint); err != ErrUnknownResultsExportFormat {
+			t.Errorf("Expected an unknown format, got: %v", err)
+		}
+	})
+
+	t.Run("Zip", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		if err := ExportResultsZip(&buf, []*SessionResults{results}, ResultsExportClassificationCSV); err != nil {
+			t.Fatal(err)
+		}
+
+		z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(z.File) != 1 || z.File[0].Name != "2019_3_2_22_28_RACE_classification.csv" {
+			t.Errorf("Incorrect zip contents: %+v", z.File)
+		}
+	})
+}
+
+func TestAttachmentContentDisposition(t *testing.T) {
+	contentDisposition := attachmentContentDisposition("My \"Championship\"\r\nSet-Cookie: a=b_csv.zip")
+
+	if strings.ContainsAny(contentDisposition, "\r\n") {
+		t.Errorf("Expected the file name to be escaped, got: %s", contentDisposition)
+	}
+
+	_, params, err := mime.ParseMediaType(contentDisposition)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if params["filename"] != "My \"Championship\"\r\nSet-Cookie: a=b_csv.zip" {
+		t.Errorf("Incorrect file name: %s", params["filename"])
+	}
+}
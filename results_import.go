@@ -0,0 +1,1053 @@
+package servermanager
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	_ "modernc.org/sqlite"
+)
+
+// ResultsImportFormat is a results format from another tool which can be converted into SessionResults.
+type ResultsImportFormat string
+
+const (
+	ResultsImportRaceOut  ResultsImportFormat = "race_out.json"
+	ResultsImportStracker ResultsImportFormat = "stracker"
+)
+
+var ResultsImportFormats = []ResultsImportFormat{
+	ResultsImportRaceOut,
+	ResultsImportStracker,
+}
+
+var (
+	ErrUnknownResultsImportFormat    = errors.New("servermanager: unknown results import format")
+	ErrResultsImportSessionNotFound  = errors.New("servermanager: results import session not found")
+	ErrRaceWeekendEventResultsImport = errors.New("servermanager: results must be imported into race weekend sessions, not the race weekend event")
+)
+
+func (f ResultsImportFormat) Name() string {
+	switch f {
+	case ResultsImportRaceOut:
+		return "race_out.json (Assetto Corsa offline results)"
+	case ResultsImportStracker:
+		return "stracker database (SQLite)"
+	default:
+		return string(f)
+	}
+}
+
+func (f ResultsImportFormat) valid() bool {
+	for _, format := range ResultsImportFormats {
+		if f == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ResultsImportSession is a session found in a file being imported.
+type ResultsImportSession struct {
+	ID          string
+	Type        SessionType
+	TrackName   string
+	TrackConfig string
+	ServerName  string
+
+	// Date is zero if the source does not record when the session took place.
+	Date time.Time
+
+	Drivers []string
+
+	// UnmappedDrivers are the names of drivers that the source has no GUID for. They need a name to GUID
+	// mapping to be matched to drivers in Server Manager.
+	UnmappedDrivers []string
+}
+
+// ResultsImportOptions fill in the information that sources don't record themselves.
+type ResultsImportOptions struct {
+	// NameToGUID maps driver names to GUIDs, for drivers who have no GUID in the source.
+	NameToGUID map[string]string
+
+	// TrackConfig overrides the track layout of the session, if set.
+	TrackConfig string
+
+	// Date is used for sessions which have no date in the source.
+	Date time.Time
+}
+
+// ResultsImporter converts sessions from another tool into SessionResults. It must be closed once it is finished with.
+type ResultsImporter interface {
+	io.Closer
+
+	Sessions() ([]*ResultsImportSession, error)
+	SessionResults(sessionID string, opts ResultsImportOptions) (*SessionResults, error)
+}
+
+// NewResultsImporter reads a file of the given format.
+func NewResultsImporter(format ResultsImportFormat, fileName string) (ResultsImporter, error) {
+	switch format {
+	case ResultsImportRaceOut:
+		return newRaceOutImporter(fileName)
+	case ResultsImportStracker:
+		return newStrackerImporter(fileName)
+	default:
+		return nil, ErrUnknownResultsImportFormat
+	}
+}
+
+// parseNameToGUID reads a name to GUID mapping, either as a JSON object (as used by the race out converter) or as
+// one "Name=GUID" pair per line.
+func parseNameToGUID(mapping string) (map[string]string, error) {
+	mapping = strings.TrimSpace(mapping)
+	nameToGUID := make(map[string]string)
+
+	if strings.HasPrefix(mapping, "{") {
+		if err := json.Unmarshal([]byte(mapping), &nameToGUID); err != nil {
+			return nil, err
+		}
+
+		return nameToGUID, nil
+	}
+
+	for _, line := range strings.Split(mapping, "\n") {
+		line = strings.TrimSpace(line)
+
+		if line == "" {
+			continue
+		}
+
+		// GUIDs never contain an equals sign, but names might.
+		separator := strings.LastIndex(line, "=")
+
+		if separator < 0 {
+			return nil, fmt.Errorf("servermanager: invalid name to GUID mapping: %s", line)
+		}
+
+		if guid := strings.TrimSpace(line[separator+1:]); guid != "" {
+			nameToGUID[strings.TrimSpace(line[:separator])] = guid
+		}
+	}
+
+	return nameToGUID, nil
+}
+
+type raceOutImporter struct {
+	raceOut *RaceOut
+}
+
+func newRaceOutImporter(fileName string) (*raceOutImporter, error) {
+	f, err := os.Open(fileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var raceOut *RaceOut
+
+	if err := json.NewDecoder(f).Decode(&raceOut); err != nil {
+		return nil, err
+	}
+
+	if raceOut == nil || len(raceOut.Sessions) == 0 {
+		return nil, errors.New("servermanager: race_out.json has no sessions")
+	}
+
+	return &raceOutImporter{raceOut: raceOut}, nil
+}
+
+func (ri *raceOutImporter) Close() error {
+	return nil
+}
+
+func (ri *raceOutImporter) Sessions() ([]*ResultsImportSession, error) {
+	var drivers []string
+
+	for _, player := range ri.raceOut.Players {
+		drivers = append(drivers, player.Name)
+	}
+
+	var sessions []*ResultsImportSession
+
+	for i, results := range ri.raceOut.SessionResults(nil, "", time.Time{}) {
+		sessions = append(sessions, &ResultsImportSession{
+			ID:              strconv.Itoa(i),
+			Type:            results.Type,
+			TrackName:       results.TrackName,
+			Drivers:         drivers,
+			UnmappedDrivers: drivers,
+		})
+	}
+
+	return sessions, nil
+}
+
+func (ri *raceOutImporter) SessionResults(sessionID string, opts ResultsImportOptions) (*SessionResults, error) {
+	i, err := strconv.Atoi(sessionID)
+
+	if err != nil || i < 0 || i >= len(ri.raceOut.Sessions) {
+		return nil, ErrResultsImportSessionNotFound
+	}
+
+	date := opts.Date
+
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	return ri.raceOut.sessionResults(ri.raceOut.Sessions[i], opts.NameToGUID, opts.TrackConfig, date), nil
+}
+
+// strackerImporter reads sessions from an stracker SQLite database. Only the tables and columns needed to build
+// results are read, so databases from older or newer versions of stracker should import as long as these exist.
+type strackerImporter struct {
+	db *sql.DB
+}
+
+func newStrackerImporter(fileName string) (*strackerImporter, error) {
+	db, err := sql.Open("sqlite", fileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// check that the file is an stracker database before it is used.
+	if err := db.QueryRow("SELECT COUNT(*) FROM Session").Scan(new(int64)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("servermanager: could not read stracker database: %w", err)
+	}
+
+	return &strackerImporter{db: db}, nil
+}
+
+func (si *strackerImporter) Close() error {
+	return si.db.Close()
+}
+
+// strackerRow is a single row of an stracker query, keyed by column name. Values are nil, int64, float64, string or
+// []byte.
+type strackerRow map[string]interface{}
+
+// Int64 returns the value of the column as an int64. Real values are truncated, other values are 0.
+func (r strackerRow) Int64(column string) int64 {
+	switch v := r[column].(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	default:
+		return 0
+	}
+}
+
+// String returns the value of the column as a string. NULL is the empty string.
+func (r strackerRow) String(column string) string {
+	switch v := r[column].(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// query calls fn with every row returned by the query.
+func (si *strackerImporter) query(fn func(row strackerRow) error, query string, args ...interface{}) error {
+	rows, err := si.db.Query(query, args...)
+
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+
+	if err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		values := make([]interface{}, len(columns))
+		pointers := make([]interface{}, len(columns))
+
+		for i := range values {
+			pointers[i] = &values[i]
+		}
+
+		if err := rows.Scan(pointers...); err != nil {
+			return err
+		}
+
+		row := make(strackerRow, len(columns))
+
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+type strackerPlayer struct {
+	GUID string
+	Name string
+}
+
+type strackerPlayerInSession struct {
+	ID             int64
+	Player         strackerPlayer
+	CarID          int64
+	FinishPosition int64
+}
+
+type strackerLap struct {
+	ID        int64
+	LapTime   int
+	Sectors   []int
+	Cuts      int
+	Tyre      string
+	Timestamp int64
+}
+
+func (si *strackerImporter) lookup(table, idColumn, valueColumn string) (map[int64]string, error) {
+	values := make(map[int64]string)
+
+	err := si.query(func(row strackerRow) error {
+		values[row.Int64(idColumn)] = row.String(valueColumn)
+		return nil
+	}, "SELECT * FROM "+table)
+
+	return values, err
+}
+
+func (si *strackerImporter) players() (map[int64]strackerPlayer, error) {
+	players := make(map[int64]strackerPlayer)
+
+	err := si.query(func(row strackerRow) error {
+		players[row.Int64("PlayerId")] = strackerPlayer{
+			GUID: row.String("SteamGuid"),
+			Name: row.String("Name"),
+		}
+
+		return nil
+	}, "SELECT * FROM Players")
+
+	return players, err
+}
+
+func (si *strackerImporter) playersInSession(sessionID int64) ([]*strackerPlayerInSession, error) {
+	players, err := si.players()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var playersInSession []*strackerPlayerInSession
+
+	err = si.query(func(row strackerRow) error {
+		playersInSession = append(playersInSession, &strackerPlayerInSession{
+			ID:             row.Int64("PlayerInSessionId"),
+			Player:         players[row.Int64("PlayerId")],
+			CarID:          row.Int64("CarId"),
+			FinishPosition: row.Int64("FinishPosition"),
+		})
+
+		return nil
+	}, "SELECT * FROM PlayerInSession WHERE SessionId = ? ORDER BY PlayerInSessionId", sessionID)
+
+	return playersInSession, err
+}
+
+// strackerSessionType converts stracker's session names ("Practice", "Qualify", "Race") to SessionTypes.
+func strackerSessionType(sessionType string) SessionType {
+	sessionType = strings.ToLower(sessionType)
+
+	switch {
+	case strings.HasPrefix(sessionType, "prac"):
+		return SessionTypePractice
+	case strings.HasPrefix(sessionType, "qual"):
+		return SessionTypeQualifying
+	case strings.HasPrefix(sessionType, "race"):
+		return SessionTypeRace
+	case strings.HasPrefix(sessionType, "book"):
+		return SessionTypeBooking
+	default:
+		return SessionType(sessionType)
+	}
+}
+
+// strackerTrack splits stracker's track name, which is stored as "track-layout" for tracks with layouts.
+func strackerTrack(track string) (name, layout string) {
+	parts := strings.SplitN(track, "-", 2)
+
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+
+	return track, ""
+}
+
+func (si *strackerImporter) session(row strackerRow, tracks map[int64]string) *ResultsImportSession {
+	trackName, trackConfig := strackerTrack(tracks[row.Int64("TrackId")])
+
+	var date time.Time
+
+	if end := row.Int64("EndTimeDate"); end > 0 {
+		date = time.Unix(end, 0)
+	} else if start := row.Int64("StartTimeDate"); start > 0 {
+		date = time.Unix(start, 0)
+	}
+
+	return &ResultsImportSession{
+		ID:          strconv.FormatInt(row.Int64("SessionId"), 10),
+		Type:        strackerSessionType(row.String("SessionType")),
+		TrackName:   trackName,
+		TrackConfig: trackConfig,
+		ServerName:  row.String("ServerName"),
+		Date:        date,
+	}
+}
+
+func (si *strackerImporter) Sessions() ([]*ResultsImportSession, error) {
+	tracks, err := si.lookup("Tracks", "TrackId", "Track")
+
+	if err != nil {
+		return nil, err
+	}
+
+	players, err := si.players()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*ResultsImportSession
+	sessionsByID := make(map[int64]*ResultsImportSession)
+
+	err = si.query(func(row strackerRow) error {
+		session := si.session(row, tracks)
+
+		sessions = append(sessions, session)
+		sessionsByID[row.Int64("SessionId")] = session
+
+		return nil
+	}, "SELECT * FROM Session ORDER BY SessionId")
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = si.query(func(row strackerRow) error {
+		session, ok := sessionsByID[row.Int64("SessionId")]
+
+		if !ok {
+			return nil
+		}
+
+		player := players[row.Int64("PlayerId")]
+
+		session.Drivers = append(session.Drivers, player.Name)
+
+		if player.GUID == "" {
+			session.UnmappedDrivers = append(session.UnmappedDrivers, player.Name)
+		}
+
+		return nil
+	}, "SELECT * FROM PlayerInSession ORDER BY PlayerInSessionId")
+
+	if err != nil {
+		return nil, err
+	}
+
+	// sessions with no drivers are common in stracker databases, as it records every session the server runs.
+	var out []*ResultsImportSession
+
+	for _, session := range sessions {
+		if len(session.Drivers) > 0 {
+			out = append(out, session)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Date.After(out[j].Date)
+	})
+
+	return out, nil
+}
+
+func (si *strackerImporter) SessionResults(sessionID string, opts ResultsImportOptions) (*SessionResults, error) {
+	id, err := strconv.ParseInt(sessionID, 10, 64)
+
+	if err != nil {
+		return nil, ErrResultsImportSessionNotFound
+	}
+
+	tracks, err := si.lookup("Tracks", "TrackId", "Track")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var session *ResultsImportSession
+
+	err = si.query(func(row strackerRow) error {
+		session = si.session(row, tracks)
+		return nil
+	}, "SELECT * FROM Session WHERE SessionId = ?", id)
+
+	if err != nil {
+		return nil, err
+	} else if session == nil {
+		return nil, ErrResultsImportSessionNotFound
+	}
+
+	cars, err := si.lookup("Cars", "CarId", "Car")
+
+	if err != nil {
+		return nil, err
+	}
+
+	tyres, err := si.lookup("TyreCompounds", "TyreCompoundId", "TyreCompound")
+
+	if err != nil {
+		return nil, err
+	}
+
+	playersInSession, err := si.playersInSession(id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	laps := make(map[int64][]*strackerLap)
+
+	err = si.query(func(row strackerRow) error {
+		playerInSessionID := row.Int64("PlayerInSessionId")
+
+		lap := &strackerLap{
+			ID:        row.Int64("LapId"),
+			LapTime:   int(row.Int64("LapTime")),
+			Cuts:      int(row.Int64("Cuts")),
+			Tyre:      tyres[row.Int64("TyreCompoundId")],
+			Timestamp: row.Int64("Timestamp"),
+		}
+
+		// stracker can mark laps as invalid for reasons other than cuts, e.g. collisions.
+		if valid, ok := row["Valid"].(int64); ok && valid == 0 && lap.Cuts == 0 {
+			lap.Cuts = 1
+		}
+
+		for i := 0; i < 10; i++ {
+			sector, ok := row[fmt.Sprintf("SectorTime%d", i)].(int64)
+
+			if !ok {
+				break
+			}
+
+			lap.Sectors = append(lap.Sectors, int(sector))
+		}
+
+		laps[playerInSessionID] = append(laps[playerInSessionID], lap)
+
+		return nil
+	}, "SELECT Lap.* FROM Lap JOIN PlayerInSession USING (PlayerInSessionId) WHERE PlayerInSession.SessionId = ?", id)
+
+	if err != nil {
+		return nil, err
+	}
+
+	date := session.Date
+
+	if date.IsZero() {
+		date = opts.Date
+	}
+
+	trackConfig := session.TrackConfig
+
+	if opts.TrackConfig != "" {
+		trackConfig = opts.TrackConfig
+	}
+
+	results := &SessionResults{
+		TrackName:   session.TrackName,
+		TrackConfig: trackConfig,
+		Type:        session.Type,
+	}
+
+	results.UpdateDate(date)
+	results.SessionFile = strings.TrimSuffix(results.SessionFile, ".json")
+
+	finishPositions := make(map[int]int64)
+
+	for carID, playerInSession := range playersInSession {
+		guid := playerInSession.Player.GUID
+
+		if guid == "" {
+			guid = opts.NameToGUID[playerInSession.Player.Name]
+		}
+
+		car := &SessionCar{
+			CarID: carID,
+			Driver: SessionDriver{
+				GUID:      guid,
+				GuidsList: []string{guid},
+				Name:      playerInSession.Player.Name,
+			},
+			Model: cars[playerInSession.CarID],
+		}
+
+		results.Cars = append(results.Cars, car)
+
+		finishPositions[carID] = playerInSession.FinishPosition
+
+		carLaps := laps[playerInSession.ID]
+
+		sort.SliceStable(carLaps, func(i, j int) bool {
+			if carLaps[i].Timestamp == carLaps[j].Timestamp {
+				return carLaps[i].ID < carLaps[j].ID
+			}
+
+			return carLaps[i].Timestamp < carLaps[j].Timestamp
+		})
+
+		result := &SessionResult{
+			CarID:      carID,
+			CarModel:   car.Model,
+			DriverGUID: guid,
+			DriverName: car.Driver.Name,
+		}
+
+		for _, lap := range carLaps {
+			result.TotalTime += lap.LapTime
+
+			if lap.Cuts == 0 && (result.BestLap == 0 || lap.LapTime < result.BestLap) {
+				result.BestLap = lap.LapTime
+			}
+
+			results.Laps = append(results.Laps, &SessionLap{
+				CarID:      carID,
+				CarModel:   car.Model,
+				Cuts:       lap.Cuts,
+				DriverGUID: guid,
+				DriverName: car.Driver.Name,
+				LapTime:    lap.LapTime,
+				Sectors:    lap.Sectors,
+				Timestamp:  result.TotalTime,
+				Tyre:       lap.Tyre,
+			})
+		}
+
+		results.Result = append(results.Result, result)
+	}
+
+	sort.SliceStable(results.Laps, func(i, j int) bool {
+		return results.Laps[i].Timestamp < results.Laps[j].Timestamp
+	})
+
+	numLaps := func(carID int) int {
+		return len(laps[playersInSession[carID].ID])
+	}
+
+	sort.SliceStable(results.Result, func(i, j int) bool {
+		resultI, resultJ := results.Result[i], results.Result[j]
+
+		if results.Type == SessionTypeRace {
+			positionI, positionJ := finishPositions[resultI.CarID], finishPositions[resultJ.CarID]
+
+			if positionI > 0 && positionJ > 0 {
+				return positionI < positionJ
+			} else if positionI > 0 || positionJ > 0 {
+				return positionI > 0
+			}
+
+			if numLaps(resultI.CarID) != numLaps(resultJ.CarID) {
+				return numLaps(resultI.CarID) > numLaps(resultJ.CarID)
+			}
+
+			return resultI.TotalTime < resultJ.TotalTime
+		}
+
+		if resultI.BestLap == 0 || resultJ.BestLap == 0 {
+			return resultJ.BestLap == 0 && resultI.BestLap != 0
+		}
+
+		return resultI.BestLap < resultJ.BestLap
+	})
+
+	return results, nil
+}
+
+// ResultsImportManager stores files uploaded for import while the sessions to import are chosen, then converts and
+// saves the chosen sessions.
+type ResultsImportManager struct {
+	championshipManager    *ChampionshipManager
+	raceWeekendManager     *RaceWeekendManager
+	resultsRevisionManager *ResultsRevisionManager
+}
+
+func NewResultsImportManager(championshipManager *ChampionshipManager, raceWeekendManager *RaceWeekendManager, resultsRevisionManager *ResultsRevisionManager) *ResultsImportManager {
+	return &ResultsImportManager{
+		championshipManager:    championshipManager,
+		raceWeekendManager:     raceWeekendManager,
+		resultsRevisionManager: resultsRevisionManager,
+	}
+}
+
+// uploadPath is where an uploaded file is kept until its sessions have been imported. Uploads are kept in the
+// temporary directory so that abandoned imports are eventually cleaned up by the operating system.
+func (rim *ResultsImportManager) uploadPath(importID uuid.UUID) string {
+	return filepath.Join(os.TempDir(), "server-manager-results-import-"+importID.String())
+}
+
+// Upload stores the file to be imported, checking that it is valid for the format first.
+func (rim *ResultsImportManager) Upload(format ResultsImportFormat, r io.Reader) (string, error) {
+	if !format.valid() {
+		return "", ErrUnknownResultsImportFormat
+	}
+
+	importID := uuid.New()
+
+	f, err := os.Create(rim.uploadPath(importID))
+
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(f, r)
+
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+
+	var importer ResultsImporter
+
+	if err == nil {
+		importer, err = NewResultsImporter(format, f.Name())
+	}
+
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", err
+	}
+
+	if err := importer.Close(); err != nil {
+		return "", err
+	}
+
+	return importID.String(), nil
+}
+
+// Open opens an uploaded file. The returned ResultsImporter must be closed once it is finished with.
+func (rim *ResultsImportManager) Open(format ResultsImportFormat, importID string) (ResultsImporter, error) {
+	id, err := uuid.Parse(importID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewResultsImporter(format, rim.uploadPath(id))
+}
+
+// ResultsImportRequest is a session chosen to be imported, optionally attached to a championship event
+// or race weekend session.
+type ResultsImportRequest struct {
+	SessionID string
+
+	ChampionshipID, ChampionshipEventID string
+	RaceWeekendID, RaceWeekendSessionID string
+}
+
+// Import converts and saves the requested sessions, then removes the uploaded file. Results are saved with a file name
+// based on their date, which is moved on a minute at a time if a results file of that name already exists.
+func (rim *ResultsImportManager) Import(format ResultsImportFormat, importID string, requests []*ResultsImportRequest, opts ResultsImportOptions) ([]*SessionResults, error) {
+	importer, err := rim.Open(format, importID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var imported []*SessionResults
+
+	for _, request := range requests {
+		results, err := importer.SessionResults(request.SessionID, opts)
+
+		if err != nil {
+			importer.Close()
+			return imported, err
+		}
+
+		for resultsFileExists(results.SessionFile) {
+			results.UpdateDate(results.Date.Add(time.Minute))
+			results.SessionFile = strings.TrimSuffix(results.SessionFile, ".json")
+		}
+
+		switch {
+		case request.ChampionshipID != "":
+			err = rim.championshipManager.ImportEventResults(request.ChampionshipID, request.ChampionshipEventID, results)
+		case request.RaceWeekendID != "":
+			err = rim.raceWeekendManager.ImportSessionResults(request.RaceWeekendID, request.RaceWeekendSessionID, results)
+		default:
+			err = rim.resultsRevisionManager.UpdateResults(results.SessionFile, results, "Imported")
+		}
+
+		if err != nil {
+			importer.Close()
+			return imported, err
+		}
+
+		imported = append(imported, results)
+	}
+
+	if err := importer.Close(); err != nil {
+		return imported, err
+	}
+
+	id, _ := uuid.Parse(importID)
+
+	return imported, os.Remove(rim.uploadPath(id))
+}
+
+func resultsFileExists(sessionFile string) bool {
+	_, err := os.Stat(filepath.Join(ServerInstallPath, "results", sessionFile+".json"))
+
+	return err == nil
+}
+
+type ResultsImportHandler struct {
+	*BaseHandler
+
+	resultsImportManager *ResultsImportManager
+	championshipManager  *ChampionshipManager
+	raceWeekendManager   *RaceWeekendManager
+}
+
+func NewResultsImportHandler(baseHandler *BaseHandler, resultsImportManager *ResultsImportManager, championshipManager *ChampionshipManager, raceWeekendManager *RaceWeekendManager) *ResultsImportHandler {
+	return &ResultsImportHandler{
+		BaseHandler:          baseHandler,
+		resultsImportManager: resultsImportManager,
+		championshipManager:  championshipManager,
+		raceWeekendManager:   raceWeekendManager,
+	}
+}
+
+type resultsImportTemplateVars struct {
+	BaseTemplateVars
+
+	Formats []ResultsImportFormat
+
+	Format        ResultsImportFormat
+	ImportID      string
+	Sessions      []*ResultsImportSession
+	NameToGUID    string
+	Championships []*Championship
+	RaceWeekends  []*RaceWeekend
+}
+
+// uploadFileSizeLimitImport is larger than the results upload limit as stracker databases contain every session a
+// server has run.
+const uploadFileSizeLimitImport = 500e6
+
+func (rih *ResultsImportHandler) upload(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodPost {
+		importID, format, err := rih.saveUpload(r)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("could not read results import file")
+			AddErrorFlash(w, r, "Sorry, we couldn't read that file! Please make sure it matches the format you selected.")
+			http.Redirect(w, r, r.Referer(), http.StatusFound)
+			return
+		}
+
+		http.Redirect(w, r, fmt.Sprintf("/results/import/%s/%s", format, importID), http.StatusFound)
+		return
+	}
+
+	rih.viewRenderer.MustLoadTemplate(w, r, "results/import.html", &resultsImportTemplateVars{
+		Formats: ResultsImportFormats,
+	})
+}
+
+func (rih *ResultsImportHandler) saveUpload(r *http.Request) (string, ResultsImportFormat, error) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		return "", "", err
+	}
+
+	file, header, err := r.FormFile("ImportFile")
+
+	if err != nil {
+		return "", "", err
+	}
+
+	defer file.Close()
+
+	if header.Size > uploadFileSizeLimitImport {
+		return "", "", fmt.Errorf("servermanager: file size too large, limit is: %d, this file is: %d", int64(uploadFileSizeLimitImport), header.Size)
+	}
+
+	format := ResultsImportFormat(r.FormValue("Format"))
+
+	importID, err := rih.resultsImportManager.Upload(format, file)
+
+	return importID, format, err
+}
+
+func (rih *ResultsImportHandler) sessions(w http.ResponseWriter, r *http.Request) {
+	format := ResultsImportFormat(chi.URLParam(r, "format"))
+	importID := chi.URLParam(r, "importID")
+
+	if r.Method == http.MethodPost {
+		imported, err := rih.importSessions(format, importID, r)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("could not import results")
+			AddErrorFlash(w, r, fmt.Sprintf("Could not import results (%d sessions were imported before the error), please check the server logs.", len(imported)))
+			http.Redirect(w, r, r.Referer(), http.StatusFound)
+			return
+		}
+
+		AddFlash(w, r, fmt.Sprintf("Successfully imported %d sessions!", len(imported)))
+
+		if len(imported) == 1 {
+			http.Redirect(w, r, "/results/"+imported[0].SessionFile, http.StatusFound)
+		} else {
+			http.Redirect(w, r, "/results", http.StatusFound)
+		}
+
+		return
+	}
+
+	importer, err := rih.resultsImportManager.Open(format, importID)
+
+	if os.IsNotExist(err) {
+		AddErrorFlash(w, r, "That import could not be found, it may have already been imported. Please upload the file again.")
+		http.Redirect(w, r, "/results/import", http.StatusFound)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Errorf("could not open results import")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	defer importer.Close()
+
+	sessions, err := importer.Sessions()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("could not read results import sessions")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	championships, err := rih.championshipManager.ListChampionships()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("could not list championships")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	raceWeekends, err := rih.raceWeekendManager.ListRaceWeekends()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("could not list race weekends")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	// fill in the mapping with every driver that needs a GUID, so only the GUIDs need typing in.
+	var nameToGUID []string
+	unmapped := make(map[string]bool)
+
+	for _, session := range sessions {
+		for _, driver := range session.UnmappedDrivers {
+			if !unmapped[driver] {
+				unmapped[driver] = true
+				nameToGUID = append(nameToGUID, driver+"=")
+			}
+		}
+	}
+
+	rih.viewRenderer.MustLoadTemplate(w, r, "results/import.html", &resultsImportTemplateVars{
+		Format:        format,
+		ImportID:      importID,
+		Sessions:      sessions,
+		NameToGUID:    strings.Join(nameToGUID, "\n"),
+		Championships: championships,
+		RaceWeekends:  raceWeekends,
+	})
+}
+
+func (rih *ResultsImportHandler) importSessions(format ResultsImportFormat, importID string, r *http.Request) ([]*SessionResults, error) {
+	if err := r.ParseForm(); err != nil {
+		return nil, err
+	}
+
+	nameToGUID, err := parseNameToGUID(r.FormValue("NameToGUID"))
+
+	if err != nil {
+		return nil, err
+	}
+
+	opts := ResultsImportOptions{
+		NameToGUID:  nameToGUID,
+		TrackConfig: r.FormValue("TrackConfig"),
+	}
+
+	if date := r.FormValue("Date"); date != "" {
+		opts.Date, err = time.ParseInLocation("2006-01-02T15:04", date, time.Local)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var requests []*ResultsImportRequest
+
+	for _, sessionID := range r.Form["Session"] {
+		request := &ResultsImportRequest{SessionID: sessionID}
+
+		// attach targets are "championship/{championshipID}/{eventID}" or "race-weekend/{raceWeekendID}/{sessionID}"
+		attach := strings.Split(r.FormValue("Attach-"+sessionID), "/")
+
+		if len(attach) == 3 {
+			switch attach[0] {
+			case "championship":
+				request.ChampionshipID, request.ChampionshipEventID = attach[1], attach[2]
+			case "race-weekend":
+				request.RaceWeekendID, request.RaceWeekendSessionID = attach[1], attach[2]
+			}
+		}
+
+		requests = append(requests, request)
+	}
+
+	if len(requests) == 0 {
+		return nil, errors.New("servermanager: no sessions were selected for import")
+	}
+
+	return rih.resultsImportManager.Import(format, importID, requests, opts)
+}
@@ -0,0 +1,235 @@
+package servermanager
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newStrackerTestDatabase creates an stracker database from fixtures/stracker.sql, returning its file name.
+func newStrackerTestDatabase(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "stracker")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cleanup := func() {
+		_ = os.RemoveAll(dir)
+	}
+
+	schema, err := ioutil.ReadFile(filepath.Join("fixtures", "stracker.sql"))
+
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	fileName := filepath.Join(dir, "stracker.db3")
+
+	db, err := sql.Open("sqlite", fileName)
+
+	if err == nil {
+		_, err = db.Exec(string(schema))
+
+		if closeErr := db.Close(); err == nil {
+			err = closeErr
+		}
+	}
+
+	if err != nil {
+		cleanup()
+		t.Fatal(err)
+	}
+
+	return fileName, cleanup
+}
+
+func TestStrackerImporter(t *testing.T) {
+	fileName, cleanup := newStrackerTestDatabase(t)
+	defer cleanup()
+
+	importer, err := NewResultsImporter(ResultsImportStracker, fileName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer importer.Close()
+
+	sessions, err := importer.Sessions()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sessions) != 2 || sessions[0].ID != "2" || sessions[0].Type != SessionTypeRace || sessions[1].Type != SessionTypeQualifying {
+		t.Fatalf("Expected the race then qualifying sessions, got: %+v", sessions)
+	}
+
+	if sessions[0].TrackName != "ks_barcelona" || sessions[0].TrackConfig != "layout_gp" || len(sessions[0].Drivers) != 3 || len(sessions[0].UnmappedDrivers) != 0 {
+		t.Errorf("Incorrect session: %+v", sessions[0])
+	}
+
+	t.Run("Race", func(t *testing.T) {
+		results, err := importer.SessionResults("2", ResultsImportOptions{})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !results.Date.Equal(time.Unix(1577884000, 0)) {
+			t.Errorf("Incorrect date: %s", results.Date)
+		}
+
+		var order []string
+
+		for _, result := range results.Result {
+			order = append(order, result.DriverName)
+		}
+
+		if len(order) != 3 || order[0] != "Bob" || order[1] != "Alice" || order[2] != "Charlie" {
+			t.Errorf("Expected finishers in order of finish position, then the retired driver, got: %v", order)
+		}
+
+		if len(results.Laps) != 47 || len(results.Laps[0].Sectors) != 3 || results.Laps[0].Tyre != "SM" {
+			t.Errorf("Incorrect laps: %d", len(results.Laps))
+		}
+
+		if results.Cars[0].Model != "ks_mazda_mx5_cup" || results.Cars[0].Driver.GUID != "76561198000000001" {
+			t.Errorf("Incorrect car: %+v", results.Cars[0])
+		}
+	})
+
+	t.Run("Qualifying", func(t *testing.T) {
+		results, err := importer.SessionResults("1", ResultsImportOptions{TrackConfig: "layout_moto"})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if results.TrackConfig != "layout_moto" {
+			t.Errorf("Expected the track config to be overridden, got: %s", results.TrackConfig)
+		}
+
+		for i, result := range results.Result {
+			if i > 0 && result.BestLap < results.Result[i-1].BestLap {
+				t.Errorf("Expected qualifying to be sorted by best lap")
+			}
+		}
+
+		invalidLaps := 0
+
+		for _, lap := range results.Laps {
+			if lap.Cuts > 0 {
+				invalidLaps++
+			}
+		}
+
+		if invalidLaps != 2 {
+			t.Errorf("Expected the cut lap and the lap stracker marked invalid to have cuts, got %d", invalidLaps)
+		}
+	})
+
+	t.Run("Unknown session", func(t *testing.T) {
+		if _, err := importer.SessionResults("3", ResultsImportOptions{}); err != ErrResultsImportSessionNotFound {
+			t.Errorf("Expected session not found, got: %v", err)
+		}
+	})
+}
+
+func TestRaceOutImporter(t *testing.T) {
+	_, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	original, err := LoadResult("2019_3_2_22_28_RACE.json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(NewRaceOut(original))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fileName := filepath.Join(os.TempDir(), "race_out.json")
+
+	if err := ioutil.WriteFile(fileName, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.Remove(fileName)
+
+	importer, err := NewResultsImporter(ResultsImportRaceOut, fileName)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer importer.Close()
+
+	sessions, err := importer.Sessions()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sessions) != 1 || sessions[0].Type != SessionTypeRace || len(sessions[0].UnmappedDrivers) != len(original.Cars) {
+		t.Fatalf("Incorrect sessions: %+v", sessions)
+	}
+
+	nameToGUID := make(map[string]string)
+
+	for _, car := range original.Cars {
+		nameToGUID[car.Driver.Name] = car.Driver.GUID
+	}
+
+	date := time.Date(2019, 3, 2, 22, 28, 0, 0, time.Local)
+
+	results, err := importer.SessionResults("0", ResultsImportOptions{NameToGUID: nameToGUID, TrackConfig: original.TrackConfig, Date: date})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if results.SessionFile != "2019_3_2_22_28_RACE" || results.TrackConfig != original.TrackConfig {
+		t.Errorf("Incorrect session file or track config: %s, %s", results.SessionFile, results.TrackConfig)
+	}
+
+	if len(results.Result) != len(original.Result) || len(results.Laps) != len(original.Laps) {
+		t.Fatalf("Expected %d results and %d laps, got %d and %d", len(original.Result), len(original.Laps), len(results.Result), len(results.Laps))
+	}
+
+	for i, result := range results.Result {
+		if result.DriverGUID != original.Result[i].DriverGUID {
+			t.Errorf("Expected %s in P%d, got %s", original.Result[i].DriverGUID, i+1, result.DriverGUID)
+		}
+	}
+}
+
+func TestParseNameToGUID(t *testing.T) {
+	for _, mapping := range []string{
+		"Joseph Elton=76561198029578060\nnamelesssboy = 76561198022717360\nNo GUID=\n",
+		`{"Joseph Elton": "76561198029578060", "namelesssboy": "76561198022717360"}`,
+	} {
+		nameToGUID, err := parseNameToGUID(mapping)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(nameToGUID) != 2 || nameToGUID["Joseph Elton"] != "76561198029578060" || nameToGUID["namelesssboy"] != "76561198022717360" {
+			t.Errorf("Incorrect mapping: %v", nameToGUID)
+		}
+	}
+
+	if _, err := parseNameToGUID("no separator"); err == nil {
+		t.Error("Expected an invalid mapping to error")
+	}
+}
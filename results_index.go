@@ -0,0 +1,463 @@
+package servermanager
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/blevesearch/bleve"
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+// resultsIndexVersion is the version of the ResultsIndexEntry format. Entries indexed by an older version are re-read
+// from their results file.
+const resultsIndexVersion = 1
+
+// ResultsIndexEntry describes a results file, so that results can be listed, searched and built into leaderboards and
+// driver profiles without reading every results file.
+type ResultsIndexEntry struct {
+	Version  int       `json:"Version"`
+	FileName string    `json:"FileName"`
+	ModTime  time.Time `json:"ModTime"`
+	Size     int64     `json:"Size"`
+
+	// Error is set if the results file could not be read. Entries with an error are not listed.
+	Error string `json:"Error,omitempty"`
+
+	SessionFile    string                `json:"SessionFile"`
+	Date           time.Time             `json:"Date"`
+	Type           SessionType           `json:"Type"`
+	TrackName      string                `json:"TrackName"`
+	TrackConfig    string                `json:"TrackConfig"`
+	ChampionshipID string                `json:"ChampionshipID"`
+	RaceWeekendID  string                `json:"RaceWeekendID"`
+	Drivers        []*ResultsIndexDriver `json:"Drivers"`
+	Cars           []string              `json:"Cars"`
+	NumLaps        int                   `json:"NumLaps"`
+
+	// Laps are the clean laps of the results, for leaderboards.
+	Laps []*LeaderboardLap `json:"Laps"`
+	// DriverSessions are each driver's part in the session, for driver profiles.
+	DriverSessions []*DriverProfileSession `json:"DriverSessions"`
+}
+
+type ResultsIndexDriver struct {
+	GUID     string `json:"GUID"`
+	Name     string `json:"Name"`
+	CarModel string `json:"CarModel"`
+}
+
+// newResultsIndexEntry describes a results file. trackLength is the length (in km) of the track layout of the results,
+// or zero if it is not known.
+func newResultsIndexEntry(resultFile os.FileInfo, results *SessionResults, trackLength float64) *ResultsIndexEntry {
+	entry := &ResultsIndexEntry{
+		Version:        resultsIndexVersion,
+		FileName:       resultFile.Name(),
+		ModTime:        resultFile.ModTime(),
+		Size:           resultFile.Size(),
+		SessionFile:    results.SessionFile,
+		Date:           results.Date,
+		Type:           results.Type,
+		TrackName:      results.TrackName,
+		TrackConfig:    results.TrackConfig,
+		ChampionshipID: results.ChampionshipID,
+		RaceWeekendID:  results.RaceWeekendID,
+		NumLaps:        len(results.Laps),
+		Laps:           leaderboardLaps(results),
+		DriverSessions: driverProfileSessions(results, trackLength),
+	}
+
+	for _, result := range results.Result {
+		entry.Drivers = append(entry.Drivers, &ResultsIndexDriver{
+			GUID:     result.DriverGUID,
+			Name:     result.DriverName,
+			CarModel: result.CarModel,
+		})
+	}
+
+	cars := make(map[string]bool)
+
+	for _, car := range results.Cars {
+		if car.Model != "" && !cars[car.Model] {
+			cars[car.Model] = true
+			entry.Cars = append(entry.Cars, car.Model)
+		}
+	}
+
+	return entry
+}
+
+func (e *ResultsIndexEntry) GetDate() string {
+	return e.Date.Format(time.RFC822)
+}
+
+func (e *ResultsIndexEntry) IsTimeAttack() bool {
+	return strings.HasSuffix(e.SessionFile, timeAttackSuffix)
+}
+
+func (e *ResultsIndexEntry) GetDrivers() string {
+	var drivers []string
+
+	for _, driver := range e.Drivers {
+		if driver.Name != "" {
+			drivers = append(drivers, driverName(driver.Name))
+		}
+	}
+
+	return strings.Join(drivers, ", ")
+}
+
+// HasCar reports whether any driver in the results drove the given car.
+func (e *ResultsIndexEntry) HasCar(model string) bool {
+	for _, driver := range e.Drivers {
+		if driver.CarModel == model {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortResultsIndexEntries sorts entries by date, newest first.
+func sortResultsIndexEntries(entries []*ResultsIndexEntry) {
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Date.Equal(entries[j].Date) {
+			return entries[i].FileName > entries[j].FileName
+		}
+
+		return entries[i].Date.After(entries[j].Date)
+	})
+}
+
+// resultsIndexRescanInterval is how often the results directory is rescanned for results files which have been changed
+// outside of Server Manager, in place, so that the results directory's modification time is unchanged.
+const resultsIndexRescanInterval = time.Minute
+
+// ResultsIndex keeps a ResultsIndexEntry for each file in the results directory in the Store, and a search index of
+// them. Results files which Server Manager saves, or the server writes at the end of a session, are invalidated in the
+// index as they are saved. Otherwise, the results directory is rescanned when its modification time changes (i.e.
+// results files were added or deleted) and every resultsIndexRescanInterval, re-reading only results files whose size
+// or modification time have changed. Leaderboards and driver profiles are built from the index, so that each results
+// file is only read once.
+type ResultsIndex struct {
+	store       Store
+	searchIndex bleve.Index
+
+	mutex sync.Mutex
+
+	// entries are the entries of every readable results file as of the last update, newest first.
+	entries          []*ResultsIndexEntry
+	invalidated      map[string]bool
+	directoryModTime time.Time
+	lastUpdate       time.Time
+
+	// trackLengths caches the length of each track layout while results files are indexed.
+	trackLengths map[string]float64
+}
+
+func NewResultsIndex(store Store) *ResultsIndex {
+	return &ResultsIndex{
+		store:        store,
+		invalidated:  make(map[string]bool),
+		trackLengths: make(map[string]float64),
+	}
+}
+
+func (ri *ResultsIndex) UDPCallback(message udp.Message) {
+	if m, ok := message.(udp.EndSession); ok {
+		ri.Invalidate(filepath.Base(string(m)))
+	}
+}
+
+// Invalidate marks a results file as changed, so that it is re-read the next time the index is used.
+func (ri *ResultsIndex) Invalidate(fileName string) {
+	ri.mutex.Lock()
+	defer ri.mutex.Unlock()
+
+	ri.invalidated[fileName] = true
+}
+
+// sync updates the index from the results directory if it may be out of date, and returns the entries of every
+// readable results file, newest first.
+func (ri *ResultsIndex) sync() ([]*ResultsIndexEntry, error) {
+	ri.mutex.Lock()
+	defer ri.mutex.Unlock()
+
+	if ri.entries != nil && len(ri.invalidated) == 0 && time.Since(ri.lastUpdate) < resultsIndexRescanInterval {
+		directory, err := os.Stat(filepath.Join(ServerInstallPath, "results"))
+
+		if err != nil {
+			return nil, err
+		}
+
+		if directory.ModTime().Equal(ri.directoryModTime) {
+			return ri.entries, nil
+		}
+	}
+
+	return ri.update()
+}
+
+// update rescans the results directory, without locking. ri.mutex must be held.
+func (ri *ResultsIndex) update() ([]*ResultsIndexEntry, error) {
+	resultsPath := filepath.Join(ServerInstallPath, "results")
+
+	// the directory is checked before it is read, so that any change made while it is being read causes another scan.
+	directory, err := os.Stat(resultsPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resultFiles, err := ioutil.ReadDir(resultsPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	indexed, err := ri.store.ListResultsIndexEntries()
+
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[string]*ResultsIndexEntry)
+
+	for _, entry := range indexed {
+		existing[entry.FileName] = entry
+	}
+
+	var entries, changed []*ResultsIndexEntry
+
+	for _, resultFile := range resultFiles {
+		if resultFile.IsDir() || filepath.Ext(resultFile.Name()) != ".json" {
+			continue
+		}
+
+		entry, ok := existing[resultFile.Name()]
+		delete(existing, resultFile.Name())
+
+		if !ok || ri.invalidated[resultFile.Name()] || entry.Version != resultsIndexVersion || entry.Size != resultFile.Size() || !entry.ModTime.Equal(resultFile.ModTime()) {
+			entry = ri.indexResultsFile(resultFile)
+			changed = append(changed, entry)
+		}
+
+		if entry.Error == "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	if len(changed) > 0 {
+		if err := ri.store.UpsertResultsIndexEntries(changed); err != nil {
+			return nil, err
+		}
+	}
+
+	var deleted []string
+
+	for fileName := range existing {
+		deleted = append(deleted, fileName)
+	}
+
+	if len(deleted) > 0 {
+		if err := ri.store.DeleteResultsIndexEntries(deleted); err != nil {
+			return nil, err
+		}
+
+		for _, fileName := range deleted {
+			// the replay of a session is only useful alongside its results.
+			if err := deleteReplay(fileName); err != nil {
+				logrus.WithError(err).Errorf("Could not delete replay of results file: %s", fileName)
+			}
+		}
+	}
+
+	if len(changed) > 0 || len(deleted) > 0 {
+		logrus.Debugf("Results index: %d results files indexed, %d removed", len(changed), len(deleted))
+	}
+
+	// the results index can still be listed if the search index fails
+	if err := ri.updateSearchIndex(entries, changed, deleted); err != nil {
+		logrus.WithError(err).Error("Could not update results search index")
+	}
+
+	sortResultsIndexEntries(entries)
+
+	if entries == nil {
+		entries = []*ResultsIndexEntry{}
+	}
+
+	ri.entries = entries
+	ri.invalidated = make(map[string]bool)
+	ri.directoryModTime = directory.ModTime()
+	ri.lastUpdate = time.Now()
+
+	return entries, nil
+}
+
+func (ri *ResultsIndex) indexResultsFile(resultFile os.FileInfo) *ResultsIndexEntry {
+	results, err := LoadResult(resultFile.Name(), LoadResultWithoutPluginFire)
+
+	if err != nil {
+		logrus.WithError(err).Errorf("Could not load results file: %s", resultFile.Name())
+
+		return &ResultsIndexEntry{
+			FileName: resultFile.Name(),
+			ModTime:  resultFile.ModTime(),
+			Size:     resultFile.Size(),
+			Error:    err.Error(),
+		}
+	}
+
+	results.ClearKickedGUIDs()
+
+	return newResultsIndexEntry(resultFile, results, ri.trackLength(results.TrackName, results.TrackConfig))
+}
+
+// trackLength returns the length (in km) of a track layout, or zero if it is not known. ri.mutex must be held.
+func (ri *ResultsIndex) trackLength(track, layout string) float64 {
+	key := track + "/" + layout
+
+	if length, ok := ri.trackLengths[key]; ok {
+		return length
+	}
+
+	var length float64
+
+	trackInfo, err := GetTrackInfo(track, layout)
+
+	if err == nil && trackInfo != nil {
+		length, err = parseTrackLength(trackInfo.Length)
+
+		if err != nil {
+			logrus.WithError(err).Debugf("Could not parse length of track: %s (%s)", track, layout)
+		}
+	}
+
+	ri.trackLengths[key] = length
+
+	return length
+}
+
+// Rebuild discards the index and search index and re-reads every results file, returning the number of results
+// files indexed.
+func (ri *ResultsIndex) Rebuild() (int, error) {
+	ri.mutex.Lock()
+	defer ri.mutex.Unlock()
+
+	if err := ri.removeSearchIndex(); err != nil {
+		return 0, err
+	}
+
+	indexed, err := ri.store.ListResultsIndexEntries()
+
+	if err != nil {
+		return 0, err
+	}
+
+	var fileNames []string
+
+	for _, entry := range indexed {
+		fileNames = append(fileNames, entry.FileName)
+	}
+
+	if err := ri.store.DeleteResultsIndexEntries(fileNames); err != nil {
+		return 0, err
+	}
+
+	entries, err := ri.update()
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(entries), nil
+}
+
+// Find returns the entries which match, newest first. A nil match returns every entry.
+func (ri *ResultsIndex) Find(match func(entry *ResultsIndexEntry) bool) ([]*ResultsIndexEntry, error) {
+	entries, err := ri.sync()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if match == nil {
+		return entries, nil
+	}
+
+	var out []*ResultsIndexEntry
+
+	for _, entry := range entries {
+		if match(entry) {
+			out = append(out, entry)
+		}
+	}
+
+	return out, nil
+}
+
+const pageSize = 25
+
+var ErrResultsPageNotFound = errors.New("servermanager: results page not found")
+
+// List returns a page of entries, newest first, and the page numbers available.
+func (ri *ResultsIndex) List(page int) ([]*ResultsIndexEntry, []int, error) {
+	entries, err := ri.sync()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	numPages := int(math.Ceil(float64(len(entries)) / float64(pageSize)))
+
+	if page < 0 || (page >= numPages && page != 0) {
+		return nil, nil, ErrResultsPageNotFound
+	}
+
+	var pages []int
+
+	for x := 0; x < numPages; x++ {
+		pages = append(pages, x)
+	}
+
+	if len(entries) > page*pageSize+pageSize {
+		entries = entries[page*pageSize : page*pageSize+pageSize]
+	} else {
+		entries = entries[page*pageSize:]
+	}
+
+	return entries, pages, nil
+}
+
+// LoadResults reads the results files whose entries match, newest first.
+func (ri *ResultsIndex) LoadResults(match func(entry *ResultsIndexEntry) bool) ([]SessionResults, error) {
+	entries, err := ri.Find(match)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SessionResults
+
+	for _, entry := range entries {
+		result, err := LoadResult(entry.FileName, LoadResultWithoutPluginFire)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("Could not load results file: %s", entry.FileName)
+			continue
+		}
+
+		results = append(results, *result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,348 @@
+package servermanager
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/etcd-io/bbolt"
+)
+
+func TestResultsIndex(t *testing.T) {
+	dir, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	db, err := bbolt.Open(filepath.Join(dir, "results_index.db"), 0644, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	stores := map[string]Store{
+		"JSON": NewJSONStore(filepath.Join(dir, "store"), filepath.Join(dir, "store-shared")),
+		"Bolt": NewBoltStore(db),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			testResultsIndex(t, dir, store)
+		})
+	}
+}
+
+func testResultsIndex(t *testing.T, dir string, store Store) {
+	resultsIndex := NewResultsIndex(store)
+	defer resultsIndex.Close()
+
+	entries, pages, err := resultsIndex.List(0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	numResults := len(entries)
+
+	if numResults == 0 || len(pages) != 1 {
+		t.Fatalf("Expected one page of results, got %d results and %d pages", numResults, len(pages))
+	}
+
+	for i := 1; i < len(entries); i++ {
+		if entries[i].Date.After(entries[i-1].Date) {
+			t.Errorf("Results are not ordered newest first")
+		}
+	}
+
+	if _, _, err := resultsIndex.List(1); err != ErrResultsPageNotFound {
+		t.Errorf("Expected results page not found, got: %v", err)
+	}
+
+	indexed, err := store.ListResultsIndexEntries()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(indexed) != numResults {
+		t.Errorf("Expected %d entries in the store, got %d", numResults, len(indexed))
+	}
+
+	t.Run("Find", func(t *testing.T) {
+		entries, err := resultsIndex.Find(func(entry *ResultsIndexEntry) bool {
+			return entry.TrackName == "suzuka" && entry.TrackConfig == "suzukaeast"
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 2 || entries[0].FileName != "2019_3_2_22_28_RACE.json" || entries[0].SessionFile != "2019_3_2_22_28_RACE" {
+			t.Errorf("Incorrect entries: %+v", entries)
+		}
+
+		if len(entries) > 0 && (!entries[0].HasCar("ks_porsche_911_carrera_rsr") || len(entries[0].Drivers) == 0 || entries[0].NumLaps == 0) {
+			t.Errorf("Incorrect entry: %+v", entries[0])
+		}
+	})
+
+	t.Run("Entries indexed by an older version are re-indexed", func(t *testing.T) {
+		oldEntry := *indexed[0]
+		oldEntry.Version = 0
+		oldEntry.Laps = nil
+		oldEntry.DriverSessions = nil
+
+		if err := store.UpsertResultsIndexEntries([]*ResultsIndexEntry{&oldEntry}); err != nil {
+			t.Fatal(err)
+		}
+
+		resultsIndex.mutex.Lock()
+		resultsIndex.lastUpdate = time.Now().Add(-resultsIndexRescanInterval)
+		resultsIndex.mutex.Unlock()
+
+		entries, err := resultsIndex.Find(func(entry *ResultsIndexEntry) bool {
+			return entry.FileName == oldEntry.FileName
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != 1 || entries[0].Version != resultsIndexVersion || len(entries[0].Laps) == 0 || len(entries[0].DriverSessions) == 0 {
+			t.Errorf("Expected the entry to be re-indexed, got: %+v", entries)
+		}
+	})
+
+	t.Run("Invalidated results files are re-indexed", func(t *testing.T) {
+		results, err := LoadResult("2019_3_2_22_28_RACE.json")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results.TrackConfig = "suzukawest"
+
+		if err := saveResults("2019_3_2_22_28_RACE.json", results); err != nil {
+			t.Fatal(err)
+		}
+
+		resultsIndex.Invalidate("2019_3_2_22_28_RACE.json")
+
+		results2, err := resultsIndex.LoadResults(func(entry *ResultsIndexEntry) bool {
+			return entry.TrackConfig == "suzukawest"
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(results2) != 1 || results2[0].SessionFile != "2019_3_2_22_28_RACE" {
+			t.Errorf("Expected the changed results file, got %d results", len(results2))
+		}
+
+		results.TrackConfig = "suzukaeast"
+
+		if err := saveResults("2019_3_2_22_28_RACE.json", results); err != nil {
+			t.Fatal(err)
+		}
+
+		resultsIndex.Invalidate("2019_3_2_22_28_RACE.json")
+	})
+
+	t.Run("Results files changed in place are re-indexed when the results directory is rescanned", func(t *testing.T) {
+		results, err := LoadResult("2019_3_2_22_28_RACE.json")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		results.TrackConfig = "suzukawest"
+
+		if err := saveResults("2019_3_2_22_28_RACE.json", results); err != nil {
+			t.Fatal(err)
+		}
+
+		// make sure the modification time changes, even on file systems with a coarse resolution.
+		modTime := time.Now().Add(time.Minute)
+
+		if err := os.Chtimes(filepath.Join(dir, "results", "2019_3_2_22_28_RACE.json"), modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+
+		resultsIndex.mutex.Lock()
+		resultsIndex.lastUpdate = time.Now().Add(-resultsIndexRescanInterval)
+		resultsIndex.mutex.Unlock()
+
+		results2, err := resultsIndex.LoadResults(func(entry *ResultsIndexEntry) bool {
+			return entry.TrackConfig == "suzukawest"
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(results2) != 1 || results2[0].SessionFile != "2019_3_2_22_28_RACE" {
+			t.Errorf("Expected the changed results file, got %d results", len(results2))
+		}
+
+		results.TrackConfig = "suzukaeast"
+
+		if err := saveResults("2019_3_2_22_28_RACE.json", results); err != nil {
+			t.Fatal(err)
+		}
+
+		resultsIndex.Invalidate("2019_3_2_22_28_RACE.json")
+	})
+
+	t.Run("Unreadable and deleted results files are not listed", func(t *testing.T) {
+		if err := ioutil.WriteFile(filepath.Join(dir, "results", "2020_1_1_12_0_RACE.json"), []byte("{"), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Rename(filepath.Join(dir, "results", "2019_2_15_21_16_RACE.json"), filepath.Join(dir, "2019_2_15_21_16_RACE.json")); err != nil {
+			t.Fatal(err)
+		}
+
+		defer func() {
+			_ = os.Remove(filepath.Join(dir, "results", "2020_1_1_12_0_RACE.json"))
+			_ = os.Rename(filepath.Join(dir, "2019_2_15_21_16_RACE.json"), filepath.Join(dir, "results", "2019_2_15_21_16_RACE.json"))
+		}()
+
+		entries, err := resultsIndex.Find(nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(entries) != numResults-1 {
+			t.Errorf("Expected %d results, got %d", numResults-1, len(entries))
+		}
+
+		for _, entry := range entries {
+			if entry.FileName == "2020_1_1_12_0_RACE.json" || entry.FileName == "2019_2_15_21_16_RACE.json" {
+				t.Errorf("Unexpected entry: %s", entry.FileName)
+			}
+		}
+	})
+
+	t.Run("Rebuild", func(t *testing.T) {
+		n, err := resultsIndex.Rebuild()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n != numResults {
+			t.Errorf("Expected %d results to be indexed, got %d", numResults, n)
+		}
+	})
+}
+
+func TestNewResultsSearch(t *testing.T) {
+	values := url.Values{
+		"q":       {"suzuka"},
+		"driver":  {"Joseph"},
+		"track":   {"suzuka"},
+		"car":     {"ks_porsche_911_carrera_rsr"},
+		"session": {"RACE"},
+		"from":    {"2019-03-01"},
+		"to":      {"2019-03-02"},
+	}
+
+	search, err := NewResultsSearch(values)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if search.IsEmpty() || search.Values().Encode() != values.Encode() {
+		t.Errorf("Expected the search's values to be %s, got %s", values.Encode(), search.Values().Encode())
+	}
+
+	if search.URLWithout("driver") != "/results?car=ks_porsche_911_carrera_rsr&from=2019-03-01&q=suzuka&session=RACE&to=2019-03-02&track=suzuka" {
+		t.Errorf("Incorrect URL: %s", search.URLWithout("driver"))
+	}
+
+	if _, err := NewResultsSearch(url.Values{"to": {"yesterday"}}); err == nil {
+		t.Error("Expected an error for an invalid date")
+	}
+
+	if search, err := NewResultsSearch(url.Values{"page": {"2"}}); err != nil || !search.IsEmpty() {
+		t.Errorf("Expected an empty search, got: %+v (%v)", search, err)
+	}
+}
+
+func TestResultsIndex_Search(t *testing.T) {
+	dir, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	resultsIndex := NewResultsIndex(NewJSONStore(filepath.Join(dir, "store"), filepath.Join(dir, "store-shared")))
+	defer resultsIndex.Close()
+
+	searches := map[string]struct {
+		search     ResultsSearch
+		numResults int
+	}{
+		"Full text":            {ResultsSearch{Term: "suzuka"}, 5},
+		"Driver name":          {ResultsSearch{Driver: "joseph"}, 14},
+		"Driver GUID":          {ResultsSearch{Driver: "76561198029578060"}, 14},
+		"Track and session":    {ResultsSearch{Track: "suzuka", SessionType: SessionTypeRace}, 1},
+		"Car":                  {ResultsSearch{Car: "ks_mclaren_650_gt3"}, 2},
+		"Date range":           {ResultsSearch{To: time.Date(2019, 2, 28, 0, 0, 0, 0, time.Local)}, 4},
+		"No matching sessions": {ResultsSearch{Driver: "Nobody"}, 0},
+	}
+
+	for name, test := range searches {
+		test := test
+
+		t.Run(name, func(t *testing.T) {
+			results, err := resultsIndex.Search(context.Background(), &test.search, 0)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if results.NumResults != test.numResults || len(results.Entries) != test.numResults {
+				t.Errorf("Expected %d results, got %d (%d entries)", test.numResults, results.NumResults, len(results.Entries))
+			}
+
+			for i := 1; i < len(results.Entries); i++ {
+				if results.Entries[i].Date.After(results.Entries[i-1].Date) {
+					t.Errorf("Results are not ordered newest first")
+				}
+			}
+		})
+	}
+
+	t.Run("Facets", func(t *testing.T) {
+		results, err := resultsIndex.Search(context.Background(), &ResultsSearch{Term: "suzuka"}, 0)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		counts := make(map[string]map[string]int)
+
+		for _, facet := range results.Facets {
+			counts[facet.Param] = make(map[string]int)
+
+			for _, term := range facet.Terms {
+				counts[facet.Param][term.Value] = term.Count
+			}
+		}
+
+		if counts["track"]["suzuka"] != 5 || counts["session"]["PRACTICE"] != 3 || counts["session"]["RACE"] != 1 {
+			t.Errorf("Incorrect facets: %+v", counts)
+		}
+	})
+
+	t.Run("Pages", func(t *testing.T) {
+		if _, err := resultsIndex.Search(context.Background(), &ResultsSearch{Term: "suzuka"}, 1); err != ErrResultsPageNotFound {
+			t.Errorf("Expected results page not found, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,539 @@
+package servermanager
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrResultsRevisionNotFound = errors.New("servermanager: results revision not found")
+
+// ResultsRevision is a copy of a results file as it was after an edit, e.g. a driver rename or a penalty. The first
+// revision of a results file is the results as they were before they were first edited.
+type ResultsRevision struct {
+	ID          uuid.UUID
+	SessionFile string
+	Created     time.Time
+
+	// Account is the name of the account which made the edit. It is empty for the original results and edits made by
+	// Server Manager, such as automatic penalties.
+	Account     string
+	Description string
+
+	// RevertedFrom is the revision that the results were reverted to, if this revision was a revert.
+	RevertedFrom uuid.UUID
+
+	Results *SessionResults
+}
+
+func (r *ResultsRevision) IsRevert() bool {
+	return r.RevertedFrom != uuid.Nil
+}
+
+// sortResultsRevisions sorts revisions newest first.
+func sortResultsRevisions(revisions []*ResultsRevision) {
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Created.After(revisions[j].Created)
+	})
+}
+
+// ResultsRevisionManager saves edits to results files, keeping every version of the results so that edits can be
+// compared and reverted.
+type ResultsRevisionManager struct {
+	store        Store
+	resultsIndex *ResultsIndex
+}
+
+func NewResultsRevisionManager(store Store, resultsIndex *ResultsIndex) *ResultsRevisionManager {
+	return &ResultsRevisionManager{
+		store:        store,
+		resultsIndex: resultsIndex,
+	}
+}
+
+// SaveResults saves an edit to a results file, and any championship or race weekend that the results are part of.
+// The results file as it was before its first edit is kept as the original revision.
+func (rrm *ResultsRevisionManager) SaveResults(sessionFile string, results *SessionResults, account, description string) error {
+	return rrm.saveResults(sessionFile, results, &ResultsRevision{
+		Account:     account,
+		Description: description,
+	})
+}
+
+// UpdateResults saves changes which Server Manager makes to results itself, such as adding championship information
+// or pit stops when a session ends, or importing results. Any championship or race weekend that the results are part
+// of must be updated by the caller. If the results have already been edited, the change is kept as a new revision, so
+// that reverting to an earlier edit can't lose it. Otherwise it becomes part of the original revision, which is taken
+// when the results are first edited.
+func (rrm *ResultsRevisionManager) UpdateResults(sessionFile string, results *SessionResults, description string) error {
+	revisions, err := rrm.store.ListResultsRevisions(sessionFile)
+
+	if err != nil {
+		return err
+	}
+
+	if err := saveResults(sessionFile+".json", results); err != nil {
+		return err
+	}
+
+	rrm.resultsIndex.Invalidate(sessionFile + ".json")
+
+	if len(revisions) == 0 {
+		return nil
+	}
+
+	return rrm.store.AddResultsRevision(&ResultsRevision{
+		ID:          uuid.New(),
+		SessionFile: sessionFile,
+		Created:     time.Now(),
+		Description: description,
+		Results:     results,
+	})
+}
+
+func (rrm *ResultsRevisionManager) saveResults(sessionFile string, results *SessionResults, revision *ResultsRevision) error {
+	revisions, err := rrm.store.ListResultsRevisions(sessionFile)
+
+	if err != nil {
+		return err
+	}
+
+	if len(revisions) == 0 {
+		original, err := LoadResult(sessionFile + ".json")
+
+		if err == nil {
+			err = rrm.store.AddResultsRevision(&ResultsRevision{
+				ID:          uuid.New(),
+				SessionFile: sessionFile,
+				Created:     original.Date,
+				Description: "Original results",
+				Results:     original,
+			})
+		}
+
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if err := saveResults(sessionFile+".json", results); err != nil {
+		return err
+	}
+
+	rrm.resultsIndex.Invalidate(sessionFile + ".json")
+
+	if err := rrm.updateLinkedResults(sessionFile, results); err != nil {
+		return err
+	}
+
+	revision.ID = uuid.New()
+	revision.SessionFile = sessionFile
+	revision.Created = time.Now()
+	revision.Results = results
+
+	return rrm.store.AddResultsRevision(revision)
+}
+
+// updateLinkedResults replaces the results in any championship or race weekend that the results are part of.
+func (rrm *ResultsRevisionManager) updateLinkedResults(sessionFile string, results *SessionResults) error {
+	if results.ChampionshipID != "" {
+		championship, err := rrm.store.LoadChampionship(results.ChampionshipID)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("Couldn't load championship with ID: %s", results.ChampionshipID)
+			return err
+		}
+
+	champEvents:
+		for i, event := range championship.Events {
+			if event.IsRaceWeekend() {
+				raceWeekend, err := rrm.store.LoadRaceWeekend(event.RaceWeekendID.String())
+
+				if err != nil {
+					return err
+				}
+
+				for key, session := range raceWeekend.Sessions {
+					if !session.Completed() {
+						continue
+					}
+
+					if session.Results.SessionFile == sessionFile {
+						raceWeekend.Sessions[key].Results = results
+
+						break champEvents
+					}
+				}
+			} else {
+				for key, session := range event.Sessions {
+					if !session.Completed() {
+						continue
+					}
+
+					if session.Results.SessionFile == sessionFile {
+						championship.Events[i].Sessions[key].Results = results
+
+						// grid drops given in a race weekend are applied to the next session of the race weekend
+						// instead, see RaceWeekendSessionToSessionFilter.
+						championship.GridPenalties = championship.GridPenalties.sync(event.ID, results)
+
+						break champEvents
+					}
+				}
+			}
+		}
+
+		err = rrm.store.UpsertChampionship(championship)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("Couldn't save championship with ID: %s", results.ChampionshipID)
+			return err
+		}
+	}
+
+	if results.RaceWeekendID != "" {
+		raceWeekend, err := rrm.store.LoadRaceWeekend(results.RaceWeekendID)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("Couldn't load race weekend with id: %s", results.RaceWeekendID)
+			return err
+		}
+
+		for _, session := range raceWeekend.Sessions {
+			if !session.Completed() {
+				continue
+			}
+
+			if session.Results.SessionFile == sessionFile {
+				session.Results = results
+				break
+			}
+		}
+
+		err = rrm.store.UpsertRaceWeekend(raceWeekend)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("Could not update race weekend: %s", raceWeekend.ID.String())
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ListRevisions lists the revisions of a results file, newest first.
+func (rrm *ResultsRevisionManager) ListRevisions(sessionFile string) ([]*ResultsRevision, error) {
+	return rrm.store.ListResultsRevisions(sessionFile)
+}
+
+// Revert saves the results of a revision as a new revision, so that the revert itself can also be reverted.
+func (rrm *ResultsRevisionManager) Revert(sessionFile, revisionID, account string) error {
+	revision, err := rrm.store.LoadResultsRevision(sessionFile, revisionID)
+
+	if err != nil {
+		return err
+	}
+
+	return rrm.saveResults(sessionFile, revision.Results, &ResultsRevision{
+		Account:      account,
+		Description:  fmt.Sprintf("Reverted to the revision from %s", revision.Created.Format(time.RFC822)),
+		RevertedFrom: revision.ID,
+	})
+}
+
+// ResultsRevisionDiff is the difference between a revision and the revision before it.
+type ResultsRevisionDiff struct {
+	Revision, Previous *ResultsRevision
+
+	// Latest is true if the revision is the current version of the results. Original is true if the revision is the
+	// results as they were before they were first edited.
+	Latest, Original bool
+
+	Changes []*ResultsChange
+
+	Before, After []*ResultsClassificationEntry
+}
+
+// Diff compares a revision to the revision before it. The original revision is compared to itself, so has no changes.
+func (rrm *ResultsRevisionManager) Diff(sessionFile, revisionID string) (*ResultsRevisionDiff, error) {
+	revisions, err := rrm.store.ListResultsRevisions(sessionFile)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, revision := range revisions {
+		if revision.ID.String() != revisionID {
+			continue
+		}
+
+		diff := &ResultsRevisionDiff{
+			Revision: revision,
+			Previous: revision,
+			Latest:   i == 0,
+		}
+
+		if i+1 < len(revisions) {
+			diff.Previous = revisions[i+1]
+		} else {
+			diff.Original = true
+		}
+
+		diff.Changes = DiffResults(diff.Previous.Results, revision.Results)
+		diff.Before = diff.Previous.Results.Classification()
+		diff.After = revision.Results.Classification()
+
+		return diff, nil
+	}
+
+	return nil, ErrResultsRevisionNotFound
+}
+
+// ResultsChange is a change to a driver's result between two versions of a results file.
+type ResultsChange struct {
+	DriverName  string
+	Description string
+}
+
+// DiffResults describes the changes to each driver's result between two versions of a results file.
+func DiffResults(before, after *SessionResults) []*ResultsChange {
+	var changes []*ResultsChange
+
+	resultKey := func(result *SessionResult) string {
+		return result.DriverGUID + ":" + result.CarModel
+	}
+
+	beforePositions := make(map[string]int)
+
+	for i, result := range before.Result {
+		beforePositions[resultKey(result)] = i
+	}
+
+	afterKeys := make(map[string]bool)
+
+	for position, result := range after.Result {
+		key := resultKey(result)
+		afterKeys[key] = true
+
+		change := func(format string, args ...interface{}) {
+			changes = append(changes, &ResultsChange{DriverName: result.DriverName, Description: fmt.Sprintf(format, args...)})
+		}
+
+		previousPosition, ok := beforePositions[key]
+
+		if !ok {
+			change("Added to the results in P%d", position+1)
+			continue
+		}
+
+		previous := before.Result[previousPosition]
+
+		if previous.DriverName != result.DriverName {
+			change("Renamed from %s", previous.DriverName)
+		}
+
+		if previousPosition != position {
+			change("Moved from P%d to P%d", previousPosition+1, position+1)
+		}
+
+		if previousLaps, laps := before.GetNumLaps(previous.DriverGUID, previous.CarModel), after.GetNumLaps(result.DriverGUID, result.CarModel); previousLaps != laps {
+			change("Laps changed from %d to %d", previousLaps, laps)
+		}
+
+		if previous.TotalTime != result.TotalTime {
+			change("Total time changed from %s to %s", formatDuration(time.Duration(previous.TotalTime)*time.Millisecond, false), formatDuration(time.Duration(result.TotalTime)*time.Millisecond, false))
+		}
+
+		changes = append(changes, diffPenalties(previous, result)...)
+	}
+
+	for _, result := range before.Result {
+		if !afterKeys[resultKey(result)] {
+			changes = append(changes, &ResultsChange{DriverName: result.DriverName, Description: "Removed from the results"})
+		}
+	}
+
+	return changes
+}
+
+func diffPenalties(before, after *SessionResult) []*ResultsChange {
+	var changes []*ResultsChange
+
+	change := func(format string, args ...interface{}) {
+		changes = append(changes, &ResultsChange{DriverName: after.DriverName, Description: fmt.Sprintf(format, args...)})
+	}
+
+	if len(before.Penalties) == 0 && len(after.Penalties) == 0 {
+		// results from before penalties were recorded individually only have the totals.
+		if before.PenaltyTime != after.PenaltyTime {
+			change("Time penalty changed from %s to %s", before.PenaltyTime, after.PenaltyTime)
+		}
+
+		if before.LapPenalty != after.LapPenalty {
+			change("Lap penalty changed from %d to %d", before.LapPenalty, after.LapPenalty)
+		}
+
+		if before.Disqualified != after.Disqualified {
+			change("Disqualified changed from %s to %s", yesNo(before.Disqualified), yesNo(after.Disqualified))
+		}
+
+		return changes
+	}
+
+	previousPenalties := make(map[uuid.UUID]*Penalty)
+
+	for _, penalty := range before.Penalties {
+		previousPenalties[penalty.ID] = penalty
+	}
+
+	for _, penalty := range after.Penalties {
+		previous, ok := previousPenalties[penalty.ID]
+		delete(previousPenalties, penalty.ID)
+
+		switch {
+		case !ok:
+			change("Given a %s", penalty)
+
+			if penalty.IsRevoked() {
+				change("%s revoked", penalty)
+			}
+		case !previous.IsRevoked() && penalty.IsRevoked():
+			change("%s revoked", penalty)
+		case previous.IsRevoked() && !penalty.IsRevoked():
+			change("%s reinstated", penalty)
+		}
+	}
+
+	for _, penalty := range before.Penalties {
+		if _, ok := previousPenalties[penalty.ID]; ok {
+			change("%s removed", penalty)
+		}
+	}
+
+	return changes
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+// MoveResults changes the finishing order of the results. positions maps the current index of a result to its new
+// (1-based) position. Drivers who are moved take the position ahead of a driver who was already there.
+func (s *SessionResults) MoveResults(positions map[int]int) {
+	type move struct {
+		result   *SessionResult
+		index    int
+		position int
+	}
+
+	moves := make([]move, len(s.Result))
+
+	for i, result := range s.Result {
+		position, ok := positions[i]
+
+		if !ok || position < 1 {
+			position = i + 1
+		}
+
+		moves[i] = move{result: result, index: i, position: position}
+	}
+
+	sort.SliceStable(moves, func(i, j int) bool {
+		if moves[i].position != moves[j].position {
+			return moves[i].position < moves[j].position
+		}
+
+		movedI, movedJ := moves[i].position != moves[i].index+1, moves[j].position != moves[j].index+1
+
+		if movedI != movedJ {
+			return movedI
+		}
+
+		return moves[i].index < moves[j].index
+	})
+
+	for i, move := range moves {
+		s.Result[i] = move.result
+	}
+}
+
+type resultsRevisionTemplateVars struct {
+	BaseTemplateVars
+
+	Result *SessionResults
+	Diff   *ResultsRevisionDiff
+}
+
+func (rh *ResultsHandler) viewRevision(w http.ResponseWriter, r *http.Request) {
+	fileName := chi.URLParam(r, "fileName")
+
+	results, err := LoadResult(fileName + ".json")
+
+	if os.IsNotExist(err) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Error("could not load results")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	diff, err := rh.resultsRevisionManager.Diff(fileName, chi.URLParam(r, "revisionID"))
+
+	if err == ErrResultsRevisionNotFound {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Error("could not load results revision")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	rh.viewRenderer.MustLoadTemplate(w, r, "results/revision.html", &resultsRevisionTemplateVars{
+		Result: results,
+		Diff:   diff,
+	})
+}
+
+func (rh *ResultsHandler) revertRevision(w http.ResponseWriter, r *http.Request) {
+	fileName := chi.URLParam(r, "fileName")
+
+	err := rh.resultsRevisionManager.Revert(fileName, chi.URLParam(r, "revisionID"), AccountFromRequest(r).Name)
+
+	if err != nil {
+		logrus.WithError(err).Error("could not revert results")
+		AddErrorFlash(w, r, "Could not revert the results, please check the server logs.")
+		http.Redirect(w, r, r.Referer(), http.StatusFound)
+		return
+	}
+
+	AddFlash(w, r, "Results successfully reverted")
+	http.Redirect(w, r, "/results/"+fileName, http.StatusFound)
+}
+
+// resultsEditDescription describes the changes made by the results edit form.
+func resultsEditDescription(renames []string, moves []string) string {
+	var parts []string
+
+	if len(renames) > 0 {
+		parts = append(parts, "Renamed "+strings.Join(renames, ", "))
+	}
+
+	if len(moves) > 0 {
+		parts = append(parts, "Moved "+strings.Join(moves, ", "))
+	}
+
+	return strings.Join(parts, ". ")
+}
@@ -0,0 +1,181 @@
+package servermanager
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResultsRevisionManager(t *testing.T) {
+	_, cleanup := useResultsFixtures(t)
+	defer cleanup()
+
+	storeDir, err := ioutil.TempDir("", "asm-results-revisions")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(storeDir)
+
+	const (
+		sessionFile = "2019_3_2_22_28_RACE"
+		winner      = "76561198022717360"
+	)
+
+	store := NewJSONStore(filepath.Join(storeDir, "private"), filepath.Join(storeDir, "shared"))
+	revisionManager := NewResultsRevisionManager(store, NewResultsIndex(store))
+
+	results, err := LoadResult(sessionFile + ".json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the results should not be exported to a championship
+	results.ChampionshipID = ""
+
+	if err := saveResults(sessionFile+".json", results); err != nil {
+		t.Fatal(err)
+	}
+
+	originalName := results.Result[0].DriverName
+	results.RenameDriver(winner, "Renamed Driver")
+
+	if err := revisionManager.SaveResults(sessionFile, results, "Steward", "Renamed "+originalName+" to Renamed Driver"); err != nil {
+		t.Fatal(err)
+	}
+
+	revisions, err := revisionManager.ListRevisions(sessionFile)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(revisions) != 2 || revisions[0].Account != "Steward" || revisions[1].Results.Result[0].DriverName != originalName {
+		t.Fatalf("Expected the edit and the original results to be kept, got %d revisions", len(revisions))
+	}
+
+	t.Run("Diff", func(t *testing.T) {
+		diff, err := revisionManager.Diff(sessionFile, revisions[0].ID.String())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !diff.Latest || diff.Original || len(diff.Changes) != 1 || diff.Changes[0].Description != "Renamed from "+originalName {
+			t.Errorf("Incorrect diff: %+v", diff.Changes)
+		}
+
+		diff, err = revisionManager.Diff(sessionFile, revisions[1].ID.String())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !diff.Original || len(diff.Changes) != 0 {
+			t.Errorf("Expected the original revision to have no changes, got: %+v", diff.Changes)
+		}
+	})
+
+	t.Run("Penalties are recorded as revisions", func(t *testing.T) {
+		penaltiesManager := NewPenaltiesManager(store, revisionManager)
+
+		err := penaltiesManager.AddPenalty(sessionFile, winner, results.Result[0].CarModel, &Penalty{
+			Type:    PenaltyTypeDisqualification,
+			Reason:  "Technical infringement",
+			Steward: "Race Director",
+		})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		revisions, err := revisionManager.ListRevisions(sessionFile)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(revisions) != 3 || revisions[0].Account != "Race Director" || !strings.Contains(revisions[0].Description, "Disqualification given to Renamed Driver") {
+			t.Fatalf("Expected the penalty to be recorded as a revision, got: %+v", revisions[0])
+		}
+
+		diff, err := revisionManager.Diff(sessionFile, revisions[0].ID.String())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var descriptions []string
+
+		for _, change := range diff.Changes {
+			descriptions = append(descriptions, change.DriverName+": "+change.Description)
+		}
+
+		if !strings.Contains(strings.Join(descriptions, "\n"), "Renamed Driver: Moved from P1 to P") || !strings.Contains(strings.Join(descriptions, "\n"), "Given a Disqualification") {
+			t.Errorf("Incorrect changes: %v", descriptions)
+		}
+	})
+
+	t.Run("Revert", func(t *testing.T) {
+		revisions, err := revisionManager.ListRevisions(sessionFile)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		original := revisions[len(revisions)-1]
+
+		if err := revisionManager.Revert(sessionFile, original.ID.String(), "Admin"); err != nil {
+			t.Fatal(err)
+		}
+
+		results, err := LoadResult(sessionFile + ".json")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if results.Result[0].DriverGUID != winner || results.Result[0].DriverName != originalName || len(results.Result[0].Penalties) != 0 {
+			t.Errorf("Expected the original results to be restored, got: %+v", results.Result[0])
+		}
+
+		revisions, err = revisionManager.ListRevisions(sessionFile)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(revisions) != 4 || revisions[0].RevertedFrom != original.ID || revisions[0].Account != "Admin" {
+			t.Errorf("Expected the revert to be recorded as a revision, got: %+v", revisions[0])
+		}
+
+		if _, err := revisionManager.Diff(sessionFile, "not-a-revision"); err != ErrResultsRevisionNotFound {
+			t.Errorf("Expected revision not found, got: %v", err)
+		}
+	})
+}
+
+func TestSessionResults_MoveResults(t *testing.T) {
+	results := &SessionResults{}
+
+	for _, name := range []string{"A", "B", "C", "D"} {
+		results.Result = append(results.Result, &SessionResult{DriverName: name, DriverGUID: name})
+	}
+
+	// D moves up to P2, ahead of B who was already there. A and C keep their positions.
+	results.MoveResults(map[int]int{3: 2})
+
+	var order []string
+
+	for _, result := range results.Result {
+		order = append(order, result.DriverName)
+	}
+
+	if strings.Join(order, "") != "ADBC" {
+		t.Errorf("Expected ADBC, got: %v", order)
+	}
+}
@@ -0,0 +1,560 @@
+package servermanager
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/blevesearch/bleve"
+	"github.com/blevesearch/bleve/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/mapping"
+	"github.com/blevesearch/bleve/search/query"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrResultsSearchUnavailable = errors.New("servermanager: results search index is unavailable")
+
+// resultsSearchDocument is the representation of a results file in the results search index. Text fields are
+// analysed for full text search, keyword fields are matched exactly and used for facets.
+type resultsSearchDocument struct {
+	// text
+	Text        string   `json:"Text"`
+	DriverNames []string `json:"DriverNames"`
+
+	// keywords
+	Driver       []string `json:"Driver"`
+	DriverGUID   []string `json:"DriverGUID"`
+	Track        string   `json:"Track"`
+	TrackLayout  string   `json:"TrackLayout"`
+	Car          []string `json:"Car"`
+	SessionType  string   `json:"SessionType"`
+	Championship string   `json:"Championship"`
+	RaceWeekend  string   `json:"RaceWeekend"`
+
+	Date time.Time `json:"Date"`
+}
+
+func newResultsSearchDocument(entry *ResultsIndexEntry) *resultsSearchDocument {
+	doc := &resultsSearchDocument{
+		Track:        entry.TrackName,
+		TrackLayout:  entry.TrackConfig,
+		SessionType:  string(entry.Type),
+		Championship: entry.ChampionshipID,
+		RaceWeekend:  entry.RaceWeekendID,
+		Date:         entry.Date,
+	}
+
+	text := []string{prettifyName(entry.TrackName, false), prettifyName(entry.TrackConfig, true), entry.Type.String()}
+	cars := make(map[string]bool)
+
+	for _, driver := range entry.Drivers {
+		if driver.Name != "" {
+			doc.DriverNames = append(doc.DriverNames, driver.Name)
+			doc.Driver = append(doc.Driver, driver.Name)
+		}
+
+		if driver.GUID != "" {
+			doc.DriverGUID = append(doc.DriverGUID, driver.GUID)
+		}
+
+		if driver.CarModel != "" && !cars[driver.CarModel] {
+			cars[driver.CarModel] = true
+			doc.Car = append(doc.Car, driver.CarModel)
+			text = append(text, prettifyName(driver.CarModel, true))
+		}
+	}
+
+	doc.Text = strings.Join(append(text, doc.DriverNames...), " ")
+
+	return doc
+}
+
+func resultsSearchIndexMapping() mapping.IndexMapping {
+	textFieldMapping := bleve.NewTextFieldMapping()
+	textFieldMapping.Analyzer = standard.Name
+
+	keywordFieldMapping := bleve.NewTextFieldMapping()
+	keywordFieldMapping.Analyzer = keyword.Name
+
+	documentMapping := bleve.NewDocumentStaticMapping()
+	documentMapping.AddFieldMappingsAt("Text", textFieldMapping)
+	documentMapping.AddFieldMappingsAt("DriverNames", textFieldMapping)
+
+	for _, field := range []string{"Driver", "DriverGUID", "Track", "TrackLayout", "Car", "SessionType", "Championship", "RaceWeekend"} {
+		documentMapping.AddFieldMappingsAt(field, keywordFieldMapping)
+	}
+
+	documentMapping.AddFieldMappingsAt("Date", bleve.NewDateTimeFieldMapping())
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultMapping = documentMapping
+	indexMapping.DefaultAnalyzer = standard.Name
+
+	return indexMapping
+}
+
+func resultsSearchIndexPath() string {
+	return filepath.Join(ServerInstallPath, "search-index", "results")
+}
+
+// updateSearchIndex applies changes to the results index to the search index, opening (or creating) the search index
+// if needed. If the search index doesn't match the results index, it is rebuilt. ri.mutex must be held.
+func (ri *ResultsIndex) updateSearchIndex(entries, changed []*ResultsIndexEntry, deleted []string) error {
+	if ri.searchIndex == nil {
+		searchIndex, err := bleve.Open(resultsSearchIndexPath())
+
+		if err == bleve.ErrorIndexPathDoesNotExist {
+			logrus.Infof("Creating results search index")
+
+			searchIndex, err = bleve.New(resultsSearchIndexPath(), resultsSearchIndexMapping())
+
+			if err != nil {
+				return err
+			}
+		} else if err != nil {
+			return err
+		}
+
+		ri.searchIndex = searchIndex
+	}
+
+	batch := ri.searchIndex.NewBatch()
+
+	for _, entry := range changed {
+		if entry.Error != "" {
+			batch.Delete(entry.FileName)
+			continue
+		}
+
+		if err := batch.Index(entry.FileName, newResultsSearchDocument(entry)); err != nil {
+			return err
+		}
+	}
+
+	for _, fileName := range deleted {
+		batch.Delete(fileName)
+	}
+
+	if batch.Size() > 0 {
+		if err := ri.searchIndex.Batch(batch); err != nil {
+			return err
+		}
+	}
+
+	count, err := ri.searchIndex.DocCount()
+
+	if err != nil {
+		return err
+	}
+
+	if int(count) == len(entries) {
+		return nil
+	}
+
+	logrus.Infof("Results search index is out of date (%d documents, %d results files), rebuilding", count, len(entries))
+
+	if err := ri.removeSearchIndex(); err != nil {
+		return err
+	}
+
+	return ri.updateSearchIndex(entries, entries, nil)
+}
+
+// removeSearchIndex closes and deletes the search index. ri.mutex must be held.
+func (ri *ResultsIndex) removeSearchIndex() error {
+	if ri.searchIndex != nil {
+		if err := ri.searchIndex.Close(); err != nil {
+			return err
+		}
+
+		ri.searchIndex = nil
+	}
+
+	return os.RemoveAll(resultsSearchIndexPath())
+}
+
+// Close closes the search index.
+func (ri *ResultsIndex) Close() error {
+	ri.mutex.Lock()
+	defer ri.mutex.Unlock()
+
+	if ri.searchIndex == nil {
+		return nil
+	}
+
+	err := ri.searchIndex.Close()
+	ri.searchIndex = nil
+
+	return err
+}
+
+// ResultsSearch finds results by a full text query, and by driver, track, car, session type, championship, race
+// weekend and date. Empty fields match all results.
+type ResultsSearch struct {
+	Term string
+
+	// Driver is a driver's GUID, or (part of) their name.
+	Driver         string
+	Track          string
+	TrackLayout    string
+	Car            string
+	SessionType    SessionType
+	ChampionshipID string
+	RaceWeekendID  string
+
+	From time.Time
+	To   time.Time
+}
+
+// NewResultsSearch reads a ResultsSearch from the query parameters: q, driver, track, layout, car, session,
+// championship, race_weekend, from and to. Dates are in the format YYYY-MM-DD.
+func NewResultsSearch(values url.Values) (*ResultsSearch, error) {
+	s := &ResultsSearch{
+		Term:           strings.TrimSpace(values.Get("q")),
+		Driver:         strings.TrimSpace(values.Get("driver")),
+		Track:          values.Get("track"),
+		TrackLayout:    values.Get("layout"),
+		Car:            values.Get("car"),
+		SessionType:    SessionType(values.Get("session")),
+		ChampionshipID: values.Get("championship"),
+		RaceWeekendID:  values.Get("race_weekend"),
+	}
+
+	var err error
+
+	if from := values.Get("from"); from != "" {
+		s.From, err = time.ParseInLocation(dateInputFormat, from, time.Local)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if to := values.Get("to"); to != "" {
+		s.To, err = time.ParseInLocation(dateInputFormat, to, time.Local)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// IsEmpty reports whether the search matches all results.
+func (s *ResultsSearch) IsEmpty() bool {
+	return len(s.Values()) == 0
+}
+
+// Values returns the search as query parameters, for use with NewResultsSearch.
+func (s *ResultsSearch) Values() url.Values {
+	values := make(url.Values)
+
+	for key, value := range map[string]string{
+		"q":            s.Term,
+		"driver":       s.Driver,
+		"track":        s.Track,
+		"layout":       s.TrackLayout,
+		"car":          s.Car,
+		"session":      string(s.SessionType),
+		"championship": s.ChampionshipID,
+		"race_weekend": s.RaceWeekendID,
+		"from":         s.FromString(),
+		"to":           s.ToString(),
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+
+	return values
+}
+
+// URLWith is the URL of the search with a query parameter set.
+func (s *ResultsSearch) URLWith(key, value string) string {
+	values := s.Values()
+	values.Set(key, value)
+
+	return "/results?" + values.Encode()
+}
+
+// URLWithout is the URL of the search without a query parameter.
+func (s *ResultsSearch) URLWithout(key string) string {
+	values := s.Values()
+	values.Del(key)
+
+	return "/results?" + values.Encode()
+}
+
+func (s *ResultsSearch) FromString() string {
+	if s.From.IsZero() {
+		return ""
+	}
+
+	return s.From.Format(dateInputFormat)
+}
+
+func (s *ResultsSearch) ToString() string {
+	if s.To.IsZero() {
+		return ""
+	}
+
+	return s.To.Format(dateInputFormat)
+}
+
+func isDriverGUID(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func resultsSearchTermQuery(field, term string) query.Query {
+	q := bleve.NewTermQuery(term)
+	q.SetField(field)
+
+	return q
+}
+
+func (s *ResultsSearch) query() query.Query {
+	var conjuncts []query.Query
+
+	if s.Term != "" {
+		conjuncts = append(conjuncts, bleve.NewQueryStringQuery(s.Term))
+	}
+
+	if isDriverGUID(s.Driver) {
+		conjuncts = append(conjuncts, resultsSearchTermQuery("DriverGUID", s.Driver))
+	} else if s.Driver != "" {
+		q := bleve.NewMatchPhraseQuery(s.Driver)
+		q.SetField("DriverNames")
+
+		conjuncts = append(conjuncts, q)
+	}
+
+	for field, term := range map[string]string{
+		"Track":        s.Track,
+		"TrackLayout":  s.TrackLayout,
+		"Car":          s.Car,
+		"SessionType":  string(s.SessionType),
+		"Championship": s.ChampionshipID,
+		"RaceWeekend":  s.RaceWeekendID,
+	} {
+		if term != "" {
+			conjuncts = append(conjuncts, resultsSearchTermQuery(field, term))
+		}
+	}
+
+	if !s.From.IsZero() || !s.To.IsZero() {
+		var to time.Time
+
+		if !s.To.IsZero() {
+			// the To date is inclusive.
+			to = s.To.AddDate(0, 0, 1)
+		}
+
+		q := bleve.NewDateRangeQuery(s.From, to)
+		q.SetField("Date")
+
+		conjuncts = append(conjuncts, q)
+	}
+
+	if len(conjuncts) == 0 {
+		return bleve.NewMatchAllQuery()
+	}
+
+	return bleve.NewConjunctionQuery(conjuncts...)
+}
+
+// ResultsSearchFacet is the number of results found for each value of a field, e.g. each track.
+type ResultsSearchFacet struct {
+	Name  string
+	Param string
+	Terms []*ResultsSearchFacetTerm
+}
+
+type ResultsSearchFacetTerm struct {
+	Value string
+	Label string
+	Count int
+}
+
+type ResultsSearchResults struct {
+	Entries    []*ResultsIndexEntry
+	NumResults int
+	Pages      []int
+	Facets     []*ResultsSearchFacet
+	Took       time.Duration
+}
+
+const resultsSearchFacetSize = 10
+
+var resultsSearchFacets = []struct {
+	name, field, param string
+}{
+	{name: "Drivers", field: "Driver", param: "driver"},
+	{name: "Tracks", field: "Track", param: "track"},
+	{name: "Cars", field: "Car", param: "car"},
+	{name: "Sessions", field: "SessionType", param: "session"},
+	{name: "Championships", field: "Championship", param: "championship"},
+	{name: "Race Weekends", field: "RaceWeekend", param: "race_weekend"},
+}
+
+// Search returns a page of the results which match the search, newest first, with the number of matching results
+// for the most common drivers, tracks, cars, session types, championships and race weekends.
+func (ri *ResultsIndex) Search(ctx context.Context, s *ResultsSearch, page int) (*ResultsSearchResults, error) {
+	entries, err := ri.sync()
+
+	if err != nil {
+		return nil, err
+	}
+
+	ri.mutex.Lock()
+	searchIndex := ri.searchIndex
+	ri.mutex.Unlock()
+
+	if searchIndex == nil {
+		return nil, ErrResultsSearchUnavailable
+	}
+
+	if page < 0 {
+		return nil, ErrResultsPageNotFound
+	}
+
+	request := bleve.NewSearchRequestOptions(s.query(), pageSize, page*pageSize, false)
+	request.SortBy([]string{"-Date", "-_id"})
+
+	for _, facet := range resultsSearchFacets {
+		request.AddFacet(facet.name, bleve.NewFacetRequest(facet.field, resultsSearchFacetSize))
+	}
+
+	searchResult, err := searchIndex.SearchInContext(ctx, request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	numPages := int(math.Ceil(float64(searchResult.Total) / float64(pageSize)))
+
+	if page >= numPages && page != 0 {
+		return nil, ErrResultsPageNotFound
+	}
+
+	entriesByFileName := make(map[string]*ResultsIndexEntry)
+
+	for _, entry := range entries {
+		entriesByFileName[entry.FileName] = entry
+	}
+
+	results := &ResultsSearchResults{
+		NumResults: int(searchResult.Total),
+		Took:       searchResult.Took,
+	}
+
+	for x := 0; x < numPages; x++ {
+		results.Pages = append(results.Pages, x)
+	}
+
+	for _, hit := range searchResult.Hits {
+		if entry, ok := entriesByFileName[hit.ID]; ok {
+			results.Entries = append(results.Entries, entry)
+		}
+	}
+
+	labels, err := ri.facetLabels()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, facet := range resultsSearchFacets {
+		facetResult, ok := searchResult.Facets[facet.name]
+
+		if !ok || len(facetResult.Terms) == 0 {
+			continue
+		}
+
+		resultsSearchFacet := &ResultsSearchFacet{
+			Name:  facet.name,
+			Param: facet.param,
+		}
+
+		for _, term := range facetResult.Terms {
+			resultsSearchFacet.Terms = append(resultsSearchFacet.Terms, &ResultsSearchFacetTerm{
+				Value: term.Term,
+				Label: labels[facet.field](term.Term),
+				Count: term.Count,
+			})
+		}
+
+		results.Facets = append(results.Facets, resultsSearchFacet)
+	}
+
+	return results, nil
+}
+
+// facetLabels returns a function for each facet field which gives a readable name for a value of that field.
+func (ri *ResultsIndex) facetLabels() (map[string]func(string) string, error) {
+	championships, err := ri.store.ListChampionships()
+
+	if err != nil {
+		return nil, err
+	}
+
+	raceWeekends, err := ri.store.ListRaceWeekends()
+
+	if err != nil {
+		return nil, err
+	}
+
+	championshipNames := make(map[string]string)
+
+	for _, championship := range championships {
+		championshipNames[championship.ID.String()] = championship.Name
+	}
+
+	raceWeekendNames := make(map[string]string)
+
+	for _, raceWeekend := range raceWeekends {
+		raceWeekendNames[raceWeekend.ID.String()] = raceWeekend.Name
+	}
+
+	nameOrID := func(names map[string]string) func(string) string {
+		return func(id string) string {
+			if name, ok := names[id]; ok && name != "" {
+				return name
+			}
+
+			return id
+		}
+	}
+
+	return map[string]func(string) string{
+		"Driver": driverName,
+		"Track": func(track string) string {
+			return prettifyName(track, false)
+		},
+		"Car": func(car string) string {
+			return prettifyName(car, true)
+		},
+		"SessionType": func(sessionType string) string {
+			return SessionType(sessionType).String()
+		},
+		"Championship": nameOrID(championshipNames),
+		"RaceWeekend":  nameOrID(raceWeekendNames),
+	}, nil
+}
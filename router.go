@@ -56,6 +56,7 @@ func Router(
 	weatherHandler *WeatherHandler,
 	penaltiesHandler *PenaltiesHandler,
 	resultsHandler *ResultsHandler,
+	resultsImportHandler *ResultsImportHandler,
 	contentUploadHandler *ContentUploadHandler,
 	serverAdministrationHandler *ServerAdministrationHandler,
 	raceControlHandler *RaceControlHandler,
@@ -65,6 +66,12 @@ func Router(
 	healthCheck *HealthCheck,
 	kissMyRankHandler *KissMyRankHandler,
 	realPenaltyHandler *RealPenaltyHandler,
+	replayHandler *ReplayHandler,
+	stewardsHandler *StewardsHandler,
+	raceControlAPIHandler *RaceControlAPIHandler,
+	driverProfilesHandler *DriverProfilesHandler,
+	leaderboardsHandler *LeaderboardsHandler,
+	lapAnomaliesHandler *LapAnomaliesHandler,
 ) http.Handler {
 	r := chi.NewRouter()
 
@@ -100,6 +107,15 @@ func Router(
 		http.NotFound(w, r)
 	})
 
+	// race control api
+	r.Group(func(r chi.Router) {
+		r.Use(liveTimingsEnabledMiddleware)
+		r.Use(raceControlAPIHandler.KeyMiddleware)
+
+		r.Get("/api/v1/race-control", raceControlAPIHandler.snapshot)
+		r.Get("/api/v1/race-control/events", raceControlAPIHandler.events)
+	})
+
 	// readers
 	r.Group(func(r chi.Router) {
 		r.Use(accountHandler.ReadAccessMiddleware)
@@ -125,7 +141,17 @@ func Router(
 		r.Get("/results", resultsHandler.list)
 		r.Get("/results/{fileName}", resultsHandler.view)
 		r.HandleFunc("/results/{fileName}/collisions", resultsHandler.renderCollisions)
+		r.Get("/results/{fileName}/revisions/{revisionID}", resultsHandler.viewRevision)
 		r.HandleFunc("/results/download/{fileName}", resultsHandler.file)
+		r.Get("/results/{fileName}/export/{format}", resultsHandler.export)
+
+		// drivers
+		r.Get("/driver/{guid}", driverProfilesHandler.view)
+		r.Get("/api/driver/{guid}", driverProfilesHandler.json)
+
+		// leaderboards
+		r.Get("/leaderboards", leaderboardsHandler.view)
+		r.Get("/api/leaderboards", leaderboardsHandler.json)
 
 		r.Get("/custom", customRaceHandler.list)
 
@@ -134,7 +160,11 @@ func Router(
 		r.Get("/championship/{championshipID}", championshipsHandler.view)
 		r.Get("/championship/{championshipID}/export", championshipsHandler.export)
 		r.HandleFunc("/championship/{championshipID}/export-results", championshipsHandler.exportResults)
+		r.Get("/championship/{championshipID}/export-results/{format}", championshipsHandler.exportAllResults)
 		r.Get("/championship/{championshipID}/ics", championshipsHandler.icalFeed)
+		r.Get("/api/championship/{championshipID}/standings", championshipsHandler.standingsJSON)
+		r.Get("/api/championship/{championshipID}/standings/history", championshipsHandler.standingsHistoryJSON)
+		r.Get("/api/championship/{championshipID}/title-permutations", championshipsHandler.titlePermutations)
 		r.Get("/championship/{championshipID}/sign-up", championshipsHandler.signUpForm)
 		r.Post("/championship/{championshipID}/sign-up", championshipsHandler.signUpForm)
 		r.Get("/championship/{championshipID}/sign-up/steam", championshipsHandler.redirectToSteamLogin(func(r *http.Request) string {
@@ -144,17 +174,7 @@ func Router(
 
 		// race control
 		r.Group(func(r chi.Router) {
-			r.Use(func(next http.Handler) http.Handler {
-				fn := func(w http.ResponseWriter, req *http.Request) {
-					if config.Server.PerformanceMode {
-						http.NotFound(w, req)
-					} else {
-						next.ServeHTTP(w, req)
-					}
-				}
-
-				return http.HandlerFunc(fn)
-			})
+			r.Use(liveTimingsEnabledMiddleware)
 
 			r.Get("/live-timing", raceControlHandler.liveTiming)
 			r.Get("/api/race-control", raceControlHandler.websocket)
@@ -207,6 +227,8 @@ func Router(
 		r.Post("/track/{name}/metadata", tracksHandler.saveMetadata)
 		r.Post("/results/upload", resultsHandler.uploadHandler)
 		r.HandleFunc("/results/combine", resultsHandler.combineResults)
+		r.HandleFunc("/results/import", resultsImportHandler.upload)
+		r.HandleFunc("/results/import/{format}/{importID}", resultsImportHandler.sessions)
 
 		// races
 		r.Get("/quick", quickRaceHandler.create)
@@ -244,6 +266,10 @@ func Router(
 
 		r.Post("/championship/{championshipID}/driver-penalty/{classID}/{driverGUID}", championshipsHandler.driverPenalty)
 		r.Post("/championship/{championshipID}/team-penalty/{classID}/{team}", championshipsHandler.teamPenalty)
+		r.Get("/championship/{championshipID}/teams", championshipsHandler.teams)
+		r.Post("/championship/{championshipID}/teams", championshipsHandler.saveTeams)
+		r.Post("/championship/{championshipID}/grid-penalty", championshipsHandler.addGridPenalty)
+		r.Post("/championship/{championshipID}/grid-penalty/{gridPenaltyID}/delete", championshipsHandler.deleteGridPenalty)
 		r.Get("/championship/{championshipID}/entrants", championshipsHandler.signedUpEntrants)
 		r.Get("/championship/{championshipID}/entrants.csv", championshipsHandler.signedUpEntrantsCSV)
 		r.Get("/championship/{championshipID}/entrant/{entrantGUID}", championshipsHandler.modifyEntrantStatus)
@@ -262,12 +288,27 @@ func Router(
 		// penalties
 		r.Post("/penalties/{sessionFile}/{driverGUID}", penaltiesHandler.managePenalty)
 
+		// stewards
+		r.Get("/stewards", stewardsHandler.incidents)
+		r.Get("/stewards/incident/{incidentID}", stewardsHandler.incident)
+		r.Post("/stewards/incident/{incidentID}/decision", stewardsHandler.decide)
+
 		// results
 		r.Post("/results/{fileName}/edit", resultsHandler.edit)
+		r.Post("/results/{fileName}/revisions/{revisionID}/revert", resultsHandler.revertRevision)
 
 		// live timings
 		r.Post("/live-timing/save-frames", raceControlHandler.saveIFrames)
 
+		// replays
+		r.Group(func(r chi.Router) {
+			r.Use(liveTimingsEnabledMiddleware)
+
+			r.Get("/results/{fileName}/replay", replayHandler.view)
+			r.Post("/results/{fileName}/replay/{action}", replayHandler.control)
+			r.Get("/api/replay", replayHandler.websocket)
+		})
+
 		// endpoints
 		r.Post("/api/track/upload", contentUploadHandler.upload(ContentTypeTrack))
 		r.Post("/api/car/upload", contentUploadHandler.upload(ContentTypeCar))
@@ -338,6 +379,8 @@ func Router(
 		r.HandleFunc("/accounts/toggle-open", accountHandler.toggleServerOpenStatus)
 		r.HandleFunc("/accounts", accountHandler.manageAccounts)
 		r.HandleFunc("/search-index", carsHandler.rebuildSearchIndex)
+		r.HandleFunc("/results-index", resultsHandler.rebuildIndex)
+		r.Get("/results/{fileName}/anomalies", lapAnomaliesHandler.report)
 
 		r.HandleFunc("/restart-session", raceControlHandler.restartSession)
 		r.HandleFunc("/next-session", raceControlHandler.nextSession)
@@ -346,6 +389,8 @@ func Router(
 		r.HandleFunc("/kick-user", raceControlHandler.kickUser)
 		r.HandleFunc("/send-chat", raceControlHandler.sendChat)
 		r.HandleFunc("/countdown", raceControlHandler.countdown)
+		r.HandleFunc("/full-course-yellow/start", raceControlHandler.startFullCourseYellow)
+		r.HandleFunc("/full-course-yellow/end", raceControlHandler.endFullCourseYellow)
 
 		r.HandleFunc("/stracker/options", strackerHandler.options)
 		r.HandleFunc("/kissmyrank/options", kissMyRankHandler.options)
@@ -403,6 +448,19 @@ func getErrSession(r *http.Request) *sessions.Session {
 	return session
 }
 
+// liveTimingsEnabledMiddleware hides Live Timings pages when performance mode is on.
+func liveTimingsEnabledMiddleware(next http.Handler) http.Handler {
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		if config.Server.PerformanceMode {
+			http.NotFound(w, r)
+		} else {
+			next.ServeHTTP(w, r)
+		}
+	}
+
+	return http.HandlerFunc(fn)
+}
+
 // Helper function to get message session and add a flash
 func AddFlash(w http.ResponseWriter, r *http.Request, message string) {
 	session := getSession(r)
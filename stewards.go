@@ -0,0 +1,726 @@
+package servermanager
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+// defaultStewardingMinimumImpactSpeed is the default impact speed (in km/h) below which contacts are ignored.
+const defaultStewardingMinimumImpactSpeed = 20
+
+var (
+	// incidentGroupingWindow is how long after a contact between two cars any further contacts between them are
+	// considered to be part of the same incident.
+	incidentGroupingWindow = time.Second * 5
+
+	// incidentNearbyCarDistance is the distance (in metres) from the incident within which other cars are included
+	// in the incident's snapshot.
+	incidentNearbyCarDistance = 100.0
+
+	// fullCourseYellowGracePeriod is how long drivers are given to slow down once a Full Course Yellow has started.
+	fullCourseYellowGracePeriod = time.Second * 10
+
+	// fullCourseYellowPositionCheckInterval is how often the race order is checked for position changes during a
+	// Full Course Yellow.
+	fullCourseYellowPositionCheckInterval = time.Second
+
+	ErrIncidentNotFound       = errors.New("servermanager: incident not found")
+	ErrIncidentDriverNotFound = errors.New("servermanager: driver is not involved in incident")
+	ErrInvalidIncidentVerdict = errors.New("servermanager: invalid incident verdict")
+)
+
+type IncidentType string
+
+const (
+	IncidentTypeContact          IncidentType = "Contact"
+	IncidentTypeFullCourseYellow IncidentType = "Full Course Yellow"
+)
+
+type IncidentVerdict string
+
+const (
+	IncidentVerdictNoFurtherAction IncidentVerdict = "No Further Action"
+	IncidentVerdictRacingIncident  IncidentVerdict = "Racing Incident"
+	IncidentVerdictWarning         IncidentVerdict = "Warning"
+	IncidentVerdictTimePenalty     IncidentVerdict = "Time Penalty"
+	IncidentVerdictDisqualified    IncidentVerdict = "Disqualification"
+)
+
+var IncidentVerdicts = []IncidentVerdict{
+	IncidentVerdictNoFurtherAction,
+	IncidentVerdictRacingIncident,
+	IncidentVerdictWarning,
+	IncidentVerdictTimePenalty,
+	IncidentVerdictDisqualified,
+}
+
+// IsPenalty reports whether the verdict results in a penalty being applied to the session results.
+func (v IncidentVerdict) IsPenalty() bool {
+	return v == IncidentVerdictTimePenalty || v == IncidentVerdictDisqualified
+}
+
+func (v IncidentVerdict) valid() bool {
+	for _, verdict := range IncidentVerdicts {
+		if v == verdict {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IncidentCar is a snapshot of a car at the time of an incident.
+type IncidentCar struct {
+	CarID          udp.CarID      `json:"CarID"`
+	DriverGUID     udp.DriverGUID `json:"DriverGUID"`
+	DriverName     string         `json:"DriverName"`
+	CarModel       string         `json:"CarModel"`
+	Position       int            `json:"Position"`
+	LapsCompleted  int            `json:"LapsCompleted"`
+	SplinePosition float32        `json:"SplinePosition"`
+	Speed          float64        `json:"Speed"`
+
+	// Distance is how far (in metres) this car was from the incident.
+	Distance float64 `json:"Distance"`
+}
+
+// IncidentDecision is a steward's verdict on an incident. Each decision is kept, so that the history of an incident
+// can be reviewed.
+type IncidentDecision struct {
+	Time    time.Time       `json:"Time"`
+	Steward string          `json:"Steward"`
+	Verdict IncidentVerdict `json:"Verdict"`
+	Notes   string          `json:"Notes"`
+
+	PenalisedDriverGUID udp.DriverGUID `json:"PenalisedDriverGUID"`
+	PenaltyTime         time.Duration  `json:"PenaltyTime"`
+
+	// Applied is true once the penalty has been applied to the session results. Penalties decided while the
+	// session is still running are applied when the session ends.
+	Applied bool `json:"Applied"`
+}
+
+// Incident is one or more contacts between two cars within a short space of time, or a driver's infringement of
+// the rules during a Full Course Yellow.
+type Incident struct {
+	ID uuid.UUID `json:"ID"`
+
+	// Created and Updated are set by the StewardsManager from the RaceControl's clock, so that they match the time of
+	// the session, rather than when the incident was saved.
+	Created time.Time `json:"Created"`
+	Updated time.Time `json:"Updated"`
+
+	Type        IncidentType `json:"Type"`
+	Description string       `json:"Description"`
+
+	SessionID   uuid.UUID       `json:"SessionID"`
+	SessionType udp.SessionType `json:"SessionType"`
+	SessionName string          `json:"SessionName"`
+	Track       string          `json:"Track"`
+	TrackLayout string          `json:"TrackLayout"`
+
+	// SessionFile is the results file of the session the incident occurred in. It is empty until the session ends.
+	SessionFile string `json:"SessionFile"`
+
+	Time        time.Time     `json:"Time"`
+	SessionTime time.Duration `json:"SessionTime"`
+	LastContact time.Time     `json:"LastContact"`
+	NumContacts int           `json:"NumContacts"`
+	ImpactSpeed float64       `json:"ImpactSpeed"`
+	WorldPos    udp.Vec       `json:"WorldPos"`
+
+	Car        IncidentCar   `json:"Car"`
+	OtherCar   IncidentCar   `json:"OtherCar"`
+	NearbyCars []IncidentCar `json:"NearbyCars"`
+
+	Decisions []*IncidentDecision `json:"Decisions"`
+}
+
+// LatestDecision is the decision which currently stands for the incident, or nil if it has not been reviewed.
+func (i *Incident) LatestDecision() *IncidentDecision {
+	if len(i.Decisions) == 0 {
+		return nil
+	}
+
+	return i.Decisions[len(i.Decisions)-1]
+}
+
+func (i *Incident) Reviewed() bool {
+	return len(i.Decisions) > 0
+}
+
+// IsContact is true for incidents which are contacts between cars. Incidents recorded before other types of
+// incident were added have no type, and are all contacts.
+func (i *Incident) IsContact() bool {
+	return i.Type == "" || i.Type == IncidentTypeContact
+}
+
+// Summary is a short description of the incident, used as the reason for any penalty given for it.
+func (i *Incident) Summary() string {
+	if i.Description != "" {
+		return i.Description
+	}
+
+	if i.IsContact() && i.HasOtherCar() {
+		return fmt.Sprintf("Contact between %s and %s", i.Car.DriverName, i.OtherCar.DriverName)
+	}
+
+	return string(i.Type)
+}
+
+// HasOtherCar is false for incidents which only involve one car.
+func (i *Incident) HasOtherCar() bool {
+	return i.OtherCar.DriverGUID != ""
+}
+
+// InvolvedCars are the cars which are involved in the incident, and may be penalised for it.
+func (i *Incident) InvolvedCars() []IncidentCar {
+	if !i.HasOtherCar() {
+		return []IncidentCar{i.Car}
+	}
+
+	return []IncidentCar{i.Car, i.OtherCar}
+}
+
+func (i *Incident) involves(carID, otherCarID udp.CarID) bool {
+	return (i.Car.CarID == carID && i.OtherCar.CarID == otherCarID) || (i.Car.CarID == otherCarID && i.OtherCar.CarID == carID)
+}
+
+func (i *Incident) findCar(guid udp.DriverGUID) (*IncidentCar, error) {
+	if guid == "" {
+		return nil, ErrIncidentDriverNotFound
+	}
+
+	switch guid {
+	case i.Car.DriverGUID:
+		return &i.Car, nil
+	case i.OtherCar.DriverGUID:
+		return &i.OtherCar, nil
+	default:
+		return nil, ErrIncidentDriverNotFound
+	}
+}
+
+// StewardsManager groups contacts between cars into incidents, which stewards can then review and penalise.
+type StewardsManager struct {
+	store            Store
+	raceControl      *RaceControl
+	penaltiesManager *PenaltiesManager
+
+	mutex            sync.Mutex
+	sessionID        uuid.UUID
+	sessionIncidents []*Incident
+	fullCourseYellow *fullCourseYellowMonitor
+}
+
+// fullCourseYellowMonitor is the state of the checks made on cars during a Full Course Yellow.
+type fullCourseYellowMonitor struct {
+	start             time.Time
+	stewardingEnabled bool
+
+	// speeding is the incident for each car that has exceeded the speed limit during the Full Course Yellow.
+	speeding map[udp.CarID]*Incident
+
+	// positions is the race position of each car at the last check.
+	positions         map[udp.CarID]int
+	lastPositionCheck time.Time
+}
+
+func NewStewardsManager(store Store, raceControl *RaceControl, penaltiesManager *PenaltiesManager) *StewardsManager {
+	return &StewardsManager{
+		store:            store,
+		raceControl:      raceControl,
+		penaltiesManager: penaltiesManager,
+		sessionID:        uuid.New(),
+	}
+}
+
+// UDPCallback must be called after RaceControl.UDPCallback, so that incident snapshots are taken from the
+// RaceControl's view of the session. Outside of replays, RaceControl handles CarUpdates asynchronously, so the
+// positions and speeds of drivers in a snapshot may be a few updates behind. Where an incident is caused by a
+// CarUpdate, the snapshot of that car is taken from the update itself.
+func (sm *StewardsManager) UDPCallback(message udp.Message) {
+	var err error
+
+	switch m := message.(type) {
+	case udp.SessionInfo:
+		if m.Event() == udp.EventNewSession {
+			sm.onNewSession()
+		}
+	case udp.CollisionWithCar:
+		err = sm.onCollisionWithCar(m)
+	case udp.CarUpdate:
+		err = sm.onCarUpdate(m)
+	case udp.EndSession:
+		err = sm.onEndSession(filepath.Base(string(m)))
+	}
+
+	if err != nil {
+		logrus.WithError(err).Errorf("Stewards could not handle event: %d", message.Event())
+	}
+}
+
+func (sm *StewardsManager) onNewSession() {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	sm.sessionID = uuid.New()
+	sm.sessionIncidents = nil
+	sm.fullCourseYellow = nil
+}
+
+func (sm *StewardsManager) onCollisionWithCar(collision udp.CollisionWithCar) error {
+	serverOpts, err := sm.store.LoadServerOptions()
+
+	if err != nil {
+		return err
+	}
+
+	impactSpeed := metersPerSecondToKilometersPerHour(float64(collision.ImpactSpeed))
+
+	if serverOpts.EnableStewarding != 1 || impactSpeed < float64(serverOpts.StewardingMinimumImpactSpeed) {
+		return nil
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	now := sm.raceControl.now()
+
+	for _, incident := range sm.sessionIncidents {
+		if incident.involves(collision.CarID, collision.OtherCarID) && now.Sub(incident.LastContact) <= incidentGroupingWindow {
+			incident.LastContact = now
+			incident.Updated = now
+			incident.NumContacts++
+
+			if impactSpeed > incident.ImpactSpeed {
+				incident.ImpactSpeed = impactSpeed
+			}
+
+			return sm.store.UpsertIncident(incident)
+		}
+	}
+
+	incident, err := sm.newIncident(IncidentTypeContact, collision.WorldPos, now, collision.CarID, collision.OtherCarID)
+
+	if err != nil {
+		return err
+	}
+
+	incident.NumContacts = 1
+	incident.ImpactSpeed = impactSpeed
+
+	sm.sessionIncidents = append(sm.sessionIncidents, incident)
+
+	logrus.Infof("Stewards: new incident between %s and %s at %.1f km/h", incident.Car.DriverName, incident.OtherCar.DriverName, impactSpeed)
+
+	return sm.store.UpsertIncident(incident)
+}
+
+// onCarUpdate checks that cars slow down and hold their positions during a Full Course Yellow.
+func (sm *StewardsManager) onCarUpdate(update udp.CarUpdate) error {
+	fullCourseYellow := sm.raceControl.activeFullCourseYellow()
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if fullCourseYellow == nil {
+		sm.fullCourseYellow = nil
+		return nil
+	}
+
+	if sm.fullCourseYellow == nil || !sm.fullCourseYellow.start.Equal(fullCourseYellow.Start) {
+		serverOpts, err := sm.store.LoadServerOptions()
+
+		if err != nil {
+			return err
+		}
+
+		sm.fullCourseYellow = &fullCourseYellowMonitor{
+			start:             fullCourseYellow.Start,
+			stewardingEnabled: serverOpts.EnableStewarding == 1,
+			speeding:          make(map[udp.CarID]*Incident),
+		}
+	}
+
+	if !sm.fullCourseYellow.stewardingEnabled {
+		return nil
+	}
+
+	now := sm.raceControl.now()
+
+	if fullCourseYellow.SpeedLimit > 0 && now.Sub(fullCourseYellow.Start) >= fullCourseYellowGracePeriod {
+		speed := metersPerSecondToKilometersPerHour(
+			math.Sqrt(math.Pow(float64(update.Velocity.X), 2) + math.Pow(float64(update.Velocity.Z), 2)),
+		)
+
+		if speed > float64(fullCourseYellow.SpeedLimit) {
+			if err := sm.onFullCourseYellowSpeeding(update, speed, fullCourseYellow.SpeedLimit, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	if now.Sub(sm.fullCourseYellow.lastPositionCheck) >= fullCourseYellowPositionCheckInterval {
+		sm.fullCourseYellow.lastPositionCheck = now
+
+		return sm.checkFullCourseYellowPositions(now)
+	}
+
+	return nil
+}
+
+// onFullCourseYellowSpeeding creates an incident the first time a car exceeds the Full Course Yellow speed limit,
+// and records the car's top speed in it after that. sm.mutex must be held.
+func (sm *StewardsManager) onFullCourseYellowSpeeding(update udp.CarUpdate, speed float64, speedLimit int, now time.Time) error {
+	if incident, ok := sm.fullCourseYellow.speeding[update.CarID]; ok {
+		if speed <= incident.Car.Speed {
+			return nil
+		}
+
+		incident.Car.Speed = speed
+		incident.Updated = now
+
+		return sm.store.UpsertIncident(incident)
+	}
+
+	incident, err := sm.newIncident(IncidentTypeFullCourseYellow, update.Pos, now, update.CarID)
+
+	if err != nil {
+		return err
+	}
+
+	// RaceControl may not have handled this update yet.
+	incident.Car.Speed = speed
+	incident.Car.SplinePosition = update.NormalisedSplinePos
+	incident.Car.Distance = 0
+	incident.Description = fmt.Sprintf("Exceeded the Full Course Yellow speed limit of %d km/h", speedLimit)
+
+	sm.fullCourseYellow.speeding[update.CarID] = incident
+	sm.sessionIncidents = append(sm.sessionIncidents, incident)
+
+	logrus.Infof("Stewards: %s exceeded the Full Course Yellow speed limit at %.1f km/h", incident.Car.DriverName, speed)
+
+	return sm.store.UpsertIncident(incident)
+}
+
+// checkFullCourseYellowPositions compares the race order to the last check, and creates an incident for each car
+// which has overtaken another car. sm.mutex must be held.
+func (sm *StewardsManager) checkFullCourseYellowPositions(now time.Time) error {
+	rc := sm.raceControl
+
+	positions := make(map[udp.CarID]int)
+
+	for _, gap := range rc.gapTracker.calculate(rc.connectedCarIDs()) {
+		positions[gap.CarID] = gap.Position
+	}
+
+	previousPositions := sm.fullCourseYellow.positions
+	sm.fullCourseYellow.positions = positions
+
+	if previousPositions == nil {
+		return nil
+	}
+
+	for carID, position := range positions {
+		previousPosition, ok := previousPositions[carID]
+
+		if !ok || position >= previousPosition {
+			continue
+		}
+
+		// find the car that was passed, positions gained from cars leaving the server or entering the pit lane are
+		// not overtakes.
+		var passedCarID udp.CarID
+		passedCarPosition := 0
+
+		for otherCarID, otherPreviousPosition := range previousPositions {
+			otherPosition, ok := positions[otherCarID]
+
+			if !ok || otherCarID == carID || otherPreviousPosition > previousPosition || otherPosition < position || rc.pitStops.inPitLane(otherCarID) {
+				continue
+			}
+
+			if passedCarPosition == 0 || otherPosition < passedCarPosition {
+				passedCarID = otherCarID
+				passedCarPosition = otherPosition
+			}
+		}
+
+		if passedCarPosition == 0 {
+			continue
+		}
+
+		incident, err := sm.newIncident(IncidentTypeFullCourseYellow, rc.flags.position(carID), now, carID, passedCarID)
+
+		if err != nil {
+			logrus.WithError(err).Errorf("Could not create Full Course Yellow incident for car: %d", carID)
+			continue
+		}
+
+		incident.Description = fmt.Sprintf("Gained a position during a Full Course Yellow (P%d to P%d)", previousPosition, position)
+
+		sm.sessionIncidents = append(sm.sessionIncidents, incident)
+
+		logrus.Infof("Stewards: %s overtook %s during a Full Course Yellow", incident.Car.DriverName, incident.OtherCar.DriverName)
+
+		if err := sm.store.UpsertIncident(incident); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// newIncident creates an incident at worldPos involving the given cars. The first car is the incident's Car, and
+// the second (if there is one) is its OtherCar.
+func (sm *StewardsManager) newIncident(incidentType IncidentType, worldPos udp.Vec, now time.Time, carIDs ...udp.CarID) (*Incident, error) {
+	rc := sm.raceControl
+	sessionInfo := rc.currentSessionInfo()
+
+	incident := &Incident{
+		ID:          uuid.New(),
+		Created:     now,
+		Updated:     now,
+		Type:        incidentType,
+		SessionID:   sm.sessionID,
+		SessionType: sessionInfo.Type,
+		SessionName: sessionInfo.Name,
+		Track:       sessionInfo.Track,
+		TrackLayout: sessionInfo.TrackConfig,
+		Time:        now,
+		SessionTime: now.Sub(rc.SessionStartTime).Round(time.Second),
+		LastContact: now,
+		WorldPos:    worldPos,
+	}
+
+	involvedDrivers := make(map[*RaceControlDriver]bool)
+
+	for i, carID := range carIDs {
+		driver, err := rc.findConnectedDriverByCarID(carID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		involvedDrivers[driver] = true
+
+		switch i {
+		case 0:
+			incident.Car = incidentCarSnapshot(carID, driver, worldPos)
+		case 1:
+			incident.OtherCar = incidentCarSnapshot(carID, driver, worldPos)
+		}
+	}
+
+	rc.carIDToGUIDMutex.RLock()
+	driverCarIDs := make(map[udp.DriverGUID]udp.CarID, len(rc.CarIDToGUID))
+
+	for carID, guid := range rc.CarIDToGUID {
+		driverCarIDs[guid] = carID
+	}
+	rc.carIDToGUIDMutex.RUnlock()
+
+	err := rc.ConnectedDrivers.Each(func(driverGUID udp.DriverGUID, nearbyDriver *RaceControlDriver) error {
+		if involvedDrivers[nearbyDriver] {
+			return nil
+		}
+
+		car := incidentCarSnapshot(driverCarIDs[driverGUID], nearbyDriver, worldPos)
+
+		if car.Distance <= incidentNearbyCarDistance {
+			incident.NearbyCars = append(incident.NearbyCars, car)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+func incidentCarSnapshot(carID udp.CarID, driver *RaceControlDriver, incidentPos udp.Vec) IncidentCar {
+	driver.mutex.Lock()
+	defer driver.mutex.Unlock()
+
+	return IncidentCar{
+		CarID:          carID,
+		DriverGUID:     driver.CarInfo.DriverGUID,
+		DriverName:     driver.CarInfo.DriverName,
+		CarModel:       driver.CarInfo.CarModel,
+		Position:       driver.Position,
+		LapsCompleted:  driver.TotalNumLaps,
+		SplinePosition: driver.splinePos,
+		Speed:          driver.speed,
+		Distance: math.Sqrt(
+			math.Pow(float64(driver.LastPos.X-incidentPos.X), 2) + math.Pow(float64(driver.LastPos.Z-incidentPos.Z), 2),
+		),
+	}
+}
+
+// onEndSession attaches the session's results file to each of its incidents, and applies any penalties which
+// were decided while the session was running.
+func (sm *StewardsManager) onEndSession(sessionFile string) error {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	for _, sessionIncident := range sm.sessionIncidents {
+		// reload the incident, stewards may have reviewed it since it was created.
+		incident, err := sm.store.LoadIncident(sessionIncident.ID.String())
+
+		if err != nil {
+			logrus.WithError(err).Errorf("Could not load incident: %s", sessionIncident.ID)
+			continue
+		}
+
+		incident.SessionFile = sessionFile
+		incident.Updated = sm.raceControl.now()
+
+		if decision := incident.LatestDecision(); decision != nil && decision.Verdict.IsPenalty() && !decision.Applied {
+			if err := sm.applyDecision(incident, decision); err != nil {
+				logrus.WithError(err).Errorf("Could not apply penalty for incident: %s", incident.ID)
+			}
+		}
+
+		if err := sm.store.UpsertIncident(incident); err != nil {
+			logrus.WithError(err).Errorf("Could not save incident: %s", incident.ID)
+		}
+	}
+
+	sm.sessionIncidents = nil
+
+	return nil
+}
+
+// Decide records a steward's decision on an incident. If the incident's session has finished, any penalty is applied
+// to the session results straight away, and any penalty from the previous decision is removed.
+func (sm *StewardsManager) Decide(incidentID string, decision *IncidentDecision) (*Incident, error) {
+	if !decision.Verdict.valid() {
+		return nil, ErrInvalidIncidentVerdict
+	}
+
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	incident, err := sm.store.LoadIncident(incidentID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if decision.Verdict.IsPenalty() {
+		if _, err := incident.findCar(decision.PenalisedDriverGUID); err != nil {
+			return nil, err
+		}
+	} else {
+		decision.PenalisedDriverGUID = ""
+		decision.PenaltyTime = 0
+	}
+
+	decision.Time = time.Now()
+
+	if previous := incident.LatestDecision(); previous != nil && previous.Verdict.IsPenalty() && previous.Applied {
+		if err := sm.removePenalty(incident, previous, decision.Steward); err != nil {
+			return nil, err
+		}
+	}
+
+	if decision.Verdict.IsPenalty() && incident.SessionFile != "" {
+		if err := sm.applyDecision(incident, decision); err != nil {
+			return nil, err
+		}
+	}
+
+	incident.Decisions = append(incident.Decisions, decision)
+	incident.Updated = decision.Time
+
+	for _, sessionIncident := range sm.sessionIncidents {
+		if sessionIncident.ID == incident.ID {
+			sessionIncident.Decisions = incident.Decisions
+		}
+	}
+
+	if err := sm.store.UpsertIncident(incident); err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+func (sm *StewardsManager) applyDecision(incident *Incident, decision *IncidentDecision) error {
+	car, err := incident.findCar(decision.PenalisedDriverGUID)
+
+	if err != nil {
+		return err
+	}
+
+	penalty := &Penalty{
+		Type:       PenaltyTypeTime,
+		Time:       decision.PenaltyTime,
+		Reason:     incident.Summary(),
+		Steward:    decision.Steward,
+		IncidentID: incident.ID.String(),
+	}
+
+	if decision.Verdict == IncidentVerdictDisqualified {
+		penalty.Type = PenaltyTypeDisqualification
+		penalty.Time = 0
+	}
+
+	if decision.Notes != "" {
+		penalty.Reason += ": " + decision.Notes
+	}
+
+	if err := sm.penaltiesManager.AddPenalty(incident.SessionFile, string(car.DriverGUID), car.CarModel, penalty); err != nil {
+		return err
+	}
+
+	decision.Applied = true
+
+	return nil
+}
+
+func (sm *StewardsManager) removePenalty(incident *Incident, decision *IncidentDecision, steward string) error {
+	car, err := incident.findCar(decision.PenalisedDriverGUID)
+
+	if err != nil {
+		return err
+	}
+
+	return sm.penaltiesManager.RevokeIncidentPenalties(incident.SessionFile, string(car.DriverGUID), car.CarModel, incident.ID.String(), steward, "Decision changed")
+}
+
+// ListIncidents returns all incidents, with those that are yet to be reviewed first.
+func (sm *StewardsManager) ListIncidents() (pending []*Incident, reviewed []*Incident, err error) {
+	incidents, err := sm.store.ListIncidents()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, incident := range incidents {
+		if incident.Reviewed() {
+			reviewed = append(reviewed, incident)
+		} else {
+			pending = append(pending, incident)
+		}
+	}
+
+	return pending, reviewed, nil
+}
+
+func (sm *StewardsManager) LoadIncident(id string) (*Incident, error) {
+	return sm.store.LoadIncident(id)
+}
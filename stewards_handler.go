@@ -0,0 +1,142 @@
+package servermanager
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/sirupsen/logrus"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+type StewardsHandler struct {
+	*BaseHandler
+
+	stewardsManager *StewardsManager
+}
+
+func NewStewardsHandler(baseHandler *BaseHandler, stewardsManager *StewardsManager) *StewardsHandler {
+	return &StewardsHandler{
+		BaseHandler:     baseHandler,
+		stewardsManager: stewardsManager,
+	}
+}
+
+type stewardsIncidentsTemplateVars struct {
+	BaseTemplateVars
+
+	PendingIncidents  []*Incident
+	ReviewedIncidents []*Incident
+	UseMPH            bool
+}
+
+// incidents is the stewards' review queue.
+func (sh *StewardsHandler) incidents(w http.ResponseWriter, r *http.Request) {
+	pending, reviewed, err := sh.stewardsManager.ListIncidents()
+
+	if err != nil {
+		logrus.WithError(err).Error("couldn't list incidents")
+		AddErrorFlash(w, r, "Couldn't load incidents")
+	}
+
+	serverOpts, err := sh.stewardsManager.store.LoadServerOptions()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't load server options")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	sh.viewRenderer.MustLoadTemplate(w, r, "stewards/incidents.html", &stewardsIncidentsTemplateVars{
+		BaseTemplateVars: BaseTemplateVars{
+			WideContainer: true,
+		},
+		PendingIncidents:  pending,
+		ReviewedIncidents: reviewed,
+		UseMPH:            serverOpts.UseMPH == 1,
+	})
+}
+
+type stewardsIncidentTemplateVars struct {
+	BaseTemplateVars
+
+	Incident *Incident
+	Verdicts []IncidentVerdict
+	UseMPH   bool
+}
+
+func (sh *StewardsHandler) incident(w http.ResponseWriter, r *http.Request) {
+	incident, err := sh.stewardsManager.LoadIncident(chi.URLParam(r, "incidentID"))
+
+	if err == ErrIncidentNotFound {
+		http.NotFound(w, r)
+		return
+	} else if err != nil {
+		logrus.WithError(err).Error("couldn't load incident")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	serverOpts, err := sh.stewardsManager.store.LoadServerOptions()
+
+	if err != nil {
+		logrus.WithError(err).Errorf("couldn't load server options")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	sh.viewRenderer.MustLoadTemplate(w, r, "stewards/incident.html", &stewardsIncidentTemplateVars{
+		Incident: incident,
+		Verdicts: IncidentVerdicts,
+		UseMPH:   serverOpts.UseMPH == 1,
+	})
+}
+
+func (sh *StewardsHandler) decide(w http.ResponseWriter, r *http.Request) {
+	incidentID := chi.URLParam(r, "incidentID")
+
+	if err := r.ParseForm(); err != nil {
+		logrus.WithError(err).Errorf("could not parse incident decision form")
+		AddErrorFlash(w, r, "Could not parse decision form")
+		http.Redirect(w, r, r.Referer(), http.StatusFound)
+		return
+	}
+
+	decision := &IncidentDecision{
+		Steward:             AccountFromRequest(r).Name,
+		Verdict:             IncidentVerdict(r.FormValue("Verdict")),
+		Notes:               r.FormValue("Notes"),
+		PenalisedDriverGUID: udp.DriverGUID(r.FormValue("PenalisedDriverGUID")),
+	}
+
+	if decision.Verdict == IncidentVerdictTimePenalty {
+		penalty, err := strconv.ParseFloat(r.FormValue("PenaltyTime"), 64)
+
+		if err != nil || penalty <= 0 {
+			AddErrorFlash(w, r, "A time penalty must be greater than zero seconds")
+			http.Redirect(w, r, r.Referer(), http.StatusFound)
+			return
+		}
+
+		decision.PenaltyTime = time.Duration(penalty * float64(time.Second))
+	}
+
+	incident, err := sh.stewardsManager.Decide(incidentID, decision)
+
+	if err != nil {
+		logrus.WithError(err).Errorf("could not record decision for incident: %s", incidentID)
+		AddErrorFlash(w, r, "Could not record decision")
+		http.Redirect(w, r, r.Referer(), http.StatusFound)
+		return
+	}
+
+	if decision.Verdict.IsPenalty() && !decision.Applied {
+		AddFlash(w, r, "Decision recorded. The penalty will be applied when the session ends.")
+	} else {
+		AddFlash(w, r, "Decision recorded")
+	}
+
+	http.Redirect(w, r, "/stewards/incident/"+incident.ID.String(), http.StatusFound)
+}
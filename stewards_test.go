@@ -0,0 +1,413 @@
+package servermanager
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/JustaPenguin/assetto-server-manager/pkg/udp"
+)
+
+// enableStewarding turns on stewarding, which is off by default.
+func enableStewarding(store Store) error {
+	serverOpts, err := store.LoadServerOptions()
+
+	if err != nil {
+		return err
+	}
+
+	serverOpts.EnableStewarding = 1
+
+	return store.UpsertServerOptions(serverOpts)
+}
+
+func TestStewardsManager(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asm-stewards-store")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	defer os.RemoveAll(dir)
+
+	store := NewJSONStore(dir, dir)
+
+	if err := enableStewarding(store); err != nil {
+		t.Error(err)
+		return
+	}
+
+	resultsRevisionManager := NewResultsRevisionManager(store, NewResultsIndex(store))
+	penaltiesManager := NewPenaltiesManager(store, resultsRevisionManager)
+	raceControl := NewRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, store, penaltiesManager, resultsRevisionManager)
+	stewardsManager := NewStewardsManager(store, raceControl, penaltiesManager)
+
+	sessionInfo := udp.SessionInfo{
+		Version:     4,
+		Track:       "ks_laguna_seca",
+		Name:        "Test Race",
+		Type:        udp.SessionTypeRace,
+		Laps:        10,
+		AmbientTemp: 12,
+		RoadTemp:    16,
+		EventType:   udp.EventNewSession,
+	}
+
+	if err := raceControl.OnNewSession(sessionInfo); err != nil {
+		t.Error(err)
+		return
+	}
+
+	stewardsManager.UDPCallback(sessionInfo)
+
+	// cars 1, 2 and 3 are close together, car 4 is on the other side of the track.
+	positions := []udp.Vec{{X: 0, Z: 0}, {X: 5, Z: 0}, {X: 30, Z: 40}, {X: 500, Z: 500}}
+
+	for i, entrant := range drivers[:len(positions)] {
+		if err := raceControl.OnClientConnect(entrant); err != nil {
+			t.Error(err)
+			return
+		}
+
+		if err := raceControl.OnClientLoaded(udp.ClientLoaded(entrant.CarID)); err != nil {
+			t.Error(err)
+			return
+		}
+
+		err := raceControl.handleCarUpdate(udp.CarUpdate{
+			CarID:               entrant.CarID,
+			Pos:                 positions[i],
+			Velocity:            udp.Vec{X: 30},
+			NormalisedSplinePos: 0.5,
+		})
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	collide := func(carID, otherCarID udp.CarID, impactSpeed float32) {
+		collision := udp.CollisionWithCar{
+			CarID:       carID,
+			OtherCarID:  otherCarID,
+			ImpactSpeed: impactSpeed,
+			WorldPos:    udp.Vec{X: 2, Z: 0},
+		}
+
+		raceControl.UDPCallback(collision)
+		stewardsManager.UDPCallback(collision)
+	}
+
+	t.Run("Contacts below the minimum impact speed are ignored", func(t *testing.T) {
+		collide(drivers[0].CarID, drivers[1].CarID, 1)
+
+		incidents, err := store.ListIncidents()
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(incidents) != 0 {
+			t.Errorf("Expected no incidents, got %d", len(incidents))
+		}
+	})
+
+	var incident *Incident
+
+	t.Run("Contacts between the same cars are grouped", func(t *testing.T) {
+		collide(drivers[0].CarID, drivers[1].CarID, 10)
+		collide(drivers[1].CarID, drivers[0].CarID, 15)
+
+		incidents, err := store.ListIncidents()
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(incidents) != 1 {
+			t.Errorf("Expected 1 incident, got %d", len(incidents))
+			return
+		}
+
+		incident = incidents[0]
+
+		if incident.NumContacts != 2 {
+			t.Errorf("Expected 2 contacts, got %d", incident.NumContacts)
+		}
+
+		if incident.ImpactSpeed != metersPerSecondToKilometersPerHour(15) {
+			t.Errorf("Expected impact speed to be the highest of the contacts, got %.1f", incident.ImpactSpeed)
+		}
+
+		if incident.Car.DriverGUID != drivers[0].DriverGUID || incident.OtherCar.DriverGUID != drivers[1].DriverGUID {
+			t.Errorf("Incorrect drivers in incident")
+		}
+
+		if len(incident.NearbyCars) != 1 || incident.NearbyCars[0].DriverGUID != drivers[2].DriverGUID {
+			t.Errorf("Expected only car 3 to be nearby, got: %v", incident.NearbyCars)
+		}
+
+		if incident.Car.SplinePosition != 0.5 || incident.Car.Speed == 0 {
+			t.Errorf("Car snapshot is missing position or speed")
+		}
+	})
+
+	if incident == nil {
+		return
+	}
+
+	t.Run("Penalties are not applied until the session ends", func(t *testing.T) {
+		incident, err := stewardsManager.Decide(incident.ID.String(), &IncidentDecision{
+			Verdict:             IncidentVerdictTimePenalty,
+			PenalisedDriverGUID: drivers[1].DriverGUID,
+			PenaltyTime:         time.Second * 5,
+		})
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if decision := incident.LatestDecision(); decision == nil || decision.Applied {
+			t.Errorf("Expected an unapplied decision")
+		}
+	})
+
+	t.Run("Penalties must be for a driver in the incident", func(t *testing.T) {
+		_, err := stewardsManager.Decide(incident.ID.String(), &IncidentDecision{
+			Verdict:             IncidentVerdictDisqualified,
+			PenalisedDriverGUID: drivers[2].DriverGUID,
+		})
+
+		if err != ErrIncidentDriverNotFound {
+			t.Errorf("Expected ErrIncidentDriverNotFound, got: %v", err)
+		}
+	})
+
+	t.Run("Decision history is kept", func(t *testing.T) {
+		_, err := stewardsManager.Decide(incident.ID.String(), &IncidentDecision{
+			Verdict: IncidentVerdictRacingIncident,
+		})
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		incident, err := store.LoadIncident(incident.ID.String())
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(incident.Decisions) != 2 || incident.LatestDecision().Verdict != IncidentVerdictRacingIncident {
+			t.Errorf("Incorrect decision history: %v", incident.Decisions)
+		}
+	})
+}
+
+func TestStewardsManager_FullCourseYellow(t *testing.T) {
+	dir, err := ioutil.TempDir("", "asm-stewards-fcy-store")
+
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	defer os.RemoveAll(dir)
+
+	store := NewJSONStore(dir, dir)
+
+	if err := enableStewarding(store); err != nil {
+		t.Error(err)
+		return
+	}
+
+	resultsRevisionManager := NewResultsRevisionManager(store, NewResultsIndex(store))
+	penaltiesManager := NewPenaltiesManager(store, resultsRevisionManager)
+	raceControl := newRaceControl(NilBroadcaster{}, nilTrackData{}, dummyServerProcess{}, store, penaltiesManager, resultsRevisionManager)
+	stewardsManager := NewStewardsManager(store, raceControl, penaltiesManager)
+
+	t.Run("Full Course Yellows can only be started in races", func(t *testing.T) {
+		if err := raceControl.StartFullCourseYellow("admin"); err != ErrFullCourseYellowNotRace {
+			t.Errorf("Expected ErrFullCourseYellowNotRace, got: %v", err)
+		}
+	})
+
+	// the clock is read while handling car updates, so it is only moved on between them.
+	clock := &replayClock{}
+	clock.set(time.Now())
+	raceControl.now = clock.now
+
+	advance := func(d time.Duration) {
+		clock.set(clock.now().Add(d))
+	}
+
+	sessionInfo := udp.SessionInfo{
+		Version:   4,
+		Track:     "ks_laguna_seca",
+		Name:      "Test Race",
+		Type:      udp.SessionTypeRace,
+		Laps:      10,
+		EventType: udp.EventNewSession,
+	}
+
+	if err := raceControl.OnNewSession(sessionInfo); err != nil {
+		t.Error(err)
+		return
+	}
+
+	stewardsManager.UDPCallback(sessionInfo)
+
+	// car 1 leads car 2, which leads car 3.
+	splinePositions := map[udp.CarID]float32{1: 0.5, 2: 0.4, 3: 0.3}
+
+	for _, entrant := range drivers[:3] {
+		if err := raceControl.OnClientConnect(entrant); err != nil {
+			t.Error(err)
+			return
+		}
+
+		if err := raceControl.OnClientLoaded(udp.ClientLoaded(entrant.CarID)); err != nil {
+			t.Error(err)
+			return
+		}
+
+		raceControl.gapTracker.update(entrant.CarID, entrant.DriverGUID, splinePositions[entrant.CarID], clock.now())
+	}
+
+	carUpdate := func(carID udp.CarID, speed float32) {
+		update := udp.CarUpdate{
+			CarID:               carID,
+			Velocity:            udp.Vec{X: speed},
+			NormalisedSplinePos: splinePositions[carID],
+		}
+
+		// the update is handled straight away, rather than by the car's update goroutine, so that it has been
+		// handled before the stewards see it.
+		if err := raceControl.handleCarUpdate(update); err != nil {
+			t.Error(err)
+		}
+
+		stewardsManager.UDPCallback(update)
+	}
+
+	numIncidents := func() int {
+		incidents, err := store.ListIncidents()
+
+		if err != nil {
+			t.Error(err)
+		}
+
+		return len(incidents)
+	}
+
+	if err := raceControl.StartFullCourseYellow("admin"); err != nil {
+		t.Error(err)
+		return
+	}
+
+	raceControl.FullCourseYellows[0].SpeedLimit = 80
+
+	t.Run("Only one Full Course Yellow can be active", func(t *testing.T) {
+		if err := raceControl.StartFullCourseYellow("admin"); err != ErrFullCourseYellowActive {
+			t.Errorf("Expected ErrFullCourseYellowActive, got: %v", err)
+		}
+
+		fullCourseYellow := raceControl.activeFullCourseYellow()
+
+		if fullCourseYellow == nil || len(fullCourseYellow.Drivers) != 3 || fullCourseYellow.Drivers[0].StartLap != 1 {
+			t.Errorf("Expected the laps of 3 drivers to be recorded, got: %v", fullCourseYellow)
+		}
+	})
+
+	t.Run("Drivers are given time to slow down", func(t *testing.T) {
+		carUpdate(1, 40)
+
+		if n := numIncidents(); n != 0 {
+			t.Errorf("Expected no incidents, got %d", n)
+		}
+	})
+
+	t.Run("Overtakes are flagged for review", func(t *testing.T) {
+		advance(2 * time.Second)
+		splinePositions[3] = 0.45
+		raceControl.gapTracker.update(3, drivers[2].DriverGUID, splinePositions[3], clock.now())
+
+		carUpdate(3, 10)
+
+		incidents, err := store.ListIncidents()
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		if len(incidents) != 1 {
+			t.Errorf("Expected 1 incident, got %d", len(incidents))
+			return
+		}
+
+		incident := incidents[0]
+
+		if incident.Type != IncidentTypeFullCourseYellow || incident.Car.CarID != 3 || incident.OtherCar.CarID != 2 {
+			t.Errorf("Expected car 3 to have overtaken car 2, got: %s (%d passed %d)", incident.Type, incident.Car.CarID, incident.OtherCar.CarID)
+		}
+	})
+
+	t.Run("Speeding is flagged once per car", func(t *testing.T) {
+		advance(fullCourseYellowGracePeriod)
+
+		carUpdate(1, 10)
+		carUpdate(1, 30)
+		created := clock.now()
+		advance(time.Second)
+		carUpdate(1, 40)
+
+		if n := numIncidents(); n != 2 {
+			t.Errorf("Expected 2 incidents, got %d", n)
+			return
+		}
+
+		incidents, err := store.ListIncidents()
+
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		for _, incident := range incidents {
+			if incident.Car.CarID == 1 && (incident.HasOtherCar() || incident.Car.Speed != metersPerSecondToKilometersPerHour(40)) {
+				t.Errorf("Expected the speeding incident to record the top speed of car 1 only, got: %.1f", incident.Car.Speed)
+			}
+
+			if incident.Car.CarID == 1 && (!incident.Created.Equal(created) || !incident.Updated.Equal(clock.now()) || incident.SessionName != sessionInfo.Name) {
+				t.Errorf("Expected the speeding incident to be timed by race control, got: created %s, updated %s in %q", incident.Created, incident.Updated, incident.SessionName)
+			}
+		}
+	})
+
+	t.Run("Ending a Full Course Yellow", func(t *testing.T) {
+		if err := raceControl.EndFullCourseYellow(); err != nil {
+			t.Error(err)
+			return
+		}
+
+		if err := raceControl.EndFullCourseYellow(); err != ErrFullCourseYellowNotActive {
+			t.Errorf("Expected ErrFullCourseYellowNotActive, got: %v", err)
+		}
+
+		advance(time.Second)
+		carUpdate(2, 50)
+
+		if n := numIncidents(); n != 2 {
+			t.Errorf("Expected no more incidents after the Full Course Yellow, got %d", n)
+		}
+	})
+}
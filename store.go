@@ -21,6 +21,8 @@ type Store interface {
 	ListChampionships() ([]*Championship, error)
 	LoadChampionship(id string) (*Championship, error)
 	DeleteChampionship(id string) error
+	UpsertChampionshipStandingsHistory(history *ChampionshipStandingsHistory) error
+	LoadChampionshipStandingsHistory(championshipID string) (*ChampionshipStandingsHistory, error)
 
 	// Live Timings
 	UpsertLiveTimingsData(*LiveTimingsPersistedData) error
@@ -64,6 +66,21 @@ type Store interface {
 	// RealPenalty options
 	UpsertRealPenaltyOptions(rpc *RealPenaltyConfig) error
 	LoadRealPenaltyOptions() (*RealPenaltyConfig, error)
+
+	// Stewarding
+	UpsertIncident(incident *Incident) error
+	LoadIncident(id string) (*Incident, error)
+	ListIncidents() ([]*Incident, error)
+
+	// Results Index
+	UpsertResultsIndexEntries(entries []*ResultsIndexEntry) error
+	DeleteResultsIndexEntries(fileNames []string) error
+	ListResultsIndexEntries() ([]*ResultsIndexEntry, error)
+
+	// Results Revisions
+	AddResultsRevision(revision *ResultsRevision) error
+	LoadResultsRevision(sessionFile, id string) (*ResultsRevision, error)
+	ListResultsRevisions(sessionFile string) ([]*ResultsRevision, error)
 }
 
 func loadChampionshipRaceWeekends(championship *Championship, store Store) error {
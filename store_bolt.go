@@ -3,6 +3,7 @@ package servermanager
 import (
 	"encoding/json"
 	"errors"
+	"sort"
 	"time"
 
 	"github.com/etcd-io/bbolt"
@@ -25,6 +26,11 @@ var (
 	frameLinksBucketName    = []byte("frameLinks")
 	raceWeekendsBucketName  = []byte("raceWeekends")
 	liveTimingsBucketName   = []byte("liveTimings")
+	incidentsBucketName     = []byte("incidents")
+	resultsIndexBucketName  = []byte("resultsIndex")
+
+	resultsRevisionsBucketName      = []byte("resultsRevisions")
+	championshipStandingsBucketName = []byte("championshipStandings")
 
 	serverOptionsKey      = []byte("serverOptions")
 	strackerOptionsKey    = []byte("strackerOptions")
@@ -451,6 +457,66 @@ func (rs *BoltStore) DeleteChampionship(id string) error {
 	return rs.UpsertChampionship(championship)
 }
 
+func (rs *BoltStore) championshipStandingsBucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
+	if !tx.Writable() {
+		bkt := tx.Bucket(championshipStandingsBucketName)
+
+		if bkt == nil {
+			return nil, bbolt.ErrBucketNotFound
+		}
+
+		return bkt, nil
+	}
+
+	return tx.CreateBucketIfNotExists(championshipStandingsBucketName)
+}
+
+func (rs *BoltStore) UpsertChampionshipStandingsHistory(history *ChampionshipStandingsHistory) error {
+	return rs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := rs.championshipStandingsBucket(tx)
+
+		if err != nil {
+			return err
+		}
+
+		data, err := rs.encode(history)
+
+		if err != nil {
+			return err
+		}
+
+		return b.Put([]byte(history.ChampionshipID.String()), data)
+	})
+}
+
+func (rs *BoltStore) LoadChampionshipStandingsHistory(championshipID string) (*ChampionshipStandingsHistory, error) {
+	var history *ChampionshipStandingsHistory
+
+	err := rs.db.View(func(tx *bbolt.Tx) error {
+		b, err := rs.championshipStandingsBucket(tx)
+
+		if err == bbolt.ErrBucketNotFound {
+			return ErrChampionshipStandingsHistoryNotFound
+		} else if err != nil {
+			return err
+		}
+
+		data := b.Get([]byte(championshipID))
+
+		if data == nil {
+			return ErrChampionshipStandingsHistoryNotFound
+		}
+
+		return rs.decode(data, &history)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
 func (rs *BoltStore) accountsBucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
 	if !tx.Writable() {
 		bkt := tx.Bucket(accountsBucketName)
@@ -1045,3 +1111,297 @@ func (rs *BoltStore) ClearLastRaceEvent() error {
 		return bkt.Delete(lastRaceEventKey)
 	})
 }
+
+func (rs *BoltStore) incidentsBucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
+	if !tx.Writable() {
+		bkt := tx.Bucket(incidentsBucketName)
+
+		if bkt == nil {
+			return nil, bbolt.ErrBucketNotFound
+		}
+
+		return bkt, nil
+	}
+
+	return tx.CreateBucketIfNotExists(incidentsBucketName)
+}
+
+func (rs *BoltStore) UpsertIncident(incident *Incident) error {
+	return rs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := rs.incidentsBucket(tx)
+
+		if err != nil {
+			return err
+		}
+
+		data, err := rs.encode(incident)
+
+		if err != nil {
+			return err
+		}
+
+		return b.Put([]byte(incident.ID.String()), data)
+	})
+}
+
+func (rs *BoltStore) LoadIncident(id string) (*Incident, error) {
+	var incident *Incident
+
+	err := rs.db.View(func(tx *bbolt.Tx) error {
+		b, err := rs.incidentsBucket(tx)
+
+		if err == bbolt.ErrBucketNotFound {
+			return ErrIncidentNotFound
+		} else if err != nil {
+			return err
+		}
+
+		data := b.Get([]byte(id))
+
+		if data == nil {
+			return ErrIncidentNotFound
+		}
+
+		return rs.decode(data, &incident)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+func (rs *BoltStore) ListIncidents() ([]*Incident, error) {
+	var incidents []*Incident
+
+	err := rs.db.View(func(tx *bbolt.Tx) error {
+		b, err := rs.incidentsBucket(tx)
+
+		if err == bbolt.ErrBucketNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			var incident *Incident
+
+			if err := rs.decode(v, &incident); err != nil {
+				return err
+			}
+
+			incidents = append(incidents, incident)
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(incidents, func(i, j int) bool {
+		return incidents[i].Time.After(incidents[j].Time)
+	})
+
+	return incidents, nil
+}
+
+func (rs *BoltStore) resultsIndexBucket(tx *bbolt.Tx) (*bbolt.Bucket, error) {
+	if !tx.Writable() {
+		bkt := tx.Bucket(resultsIndexBucketName)
+
+		if bkt == nil {
+			return nil, bbolt.ErrBucketNotFound
+		}
+
+		return bkt, nil
+	}
+
+	return tx.CreateBucketIfNotExists(resultsIndexBucketName)
+}
+
+func (rs *BoltStore) UpsertResultsIndexEntries(entries []*ResultsIndexEntry) error {
+	return rs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := rs.resultsIndexBucket(tx)
+
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			data, err := rs.encode(entry)
+
+			if err != nil {
+				return err
+			}
+
+			if err := b.Put([]byte(entry.FileName), data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (rs *BoltStore) DeleteResultsIndexEntries(fileNames []string) error {
+	return rs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := rs.resultsIndexBucket(tx)
+
+		if err != nil {
+			return err
+		}
+
+		for _, fileName := range fileNames {
+			if err := b.Delete([]byte(fileName)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (rs *BoltStore) ListResultsIndexEntries() ([]*ResultsIndexEntry, error) {
+	var entries []*ResultsIndexEntry
+
+	err := rs.db.View(func(tx *bbolt.Tx) error {
+		b, err := rs.resultsIndexBucket(tx)
+
+		if err == bbolt.ErrBucketNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			var entry *ResultsIndexEntry
+
+			if err := rs.decode(v, &entry); err != nil {
+				return err
+			}
+
+			entries = append(entries, entry)
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sortResultsIndexEntries(entries)
+
+	return entries, nil
+}
+
+// resultsRevisionsBucket is a bucket of results revisions for the given results file, nested in the results
+// revisions bucket.
+func (rs *BoltStore) resultsRevisionsBucket(tx *bbolt.Tx, sessionFile string) (*bbolt.Bucket, error) {
+	if !tx.Writable() {
+		bkt := tx.Bucket(resultsRevisionsBucketName)
+
+		if bkt == nil {
+			return nil, bbolt.ErrBucketNotFound
+		}
+
+		bkt = bkt.Bucket([]byte(sessionFile))
+
+		if bkt == nil {
+			return nil, bbolt.ErrBucketNotFound
+		}
+
+		return bkt, nil
+	}
+
+	bkt, err := tx.CreateBucketIfNotExists(resultsRevisionsBucketName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return bkt.CreateBucketIfNotExists([]byte(sessionFile))
+}
+
+func (rs *BoltStore) AddResultsRevision(revision *ResultsRevision) error {
+	return rs.db.Update(func(tx *bbolt.Tx) error {
+		b, err := rs.resultsRevisionsBucket(tx, revision.SessionFile)
+
+		if err != nil {
+			return err
+		}
+
+		data, err := rs.encode(revision)
+
+		if err != nil {
+			return err
+		}
+
+		return b.Put([]byte(revision.ID.String()), data)
+	})
+}
+
+func (rs *BoltStore) LoadResultsRevision(sessionFile, id string) (*ResultsRevision, error) {
+	var revision *ResultsRevision
+
+	err := rs.db.View(func(tx *bbolt.Tx) error {
+		b, err := rs.resultsRevisionsBucket(tx, sessionFile)
+
+		if err == bbolt.ErrBucketNotFound {
+			return ErrResultsRevisionNotFound
+		} else if err != nil {
+			return err
+		}
+
+		data := b.Get([]byte(id))
+
+		if data == nil {
+			return ErrResultsRevisionNotFound
+		}
+
+		return rs.decode(data, &revision)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+func (rs *BoltStore) ListResultsRevisions(sessionFile string) ([]*ResultsRevision, error) {
+	var revisions []*ResultsRevision
+
+	err := rs.db.View(func(tx *bbolt.Tx) error {
+		b, err := rs.resultsRevisionsBucket(tx, sessionFile)
+
+		if err == bbolt.ErrBucketNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		return b.ForEach(func(k, v []byte) error {
+			var revision *ResultsRevision
+
+			if err := rs.decode(v, &revision); err != nil {
+				return err
+			}
+
+			revisions = append(revisions, revision)
+
+			return nil
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	sortResultsRevisions(revisions)
+
+	return revisions, nil
+}
@@ -25,12 +25,16 @@ const (
 	realPenaltyOptionsFile = "realpenalty_options.json"
 	liveTimingsDataFile    = "live_timings.json"
 	lastRaceEventFile      = "last_race_event.json"
+	incidentsDir           = "incidents"
+	resultsIndexFile       = "results_index.json"
+	resultsRevisionsDir    = "results_revisions"
 
 	// shared data
-	championshipsDir = "championships"
-	raceWeekendsDir  = "race_weekends"
-	customRacesDir   = "custom_races"
-	entrantsFile     = "entrants.json"
+	championshipsDir         = "championships"
+	championshipStandingsDir = "championship_standings"
+	raceWeekendsDir          = "race_weekends"
+	customRacesDir           = "custom_races"
+	entrantsFile             = "entrants.json"
 )
 
 func NewJSONStore(dir string, sharedDir string) Store {
@@ -45,6 +49,9 @@ type JSONStore struct {
 	shared string
 
 	mutex sync.RWMutex
+
+	// resultsIndexMutex is held while the results index is read and rewritten.
+	resultsIndexMutex sync.Mutex
 }
 
 func (rs *JSONStore) listFiles(dir string) ([]string, error) {
@@ -364,6 +371,24 @@ func (rs *JSONStore) DeleteChampionship(id string) error {
 	return rs.UpsertChampionship(c)
 }
 
+func (rs *JSONStore) UpsertChampionshipStandingsHistory(history *ChampionshipStandingsHistory) error {
+	return rs.encodeFile(rs.shared, filepath.Join(championshipStandingsDir, history.ChampionshipID.String()+".json"), history)
+}
+
+func (rs *JSONStore) LoadChampionshipStandingsHistory(championshipID string) (*ChampionshipStandingsHistory, error) {
+	var history *ChampionshipStandingsHistory
+
+	err := rs.decodeFile(rs.shared, filepath.Join(championshipStandingsDir, championshipID+".json"), &history)
+
+	if os.IsNotExist(err) {
+		return nil, ErrChampionshipStandingsHistoryNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
 func (rs *JSONStore) UpsertLiveFrames(frameLinks []string) error {
 	return rs.encodeFile(rs.base, frameLinksFile, frameLinks)
 }
@@ -657,3 +682,157 @@ func (rs *JSONStore) ClearLastRaceEvent() error {
 
 	return err
 }
+
+func (rs *JSONStore) UpsertIncident(incident *Incident) error {
+	return rs.encodeFile(rs.base, filepath.Join(incidentsDir, incident.ID.String()+".json"), incident)
+}
+
+func (rs *JSONStore) LoadIncident(id string) (*Incident, error) {
+	var incident *Incident
+
+	err := rs.decodeFile(rs.base, filepath.Join(incidentsDir, id+".json"), &incident)
+
+	if os.IsNotExist(err) {
+		return nil, ErrIncidentNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return incident, nil
+}
+
+func (rs *JSONStore) ListIncidents() ([]*Incident, error) {
+	files, err := rs.listFiles(filepath.Join(rs.base, incidentsDir))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var incidents []*Incident
+
+	for _, file := range files {
+		incident, err := rs.LoadIncident(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		incidents = append(incidents, incident)
+	}
+
+	sort.Slice(incidents, func(i, j int) bool {
+		return incidents[i].Time.After(incidents[j].Time)
+	})
+
+	return incidents, nil
+}
+
+// loadResultsIndex reads the results index, keyed by results file name. rs.resultsIndexMutex must be held.
+func (rs *JSONStore) loadResultsIndex() (map[string]*ResultsIndexEntry, error) {
+	index := make(map[string]*ResultsIndexEntry)
+
+	err := rs.decodeFile(rs.base, resultsIndexFile, &index)
+
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return index, nil
+}
+
+func (rs *JSONStore) UpsertResultsIndexEntries(entries []*ResultsIndexEntry) error {
+	rs.resultsIndexMutex.Lock()
+	defer rs.resultsIndexMutex.Unlock()
+
+	index, err := rs.loadResultsIndex()
+
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		index[entry.FileName] = entry
+	}
+
+	return rs.encodeFile(rs.base, resultsIndexFile, index)
+}
+
+func (rs *JSONStore) DeleteResultsIndexEntries(fileNames []string) error {
+	rs.resultsIndexMutex.Lock()
+	defer rs.resultsIndexMutex.Unlock()
+
+	index, err := rs.loadResultsIndex()
+
+	if err != nil {
+		return err
+	}
+
+	for _, fileName := range fileNames {
+		delete(index, fileName)
+	}
+
+	return rs.encodeFile(rs.base, resultsIndexFile, index)
+}
+
+func (rs *JSONStore) ListResultsIndexEntries() ([]*ResultsIndexEntry, error) {
+	rs.resultsIndexMutex.Lock()
+	defer rs.resultsIndexMutex.Unlock()
+
+	index, err := rs.loadResultsIndex()
+
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]*ResultsIndexEntry, 0, len(index))
+
+	for _, entry := range index {
+		entries = append(entries, entry)
+	}
+
+	sortResultsIndexEntries(entries)
+
+	return entries, nil
+}
+
+func (rs *JSONStore) AddResultsRevision(revision *ResultsRevision) error {
+	return rs.encodeFile(rs.base, filepath.Join(resultsRevisionsDir, revision.SessionFile, revision.ID.String()+".json"), revision)
+}
+
+func (rs *JSONStore) LoadResultsRevision(sessionFile, id string) (*ResultsRevision, error) {
+	var revision *ResultsRevision
+
+	err := rs.decodeFile(rs.base, filepath.Join(resultsRevisionsDir, sessionFile, id+".json"), &revision)
+
+	if os.IsNotExist(err) {
+		return nil, ErrResultsRevisionNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return revision, nil
+}
+
+func (rs *JSONStore) ListResultsRevisions(sessionFile string) ([]*ResultsRevision, error) {
+	files, err := rs.listFiles(filepath.Join(rs.base, resultsRevisionsDir, sessionFile))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var revisions []*ResultsRevision
+
+	for _, file := range files {
+		revision, err := rs.LoadResultsRevision(sessionFile, file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	sortResultsRevisions(revisions)
+
+	return revisions, nil
+}
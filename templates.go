@@ -248,6 +248,9 @@ func (tr *Renderer) init() error {
 	funcs["trackMapURL"] = TrackMapImageURL
 	funcs["sunAngleToTimeOfDay"] = sunAngleToTimeOfDay
 	funcs["anonymiseDriverGUID"] = AnonymiseDriverGUID
+	funcs["penaltyTypes"] = func() []PenaltyType { return PenaltyTypes }
+	funcs["resultsExportFormats"] = func() []ResultsExportFormat { return ResultsExportFormats }
+	funcs["pointsSchemes"] = PointsSchemeOptions
 
 	tr.templates, err = tr.loader.Templates(funcs)
 